@@ -0,0 +1,6 @@
+// Package mailbeadsv1 holds the generated Go code for the mailbeads gRPC
+// API (mailbeads.proto), served by mb serve. Clients dial the server and
+// call it through NewMailbeadsClient.
+package mailbeadsv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative mailbeads.proto
@@ -0,0 +1,1292 @@
+// The mailbeads gRPC API, served by `mb serve`. It covers the core
+// workflow — sync, list untriaged threads, read a thread, triage, close —
+// with the same behavior as the matching mb commands: triage decisions
+// become beads issues, and every change lands in the undo journal and
+// audit log.
+//
+// Regenerate the Go code with `go generate ./api/...` (needs protoc,
+// protoc-gen-go, and protoc-gen-go-grpc).
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: mailbeads.proto
+
+package mailbeadsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CloseRequest_Outcome int32
+
+const (
+	// Unspecified closes as done.
+	CloseRequest_OUTCOME_UNSPECIFIED CloseRequest_Outcome = 0
+	CloseRequest_OUTCOME_DONE        CloseRequest_Outcome = 1
+	CloseRequest_OUTCOME_DISMISSED   CloseRequest_Outcome = 2
+)
+
+// Enum value maps for CloseRequest_Outcome.
+var (
+	CloseRequest_Outcome_name = map[int32]string{
+		0: "OUTCOME_UNSPECIFIED",
+		1: "OUTCOME_DONE",
+		2: "OUTCOME_DISMISSED",
+	}
+	CloseRequest_Outcome_value = map[string]int32{
+		"OUTCOME_UNSPECIFIED": 0,
+		"OUTCOME_DONE":        1,
+		"OUTCOME_DISMISSED":   2,
+	}
+)
+
+func (x CloseRequest_Outcome) Enum() *CloseRequest_Outcome {
+	p := new(CloseRequest_Outcome)
+	*p = x
+	return p
+}
+
+func (x CloseRequest_Outcome) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CloseRequest_Outcome) Descriptor() protoreflect.EnumDescriptor {
+	return file_mailbeads_proto_enumTypes[0].Descriptor()
+}
+
+func (CloseRequest_Outcome) Type() protoreflect.EnumType {
+	return &file_mailbeads_proto_enumTypes[0]
+}
+
+func (x CloseRequest_Outcome) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CloseRequest_Outcome.Descriptor instead.
+func (CloseRequest_Outcome) EnumDescriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{11, 0}
+}
+
+type SyncRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Account to sync; empty syncs every discovered account.
+	Account string `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	// Full rescans the last 72 hours instead of resuming.
+	Full        bool `protobuf:"varint,2,opt,name=full,proto3" json:"full,omitempty"`
+	IncludeSpam bool `protobuf:"varint,3,opt,name=include_spam,json=includeSpam,proto3" json:"include_spam,omitempty"`
+	// HeadersOnly stores headers and snippets without bodies.
+	HeadersOnly   bool `protobuf:"varint,4,opt,name=headers_only,json=headersOnly,proto3" json:"headers_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncRequest) Reset() {
+	*x = SyncRequest{}
+	mi := &file_mailbeads_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRequest) ProtoMessage() {}
+
+func (x *SyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRequest.ProtoReflect.Descriptor instead.
+func (*SyncRequest) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SyncRequest) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *SyncRequest) GetFull() bool {
+	if x != nil {
+		return x.Full
+	}
+	return false
+}
+
+func (x *SyncRequest) GetIncludeSpam() bool {
+	if x != nil {
+		return x.IncludeSpam
+	}
+	return false
+}
+
+func (x *SyncRequest) GetHeadersOnly() bool {
+	if x != nil {
+		return x.HeadersOnly
+	}
+	return false
+}
+
+type AccountSyncResult struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Account string                 `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Fetched int32                  `protobuf:"varint,2,opt,name=fetched,proto3" json:"fetched,omitempty"`
+	Skipped int32                  `protobuf:"varint,3,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	// Commented counts beads issues told about new mail on their thread.
+	Commented int32 `protobuf:"varint,4,opt,name=commented,proto3" json:"commented,omitempty"`
+	Notified  int32 `protobuf:"varint,5,opt,name=notified,proto3" json:"notified,omitempty"`
+	// Error is set when the account could not be synced.
+	Error         string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountSyncResult) Reset() {
+	*x = AccountSyncResult{}
+	mi := &file_mailbeads_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountSyncResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountSyncResult) ProtoMessage() {}
+
+func (x *AccountSyncResult) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountSyncResult.ProtoReflect.Descriptor instead.
+func (*AccountSyncResult) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AccountSyncResult) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *AccountSyncResult) GetFetched() int32 {
+	if x != nil {
+		return x.Fetched
+	}
+	return 0
+}
+
+func (x *AccountSyncResult) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *AccountSyncResult) GetCommented() int32 {
+	if x != nil {
+		return x.Commented
+	}
+	return 0
+}
+
+func (x *AccountSyncResult) GetNotified() int32 {
+	if x != nil {
+		return x.Notified
+	}
+	return 0
+}
+
+func (x *AccountSyncResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SyncResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accounts      []*AccountSyncResult   `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	TotalNew      int32                  `protobuf:"varint,2,opt,name=total_new,json=totalNew,proto3" json:"total_new,omitempty"`
+	TotalInDb     int32                  `protobuf:"varint,3,opt,name=total_in_db,json=totalInDb,proto3" json:"total_in_db,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncResponse) Reset() {
+	*x = SyncResponse{}
+	mi := &file_mailbeads_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncResponse) ProtoMessage() {}
+
+func (x *SyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncResponse.ProtoReflect.Descriptor instead.
+func (*SyncResponse) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SyncResponse) GetAccounts() []*AccountSyncResult {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetTotalNew() int32 {
+	if x != nil {
+		return x.TotalNew
+	}
+	return 0
+}
+
+func (x *SyncResponse) GetTotalInDb() int32 {
+	if x != nil {
+		return x.TotalInDb
+	}
+	return 0
+}
+
+type Thread struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	ThreadId   string                 `protobuf:"bytes,1,opt,name=thread_id,json=threadId,proto3" json:"thread_id,omitempty"`
+	Account    string                 `protobuf:"bytes,2,opt,name=account,proto3" json:"account,omitempty"`
+	Subject    string                 `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
+	Sender     string                 `protobuf:"bytes,4,opt,name=sender,proto3" json:"sender,omitempty"`
+	EmailCount int32                  `protobuf:"varint,5,opt,name=email_count,json=emailCount,proto3" json:"email_count,omitempty"`
+	// RFC 3339 date of the latest message.
+	LatestDate string `protobuf:"bytes,6,opt,name=latest_date,json=latestDate,proto3" json:"latest_date,omitempty"`
+	// BeadID is the thread's beads issue, if triaged.
+	BeadId string `protobuf:"bytes,7,opt,name=bead_id,json=beadId,proto3" json:"bead_id,omitempty"`
+	// Signs of spoofing among the thread's messages.
+	AuthWarnings []string `protobuf:"bytes,8,rep,name=auth_warnings,json=authWarnings,proto3" json:"auth_warnings,omitempty"`
+	// The local classifier's guess (mb train), if trained.
+	PredictedPriority   string  `protobuf:"bytes,9,opt,name=predicted_priority,json=predictedPriority,proto3" json:"predicted_priority,omitempty"`
+	PredictedConfidence float64 `protobuf:"fixed64,10,opt,name=predicted_confidence,json=predictedConfidence,proto3" json:"predicted_confidence,omitempty"`
+	SuggestedCategory   string  `protobuf:"bytes,11,opt,name=suggested_category,json=suggestedCategory,proto3" json:"suggested_category,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Thread) Reset() {
+	*x = Thread{}
+	mi := &file_mailbeads_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Thread) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Thread) ProtoMessage() {}
+
+func (x *Thread) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Thread.ProtoReflect.Descriptor instead.
+func (*Thread) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Thread) GetThreadId() string {
+	if x != nil {
+		return x.ThreadId
+	}
+	return ""
+}
+
+func (x *Thread) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *Thread) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *Thread) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *Thread) GetEmailCount() int32 {
+	if x != nil {
+		return x.EmailCount
+	}
+	return 0
+}
+
+func (x *Thread) GetLatestDate() string {
+	if x != nil {
+		return x.LatestDate
+	}
+	return ""
+}
+
+func (x *Thread) GetBeadId() string {
+	if x != nil {
+		return x.BeadId
+	}
+	return ""
+}
+
+func (x *Thread) GetAuthWarnings() []string {
+	if x != nil {
+		return x.AuthWarnings
+	}
+	return nil
+}
+
+func (x *Thread) GetPredictedPriority() string {
+	if x != nil {
+		return x.PredictedPriority
+	}
+	return ""
+}
+
+func (x *Thread) GetPredictedConfidence() float64 {
+	if x != nil {
+		return x.PredictedConfidence
+	}
+	return 0
+}
+
+func (x *Thread) GetSuggestedCategory() string {
+	if x != nil {
+		return x.SuggestedCategory
+	}
+	return ""
+}
+
+type ListUntriagedRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Account filter; empty lists every account.
+	Account string `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	// Limit caps the threads returned; 0 means 50.
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUntriagedRequest) Reset() {
+	*x = ListUntriagedRequest{}
+	mi := &file_mailbeads_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUntriagedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUntriagedRequest) ProtoMessage() {}
+
+func (x *ListUntriagedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUntriagedRequest.ProtoReflect.Descriptor instead.
+func (*ListUntriagedRequest) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListUntriagedRequest) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *ListUntriagedRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListUntriagedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Threads       []*Thread              `protobuf:"bytes,1,rep,name=threads,proto3" json:"threads,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUntriagedResponse) Reset() {
+	*x = ListUntriagedResponse{}
+	mi := &file_mailbeads_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUntriagedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUntriagedResponse) ProtoMessage() {}
+
+func (x *ListUntriagedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUntriagedResponse.ProtoReflect.Descriptor instead.
+func (*ListUntriagedResponse) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListUntriagedResponse) GetThreads() []*Thread {
+	if x != nil {
+		return x.Threads
+	}
+	return nil
+}
+
+type Email struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ThreadId string                 `protobuf:"bytes,2,opt,name=thread_id,json=threadId,proto3" json:"thread_id,omitempty"`
+	Account  string                 `protobuf:"bytes,3,opt,name=account,proto3" json:"account,omitempty"`
+	// RFC 822 Message-ID.
+	MessageId string `protobuf:"bytes,4,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Sender    string `protobuf:"bytes,5,opt,name=sender,proto3" json:"sender,omitempty"`
+	To        string `protobuf:"bytes,6,opt,name=to,proto3" json:"to,omitempty"`
+	Cc        string `protobuf:"bytes,7,opt,name=cc,proto3" json:"cc,omitempty"`
+	Subject   string `protobuf:"bytes,8,opt,name=subject,proto3" json:"subject,omitempty"`
+	Snippet   string `protobuf:"bytes,9,opt,name=snippet,proto3" json:"snippet,omitempty"`
+	Body      string `protobuf:"bytes,10,opt,name=body,proto3" json:"body,omitempty"`
+	// BodyMissing is set when only headers were synced and the body could
+	// not be fetched.
+	BodyMissing bool `protobuf:"varint,11,opt,name=body_missing,json=bodyMissing,proto3" json:"body_missing,omitempty"`
+	// RFC 3339 send date.
+	SentAt        string   `protobuf:"bytes,12,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`
+	Labels        []string `protobuf:"bytes,13,rep,name=labels,proto3" json:"labels,omitempty"`
+	IsRead        bool     `protobuf:"varint,14,opt,name=is_read,json=isRead,proto3" json:"is_read,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Email) Reset() {
+	*x = Email{}
+	mi := &file_mailbeads_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Email) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Email) ProtoMessage() {}
+
+func (x *Email) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Email.ProtoReflect.Descriptor instead.
+func (*Email) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Email) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Email) GetThreadId() string {
+	if x != nil {
+		return x.ThreadId
+	}
+	return ""
+}
+
+func (x *Email) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *Email) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *Email) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *Email) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Email) GetCc() string {
+	if x != nil {
+		return x.Cc
+	}
+	return ""
+}
+
+func (x *Email) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *Email) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+func (x *Email) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Email) GetBodyMissing() bool {
+	if x != nil {
+		return x.BodyMissing
+	}
+	return false
+}
+
+func (x *Email) GetSentAt() string {
+	if x != nil {
+		return x.SentAt
+	}
+	return ""
+}
+
+func (x *Email) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Email) GetIsRead() bool {
+	if x != nil {
+		return x.IsRead
+	}
+	return false
+}
+
+type GetThreadRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ThreadID may also be words from the subject or sender, as in mb show.
+	ThreadId string `protobuf:"bytes,1,opt,name=thread_id,json=threadId,proto3" json:"thread_id,omitempty"`
+	// Account is needed only if the thread exists in several accounts.
+	Account       string `protobuf:"bytes,2,opt,name=account,proto3" json:"account,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetThreadRequest) Reset() {
+	*x = GetThreadRequest{}
+	mi := &file_mailbeads_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetThreadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetThreadRequest) ProtoMessage() {}
+
+func (x *GetThreadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetThreadRequest.ProtoReflect.Descriptor instead.
+func (*GetThreadRequest) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetThreadRequest) GetThreadId() string {
+	if x != nil {
+		return x.ThreadId
+	}
+	return ""
+}
+
+func (x *GetThreadRequest) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+type GetThreadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Thread        *Thread                `protobuf:"bytes,1,opt,name=thread,proto3" json:"thread,omitempty"`
+	Emails        []*Email               `protobuf:"bytes,2,rep,name=emails,proto3" json:"emails,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetThreadResponse) Reset() {
+	*x = GetThreadResponse{}
+	mi := &file_mailbeads_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetThreadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetThreadResponse) ProtoMessage() {}
+
+func (x *GetThreadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetThreadResponse.ProtoReflect.Descriptor instead.
+func (*GetThreadResponse) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetThreadResponse) GetThread() *Thread {
+	if x != nil {
+		return x.Thread
+	}
+	return nil
+}
+
+func (x *GetThreadResponse) GetEmails() []*Email {
+	if x != nil {
+		return x.Emails
+	}
+	return nil
+}
+
+type TriageRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ThreadId string                 `protobuf:"bytes,1,opt,name=thread_id,json=threadId,proto3" json:"thread_id,omitempty"`
+	Account  string                 `protobuf:"bytes,2,opt,name=account,proto3" json:"account,omitempty"`
+	// Priority is high, medium (the default), low, or spam.
+	Priority string `protobuf:"bytes,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Action is the bead's title: what to do about the thread. Required.
+	Action     string `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Suggestion string `protobuf:"bytes,5,opt,name=suggestion,proto3" json:"suggestion,omitempty"`
+	AgentNotes string `protobuf:"bytes,6,opt,name=agent_notes,json=agentNotes,proto3" json:"agent_notes,omitempty"`
+	Category   string `protobuf:"bytes,7,opt,name=category,proto3" json:"category,omitempty"`
+	// Epic links the bead to a beads epic.
+	Epic string `protobuf:"bytes,8,opt,name=epic,proto3" json:"epic,omitempty"`
+	// Due is YYYY-MM-DD, today, tomorrow, or +Nd.
+	Due           string `protobuf:"bytes,9,opt,name=due,proto3" json:"due,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriageRequest) Reset() {
+	*x = TriageRequest{}
+	mi := &file_mailbeads_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriageRequest) ProtoMessage() {}
+
+func (x *TriageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriageRequest.ProtoReflect.Descriptor instead.
+func (*TriageRequest) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TriageRequest) GetThreadId() string {
+	if x != nil {
+		return x.ThreadId
+	}
+	return ""
+}
+
+func (x *TriageRequest) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *TriageRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *TriageRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *TriageRequest) GetSuggestion() string {
+	if x != nil {
+		return x.Suggestion
+	}
+	return ""
+}
+
+func (x *TriageRequest) GetAgentNotes() string {
+	if x != nil {
+		return x.AgentNotes
+	}
+	return ""
+}
+
+func (x *TriageRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *TriageRequest) GetEpic() string {
+	if x != nil {
+		return x.Epic
+	}
+	return ""
+}
+
+func (x *TriageRequest) GetDue() string {
+	if x != nil {
+		return x.Due
+	}
+	return ""
+}
+
+type TriageResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ThreadId string                 `protobuf:"bytes,1,opt,name=thread_id,json=threadId,proto3" json:"thread_id,omitempty"`
+	Account  string                 `protobuf:"bytes,2,opt,name=account,proto3" json:"account,omitempty"`
+	BeadId   string                 `protobuf:"bytes,3,opt,name=bead_id,json=beadId,proto3" json:"bead_id,omitempty"`
+	Action   string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Priority string                 `protobuf:"bytes,5,opt,name=priority,proto3" json:"priority,omitempty"`
+	Subject  string                 `protobuf:"bytes,6,opt,name=subject,proto3" json:"subject,omitempty"`
+	Due      string                 `protobuf:"bytes,7,opt,name=due,proto3" json:"due,omitempty"`
+	// Created is false when an existing bead was updated.
+	Created bool `protobuf:"varint,8,opt,name=created,proto3" json:"created,omitempty"`
+	// LinkedDuplicates counts copies of the thread in other accounts
+	// pointed at the bead.
+	LinkedDuplicates int32 `protobuf:"varint,9,opt,name=linked_duplicates,json=linkedDuplicates,proto3" json:"linked_duplicates,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *TriageResponse) Reset() {
+	*x = TriageResponse{}
+	mi := &file_mailbeads_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriageResponse) ProtoMessage() {}
+
+func (x *TriageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriageResponse.ProtoReflect.Descriptor instead.
+func (*TriageResponse) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TriageResponse) GetThreadId() string {
+	if x != nil {
+		return x.ThreadId
+	}
+	return ""
+}
+
+func (x *TriageResponse) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *TriageResponse) GetBeadId() string {
+	if x != nil {
+		return x.BeadId
+	}
+	return ""
+}
+
+func (x *TriageResponse) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *TriageResponse) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *TriageResponse) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *TriageResponse) GetDue() string {
+	if x != nil {
+		return x.Due
+	}
+	return ""
+}
+
+func (x *TriageResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+func (x *TriageResponse) GetLinkedDuplicates() int32 {
+	if x != nil {
+		return x.LinkedDuplicates
+	}
+	return 0
+}
+
+type CloseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BeadId        string                 `protobuf:"bytes,1,opt,name=bead_id,json=beadId,proto3" json:"bead_id,omitempty"`
+	Outcome       CloseRequest_Outcome   `protobuf:"varint,2,opt,name=outcome,proto3,enum=mailbeads.v1.CloseRequest_Outcome" json:"outcome,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseRequest) Reset() {
+	*x = CloseRequest{}
+	mi := &file_mailbeads_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseRequest) ProtoMessage() {}
+
+func (x *CloseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseRequest.ProtoReflect.Descriptor instead.
+func (*CloseRequest) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CloseRequest) GetBeadId() string {
+	if x != nil {
+		return x.BeadId
+	}
+	return ""
+}
+
+func (x *CloseRequest) GetOutcome() CloseRequest_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return CloseRequest_OUTCOME_UNSPECIFIED
+}
+
+type CloseResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	BeadId string                 `protobuf:"bytes,1,opt,name=bead_id,json=beadId,proto3" json:"bead_id,omitempty"`
+	// Outcome is "done" or "dismissed".
+	Outcome       string `protobuf:"bytes,2,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseResponse) Reset() {
+	*x = CloseResponse{}
+	mi := &file_mailbeads_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseResponse) ProtoMessage() {}
+
+func (x *CloseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mailbeads_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseResponse.ProtoReflect.Descriptor instead.
+func (*CloseResponse) Descriptor() ([]byte, []int) {
+	return file_mailbeads_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CloseResponse) GetBeadId() string {
+	if x != nil {
+		return x.BeadId
+	}
+	return ""
+}
+
+func (x *CloseResponse) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+var File_mailbeads_proto protoreflect.FileDescriptor
+
+const file_mailbeads_proto_rawDesc = "" +
+	"\n" +
+	"\x0fmailbeads.proto\x12\fmailbeads.v1\"\x81\x01\n" +
+	"\vSyncRequest\x12\x18\n" +
+	"\aaccount\x18\x01 \x01(\tR\aaccount\x12\x12\n" +
+	"\x04full\x18\x02 \x01(\bR\x04full\x12!\n" +
+	"\finclude_spam\x18\x03 \x01(\bR\vincludeSpam\x12!\n" +
+	"\fheaders_only\x18\x04 \x01(\bR\vheadersOnly\"\xb1\x01\n" +
+	"\x11AccountSyncResult\x12\x18\n" +
+	"\aaccount\x18\x01 \x01(\tR\aaccount\x12\x18\n" +
+	"\afetched\x18\x02 \x01(\x05R\afetched\x12\x18\n" +
+	"\askipped\x18\x03 \x01(\x05R\askipped\x12\x1c\n" +
+	"\tcommented\x18\x04 \x01(\x05R\tcommented\x12\x1a\n" +
+	"\bnotified\x18\x05 \x01(\x05R\bnotified\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\x88\x01\n" +
+	"\fSyncResponse\x12;\n" +
+	"\baccounts\x18\x01 \x03(\v2\x1f.mailbeads.v1.AccountSyncResultR\baccounts\x12\x1b\n" +
+	"\ttotal_new\x18\x02 \x01(\x05R\btotalNew\x12\x1e\n" +
+	"\vtotal_in_db\x18\x03 \x01(\x05R\ttotalInDb\"\x82\x03\n" +
+	"\x06Thread\x12\x1b\n" +
+	"\tthread_id\x18\x01 \x01(\tR\bthreadId\x12\x18\n" +
+	"\aaccount\x18\x02 \x01(\tR\aaccount\x12\x18\n" +
+	"\asubject\x18\x03 \x01(\tR\asubject\x12\x16\n" +
+	"\x06sender\x18\x04 \x01(\tR\x06sender\x12\x1f\n" +
+	"\vemail_count\x18\x05 \x01(\x05R\n" +
+	"emailCount\x12\x1f\n" +
+	"\vlatest_date\x18\x06 \x01(\tR\n" +
+	"latestDate\x12\x17\n" +
+	"\abead_id\x18\a \x01(\tR\x06beadId\x12#\n" +
+	"\rauth_warnings\x18\b \x03(\tR\fauthWarnings\x12-\n" +
+	"\x12predicted_priority\x18\t \x01(\tR\x11predictedPriority\x121\n" +
+	"\x14predicted_confidence\x18\n" +
+	" \x01(\x01R\x13predictedConfidence\x12-\n" +
+	"\x12suggested_category\x18\v \x01(\tR\x11suggestedCategory\"F\n" +
+	"\x14ListUntriagedRequest\x12\x18\n" +
+	"\aaccount\x18\x01 \x01(\tR\aaccount\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"G\n" +
+	"\x15ListUntriagedResponse\x12.\n" +
+	"\athreads\x18\x01 \x03(\v2\x14.mailbeads.v1.ThreadR\athreads\"\xda\x02\n" +
+	"\x05Email\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tthread_id\x18\x02 \x01(\tR\bthreadId\x12\x18\n" +
+	"\aaccount\x18\x03 \x01(\tR\aaccount\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x04 \x01(\tR\tmessageId\x12\x16\n" +
+	"\x06sender\x18\x05 \x01(\tR\x06sender\x12\x0e\n" +
+	"\x02to\x18\x06 \x01(\tR\x02to\x12\x0e\n" +
+	"\x02cc\x18\a \x01(\tR\x02cc\x12\x18\n" +
+	"\asubject\x18\b \x01(\tR\asubject\x12\x18\n" +
+	"\asnippet\x18\t \x01(\tR\asnippet\x12\x12\n" +
+	"\x04body\x18\n" +
+	" \x01(\tR\x04body\x12!\n" +
+	"\fbody_missing\x18\v \x01(\bR\vbodyMissing\x12\x17\n" +
+	"\asent_at\x18\f \x01(\tR\x06sentAt\x12\x16\n" +
+	"\x06labels\x18\r \x03(\tR\x06labels\x12\x17\n" +
+	"\ais_read\x18\x0e \x01(\bR\x06isRead\"I\n" +
+	"\x10GetThreadRequest\x12\x1b\n" +
+	"\tthread_id\x18\x01 \x01(\tR\bthreadId\x12\x18\n" +
+	"\aaccount\x18\x02 \x01(\tR\aaccount\"n\n" +
+	"\x11GetThreadResponse\x12,\n" +
+	"\x06thread\x18\x01 \x01(\v2\x14.mailbeads.v1.ThreadR\x06thread\x12+\n" +
+	"\x06emails\x18\x02 \x03(\v2\x13.mailbeads.v1.EmailR\x06emails\"\xfd\x01\n" +
+	"\rTriageRequest\x12\x1b\n" +
+	"\tthread_id\x18\x01 \x01(\tR\bthreadId\x12\x18\n" +
+	"\aaccount\x18\x02 \x01(\tR\aaccount\x12\x1a\n" +
+	"\bpriority\x18\x03 \x01(\tR\bpriority\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x1e\n" +
+	"\n" +
+	"suggestion\x18\x05 \x01(\tR\n" +
+	"suggestion\x12\x1f\n" +
+	"\vagent_notes\x18\x06 \x01(\tR\n" +
+	"agentNotes\x12\x1a\n" +
+	"\bcategory\x18\a \x01(\tR\bcategory\x12\x12\n" +
+	"\x04epic\x18\b \x01(\tR\x04epic\x12\x10\n" +
+	"\x03due\x18\t \x01(\tR\x03due\"\x87\x02\n" +
+	"\x0eTriageResponse\x12\x1b\n" +
+	"\tthread_id\x18\x01 \x01(\tR\bthreadId\x12\x18\n" +
+	"\aaccount\x18\x02 \x01(\tR\aaccount\x12\x17\n" +
+	"\abead_id\x18\x03 \x01(\tR\x06beadId\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x1a\n" +
+	"\bpriority\x18\x05 \x01(\tR\bpriority\x12\x18\n" +
+	"\asubject\x18\x06 \x01(\tR\asubject\x12\x10\n" +
+	"\x03due\x18\a \x01(\tR\x03due\x12\x18\n" +
+	"\acreated\x18\b \x01(\bR\acreated\x12+\n" +
+	"\x11linked_duplicates\x18\t \x01(\x05R\x10linkedDuplicates\"\xb2\x01\n" +
+	"\fCloseRequest\x12\x17\n" +
+	"\abead_id\x18\x01 \x01(\tR\x06beadId\x12<\n" +
+	"\aoutcome\x18\x02 \x01(\x0e2\".mailbeads.v1.CloseRequest.OutcomeR\aoutcome\"K\n" +
+	"\aOutcome\x12\x17\n" +
+	"\x13OUTCOME_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fOUTCOME_DONE\x10\x01\x12\x15\n" +
+	"\x11OUTCOME_DISMISSED\x10\x02\"B\n" +
+	"\rCloseResponse\x12\x17\n" +
+	"\abead_id\x18\x01 \x01(\tR\x06beadId\x12\x18\n" +
+	"\aoutcome\x18\x02 \x01(\tR\aoutcome2\xf9\x02\n" +
+	"\tMailbeads\x12=\n" +
+	"\x04Sync\x12\x19.mailbeads.v1.SyncRequest\x1a\x1a.mailbeads.v1.SyncResponse\x12X\n" +
+	"\rListUntriaged\x12\".mailbeads.v1.ListUntriagedRequest\x1a#.mailbeads.v1.ListUntriagedResponse\x12L\n" +
+	"\tGetThread\x12\x1e.mailbeads.v1.GetThreadRequest\x1a\x1f.mailbeads.v1.GetThreadResponse\x12C\n" +
+	"\x06Triage\x12\x1b.mailbeads.v1.TriageRequest\x1a\x1c.mailbeads.v1.TriageResponse\x12@\n" +
+	"\x05Close\x12\x1a.mailbeads.v1.CloseRequest\x1a\x1b.mailbeads.v1.CloseResponseB<Z:github.com/daviddao/mailbeads/api/mailbeads/v1;mailbeadsv1b\x06proto3"
+
+var (
+	file_mailbeads_proto_rawDescOnce sync.Once
+	file_mailbeads_proto_rawDescData []byte
+)
+
+func file_mailbeads_proto_rawDescGZIP() []byte {
+	file_mailbeads_proto_rawDescOnce.Do(func() {
+		file_mailbeads_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_mailbeads_proto_rawDesc), len(file_mailbeads_proto_rawDesc)))
+	})
+	return file_mailbeads_proto_rawDescData
+}
+
+var file_mailbeads_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_mailbeads_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_mailbeads_proto_goTypes = []any{
+	(CloseRequest_Outcome)(0),     // 0: mailbeads.v1.CloseRequest.Outcome
+	(*SyncRequest)(nil),           // 1: mailbeads.v1.SyncRequest
+	(*AccountSyncResult)(nil),     // 2: mailbeads.v1.AccountSyncResult
+	(*SyncResponse)(nil),          // 3: mailbeads.v1.SyncResponse
+	(*Thread)(nil),                // 4: mailbeads.v1.Thread
+	(*ListUntriagedRequest)(nil),  // 5: mailbeads.v1.ListUntriagedRequest
+	(*ListUntriagedResponse)(nil), // 6: mailbeads.v1.ListUntriagedResponse
+	(*Email)(nil),                 // 7: mailbeads.v1.Email
+	(*GetThreadRequest)(nil),      // 8: mailbeads.v1.GetThreadRequest
+	(*GetThreadResponse)(nil),     // 9: mailbeads.v1.GetThreadResponse
+	(*TriageRequest)(nil),         // 10: mailbeads.v1.TriageRequest
+	(*TriageResponse)(nil),        // 11: mailbeads.v1.TriageResponse
+	(*CloseRequest)(nil),          // 12: mailbeads.v1.CloseRequest
+	(*CloseResponse)(nil),         // 13: mailbeads.v1.CloseResponse
+}
+var file_mailbeads_proto_depIdxs = []int32{
+	2,  // 0: mailbeads.v1.SyncResponse.accounts:type_name -> mailbeads.v1.AccountSyncResult
+	4,  // 1: mailbeads.v1.ListUntriagedResponse.threads:type_name -> mailbeads.v1.Thread
+	4,  // 2: mailbeads.v1.GetThreadResponse.thread:type_name -> mailbeads.v1.Thread
+	7,  // 3: mailbeads.v1.GetThreadResponse.emails:type_name -> mailbeads.v1.Email
+	0,  // 4: mailbeads.v1.CloseRequest.outcome:type_name -> mailbeads.v1.CloseRequest.Outcome
+	1,  // 5: mailbeads.v1.Mailbeads.Sync:input_type -> mailbeads.v1.SyncRequest
+	5,  // 6: mailbeads.v1.Mailbeads.ListUntriaged:input_type -> mailbeads.v1.ListUntriagedRequest
+	8,  // 7: mailbeads.v1.Mailbeads.GetThread:input_type -> mailbeads.v1.GetThreadRequest
+	10, // 8: mailbeads.v1.Mailbeads.Triage:input_type -> mailbeads.v1.TriageRequest
+	12, // 9: mailbeads.v1.Mailbeads.Close:input_type -> mailbeads.v1.CloseRequest
+	3,  // 10: mailbeads.v1.Mailbeads.Sync:output_type -> mailbeads.v1.SyncResponse
+	6,  // 11: mailbeads.v1.Mailbeads.ListUntriaged:output_type -> mailbeads.v1.ListUntriagedResponse
+	9,  // 12: mailbeads.v1.Mailbeads.GetThread:output_type -> mailbeads.v1.GetThreadResponse
+	11, // 13: mailbeads.v1.Mailbeads.Triage:output_type -> mailbeads.v1.TriageResponse
+	13, // 14: mailbeads.v1.Mailbeads.Close:output_type -> mailbeads.v1.CloseResponse
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_mailbeads_proto_init() }
+func file_mailbeads_proto_init() {
+	if File_mailbeads_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_mailbeads_proto_rawDesc), len(file_mailbeads_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_mailbeads_proto_goTypes,
+		DependencyIndexes: file_mailbeads_proto_depIdxs,
+		EnumInfos:         file_mailbeads_proto_enumTypes,
+		MessageInfos:      file_mailbeads_proto_msgTypes,
+	}.Build()
+	File_mailbeads_proto = out.File
+	file_mailbeads_proto_goTypes = nil
+	file_mailbeads_proto_depIdxs = nil
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/classify"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/llm"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	autoTriageAccount   string
+	autoTriageLimit     int
+	autoTriageReview    bool
+	autoTriageDryRun    bool
+	autoTriageMaxTokens int
+)
+
+// Sources of queued suggestions: the configured LLM, a triage rule (the
+// prefix of "rule:NAME"), and mb triage --confidence.
+const (
+	suggestionSourceLLM    = "llm"
+	suggestionSourceRule   = "rule:"
+	suggestionSourceTriage = "triage"
+)
+
+// autoTriageResult is what mb auto-triage did with one thread.
+type autoTriageResult struct {
+	ThreadID string `json:"thread_id"`
+	Account  string `json:"account"`
+	Subject  string `json:"subject"`
+	// Status is triaged, queued, would_triage, would_queue, skipped, or
+	// failed.
+	Status   string         `json:"status"`
+	Source   string         `json:"source,omitempty"`
+	Decision *triageRequest `json:"decision,omitempty"`
+	// LowConfidence marks a decision queued because its confidence is
+	// below triage.review_below.
+	LowConfidence bool   `json:"low_confidence,omitempty"`
+	BeadID        string `json:"bead_id,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+var autoTriageCmd = &cobra.Command{
+	Use:   "auto-triage [THREAD_ID...]",
+	Short: "Triage untriaged threads with triage rules and the configured LLM",
+	Long: `Ask the configured LLM (see "llm" in .mailbeads/config.json) for a triage
+decision on each untriaged thread — priority, action, suggestion, due date,
+category, and how confident it is — and apply it as mb triage would, hooks
+and undo journal included. Without arguments the oldest --limit untriaged
+threads are triaged; THREAD_IDs pick threads, triaged or not.
+
+A triage rule (see mb rules) matching the thread's first message sets the
+priority, with confidence 1, or for a "predicted" rule the local
+classifier's probability. Without an LLM only threads a rule matches are
+triaged, with their subject as the action.
+
+Every decision carries its confidence (0-1) into the bead's notes.
+Decisions below "triage": {"review_below": ...} (default 0.7) — including
+any where the model gave no valid priority or confidence — are queued for
+mb review instead of becoming tasks. With --review, all decisions are
+queued: nothing reaches beads until mb review steps through them and you
+accept each one. Threads with a queued or rejected suggestion are skipped
+by later runs unless named explicitly, so a scheduled
+"auto-triage --review" job doesn't ask twice.
+
+With --dry-run, the decisions are printed and nothing is written.
+
+Each thread is one LLM call with its messages (oldest dropped beyond
+--max-tokens). The model's reasoning is kept in the bead's notes.
+
+Examples:
+  mb auto-triage --review
+  mb auto-triage --account work -n 5 --dry-run
+  mb auto-triage 19abc123 --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !llm.Configured(cfg.LLM) && len(cfg.Triage.Rules) == 0 {
+			return llm.ErrNotConfigured
+		}
+		if err := validateTriageRules(cfg.Triage.Rules); err != nil {
+			return err
+		}
+		if !autoTriageReview && !autoTriageDryRun && !beads.Available() {
+			return errBDMissing
+		}
+		if autoTriageMaxTokens < 0 {
+			return codedErrorf(codeInvalidArgument, "--max-tokens must be 0 or more")
+		}
+		threads, err := autoTriageThreads(args)
+		if err != nil {
+			return err
+		}
+		model, err := loadClassifier()
+		if err != nil {
+			return err
+		}
+
+		results := []autoTriageResult{}
+		failed, queued := 0, 0
+		for _, t := range threads {
+			r := autoTriageThread(t, model)
+			switch r.Status {
+			case "failed":
+				failed++
+			case "queued":
+				queued++
+			}
+			results = append(results, r)
+			if !jsonOutput {
+				printAutoTriageResult(r)
+			}
+		}
+
+		if jsonOutput {
+			if err := writeOutput(cmd.OutOrStdout(), results); err != nil {
+				return err
+			}
+		} else if len(threads) == 0 {
+			fmt.Println("No untriaged threads without a suggestion.")
+		} else if queued > 0 && !quietFlag {
+			fmt.Printf("\nQueued %d suggestion(s); run mb review to accept or reject them.\n", queued)
+		}
+		if failed > 0 {
+			err := fmt.Errorf("%d of %d threads failed", failed, len(threads))
+			if jsonOutput {
+				// The results already say which threads failed.
+				return reported(err)
+			}
+			return err
+		}
+		return nil
+	},
+}
+
+// autoTriageThreads returns the named threads, or the oldest untriaged
+// threads without a suggestion, up to --limit.
+func autoTriageThreads(args []string) ([]*types.Thread, error) {
+	var threads []*types.Thread
+	if len(args) > 0 {
+		for _, arg := range args {
+			threadID, account, err := resolveThread(arg, autoTriageAccount)
+			if err != nil {
+				return nil, err
+			}
+			t, err := store.ThreadInfo(threadID, account)
+			if err != nil {
+				return nil, fmt.Errorf("thread %q not found in %s", threadID, account)
+			}
+			threads = append(threads, t)
+		}
+		return threads, nil
+	}
+
+	suggested, err := store.Suggestions(false)
+	if err != nil {
+		return nil, fmt.Errorf("query suggestions: %w", err)
+	}
+	skip := make(map[string]bool, len(suggested))
+	for _, s := range suggested {
+		skip[s.ThreadID+"|"+s.Account] = true
+	}
+	untriaged, err := store.UntriagedThreads(autoTriageAccount, 0)
+	if err != nil {
+		return nil, fmt.Errorf("query untriaged: %w", err)
+	}
+	// Untriaged threads come newest first; the oldest have waited longest.
+	for i := len(untriaged) - 1; i >= 0; i-- {
+		t := untriaged[i]
+		if skip[t.ThreadID+"|"+t.Account] {
+			continue
+		}
+		threads = append(threads, t)
+		if autoTriageLimit > 0 && len(threads) >= autoTriageLimit {
+			break
+		}
+	}
+	return threads, nil
+}
+
+// autoTriageThread decides t by triage rule and LLM, and applies, queues,
+// or (with --dry-run) only reports the decision.
+func autoTriageThread(t *types.Thread, model *classify.Model) autoTriageResult {
+	r := autoTriageResult{ThreadID: t.ThreadID, Account: t.Account, Subject: t.Subject, Status: "failed"}
+	emails, err := store.ThreadEmails(t.ThreadID, t.Account)
+	if err != nil || len(emails) == 0 {
+		r.Error = fmt.Sprintf("no emails found for thread %q in %s", t.ThreadID, t.Account)
+		return r
+	}
+	rule, ruleConfidence := matchTriageRule(t, emails[0], model)
+
+	var req triageRequest
+	switch {
+	case llm.Configured(cfg.LLM):
+		fetchBodies(emails, !autoTriageDryRun)
+		if req, err = llmTriage(t.ThreadID, t.Account, emails); err != nil {
+			r.Error = err.Error()
+			return r
+		}
+		r.Source = suggestionSourceLLM
+		if rule != nil {
+			req.Priority, req.Confidence = rule.Priority, &ruleConfidence
+			req.AgentNotes += fmt.Sprintf(" Priority set by triage rule %q.", rule.Name)
+		}
+	case rule != nil:
+		req = triageRequest{
+			ThreadID:   t.ThreadID,
+			Account:    t.Account,
+			Priority:   rule.Priority,
+			Action:     t.Subject,
+			AgentNotes: fmt.Sprintf("Auto-triaged by triage rule %q.", rule.Name),
+			Confidence: &ruleConfidence,
+		}
+		if req.Action == "" {
+			req.Action = "(no subject)"
+		}
+	default:
+		r.Status, r.Error = "skipped", "no triage rule matches"
+		return r
+	}
+	if rule != nil {
+		r.Source = suggestionSourceRule + rule.Name
+	}
+	r.Decision = &req
+	r.LowConfidence = req.lowConfidence()
+
+	switch {
+	case autoTriageDryRun && (autoTriageReview || r.LowConfidence):
+		r.Status = "would_queue"
+	case autoTriageDryRun:
+		r.Status = "would_triage"
+	case autoTriageReview || r.LowConfidence:
+		if _, err := queueTriage(req, r.Source); err != nil {
+			r.Error = err.Error()
+			return r
+		}
+		r.Status = "queued"
+	default:
+		out, err := applyTriage(req)
+		if err != nil {
+			r.Error = err.Error()
+			return r
+		}
+		r.Status, r.BeadID = "triaged", out.BeadID
+	}
+	return r
+}
+
+// llmTriage asks the configured LLM for a triage decision on a thread. A
+// category or due date the model gets wrong is dropped rather than failing
+// the thread; a missing or invalid priority or confidence gets confidence
+// 0, so the decision goes to review.
+func llmTriage(threadID, account string, emails []*types.Email) (triageRequest, error) {
+	prompt, _, _ := buildPrompt(emails, account, nil, "", autoTriageTask(time.Now()), autoTriageMaxTokens)
+	reply, err := completeLLM(prompt)
+	if err != nil {
+		return triageRequest{}, err
+	}
+	var raw struct {
+		Priority   string   `json:"priority"`
+		Action     string   `json:"action"`
+		Suggestion string   `json:"suggestion"`
+		Category   string   `json:"category"`
+		Due        string   `json:"due"`
+		Notes      string   `json:"notes"`
+		Confidence *float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(llm.JSONBlock(reply)), &raw); err != nil {
+		return triageRequest{}, fmt.Errorf("parse llm reply: %w", err)
+	}
+
+	req := triageRequest{
+		ThreadID:   threadID,
+		Account:    account,
+		Priority:   strings.ToLower(strings.TrimSpace(raw.Priority)),
+		Action:     strings.TrimSpace(raw.Action),
+		Suggestion: strings.TrimSpace(raw.Suggestion),
+		Category:   strings.TrimSpace(raw.Category),
+		Due:        strings.TrimSpace(raw.Due),
+		AgentNotes: "Auto-triaged by LLM.",
+	}
+	if notes := strings.TrimSpace(raw.Notes); notes != "" {
+		req.AgentNotes += " " + notes
+	}
+	if req.Action == "" {
+		return req, fmt.Errorf("llm reply has no action")
+	}
+	confidence := 0.0
+	if raw.Confidence != nil && *raw.Confidence >= 0 && *raw.Confidence <= 1 {
+		confidence = *raw.Confidence
+	} else {
+		req.AgentNotes += " The model gave no valid confidence."
+	}
+	if !types.IsValidPriority(req.Priority) {
+		req.Priority, confidence = "", 0
+		req.AgentNotes += " The model gave no valid priority."
+	}
+	req.Confidence = &confidence
+	if _, err := canonicalCategory(req.Category); err != nil {
+		req.Category = ""
+	}
+	if req.Due != "" {
+		if _, err := parseDueDate(req.Due); err != nil {
+			req.Due = ""
+		}
+	}
+	if err := req.validate(); err != nil {
+		return req, fmt.Errorf("llm reply: %w", err)
+	}
+	return req, nil
+}
+
+// autoTriageTask is the closing request of an auto-triage prompt.
+func autoTriageTask(now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `Triage this thread for its reader. Today is %s. Reply with only a JSON
+object with these fields:
+  "priority": "high" (needs the reader soon), "medium", "low" (FYI, no
+    action needed), or "spam"
+  "action": the reader's concrete next action as a short imperative
+    phrase, e.g. "Reply with the signed contract"
+  "suggestion": one or two sentences on how to handle it
+  "due": the deadline as YYYY-MM-DD, or "" if there is none
+  "notes": why you chose this priority, in one sentence
+  "confidence": how sure you are of the priority and action, from 0 to 1
+`, now.Format("2006-01-02"))
+	if names := categoryNames(); len(names) > 0 {
+		fmt.Fprintf(&b, "  \"category\": one of %s, or \"\" if none fits\n", strings.Join(names, ", "))
+	}
+	return b.String()
+}
+
+func printAutoTriageResult(r autoTriageResult) {
+	switch r.Status {
+	case "failed":
+		display.ErrorMsg("%s: %s", r.ThreadID, r.Error)
+		return
+	case "skipped":
+		fmt.Printf("  %s\n", display.Dim.Render("Skipped "+r.ThreadID+": "+r.Error))
+		return
+	}
+	detail := "confidence " + confidenceLabel(*r.Decision.Confidence) + ", " + r.Source
+	if r.LowConfidence {
+		detail += ", low confidence"
+	}
+	switch r.Status {
+	case "triaged":
+		display.SuccessMsg("Triaged %s [%s] %q (%s)", r.BeadID, r.Decision.Priority, r.Decision.Action, detail)
+	case "queued":
+		display.SuccessMsg("Queued [%s] %q for review (%s)", r.Decision.Priority, r.Decision.Action, detail)
+	case "would_triage":
+		fmt.Printf("  [dry-run] Would triage [%s] %q (%s)\n", r.Decision.Priority, r.Decision.Action, detail)
+	case "would_queue":
+		fmt.Printf("  [dry-run] Would queue [%s] %q for review (%s)\n", r.Decision.Priority, r.Decision.Action, detail)
+	}
+	fmt.Printf("    %s\n", display.Dim.Render(r.ThreadID+"  "+display.Truncate(r.Subject, 60)))
+}
+
+func init() {
+	autoTriageCmd.Flags().StringVar(&autoTriageAccount, "account", "", "Only threads in this account")
+	autoTriageCmd.Flags().IntVarP(&autoTriageLimit, "limit", "n", 10, "Max threads to triage (0 = all)")
+	autoTriageCmd.Flags().BoolVar(&autoTriageReview, "review", false, "Queue decisions for mb review instead of applying them")
+	autoTriageCmd.Flags().BoolVar(&autoTriageDryRun, "dry-run", false, "Print the decisions without writing anything")
+	autoTriageCmd.Flags().IntVar(&autoTriageMaxTokens, "max-tokens", 6000, "Prompt budget per thread; drops the oldest messages first (0 = no limit)")
+	rootCmd.AddCommand(autoTriageCmd)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/ical"
+	msync "github.com/daviddao/mailbeads/internal/sync"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	calendarAccount string
+	calendarDays    int
+	calendarPast    bool
+	calendarRescan  bool
+)
+
+var calendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "List upcoming meeting invites found in email",
+	Long: `List meeting invites parsed from text/calendar parts and inline ICS.
+
+Invites are detected during mb sync. Use --rescan to pick up VCALENDAR blocks
+pasted into bodies of emails that are already in the database.
+
+Examples:
+  mb calendar                # Invites in the next 30 days
+  mb calendar --days 7       # Next week only
+  mb calendar --days 0       # All upcoming invites
+  mb calendar --past --json  # Include invites that already started`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if calendarRescan {
+			emails, err := store.EmailsWithInlineCalendar()
+			if err != nil {
+				return fmt.Errorf("scan bodies: %w", err)
+			}
+			found := 0
+			for _, e := range emails {
+				if ics := ical.Extract(e.Body); ics != "" {
+					found += msync.RecordInvites(store, e, ics)
+				}
+			}
+			if !quietFlag && !jsonOutput {
+				display.SuccessMsg("Rescanned %d emails, found %d invites", len(emails), found)
+			}
+		}
+
+		now := time.Now().UTC()
+		from := now.Format(time.RFC3339)
+		if calendarPast {
+			from = ""
+		}
+		until := ""
+		if calendarDays > 0 {
+			until = now.AddDate(0, 0, calendarDays).Format(time.RFC3339)
+		}
+
+		events, err := store.UpcomingEvents(calendarAccount, from, until)
+		if err != nil {
+			return fmt.Errorf("query events: %w", err)
+		}
+
+		if jsonOutput {
+			if events == nil {
+				events = []*types.CalendarEvent{}
+			}
+			return writeOutput(cmd.OutOrStdout(), events)
+		}
+
+		if len(events) == 0 {
+			fmt.Println("No upcoming invites.")
+			return nil
+		}
+
+		fmt.Printf("Invites (%d):\n\n", len(events))
+		for _, ev := range events {
+			fmt.Printf("  %s  %s\n", display.Bold.Render(eventWhen(ev)), ev.Summary)
+			details := []string{display.AccountName(ev.Account), "thread " + ev.ThreadID}
+			if ev.Organizer != "" {
+				details = append([]string{"from " + ev.Organizer}, details...)
+			}
+			if ev.Method == "CANCEL" || ev.Status == "CANCELLED" {
+				details = append([]string{display.ErrStyle.Render("cancelled")}, details...)
+			}
+			fmt.Printf("    %s\n", display.Dim.Render(strings.Join(details, "  ·  ")))
+			if ev.Location != "" {
+				fmt.Printf("    %s\n", display.Dim.Render(display.Truncate(ev.Location, 70)))
+			}
+		}
+		return nil
+	},
+}
+
+// eventWhen formats an event's start (and end, if on the same day) in local time.
+func eventWhen(ev *types.CalendarEvent) string {
+	start, err := time.Parse(time.RFC3339, ev.Start)
+	if err != nil {
+		return ev.Start
+	}
+	start = start.Local()
+	if ev.AllDay {
+		return start.Format("Mon Jan 2") + " (all day)"
+	}
+	when := start.Format("Mon Jan 2 " + display.ClockLayout())
+	if end, err := time.Parse(time.RFC3339, ev.End); err == nil {
+		end = end.Local()
+		if end.YearDay() == start.YearDay() && end.Year() == start.Year() {
+			when += "–" + end.Format(display.ClockLayout())
+		}
+	}
+	return when
+}
+
+func init() {
+	calendarCmd.Flags().StringVar(&calendarAccount, "account", "", "Filter by account")
+	calendarCmd.Flags().IntVar(&calendarDays, "days", 30, "Look ahead this many days (0 = no limit)")
+	calendarCmd.Flags().BoolVar(&calendarPast, "past", false, "Include invites that already started")
+	calendarCmd.Flags().BoolVar(&calendarRescan, "rescan", false, "Re-scan stored email bodies for inline ICS")
+	rootCmd.AddCommand(calendarCmd)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/config"
+	"github.com/daviddao/mailbeads/internal/notify"
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// categoryNames lists the configured categories, for error messages.
+func categoryNames() []string {
+	names := make([]string, 0, len(cfg.Triage.Categories))
+	for _, c := range cfg.Triage.Categories {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// canonicalCategory checks category against the configured taxonomy and
+// returns it as configured. Any category is allowed when none are
+// configured.
+func canonicalCategory(category string) (string, error) {
+	if category == "" || len(cfg.Triage.Categories) == 0 {
+		return category, nil
+	}
+	for _, c := range cfg.Triage.Categories {
+		if strings.EqualFold(c.Name, strings.TrimSpace(category)) {
+			return c.Name, nil
+		}
+	}
+	return "", codedErrorf(codeInvalidArgument, "unknown category %q (configured: %s)",
+		category, strings.Join(categoryNames(), ", "))
+}
+
+// suggestCategory returns the first configured category whose hints match
+// one of a thread's messages, or "" if none does.
+func suggestCategory(emails []*types.Email) string {
+	for _, c := range cfg.Triage.Categories {
+		for _, e := range emails {
+			if categoryMatches(c, e) {
+				return c.Name
+			}
+		}
+	}
+	return ""
+}
+
+func categoryMatches(c config.Category, e *types.Email) bool {
+	if len(c.Senders) > 0 && notify.MatchSender(c.Senders, e.From) {
+		return true
+	}
+	subject := strings.ToLower(e.Subject)
+	text := subject + "\n" + strings.ToLower(e.Snippet)
+	for _, s := range c.SubjectContains {
+		if s != "" && strings.Contains(subject, strings.ToLower(s)) {
+			return true
+		}
+	}
+	for _, k := range c.Keywords {
+		if k != "" && strings.Contains(text, strings.ToLower(k)) {
+			return true
+		}
+	}
+	return false
+}
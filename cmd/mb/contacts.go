@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	contactsSearch  string
+	contactsSort    string
+	contactsLimit   int
+	contactsRebuild bool
+)
+
+var contactsCmd = &cobra.Command{
+	Use:   "contacts",
+	Short: "List senders with message counts and triage priority",
+	Long: `List everyone who has sent you email, aggregated from the senders table.
+
+The senders table is maintained during mb sync. For each sender it shows the
+number of messages, when they were last seen, and the average beads priority
+of their triaged threads — useful for spotting who actually generates work.
+
+Examples:
+  mb contacts                       # Top senders by message count
+  mb contacts --sort priority       # Senders whose threads are most urgent
+  mb contacts --search example.com  # Filter by address or name
+  mb contacts --rebuild             # Recompute from stored emails`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if contactsRebuild {
+			n, err := store.RebuildSenders()
+			if err != nil {
+				return fmt.Errorf("rebuild senders: %w", err)
+			}
+			if !quietFlag && !jsonOutput {
+				display.SuccessMsg("Rebuilt senders table (%d contacts)", n)
+			}
+		}
+
+		contacts, err := store.Senders(contactsSearch)
+		if err != nil {
+			return fmt.Errorf("query senders: %w", err)
+		}
+
+		if err := attachContactPriorities(contacts); err != nil {
+			return err
+		}
+
+		switch contactsSort {
+		case "count", "":
+			// Already ordered by message count.
+		case "recent":
+			sort.SliceStable(contacts, func(i, j int) bool {
+				return contacts[i].LastSeen > contacts[j].LastSeen
+			})
+		case "priority":
+			// Lower beads priority number = more urgent; untriaged senders last.
+			sort.SliceStable(contacts, func(i, j int) bool {
+				pi, pj := contacts[i].AvgPriority, contacts[j].AvgPriority
+				if (pi == 0) != (pj == 0) {
+					return pj == 0
+				}
+				return pi < pj
+			})
+		default:
+			return fmt.Errorf("invalid --sort %q (must be: count, recent, priority)", contactsSort)
+		}
+
+		if contactsLimit > 0 && len(contacts) > contactsLimit {
+			contacts = contacts[:contactsLimit]
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), contacts)
+		}
+
+		if listFormat != "" {
+			rows := make([][]string, 0, len(contacts))
+			for _, c := range contacts {
+				avg := ""
+				if c.AvgPriority > 0 {
+					avg = strconv.FormatFloat(c.AvgPriority, 'f', 2, 64)
+				}
+				rows = append(rows, []string{
+					c.Address, c.Name, strconv.Itoa(c.MessageCount), strconv.Itoa(c.ThreadCount),
+					strconv.Itoa(c.TriagedThreads), avg, c.FirstSeen, c.LastSeen,
+				})
+			}
+			return writeList(cmd.OutOrStdout(), listFormat, contacts,
+				[]string{"address", "name", "message_count", "thread_count", "triaged_threads", "avg_priority", "first_seen", "last_seen"}, rows)
+		}
+
+		if len(contacts) == 0 {
+			fmt.Println("No contacts yet. Run 'mb sync' (or 'mb contacts --rebuild').")
+			return nil
+		}
+
+		fmt.Printf("Contacts (%d):\n\n", len(contacts))
+		fmt.Printf("  %-36s %6s %8s %-8s %s\n",
+			display.Dim.Render("SENDER"),
+			display.Dim.Render("MSGS"),
+			display.Dim.Render("TRIAGED"),
+			display.Dim.Render("PRIORITY"),
+			display.Dim.Render("LAST SEEN"),
+		)
+		for _, c := range contacts {
+			sender := c.Address
+			if c.Name != "" {
+				sender = c.Name + " <" + c.Address + ">"
+			}
+			pri := "-"
+			if c.AvgPriority > 0 {
+				pri = fmt.Sprintf("%.1f", c.AvgPriority)
+			}
+			fmt.Printf("  %-36s %6d %8d %-8s %s\n",
+				display.Truncate(sender, 36),
+				c.MessageCount,
+				c.TriagedThreads,
+				pri,
+				display.FormatTime(c.LastSeen),
+			)
+		}
+		return nil
+	},
+}
+
+// attachContactPriorities fills in thread counts and the average beads
+// priority of each contact's triaged threads. Beads is optional: without it
+// only thread counts are set.
+func attachContactPriorities(contacts []*types.Contact) error {
+	threads, err := store.SenderThreads()
+	if err != nil {
+		return fmt.Errorf("query sender threads: %w", err)
+	}
+
+	// Map thread ID -> beads priority across all (open and closed) email beads.
+	priorities := make(map[string]int)
+	if beads.Available() {
+		issues, err := beads.List([]string{"email", "triage"}, "", 0)
+		if err == nil {
+			for _, issue := range issues {
+				if threadID := beads.ThreadIDFromRef(issue.ExternalRef); threadID != "" {
+					priorities[threadID] = issue.Priority
+				}
+			}
+		}
+	}
+
+	for _, c := range contacts {
+		ids := threads[c.Address]
+		c.ThreadCount = len(ids)
+		sum := 0
+		for _, id := range ids {
+			if p, ok := priorities[id]; ok {
+				// Treat P0 like P1 so the average stays on the mb high..spam scale.
+				sum += max(p, 1)
+				c.TriagedThreads++
+			}
+		}
+		if c.TriagedThreads > 0 {
+			c.AvgPriority = float64(sum) / float64(c.TriagedThreads)
+		}
+	}
+	return nil
+}
+
+func init() {
+	contactsCmd.Flags().StringVar(&contactsSearch, "search", "", "Filter by address or name (substring)")
+	contactsCmd.Flags().StringVar(&contactsSort, "sort", "count", "Sort by: count, recent, priority")
+	contactsCmd.Flags().IntVarP(&contactsLimit, "limit", "n", 50, "Max results")
+	contactsCmd.Flags().BoolVar(&contactsRebuild, "rebuild", false, "Recompute the senders table from stored emails")
+	addFormatFlag(contactsCmd)
+	rootCmd.AddCommand(contactsCmd)
+}
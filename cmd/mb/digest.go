@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	digestSince  time.Duration
+	digestFormat string
+)
+
+type digestOutput struct {
+	GeneratedAt string          `json:"generated_at"`
+	Since       string          `json:"since"`
+	NewThreads  []*types.Thread `json:"new_threads"`
+	Untriaged   int             `json:"untriaged"`
+	High        []beads.Issue   `json:"high_priority"`
+	Overdue     []dueItem       `json:"overdue"`
+	NextActions []beads.Issue   `json:"next_actions"`
+}
+
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Generate a morning digest of new mail and pending work",
+	Long: `Generate a digest of your inbox as markdown (default) or HTML.
+
+Sections: new mail since yesterday, high-priority pending items, overdue
+follow-ups, and suggested next actions. The output is plain text on stdout,
+suitable for piping into Slack, email, or an agent prompt.
+
+Examples:
+  mb digest                       # Markdown, last 24h
+  mb digest --since 72h           # After a long weekend
+  mb digest --format html > digest.html
+  mb digest --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		since := now.Add(-digestSince).UTC().Format(time.RFC3339)
+
+		threads, err := store.RecentThreads(since)
+		if err != nil {
+			return fmt.Errorf("query recent threads: %w", err)
+		}
+
+		out := digestOutput{
+			GeneratedAt: now.UTC().Format(time.RFC3339),
+			Since:       since,
+			NewThreads:  threads,
+			Untriaged:   store.UntriagedCount(),
+			High:        []beads.Issue{},
+			Overdue:     []dueItem{},
+			NextActions: []beads.Issue{},
+		}
+		if out.NewThreads == nil {
+			out.NewThreads = []*types.Thread{}
+		}
+
+		if beads.Available() {
+			labels := []string{"email", "triage"}
+			if open, err := beads.List(labels, "open", 0); err == nil {
+				for _, issue := range open {
+					if issue.Priority <= 1 {
+						out.High = append(out.High, issue)
+					}
+					if due, ok := parseBeadDue(issue.DueAt); ok {
+						if in := daysUntil(due, now); in < 0 {
+							out.Overdue = append(out.Overdue, dueItem{Issue: issue, Overdue: true, DueIn: in})
+						}
+					}
+				}
+			}
+			if ready, err := beads.Ready(labels, 5); err == nil {
+				out.NextActions = ready
+			}
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+
+		switch digestFormat {
+		case "markdown", "md":
+			return writeDigestMarkdown(cmd.OutOrStdout(), &out, now)
+		case "html":
+			return writeDigestHTML(cmd.OutOrStdout(), &out, now)
+		default:
+			return fmt.Errorf("invalid --format %q (must be: markdown, html)", digestFormat)
+		}
+	},
+}
+
+// digestThreadLine describes a new thread: subject, sender, and triage state.
+func digestThreadLine(t *types.Thread) (subject, detail string) {
+	state := "untriaged"
+	if t.TriageRef != nil {
+		state = t.TriageRef.BeadID
+	}
+	return t.Subject, fmt.Sprintf("%s (%s) · %d new · %s",
+		t.From, display.AccountName(t.Account), t.EmailCount, state)
+}
+
+func writeDigestMarkdown(w io.Writer, d *digestOutput, now time.Time) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Mail digest — %s\n\n", now.Format("Mon Jan 2, 2006"))
+
+	fmt.Fprintf(&b, "## New mail (%d threads)\n\n", len(d.NewThreads))
+	if len(d.NewThreads) == 0 {
+		b.WriteString("_No new mail._\n")
+	}
+	for _, t := range d.NewThreads {
+		subject, detail := digestThreadLine(t)
+		fmt.Fprintf(&b, "- **%s** — %s\n", subject, detail)
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "## High priority pending (%d)\n\n", len(d.High))
+	if len(d.High) == 0 {
+		b.WriteString("_Nothing high priority._\n")
+	}
+	for _, issue := range d.High {
+		fmt.Fprintf(&b, "- `%s` %s\n", issue.ID, issue.Title)
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "## Overdue (%d)\n\n", len(d.Overdue))
+	if len(d.Overdue) == 0 {
+		b.WriteString("_Nothing overdue._\n")
+	}
+	for _, item := range d.Overdue {
+		fmt.Fprintf(&b, "- `%s` %s — %dd late\n", item.ID, item.Title, -item.DueIn)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Suggested next actions\n\n")
+	n := 0
+	if d.Untriaged > 0 {
+		n++
+		fmt.Fprintf(&b, "%d. Triage %d untriaged threads (`mb untriaged`)\n", n, d.Untriaged)
+	}
+	for _, issue := range d.NextActions {
+		n++
+		fmt.Fprintf(&b, "%d. `%s` %s (%s)\n", n, issue.ID, issue.Title, beads.PriorityFromBeads(issue.Priority))
+	}
+	if n == 0 {
+		b.WriteString("_Inbox zero._\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeDigestHTML(w io.Writer, d *digestOutput, now time.Time) error {
+	e := html.EscapeString
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>Mail digest</title></head><body>\n")
+	fmt.Fprintf(&b, "<h1>Mail digest — %s</h1>\n", e(now.Format("Mon Jan 2, 2006")))
+
+	section := func(title string, items []string, empty string) {
+		fmt.Fprintf(&b, "<h2>%s</h2>\n", e(title))
+		if len(items) == 0 {
+			fmt.Fprintf(&b, "<p><em>%s</em></p>\n", e(empty))
+			return
+		}
+		b.WriteString("<ul>\n")
+		for _, item := range items {
+			fmt.Fprintf(&b, "  <li>%s</li>\n", item)
+		}
+		b.WriteString("</ul>\n")
+	}
+
+	var items []string
+	for _, t := range d.NewThreads {
+		subject, detail := digestThreadLine(t)
+		items = append(items, fmt.Sprintf("<strong>%s</strong> — %s", e(subject), e(detail)))
+	}
+	section(fmt.Sprintf("New mail (%d threads)", len(d.NewThreads)), items, "No new mail.")
+
+	items = nil
+	for _, issue := range d.High {
+		items = append(items, fmt.Sprintf("<code>%s</code> %s", e(issue.ID), e(issue.Title)))
+	}
+	section(fmt.Sprintf("High priority pending (%d)", len(d.High)), items, "Nothing high priority.")
+
+	items = nil
+	for _, item := range d.Overdue {
+		items = append(items, fmt.Sprintf("<code>%s</code> %s — %dd late", e(item.ID), e(item.Title), -item.DueIn))
+	}
+	section(fmt.Sprintf("Overdue (%d)", len(d.Overdue)), items, "Nothing overdue.")
+
+	items = nil
+	if d.Untriaged > 0 {
+		items = append(items, fmt.Sprintf("Triage %d untriaged threads (<code>mb untriaged</code>)", d.Untriaged))
+	}
+	for _, issue := range d.NextActions {
+		items = append(items, fmt.Sprintf("<code>%s</code> %s (%s)",
+			e(issue.ID), e(issue.Title), beads.PriorityFromBeads(issue.Priority)))
+	}
+	section("Suggested next actions", items, "Inbox zero.")
+
+	b.WriteString("</body></html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func init() {
+	digestCmd.Flags().DurationVar(&digestSince, "since", 24*time.Hour, "Include mail fetched within this window")
+	digestCmd.Flags().StringVar(&digestFormat, "format", "markdown", "Output format: markdown or html")
+	rootCmd.AddCommand(digestCmd)
+}
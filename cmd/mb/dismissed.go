@@ -0,0 +1,339 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/config"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/notify"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dismissedPolicy  string
+	dismissedAccount string
+	dismissedAll     bool
+	dismissedDryRun  bool
+)
+
+var dismissedCmd = &cobra.Command{
+	Use:   "dismissed",
+	Short: "List threads auto-dismissed by policy",
+	Long: `List the untriaged threads that auto-dismiss policies took out of
+mb untriaged, most recent first, with the policy that matched each.
+
+Policies live in the "triage" section of .mailbeads/config.json and run at
+the end of every mb sync (and mb watch and mb serve syncs):
+
+  {
+    "triage": {
+      "auto_dismiss": [
+        {"name": "stale-newsletters", "bulk": true, "older_than": "7d"},
+        {"name": "blocklist", "senders": ["@spammy.example.com", "noreply@ads.example.org"]}
+      ]
+    }
+  }
+
+A policy dismisses a thread when all of its conditions match: senders
+(full address, "@domain", or substring — a blocklist), subject_contains,
+and accounts, as in triage rules; bulk: true for newsletters and list mail;
+and older_than ("7d", "2w") for threads whose latest message is at least
+that old. The first matching policy is recorded.
+
+Dismissing only hides the thread locally: no bead is created and nothing
+changes in Gmail. mb dismissed restore brings threads back, and mb undo
+reverts the latest sync's auto-dismissals as a whole.
+
+Examples:
+  mb dismissed
+  mb dismissed --policy blocklist --json
+  mb dismissed run --dry-run
+  mb dismissed restore 19abc123`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dismissals, err := listDismissals()
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), dismissals)
+		}
+		if listFormat != "" {
+			rows := make([][]string, 0, len(dismissals))
+			for _, x := range dismissals {
+				rows = append(rows, []string{x.ThreadID, x.Account, x.Subject, x.From, x.Policy, x.DismissedAt})
+			}
+			return writeList(cmd.OutOrStdout(), listFormat, dismissals,
+				[]string{"thread_id", "account", "subject", "from", "policy", "dismissed_at"}, rows)
+		}
+		if len(dismissals) == 0 {
+			fmt.Println("No auto-dismissed threads.")
+			return nil
+		}
+		fmt.Printf("Auto-dismissed threads (%d):\n\n", len(dismissals))
+		printDismissals(dismissals)
+		return nil
+	},
+}
+
+var dismissedRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Apply auto-dismiss policies now",
+	Long: `Apply the auto-dismiss policies to the untriaged threads now, as mb sync
+does after each sync. With --dry-run, the threads that would be dismissed
+are listed and nothing is changed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(cfg.Triage.AutoDismiss) == 0 {
+			return codedErrorf(codeInvalidArgument, "no auto-dismiss policies configured (add them under \"triage\": {\"auto_dismiss\": [...]} in .mailbeads/config.json)")
+		}
+		dismissals, err := autoDismiss(dismissedDryRun)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			if dismissals == nil {
+				dismissals = []*types.Dismissal{}
+			}
+			return writeOutput(cmd.OutOrStdout(), dismissals)
+		}
+		if len(dismissals) == 0 {
+			fmt.Println("No untriaged threads match the auto-dismiss policies.")
+			return nil
+		}
+		if dismissedDryRun {
+			fmt.Printf("[dry-run] Would auto-dismiss %d thread(s):\n\n", len(dismissals))
+		} else {
+			fmt.Printf("Auto-dismissed %d thread(s):\n\n", len(dismissals))
+		}
+		printDismissals(dismissals)
+		return nil
+	},
+}
+
+var dismissedRestoreCmd = &cobra.Command{
+	Use:   "restore [THREAD_ID...]",
+	Short: "Return auto-dismissed threads to mb untriaged",
+	Long: `Restore auto-dismissed threads so they are listed by mb untriaged again.
+Pass thread IDs, or --all (optionally with --policy) to restore every
+thread a policy dismissed. A policy that still matches a restored thread
+dismisses it again at the next sync, so adjust the policy first.
+
+Examples:
+  mb dismissed restore 19abc123
+  mb dismissed restore --all --policy stale-newsletters`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if dismissedAll {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var targets []*types.Dismissal
+		if dismissedAll {
+			var err error
+			if targets, err = listDismissals(); err != nil {
+				return err
+			}
+		} else {
+			for _, arg := range args {
+				threadID, account, err := resolveThread(arg, dismissedAccount)
+				if err != nil {
+					return err
+				}
+				targets = append(targets, &types.Dismissal{ThreadID: threadID, Account: account})
+			}
+		}
+
+		restored := []*types.Dismissal{}
+		for _, t := range targets {
+			ok, err := store.RestoreDismissed(t.ThreadID, t.Account)
+			if err != nil {
+				return fmt.Errorf("restore %s: %w", t.ThreadID, err)
+			}
+			if !ok {
+				display.ErrorMsg("%s (%s) was not auto-dismissed", t.ThreadID, t.Account)
+				continue
+			}
+			audit("restore", t.ThreadID, t.Account, "", "auto-dismissal reverted")
+			restored = append(restored, t)
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), restored)
+		}
+		for _, t := range restored {
+			display.SuccessMsg("Restored %s (%s)", t.ThreadID, t.Account)
+		}
+		return nil
+	},
+}
+
+// listDismissals returns the auto-dismissed threads, filtered by --policy
+// and --account.
+func listDismissals() ([]*types.Dismissal, error) {
+	all, err := store.Dismissals()
+	if err != nil {
+		return nil, fmt.Errorf("query dismissals: %w", err)
+	}
+	dismissals := []*types.Dismissal{}
+	for _, x := range all {
+		if dismissedPolicy != "" && x.Policy != dismissedPolicy {
+			continue
+		}
+		if dismissedAccount != "" && x.Account != dismissedAccount {
+			continue
+		}
+		dismissals = append(dismissals, x)
+	}
+	return dismissals, nil
+}
+
+func printDismissals(dismissals []*types.Dismissal) {
+	for _, x := range dismissals {
+		when := ""
+		if x.DismissedAt != "" {
+			when = display.Dim.Render(display.FormatTime(x.DismissedAt))
+		}
+		fmt.Printf("  %-16s %s %s %s %s\n",
+			display.Truncate(x.ThreadID, 16),
+			display.PadRight(display.AccountLabel(x.Account), 12),
+			display.PadRight(display.Truncate(x.Subject, 40), 40),
+			display.PadRight(display.Dim.Render(display.Truncate(x.Policy, 20)), 20),
+			when,
+		)
+	}
+}
+
+// dismissPolicy is a policy from the config with its parsed age.
+type dismissPolicy struct {
+	config.DismissPolicy
+	olderThan time.Duration
+}
+
+// loadDismissPolicies validates the configured auto-dismiss policies.
+// Each needs a name and at least one condition besides accounts, so a
+// typo can't dismiss everything.
+func loadDismissPolicies() ([]dismissPolicy, error) {
+	var policies []dismissPolicy
+	for i, p := range cfg.Triage.AutoDismiss {
+		if p.Name == "" {
+			return nil, codedErrorf(codeInvalidArgument, "config triage.auto_dismiss[%d]: missing name", i)
+		}
+		if len(p.Senders) == 0 && len(p.SubjectContains) == 0 && !p.Bulk && p.OlderThan == "" {
+			return nil, codedErrorf(codeInvalidArgument, "config auto-dismiss policy %q: needs senders, subject_contains, bulk, or older_than", p.Name)
+		}
+		dp := dismissPolicy{DismissPolicy: p}
+		if p.OlderThan != "" {
+			days, err := parseDays(p.OlderThan)
+			if err != nil {
+				return nil, codedErrorf(codeInvalidArgument, "config auto-dismiss policy %q: invalid older_than %q (use e.g. 7d, 2w)", p.Name, p.OlderThan)
+			}
+			dp.olderThan = time.Duration(days) * 24 * time.Hour
+		}
+		policies = append(policies, dp)
+	}
+	return policies, nil
+}
+
+// matches reports whether the policy dismisses t at now.
+func (p dismissPolicy) matches(t *types.Thread, now time.Time) bool {
+	if p.Bulk && !t.IsBulk {
+		return false
+	}
+	if len(p.Senders) > 0 && !notify.MatchSender(p.Senders, t.From) {
+		return false
+	}
+	if len(p.SubjectContains) > 0 && !slices.ContainsFunc(p.SubjectContains, func(s string) bool {
+		return s != "" && strings.Contains(strings.ToLower(t.Subject), strings.ToLower(s))
+	}) {
+		return false
+	}
+	if !appliesTo(p.Accounts, t.Account) {
+		return false
+	}
+	if p.olderThan > 0 {
+		latest, err := time.Parse(time.RFC3339, t.LatestDate)
+		if err != nil || now.Sub(latest) < p.olderThan {
+			return false
+		}
+	}
+	return true
+}
+
+// autoDismiss applies the auto-dismiss policies to every untriaged thread
+// and returns the threads dismissed. The batch is journaled so mb undo can
+// restore it. A dry run only reports what would be dismissed.
+func autoDismiss(dryRun bool) ([]*types.Dismissal, error) {
+	policies, err := loadDismissPolicies()
+	if err != nil || len(policies) == 0 {
+		return nil, err
+	}
+	threads, err := store.UntriagedThreads("", 0)
+	if err != nil {
+		return nil, fmt.Errorf("query untriaged: %w", err)
+	}
+	now := time.Now()
+	var dismissals []*types.Dismissal
+	for _, t := range threads {
+		for _, p := range policies {
+			if p.matches(t, now) {
+				dismissals = append(dismissals, &types.Dismissal{
+					ThreadID: t.ThreadID, Account: t.Account, Policy: p.Name,
+					Subject: t.Subject, From: t.From,
+				})
+				break
+			}
+		}
+	}
+	if dryRun || len(dismissals) == 0 {
+		return dismissals, nil
+	}
+
+	if err := store.DismissThreads(dismissals); err != nil {
+		return nil, fmt.Errorf("record dismissals: %w", err)
+	}
+	op := &types.Operation{Op: types.OpAutoDismiss}
+	for _, x := range dismissals {
+		op.Refs = append(op.Refs, types.TriageRef{ThreadID: x.ThreadID, Account: x.Account, CreatedAt: x.DismissedAt})
+		audit(types.OpAutoDismiss, x.ThreadID, x.Account, "", "policy "+x.Policy)
+	}
+	if err := store.RecordOp(op); err != nil {
+		return nil, fmt.Errorf("record journal: %w", err)
+	}
+	return dismissals, nil
+}
+
+// dismissalSummary counts dismissals per policy, in order of first use:
+// "stale-newsletters 4, blocklist 1".
+func dismissalSummary(dismissals []*types.Dismissal) string {
+	var names []string
+	counts := make(map[string]int)
+	for _, x := range dismissals {
+		if counts[x.Policy] == 0 {
+			names = append(names, x.Policy)
+		}
+		counts[x.Policy]++
+	}
+	parts := make([]string, len(names))
+	for i, n := range names {
+		parts[i] = fmt.Sprintf("%s %d", n, counts[n])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	for _, c := range []*cobra.Command{dismissedCmd, dismissedRestoreCmd} {
+		c.Flags().StringVar(&dismissedPolicy, "policy", "", "Only threads dismissed by this policy")
+		c.Flags().StringVar(&dismissedAccount, "account", "", "Only threads in this account")
+	}
+	dismissedRestoreCmd.Flags().BoolVar(&dismissedAll, "all", false, "Restore every listed thread (with --policy, that policy's)")
+	dismissedRunCmd.Flags().BoolVar(&dismissedDryRun, "dry-run", false, "List the threads that would be dismissed without dismissing them")
+	addFormatFlag(dismissedCmd)
+	dismissedCmd.AddCommand(dismissedRunCmd, dismissedRestoreCmd)
+	rootCmd.AddCommand(dismissedCmd)
+}
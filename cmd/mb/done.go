@@ -5,16 +5,37 @@ import (
 
 	"github.com/daviddao/mailbeads/internal/beads"
 	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
 	"github.com/spf13/cobra"
 )
 
+var closeDryRun bool
+
+// closePreview is one bead in mb done/dismiss --dry-run --json.
+type closePreview struct {
+	BeadID  string   `json:"bead_id"`
+	Title   string   `json:"title,omitempty"`
+	Outcome string   `json:"outcome"` // done or dismissed
+	Threads []string `json:"threads,omitempty"`
+	// Task is the mirrored task that would be completed.
+	Task  string `json:"task,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
 var doneCmd = &cobra.Command{
 	Use:   "done BEAD_ID [BEAD_ID...]",
 	Short: "Mark triage entries as done (closes the beads issue)",
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Close each bead as done and clear its threads' triage entries.
+
+With --dry-run, the beads that would be closed are listed with their
+threads; nothing is closed.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if !beads.Available() {
-			return fmt.Errorf("bd (beads) CLI not found on PATH")
+			return errBDMissing
+		}
+		if closeDryRun {
+			return previewClose(cmd, args, "done")
 		}
 		for _, id := range args {
 			if err := beads.Close(id, "done"); err != nil {
@@ -22,7 +43,9 @@ var doneCmd = &cobra.Command{
 				continue
 			}
 			// Clean up local cross-reference.
-			store.DeleteTriageRef(id)
+			if err := closeTriage(id, types.OpDone); err != nil {
+				display.ErrorMsg("record %s: %v", id, err)
+			}
 			display.SuccessMsg("Done: %s", id)
 		}
 		return nil
@@ -32,10 +55,18 @@ var doneCmd = &cobra.Command{
 var dismissCmd = &cobra.Command{
 	Use:   "dismiss BEAD_ID [BEAD_ID...]",
 	Short: "Dismiss triage entries (closes as spam/irrelevant)",
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Close each bead as spam or irrelevant and clear its threads' triage
+entries.
+
+With --dry-run, the beads that would be dismissed are listed with their
+threads; nothing is closed.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if !beads.Available() {
-			return fmt.Errorf("bd (beads) CLI not found on PATH")
+			return errBDMissing
+		}
+		if closeDryRun {
+			return previewClose(cmd, args, "dismissed")
 		}
 		for _, id := range args {
 			if err := beads.Close(id, "dismissed — spam/irrelevant"); err != nil {
@@ -43,14 +74,67 @@ var dismissCmd = &cobra.Command{
 				continue
 			}
 			// Clean up local cross-reference.
-			store.DeleteTriageRef(id)
+			if err := closeTriage(id, types.OpDismiss); err != nil {
+				display.ErrorMsg("record %s: %v", id, err)
+			}
 			fmt.Printf("%s Dismissed: %s\n", display.Dim.Render("✗"), id)
 		}
 		return nil
 	},
 }
 
+// previewClose lists what mb done or dismiss would close, and the triaged
+// threads and mirrored task each close would clear.
+func previewClose(cmd *cobra.Command, ids []string, outcome string) error {
+	previews := make([]closePreview, 0, len(ids))
+	for _, id := range ids {
+		p := closePreview{BeadID: id, Outcome: outcome}
+		issue, err := beads.Show(id)
+		switch {
+		case err != nil:
+			p.Error = err.Error()
+		case issue.Status == "closed":
+			p.Title, p.Error = issue.Title, "already closed"
+		default:
+			p.Title = issue.Title
+			if cfg.Tasks.Provider != "" {
+				p.Task = beads.NoteField(issue.Notes, cfg.Tasks.Provider)
+			}
+		}
+		if refs, err := store.TriageRefsByBead(id); err == nil {
+			for _, r := range refs {
+				p.Threads = append(p.Threads, r.ThreadID)
+			}
+		}
+		previews = append(previews, p)
+	}
+
+	if jsonOutput {
+		return writeOutput(cmd.OutOrStdout(), previews)
+	}
+	verb := "close"
+	if outcome == "dismissed" {
+		verb = "dismiss"
+	}
+	for _, p := range previews {
+		if p.Error != "" {
+			display.ErrorMsg("%s %s: %s", verb, p.BeadID, p.Error)
+			continue
+		}
+		fmt.Printf("  [dry-run] Would %s %s  %s\n", verb, p.BeadID, p.Title)
+		if len(p.Threads) > 0 {
+			fmt.Printf("    clears triage of %d thread(s): %s\n", len(p.Threads), display.Dim.Render(fmt.Sprint(p.Threads)))
+		}
+		if p.Task != "" {
+			fmt.Printf("    completes %s task %s\n", cfg.Tasks.Provider, p.Task)
+		}
+	}
+	return nil
+}
+
 func init() {
+	doneCmd.Flags().BoolVar(&closeDryRun, "dry-run", false, "List the beads that would be closed without closing them")
+	dismissCmd.Flags().BoolVar(&closeDryRun, "dry-run", false, "List the beads that would be dismissed without closing them")
 	rootCmd.AddCommand(doneCmd)
 	rootCmd.AddCommand(dismissCmd)
 }
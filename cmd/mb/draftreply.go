@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	"github.com/daviddao/mailbeads/internal/llm"
+	"github.com/daviddao/mailbeads/internal/quotes"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	draftReplyAccount     string
+	draftReplyInstruction string
+	draftReplyPrint       bool
+)
+
+type draftReplyOutput struct {
+	ThreadID    string `json:"thread_id"`
+	Account     string `json:"account"`
+	To          string `json:"to"`
+	Subject     string `json:"subject"`
+	Instruction string `json:"instruction,omitempty"`
+	Body        string `json:"body"`
+	DraftID     string `json:"draft_id,omitempty"`
+	BeadID      string `json:"bead_id,omitempty"`
+}
+
+var draftReplyCmd = &cobra.Command{
+	Use:   "draft-reply THREAD_ID",
+	Short: "Draft a reply with the configured LLM and save it to Gmail drafts",
+	Long: `Ask the LLM configured under "llm" in .mailbeads/config.json to write a
+reply to a thread, then save it as a Gmail draft in the thread. Nothing is
+ever sent: review and send the draft from Gmail.
+
+The model sees the thread (quoted replies stripped), the linked bead's action
+and notes if the thread is triaged, and --instruction, which says what the
+reply should do. Use --print to see the draft without saving it.
+
+Examples:
+  mb draft-reply 19abc123 --instruction "Accept, propose Tuesday 2pm instead"
+  mb draft-reply "contract renewal" -i "Ask for the redlined version" --print`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !llm.Configured(cfg.LLM) {
+			return llm.ErrNotConfigured
+		}
+
+		threadID, account, err := resolveThread(args[0], draftReplyAccount)
+		if err != nil {
+			return err
+		}
+		emails, err := store.ThreadEmails(threadID, account)
+		if err != nil {
+			return fmt.Errorf("fetch emails: %w", err)
+		}
+		if len(emails) == 0 {
+			return fmt.Errorf("no emails found for thread %q in %s", threadID, account)
+		}
+		fetchMissingBodies(emails)
+
+		var bead *beads.Issue
+		if ref, err := store.GetTriageRef(threadID, account); err == nil && ref != nil && beads.Available() {
+			bead, _ = beads.Show(ref.BeadID)
+		}
+
+		parent, to := replyTarget(emails, account)
+		body, err := completeLLM(draftReplyPrompt(emails, account, to, bead, draftReplyInstruction))
+		if err != nil {
+			return err
+		}
+		if body == "" {
+			return fmt.Errorf("the LLM returned an empty reply")
+		}
+		out := draftReplyOutput{
+			ThreadID:    threadID,
+			Account:     account,
+			To:          to,
+			Subject:     replySubject(parent.Subject),
+			Instruction: draftReplyInstruction,
+			Body:        body + "\n",
+		}
+		if bead != nil {
+			out.BeadID = bead.ID
+		}
+
+		if !draftReplyPrint {
+			svc, err := accountService(context.Background(), account)
+			if err != nil {
+				return err
+			}
+			out.DraftID, err = gmail.CreateReplyDraft(svc, threadID, account, to, out.Subject, out.Body, replyHeaders(parent))
+			if err != nil {
+				return err
+			}
+			audit("draft-reply", threadID, account, out.BeadID, "draft "+out.DraftID)
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+
+		fmt.Printf("From: %s\nTo: %s\nSubject: %s\n\n%s\n", account, to, out.Subject, out.Body)
+		if draftReplyPrint {
+			fmt.Println(display.Dim.Render("(not saved)"))
+			return nil
+		}
+		display.SuccessMsg("Saved draft %s — review and send it from Gmail", out.DraftID)
+		return nil
+	},
+}
+
+// draftReplyPrompt builds the LLM prompt for a reply from account to to.
+func draftReplyPrompt(emails []*types.Email, account, to string, bead *beads.Issue, instruction string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `Write the body of an email reply from %s to %s in the thread below.
+Match the tone of the thread, keep it brief, and do not invent facts,
+commitments, dates, or attachments that are not in the thread or the
+instructions. Reply with only the body text: no subject line, no
+explanations, no placeholders in brackets.
+`, account, to)
+	if instruction != "" {
+		fmt.Fprintf(&b, "\nWhat the reply should do: %s\n", instruction)
+	}
+	if bead != nil {
+		fmt.Fprintf(&b, "\nTriage note for this thread: %s", bead.Title)
+		if bead.Description != "" {
+			fmt.Fprintf(&b, " — %s", bead.Description)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nThread (oldest first):\n\n")
+	for _, e := range emails {
+		body := quotes.Strip(htmltext.Readable(e.Body), quotes.Options{Markers: cfg.Show.QuoteMarkers})
+		if body == "" {
+			body = e.Snippet
+		}
+		fmt.Fprintf(&b, "--- From: %s\nDate: %s\nSubject: %s\n\n%s\n\n", e.From, e.SentAt, e.Subject, body)
+	}
+	return b.String()
+}
+
+func init() {
+	draftReplyCmd.Flags().StringVar(&draftReplyAccount, "account", "", "Account the thread belongs to")
+	draftReplyCmd.Flags().StringVarP(&draftReplyInstruction, "instruction", "i", "", "What the reply should say or do")
+	draftReplyCmd.Flags().BoolVar(&draftReplyPrint, "print", false, "Print the draft without saving it to Gmail")
+	rootCmd.AddCommand(draftReplyCmd)
+}
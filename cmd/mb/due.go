@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/spf13/cobra"
+)
+
+var dueDays int
+
+type dueItem struct {
+	beads.Issue
+	Overdue bool `json:"overdue"`
+	DueIn   int  `json:"due_in_days"`
+}
+
+var dueCmd = &cobra.Command{
+	Use:   "due",
+	Short: "List triage items by due date (overdue first)",
+	Long: `List open triage items that have a due date, soonest first.
+
+Due dates are set with 'mb triage THREAD_ID --due DATE' and stored on the
+beads issue. Overdue items are always shown.
+
+Examples:
+  mb due              # Overdue and due in the next 7 days
+  mb due --days 30    # Look further ahead
+  mb due --days 0     # Everything with a due date
+  mb today            # Overdue and due today`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDue(cmd, dueDays)
+	},
+}
+
+var todayCmd = &cobra.Command{
+	Use:   "today",
+	Short: "List triage items that are overdue or due today",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDue(cmd, 1)
+	},
+}
+
+// runDue lists open email beads due within the next days days (0 = no limit).
+func runDue(cmd *cobra.Command, days int) error {
+	if !beads.Available() {
+		return errBDMissing
+	}
+
+	issues, err := beads.List([]string{"email", "triage"}, "open", 0)
+	if err != nil {
+		return fmt.Errorf("query beads: %w", err)
+	}
+
+	today := time.Now()
+	items := []dueItem{}
+	for _, issue := range issues {
+		due, ok := parseBeadDue(issue.DueAt)
+		if !ok {
+			continue
+		}
+		in := daysUntil(due, today)
+		if days > 0 && in >= days {
+			continue
+		}
+		items = append(items, dueItem{Issue: issue, Overdue: in < 0, DueIn: in})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].DueIn != items[j].DueIn {
+			return items[i].DueIn < items[j].DueIn
+		}
+		return items[i].Priority < items[j].Priority
+	})
+
+	if jsonOutput {
+		return writeOutput(cmd.OutOrStdout(), items)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("Nothing due.")
+		return nil
+	}
+
+	fmt.Printf("Due (%d):\n\n", len(items))
+	for _, item := range items {
+		pri := beads.PriorityFromBeads(item.Priority)
+		fmt.Printf("  %s %s  %s  %s\n",
+			display.PriorityDot(pri),
+			display.Dim.Render(item.ID),
+			dueLabel(item.DueIn),
+			item.Title,
+		)
+	}
+	return nil
+}
+
+// dueLabel renders a fixed-width, colored "due in" label.
+func dueLabel(in int) string {
+	switch {
+	case in < 0:
+		return display.HighStyle.Render(fmt.Sprintf("%-10s", fmt.Sprintf("%dd late", -in)))
+	case in == 0:
+		return display.MediumStyle.Render(fmt.Sprintf("%-10s", "today"))
+	case in == 1:
+		return fmt.Sprintf("%-10s", "tomorrow")
+	default:
+		return display.Dim.Render(fmt.Sprintf("%-10s", fmt.Sprintf("in %dd", in)))
+	}
+}
+
+// parseDueDate validates a --due flag value and returns it as YYYY-MM-DD.
+// Accepts YYYY-MM-DD, RFC 3339, "today", "tomorrow", and "+Nd".
+func parseDueDate(s string) (string, error) {
+	now := time.Now()
+	switch strings.ToLower(s) {
+	case "today":
+		return now.Format("2006-01-02"), nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1).Format("2006-01-02"), nil
+	}
+	if strings.HasPrefix(s, "+") && strings.HasSuffix(s, "d") {
+		n, err := strconv.Atoi(s[1 : len(s)-1])
+		if err == nil && n >= 0 {
+			return now.AddDate(0, 0, n).Format("2006-01-02"), nil
+		}
+	}
+	if _, err := time.Parse("2006-01-02", s); err == nil {
+		return s, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Local().Format("2006-01-02"), nil
+	}
+	return "", fmt.Errorf("invalid due date %q (use YYYY-MM-DD, today, tomorrow, or +Nd)", s)
+}
+
+// parseBeadDue parses the due_at field of a beads issue.
+func parseBeadDue(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// daysUntil counts the calendar days from today to due in local time,
+// negative when due is past. It compares dates rather than hours, since a
+// day across a DST change lasts 23 or 25.
+func daysUntil(due, today time.Time) int {
+	dy, dm, dd := due.Local().Date()
+	ty, tm, td := today.Local().Date()
+	return int(time.Date(dy, dm, dd, 0, 0, 0, 0, time.UTC).Sub(time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
+
+// startOfDay truncates t to local midnight.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Local().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func init() {
+	dueCmd.Flags().IntVar(&dueDays, "days", 7, "Look ahead this many days (0 = no limit)")
+	rootCmd.AddCommand(dueCmd)
+	rootCmd.AddCommand(todayCmd)
+}
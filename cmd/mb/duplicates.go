@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var duplicatesLink bool
+
+type duplicateGroup struct {
+	Subject string          `json:"subject"`
+	BeadID  string          `json:"bead_id,omitempty"`
+	Threads []*types.Thread `json:"threads"`
+	Linked  int             `json:"linked,omitempty"`
+}
+
+var duplicatesCmd = &cobra.Command{
+	Use:   "duplicates",
+	Short: "List threads delivered to more than one account",
+	Long: `List threads that share an RFC Message-ID across accounts — the same
+mail sent to several of your addresses.
+
+Duplicates are triaged once: mb untriaged shows only one thread per group,
+and mb triage links the others to the same bead. Use --link to apply that
+to groups triaged before they were detected.
+
+Examples:
+  mb duplicates
+  mb duplicates --link
+  mb show THREAD_ID --merged   # One view across accounts`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groups, err := store.DuplicateGroups()
+		if err != nil {
+			return fmt.Errorf("find duplicates: %w", err)
+		}
+
+		out := []duplicateGroup{}
+		for _, threads := range groups {
+			g := duplicateGroup{Threads: threads}
+			for _, t := range threads {
+				if info, err := store.ThreadInfo(t.ThreadID, t.Account); err == nil {
+					t.Subject, t.From, t.EmailCount, t.LatestDate = info.Subject, info.From, info.EmailCount, info.LatestDate
+					if g.Subject == "" {
+						g.Subject = info.Subject
+					}
+				}
+				if ref, err := store.GetTriageRef(t.ThreadID, t.Account); err == nil && ref != nil {
+					t.TriageRef = ref
+					if g.BeadID == "" {
+						g.BeadID = ref.BeadID
+					}
+				}
+			}
+			if duplicatesLink && g.BeadID != "" {
+				for _, t := range threads {
+					if t.TriageRef != nil {
+						continue
+					}
+					if _, err := store.UpsertTriageRef(t.ThreadID, t.Account, g.BeadID); err != nil {
+						return fmt.Errorf("link %s: %w", t.ThreadID, err)
+					}
+					audit("link", t.ThreadID, t.Account, g.BeadID, "linked duplicate thread")
+					g.Linked++
+				}
+			}
+			out = append(out, g)
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+
+		if len(out) == 0 {
+			fmt.Println("No cross-account duplicates.")
+			return nil
+		}
+
+		fmt.Printf("Duplicates (%d):\n\n", len(out))
+		linked := 0
+		for _, g := range out {
+			state := display.Dim.Render("untriaged")
+			if g.BeadID != "" {
+				state = g.BeadID
+			}
+			fmt.Printf("  %s  %s\n", display.Bold.Render(display.Truncate(g.Subject, 60)), state)
+			for _, t := range g.Threads {
+				fmt.Printf("    %s %s\n", display.AccountLabel(t.Account), t.ThreadID)
+			}
+			linked += g.Linked
+		}
+		if duplicatesLink && !quietFlag {
+			fmt.Println()
+			display.SuccessMsg("Linked %d thread(s) to existing beads", linked)
+		}
+		return nil
+	},
+}
+
+func init() {
+	duplicatesCmd.Flags().BoolVar(&duplicatesLink, "link", false, "Link untriaged duplicates to their group's bead")
+	rootCmd.AddCommand(duplicatesCmd)
+}
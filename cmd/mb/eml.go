@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	emlAccount string
+	emlDir     string
+)
+
+// emlOutput is one exported message in mb eml --json.
+type emlOutput struct {
+	ID       string `json:"id"`
+	ThreadID string `json:"thread_id"`
+	Account  string `json:"account"`
+	// Source is "stored" (synced with --raw) or "gmail" (fetched now).
+	Source string `json:"source"`
+	Path   string `json:"path,omitempty"`
+	Raw    string `json:"raw,omitempty"`
+}
+
+var emlCmd = &cobra.Command{
+	Use:   "eml ID...",
+	Short: "Export messages as .eml files",
+	Long: `Export messages in their original RFC 822 form, as .eml files that any
+mail client or parser can open.
+
+Each ID is a message ID or a thread ID (every message in the thread). The
+source stored by mb sync --raw is used when there is one, so the export is
+lossless and works offline; otherwise it is fetched from Gmail.
+
+A single message is written to stdout; use --dir to write one ID.eml file
+per message instead.
+
+Examples:
+  mb eml 18d5a7b3c4e5f6a7 > message.eml
+  mb eml 19abc123 --dir exports/
+  mb eml 19abc123 --dir exports/ --json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var emails []*types.Email
+		for _, arg := range args {
+			found, err := emlEmails(arg)
+			if err != nil {
+				return err
+			}
+			emails = append(emails, found...)
+		}
+		if emlDir == "" && len(emails) > 1 {
+			return codedErrorf(codeInvalidArgument, "%d messages to export, use --dir to write them to files", len(emails))
+		}
+		if emlDir != "" {
+			if err := os.MkdirAll(emlDir, 0o755); err != nil {
+				return err
+			}
+		}
+
+		ctx := context.Background()
+		var out []emlOutput
+		for _, e := range emails {
+			raw, source, err := rawSource(ctx, e)
+			if err != nil {
+				return err
+			}
+			res := emlOutput{ID: e.ID, ThreadID: e.ThreadID, Account: e.Account, Source: source}
+			if emlDir == "" {
+				if jsonOutput {
+					res.Raw = string(raw)
+					out = append(out, res)
+					continue
+				}
+				_, err := cmd.OutOrStdout().Write(raw)
+				return err
+			}
+			res.Path = filepath.Join(emlDir, e.ID+".eml")
+			if err := os.WriteFile(res.Path, raw, 0o644); err != nil {
+				return err
+			}
+			out = append(out, res)
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+		if !quietFlag {
+			display.SuccessMsg("Exported %d message(s) to %s", len(out), emlDir)
+		}
+		return nil
+	},
+}
+
+// emlEmails resolves an mb eml argument to the stored messages it names.
+func emlEmails(id string) ([]*types.Email, error) {
+	if e, err := store.GetEmail(id); err != nil {
+		return nil, fmt.Errorf("lookup message: %w", err)
+	} else if e != nil {
+		return []*types.Email{e}, nil
+	}
+	accounts, err := store.ThreadAccounts(id)
+	if err != nil {
+		return nil, fmt.Errorf("lookup thread: %w", err)
+	}
+	if len(accounts) == 0 {
+		return nil, codedErrorf(codeThreadNotFound, "no synced message or thread %q", id)
+	}
+	account, err := resolveThreadAccount(id, emlAccount)
+	if err != nil {
+		return nil, err
+	}
+	return store.ThreadEmails(id, account)
+}
+
+// rawSource returns a message's RFC 822 source, from the database if it
+// was synced with --raw and from Gmail otherwise.
+func rawSource(ctx context.Context, e *types.Email) ([]byte, string, error) {
+	raw, err := store.RawSource(e.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("read stored source of %s: %w", e.ID, err)
+	}
+	if raw != nil {
+		return raw, "stored", nil
+	}
+	if offlineFlag {
+		return nil, "", errOffline(fmt.Sprintf("message %s (not synced with --raw)", e.ID))
+	}
+	svc, err := accountService(ctx, e.Account)
+	if err != nil {
+		return nil, "", err
+	}
+	msg, err := gmail.ReadRaw(svc, e.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	return []byte(msg.Raw), "gmail", nil
+}
+
+func init() {
+	emlCmd.Flags().StringVar(&emlAccount, "account", "", "Account the thread belongs to")
+	emlCmd.Flags().StringVar(&emlDir, "dir", "", "Write ID.eml files to this directory instead of stdout")
+	rootCmd.AddCommand(emlCmd)
+}
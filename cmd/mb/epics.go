@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/spf13/cobra"
+)
+
+var epicsAll bool
+
+// epicSummary is one epic in mb epics: its email-labeled children and the
+// latest mail on their threads.
+type epicSummary struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Priority string `json:"priority"`
+	Open     int    `json:"open"`
+	Closed   int    `json:"closed"`
+	// LatestActivity is the date of the latest email on any child's
+	// thread, and LatestSubject that thread's subject.
+	LatestActivity string `json:"latest_activity,omitempty"`
+	LatestSubject  string `json:"latest_subject,omitempty"`
+}
+
+var epicsCmd = &cobra.Command{
+	Use:   "epics",
+	Short: "List beads epics with email-driven work",
+	Long: `List the beads epics that have email-labeled children (as linked by
+mb triage --epic, or --epic auto), with how many children are open and
+closed and when mail last arrived on any of their threads, most recent
+activity first. Closed epics are left out unless --all is given.
+
+Examples:
+  mb epics
+  mb epics --all --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !beads.Available() {
+			return errBDMissing
+		}
+		epics, err := emailEpics()
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), epics)
+		}
+		if listFormat != "" {
+			rows := make([][]string, 0, len(epics))
+			for _, e := range epics {
+				rows = append(rows, []string{e.ID, e.Title, e.Status, e.Priority,
+					strconv.Itoa(e.Open), strconv.Itoa(e.Closed), e.LatestActivity, e.LatestSubject})
+			}
+			return writeList(cmd.OutOrStdout(), listFormat, epics,
+				[]string{"id", "title", "status", "priority", "open", "closed", "latest_activity", "latest_subject"}, rows)
+		}
+		if len(epics) == 0 {
+			fmt.Println("No epics with email children. Link beads with mb triage --epic.")
+			return nil
+		}
+		fmt.Printf("Email epics (%d):\n\n", len(epics))
+		for _, e := range epics {
+			latest := ""
+			if e.LatestActivity != "" {
+				latest = display.Dim.Render(display.FormatTime(e.LatestActivity) + "  " + display.Truncate(e.LatestSubject, 40))
+			}
+			title := e.Title
+			if e.Status == "closed" {
+				title += " (closed)"
+			}
+			fmt.Printf("  %-12s %s %3d open %3d closed  %s\n",
+				e.ID, display.PadRight(display.Truncate(title, 32), 32), e.Open, e.Closed, latest)
+		}
+		return nil
+	},
+}
+
+// emailEpics summarizes the epics that have email children.
+func emailEpics() ([]*epicSummary, error) {
+	all, err := beads.Epics("")
+	if err != nil {
+		return nil, fmt.Errorf("list epics: %w", err)
+	}
+	epics := []*epicSummary{}
+	for _, epic := range all {
+		if epic.Status == "closed" && !epicsAll {
+			continue
+		}
+		children, err := beads.Children(epic.ID, []string{"email"})
+		if err != nil {
+			return nil, fmt.Errorf("list children of %s: %w", epic.ID, err)
+		}
+		if len(children) == 0 {
+			continue
+		}
+		e := &epicSummary{
+			ID:       epic.ID,
+			Title:    epic.Title,
+			Status:   epic.Status,
+			Priority: beads.PriorityFromBeads(epic.Priority),
+		}
+		for _, c := range children {
+			if c.Status == "closed" {
+				e.Closed++
+			} else {
+				e.Open++
+			}
+			refs, err := store.TriageRefsByBead(c.ID)
+			if err != nil {
+				return nil, fmt.Errorf("query triage refs: %w", err)
+			}
+			for _, ref := range refs {
+				info, err := store.ThreadInfo(ref.ThreadID, ref.Account)
+				if err != nil {
+					continue
+				}
+				if info.LatestDate > e.LatestActivity {
+					e.LatestActivity, e.LatestSubject = info.LatestDate, info.Subject
+				}
+			}
+		}
+		epics = append(epics, e)
+	}
+	sort.SliceStable(epics, func(i, j int) bool {
+		return epics[i].LatestActivity > epics[j].LatestActivity
+	})
+	return epics, nil
+}
+
+// autoEpic is the epic mb triage --epic auto links a bead to.
+const autoEpic = "auto"
+
+// freemailDomains are personal mail providers, whose domain says nothing
+// about who sent a message; --epic auto groups their senders by address.
+var freemailDomains = map[string]bool{
+	"gmail.com": true, "googlemail.com": true, "outlook.com": true,
+	"hotmail.com": true, "live.com": true, "msn.com": true,
+	"yahoo.com": true, "icloud.com": true, "me.com": true, "mac.com": true,
+	"aol.com": true, "proton.me": true, "protonmail.com": true,
+	"gmx.com": true, "gmx.de": true, "web.de": true, "fastmail.com": true,
+}
+
+// autoEpicName names the epic for a triage: the category if there is one,
+// otherwise the sender's domain (or address, for personal mail).
+func autoEpicName(category, from string) (string, error) {
+	if category != "" {
+		return category, nil
+	}
+	address, _ := db.ParseSender(from)
+	address = strings.ToLower(address)
+	at := strings.LastIndex(address, "@")
+	if at < 0 || at == len(address)-1 {
+		return "", fmt.Errorf("--epic auto: no category and no sender domain in %q", from)
+	}
+	if domain := address[at+1:]; !freemailDomains[domain] {
+		return domain, nil
+	}
+	return address, nil
+}
+
+// findEpic returns the open epic titled name (case-insensitive), or nil.
+func findEpic(name string) (*beads.Issue, error) {
+	epics, err := beads.Epics("")
+	if err != nil {
+		return nil, fmt.Errorf("list epics: %w", err)
+	}
+	for i, e := range epics {
+		if e.Status != "closed" && strings.EqualFold(e.Title, name) {
+			return &epics[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// resolveAutoEpic finds the epic named name, creating it if there is none.
+// created reports the latter.
+func resolveAutoEpic(name string) (id string, created bool, err error) {
+	epic, err := findEpic(name)
+	if err != nil {
+		return "", false, err
+	}
+	if epic != nil {
+		return epic.ID, false, nil
+	}
+	epic, err = beads.CreateEpic(name, "Email threads triaged by mailbeads for "+name+".", []string{"email"})
+	if err != nil {
+		return "", false, fmt.Errorf("create epic %q: %w", name, err)
+	}
+	return epic.ID, true, nil
+}
+
+func init() {
+	epicsCmd.Flags().BoolVar(&epicsAll, "all", false, "Include closed epics")
+	addFormatFlag(epicsCmd)
+	rootCmd.AddCommand(epicsCmd)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/daviddao/mailbeads/internal/auth"
+	"github.com/daviddao/mailbeads/internal/llm"
+)
+
+// Stable error codes reported in --json error output. Scripts branch on
+// these, so existing codes must never change meaning.
+const (
+	codeError            = "error" // anything without a more specific code
+	codeInvalidArgument  = "invalid_argument"
+	codeNoDB             = "no_db"
+	codeThreadNotFound   = "thread_not_found"
+	codeAmbiguousAccount = "ambiguous_account"
+	codeAmbiguousThread  = "ambiguous_thread"
+	codeBDMissing        = "bd_missing"
+	codeLLMNotConfigured = "llm_not_configured"
+	codeOffline          = "offline"
+	codeHookRejected     = "hook_rejected"
+)
+
+// cliError is an error with a stable code and optional structured details
+// for --json output.
+type cliError struct {
+	Code    string
+	Err     error
+	Details any
+}
+
+func (e *cliError) Error() string { return e.Err.Error() }
+func (e *cliError) Unwrap() error { return e.Err }
+
+// codedErrorf returns a formatted error carrying code.
+func codedErrorf(code, format string, args ...any) *cliError {
+	return &cliError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// errBDMissing is returned by commands that need the bd CLI.
+var errBDMissing = codedErrorf(codeBDMissing, "bd (beads) CLI not found on PATH — install from https://beads.sh")
+
+// errOffline reports that what needs the network while --offline is set.
+func errOffline(what string) error {
+	return codedErrorf(codeOffline, "%s needs the network, but --offline is set", what)
+}
+
+// reportedError is a failure the command has already described in its
+// --json output, such as a batch whose results list the items that
+// failed. main exits non-zero without printing a second JSON object.
+type reportedError struct{ err error }
+
+func (e *reportedError) Error() string { return e.err.Error() }
+func (e *reportedError) Unwrap() error { return e.err }
+
+// reported marks err as already described in the command's output.
+func reported(err error) error {
+	return &reportedError{err: err}
+}
+
+// errorCode returns the code for err: its own if it carries one, a mapped
+// code for well-known errors from internal packages, or "error".
+func errorCode(err error) (string, any) {
+	var ce *cliError
+	if errors.As(err, &ce) {
+		return ce.Code, ce.Details
+	}
+	if errors.Is(err, llm.ErrNotConfigured) {
+		return codeLLMNotConfigured, nil
+	}
+	if errors.Is(err, auth.ErrOffline) {
+		return codeOffline, nil
+	}
+	return codeError, nil
+}
+
+// jsonError is the object printed for a failed command under --json or
+// --output.
+type jsonError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Details any    `json:"details,omitempty"`
+	} `json:"error"`
+}
+
+// writeStructuredError prints err as a jsonError: in YAML under --output
+// yaml, and as JSON otherwise (a table has no good shape for it).
+func writeStructuredError(w io.Writer, err error) {
+	var out jsonError
+	out.Error.Code, out.Error.Details = errorCode(err)
+	out.Error.Message = err.Error()
+	if outputFormat != outputYAML {
+		outputFormat = outputJSON
+	}
+	writeOutput(w, out)
+}
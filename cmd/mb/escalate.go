@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/escalate"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	"github.com/daviddao/mailbeads/internal/quotes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	escalateAccount string
+	escalateJira    string
+	escalateGitHub  string
+	escalateLinear  string
+	escalateProject string
+	escalateForce   bool
+)
+
+type escalateOutput struct {
+	ThreadID string `json:"thread_id"`
+	Account  string `json:"account"`
+	BeadID   string `json:"bead_id"`
+	*escalate.Issue
+	// Existing is set when the bead was already escalated and nothing was
+	// created.
+	Existing bool `json:"existing,omitempty"`
+}
+
+var escalateCmd = &cobra.Command{
+	Use:   "escalate THREAD_ID --jira PROJECT | --github OWNER/REPO | --linear TEAM",
+	Short: "File a triaged thread as an issue in an external tracker",
+	Long: `Create an issue for a triaged thread in the tracker where the real work
+is tracked, and record it on the thread's bead.
+
+The issue is titled with the bead's action, and describes the sender, a
+link back to the thread, and each message with quoted replies stripped.
+It is recorded in the bead's notes — as jira=KEY for Jira, github=URL and
+linear=URL for GitHub and Linear — and escalating the thread to the same tracker again reports that
+issue instead of filing another; --force files a new one and records it
+instead.
+
+With --jira PROJECT, a Jira issue is created in that project. With
+--github OWNER/REPO, a GitHub issue is opened in that repository, using
+the token in $GITHUB_TOKEN (or $GH_TOKEN). Mind that issues in public
+repositories publish the quoted mail. With --linear TEAM (a team key such
+as ENG), a Linear issue is created in that team with the bead's priority,
+using the API key in $LINEAR_API_KEY; --project NAME adds it to a Linear
+project, the way mb triage --epic links a bead to an epic.
+
+Jira is configured in .mailbeads/config.json, with the API token in
+$JIRA_API_TOKEN:
+
+  "escalate": {"jira": {"url": "https://acme.atlassian.net", "email": "me@acme.com",
+                        "issue_type": "Task", "labels": ["from-email"]}}
+
+Without an email, the token is sent as a Data Center personal access
+token. The URL and email may instead come from $JIRA_URL and $JIRA_EMAIL.
+For GitHub, "escalate": {"github": {"labels": [...], "api_url": ...}}
+adds labels and points at a GitHub Enterprise Server, and "linear":
+{"project": NAME, "cycle": "active"} sets a default project and puts new
+issues in the team's active cycle.
+
+THREAD_ID may also be words from the subject or sender, as in mb show.
+
+Examples:
+  mb escalate 19abc123 --jira OPS
+  mb escalate "outage report" --jira OPS --json
+  mb escalate 19abc123 --github acme/app
+  mb escalate 19abc123 --linear ENG --project "Customer bugs"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var tracker, target string
+		picked := 0
+		for _, t := range []struct{ name, value string }{
+			{"jira", escalateJira}, {"github", escalateGitHub}, {"linear", escalateLinear},
+		} {
+			if t.value != "" {
+				tracker, target = t.name, t.value
+				picked++
+			}
+		}
+		if picked != 1 {
+			return codedErrorf(codeInvalidArgument, "pick one tracker: --jira PROJECT, --github OWNER/REPO, or --linear TEAM")
+		}
+		if escalateProject != "" && tracker != "linear" {
+			return codedErrorf(codeInvalidArgument, "--project applies to --linear only")
+		}
+		if offlineFlag {
+			return errOffline("mb escalate")
+		}
+		if !beads.Available() {
+			return errBDMissing
+		}
+
+		threadID, account, err := resolveThread(args[0], escalateAccount)
+		if err != nil {
+			return err
+		}
+		ref, err := store.GetTriageRef(threadID, account)
+		if err == nil && ref == nil {
+			ref, err = store.DuplicateTriageRef(threadID, account)
+		}
+		if err != nil {
+			return fmt.Errorf("fetch triage ref: %w", err)
+		}
+		if ref == nil {
+			return codedErrorf(codeInvalidArgument, "thread %s is not triaged — run mb triage first", threadID)
+		}
+		bead, err := beads.Show(ref.BeadID)
+		if err != nil {
+			return fmt.Errorf("fetch bead %s: %w", ref.BeadID, err)
+		}
+
+		out := escalateOutput{ThreadID: threadID, Account: account, BeadID: bead.ID}
+		if recorded := beads.NoteField(bead.Notes, tracker); recorded != "" && !escalateForce {
+			out.Issue = escalate.Recorded(cfg.Escalate, tracker, recorded)
+			out.Existing = true
+		} else {
+			t, err := escalateThread(threadID, account, bead)
+			if err != nil {
+				return err
+			}
+			switch tracker {
+			case "github":
+				out.Issue, err = escalate.GitHub(cfg.Escalate.GitHub, target, t)
+			case "linear":
+				project := escalateProject
+				if project == "" {
+					project = cfg.Escalate.Linear.Project
+				}
+				out.Issue, err = escalate.Linear(cfg.Escalate.Linear, target, project, t)
+			default:
+				out.Issue, err = escalate.Jira(cfg.Escalate.Jira, target, t)
+			}
+			if err != nil {
+				return err
+			}
+			notes := beads.SetNoteField(bead.Notes, tracker, out.NoteValue())
+			if err := beads.Update(bead.ID, map[string]string{"notes": notes}); err != nil {
+				display.ErrorMsg("record %s on %s: %v", out.Key, bead.ID, err)
+			}
+			audit("escalate", threadID, account, bead.ID, fmt.Sprintf("%s %s", tracker, out.Key))
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+		if out.Existing {
+			fmt.Printf("%s already escalated to %s (--force to file another)\n", bead.ID, out.Key)
+			return nil
+		}
+		display.SuccessMsg("Escalated %s to %s", bead.ID, out.Key)
+		fmt.Printf("  %s\n", display.Dim.Render(out.URL))
+		return nil
+	},
+}
+
+// escalateThread gathers the context of a triaged thread for an issue
+// body, with each message's quoted replies stripped.
+func escalateThread(threadID, account string, bead *beads.Issue) (*escalate.Thread, error) {
+	emails, err := store.ThreadEmails(threadID, account)
+	if err != nil {
+		return nil, fmt.Errorf("fetch emails: %w", err)
+	}
+	if len(emails) == 0 {
+		return nil, fmt.Errorf("no emails found for thread %q in %s", threadID, account)
+	}
+	fetchMissingBodies(emails)
+
+	t := &escalate.Thread{
+		ThreadID: threadID,
+		Account:  account,
+		Subject:  emails[0].Subject,
+		From:     emails[0].From,
+		URL:      gmail.ThreadURL(account, threadID),
+		BeadID:   bead.ID,
+		Priority: beads.PriorityFromBeads(bead.Priority),
+		Title:    bead.Title,
+	}
+	if t.Title == "" {
+		t.Title = t.Subject
+	}
+	for _, e := range emails {
+		body := e.Body
+		if body == "" {
+			body = e.Snippet
+		}
+		body = quotes.Strip(htmltext.Readable(body), quotes.Options{
+			KeepSignatures: cfg.Show.KeepSignatures,
+			Markers:        cfg.Show.QuoteMarkers,
+		})
+		t.Messages = append(t.Messages, escalate.Message{From: e.From, Date: e.SentAt, Body: body})
+	}
+	return t, nil
+}
+
+func init() {
+	escalateCmd.Flags().StringVar(&escalateAccount, "account", "", "Account (if thread exists in multiple)")
+	escalateCmd.Flags().StringVar(&escalateJira, "jira", "", "Create the issue in this Jira project (key, e.g. OPS)")
+	escalateCmd.Flags().StringVar(&escalateGitHub, "github", "", "Open the issue in this GitHub repository (owner/repo)")
+	escalateCmd.Flags().StringVar(&escalateLinear, "linear", "", "Create the issue in this Linear team (key, e.g. ENG)")
+	escalateCmd.Flags().StringVar(&escalateProject, "project", "", "Linear project to add the issue to (default: escalate.linear.project)")
+	escalateCmd.Flags().BoolVar(&escalateForce, "force", false, "File a new issue even if the bead was already escalated")
+	rootCmd.AddCommand(escalateCmd)
+}
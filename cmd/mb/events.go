@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// eventHub fans mb watch events out to the /events subscribers of
+// mb serve.
+type eventHub struct {
+	mu     sync.Mutex
+	subs   map[chan hubEvent]bool
+	seq    int64 // ID of the last published event
+	closed bool
+}
+
+// hubEvent is a published event and its sequence number, the SSE event ID.
+type hubEvent struct {
+	id int64
+	watchEvent
+}
+
+// eventBuffer is how many events a subscriber may fall behind before it
+// is disconnected; it can reconnect and carry on.
+const eventBuffer = 256
+
+// eventKeepAlive is how often an idle stream gets a comment line, so
+// proxies don't time it out.
+const eventKeepAlive = 30 * time.Second
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[chan hubEvent]bool)}
+}
+
+// subscribe registers a subscriber. Its channel is closed when the hub
+// shuts down or the subscriber falls too far behind.
+func (h *eventHub) subscribe() chan hubEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := make(chan hubEvent, eventBuffer)
+	if h.closed {
+		close(ch)
+		return ch
+	}
+	h.subs[ch] = true
+	return ch
+}
+
+func (h *eventHub) unsubscribe(ch chan hubEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[ch] {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// publish stamps ev and sends it to every subscriber without blocking.
+func (h *eventHub) publish(ev watchEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ev.Time = time.Now().UTC().Format(time.RFC3339)
+	h.seq++
+	for ch := range h.subs {
+		select {
+		case ch <- hubEvent{h.seq, ev}:
+		default:
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// close disconnects every subscriber.
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// poll reports changes every interval until stop is closed. lock guards
+// the store against concurrent RPCs.
+func (h *eventHub) poll(interval time.Duration, lock sync.Locker, stop <-chan struct{}) {
+	lock.Lock()
+	state := newWatchState()
+	lock.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		lock.Lock()
+		watchChanges(state, h.publish)
+		lock.Unlock()
+	}
+}
+
+// ServeHTTP streams events as server-sent events: each has the event type
+// as its SSE event name and the mb watch --events object as its data.
+// ?types=email,triaged limits the stream to those types.
+func (h *eventHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	var types map[string]bool
+	if v := r.URL.Query().Get("types"); v != "" {
+		types = make(map[string]bool)
+		for _, t := range strings.Split(v, ",") {
+			types[strings.TrimSpace(t)] = true
+		}
+	}
+
+	ch := h.subscribe()
+	defer h.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": mailbeads events\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if types != nil && !types[ev.Type] {
+				continue
+			}
+			var data bytes.Buffer
+			enc := json.NewEncoder(&data)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(ev.watchEvent); err != nil {
+				continue
+			}
+			// Encode's trailing newline ends the data line.
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n", ev.id, ev.Type, data.Bytes())
+		}
+		flusher.Flush()
+	}
+}
+
+// httpTokenAuth requires the bearer token in the Authorization header or,
+// for browsers' EventSource, which can't set headers, a token query
+// parameter.
+func httpTokenAuth(token string, next http.Handler) http.Handler {
+	want := []byte(token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if got == "" {
+			got = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), want) != 1 {
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/mailquery"
+	"github.com/spf13/cobra"
+)
+
+var (
+	execFilter  string
+	execAccount string
+	execLimit   int
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec --filter QUERY COMMAND...",
+	Short: "Run a command for each thread matching a search",
+	Long: `Run COMMAND once per cached thread matching a Gmail-style search, with
+{field} placeholders replaced by the thread's fields — like xargs, but with
+mailbeads fields.
+
+Fields: {id} and {thread_id} (the thread), {account}, {subject}, {from},
+{date}, {message_id} (its latest message), and {bead_id} (empty if
+untriaged). Each field is also in the command's environment as MB_ and
+its name in capitals ($MB_SUBJECT, $MB_BEAD_ID, ...).
+
+A COMMAND given as one argument runs through sh. Values never become shell
+text there: each placeholder is replaced by a reference to its variable
+("$MB_SUBJECT", quoted, so a subject with spaces arrives as one word), so
+a sender can't smuggle commands in through a subject or address.
+Placeholders inside single quotes or backticks, where the reference
+wouldn't expand, are rejected. Given as several arguments (after --), the
+command runs directly, each placeholder expanding within its argument; to
+run a shell that way, use the variables: -- sh -c 'echo "$MB_SUBJECT"'.
+Threads run one at a time in the current directory; a failing command is
+reported and the rest still run, and mb exec then exits non-zero.
+
+--filter takes the operators of mb gmail search --cached (from:, subject:,
+is:unread, newer_than:, ...); use "in:anywhere" to match every thread.
+
+Examples:
+  mb exec --filter "from:billing@ newer_than:7d" -- ./file-invoice {thread_id} {subject}
+  mb exec --filter "is:unread" -n 10 'echo {from}: {subject}'
+  mb exec --filter "newer_than:1d" 'echo "$MB_FROM" >> senders.txt'
+  mb ready --exec 'my-script {id} {thread_id}'
+
+With --json, commands' output goes to stderr and a result per item
+(command, exit code) is printed.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if execFilter == "" {
+			return codedErrorf(codeInvalidArgument, `--filter is required (use "in:anywhere" for every thread)`)
+		}
+		if err := checkExecTemplate(args...); err != nil {
+			return err
+		}
+		items, unsupported, err := execThreads(execFilter, execAccount, execLimit)
+		if err != nil {
+			return err
+		}
+		for _, term := range unsupported {
+			display.ErrorMsg("ignoring unsupported search term %q", term)
+		}
+		return runExec(cmd.OutOrStdout(), args, items)
+	},
+}
+
+// execItem is one item mb exec and mb ready --exec run a command for: its
+// placeholder fields by name.
+type execItem map[string]string
+
+// execResult is the --json outcome of one command.
+type execResult struct {
+	ID       string `json:"id"`
+	Command  string `json:"command"`
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+}
+
+// execFields are the placeholders an exec template may use.
+var execFields = map[string]bool{
+	"id": true, "thread_id": true, "account": true, "subject": true, "from": true,
+	"date": true, "message_id": true, "bead_id": true, "title": true, "priority": true,
+}
+
+var execPlaceholder = regexp.MustCompile(`^\{([a-z_]+)\}`)
+
+// checkExecTemplate rejects unknown placeholders, and those an sh template
+// can't substitute safely, before anything runs.
+func checkExecTemplate(argv ...string) error {
+	if len(argv) == 1 {
+		if _, err := shellScript(argv[0]); err != nil {
+			return err
+		}
+	}
+	for _, a := range argv {
+		for i := range a {
+			m := execPlaceholder.FindStringSubmatch(a[i:])
+			if m == nil || execFields[m[1]] {
+				continue
+			}
+			names := make([]string, 0, len(execFields))
+			for name := range execFields {
+				names = append(names, "{"+name+"}")
+			}
+			sort.Strings(names)
+			return codedErrorf(codeInvalidArgument, "unknown placeholder {%s} (fields: %s)", m[1], strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// execEnvName is the environment variable holding a field's value.
+func execEnvName(field string) string {
+	return "MB_" + strings.ToUpper(field)
+}
+
+// expandExec substitutes item's fields into s, quoted with quote.
+func expandExec(s string, item execItem, quote func(string) string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if m := execPlaceholder.FindStringSubmatch(s[i:]); m != nil && execFields[m[1]] {
+			b.WriteString(quote(item[m[1]]))
+			i += len(m[0]) - 1
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// shellScript turns an sh template into a script that reads the values
+// from the MB_* variables: a placeholder becomes "$MB_FIELD" outside
+// quotes and ${MB_FIELD} inside double quotes. Expanded variables are
+// never parsed again, so no value can run as code. In single quotes or
+// backticks a reference wouldn't expand as written, so placeholders
+// there are an error. "${...}" is shell syntax, not a placeholder.
+func shellScript(tmpl string) (string, error) {
+	var b strings.Builder
+	var quotes []byte // open ', ", and ` contexts, innermost last
+	for i := 0; i < len(tmpl); i++ {
+		c := tmpl[i]
+		inner := byte(0)
+		if len(quotes) > 0 {
+			inner = quotes[len(quotes)-1]
+		}
+		if m := execPlaceholder.FindStringSubmatch(tmpl[i:]); m != nil && execFields[m[1]] && (i == 0 || tmpl[i-1] != '$') {
+			switch inner {
+			case 0:
+				b.WriteString(`"$` + execEnvName(m[1]) + `"`)
+			case '"':
+				b.WriteString("${" + execEnvName(m[1]) + "}")
+			default:
+				where := "single quotes"
+				if inner == '`' {
+					where = "backticks"
+				}
+				return "", codedErrorf(codeInvalidArgument, "placeholder {%s} inside %s can't be substituted; move it outside them or use \"$%s\"", m[1], where, execEnvName(m[1]))
+			}
+			i += len(m[0]) - 1
+			continue
+		}
+		b.WriteByte(c)
+		switch {
+		case c == '\\' && inner != '\'' && i+1 < len(tmpl):
+			i++
+			b.WriteByte(tmpl[i])
+		case inner != 0 && c == inner:
+			quotes = quotes[:len(quotes)-1]
+		case inner == 0 && (c == '\'' || c == '"' || c == '`'), inner == '"' && c == '`':
+			quotes = append(quotes, c)
+		}
+	}
+	return b.String(), nil
+}
+
+// execCommand builds the command for item: argv[0] run by sh if it is the
+// only argument, else argv run directly, with the item's fields in the
+// environment. It also returns the command as one shell line with the
+// values filled in, for reporting only.
+func execCommand(argv []string, item execItem) (*exec.Cmd, string) {
+	env := os.Environ()
+	for field := range execFields {
+		env = append(env, execEnvName(field)+"="+item[field])
+	}
+	var c *exec.Cmd
+	var line string
+	if len(argv) == 1 {
+		// checkExecTemplate has vetted the template.
+		script, _ := shellScript(argv[0])
+		c = exec.Command("sh", "-c", script)
+		line = expandExec(argv[0], item, shellQuote)
+	} else {
+		args := make([]string, len(argv))
+		words := make([]string, len(argv))
+		for i, a := range argv {
+			args[i] = expandExec(a, item, func(s string) string { return s })
+			words[i] = shellQuote(args[i])
+		}
+		c = exec.Command(args[0], args[1:]...)
+		line = strings.Join(words, " ")
+	}
+	c.Env = env
+	return c, line
+}
+
+// shellQuote quotes s as one sh word.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// runExec runs the command argv once per item, in order. Under --json the
+// commands' output goes to stderr and the results are written to w.
+func runExec(w io.Writer, argv []string, items []execItem) error {
+	var stdout io.Writer = os.Stdout
+	if jsonOutput {
+		stdout = os.Stderr
+	}
+	results := []execResult{}
+	failed := 0
+	for _, item := range items {
+		c, command := execCommand(argv, item)
+		c.Stdout, c.Stderr = stdout, os.Stderr
+		res := execResult{ID: item["id"], Command: command}
+		if err := c.Run(); err != nil {
+			failed++
+			res.ExitCode, res.Error = -1, err.Error()
+			if exit, ok := err.(*exec.ExitError); ok {
+				res.ExitCode = exit.ExitCode()
+			}
+			if !jsonOutput {
+				display.ErrorMsg("%s: %v", item["id"], err)
+			}
+		}
+		results = append(results, res)
+	}
+
+	err := fmt.Errorf("%d of %d commands failed", failed, len(items))
+	if jsonOutput {
+		if werr := writeOutput(w, results); werr != nil {
+			return werr
+		}
+		// The results already report each failure.
+		err = reported(err)
+	} else if !quietFlag {
+		fmt.Fprintf(os.Stderr, "Ran %d command(s), %d failed\n", len(items), failed)
+	}
+	if failed > 0 {
+		return err
+	}
+	return nil
+}
+
+// execThreads returns the cached threads matching a Gmail-style query,
+// newest first, as exec items, and the query terms it had to ignore.
+func execThreads(query, account string, limit int) ([]execItem, []string, error) {
+	q := mailquery.Parse(query, time.Now())
+	emails, err := store.SearchEmails(strings.Join(q.Words(), " "), account, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("search cache: %w", err)
+	}
+	seen := make(map[string]bool)
+	var items []execItem
+	for _, e := range emails {
+		key := e.Account + "\x00" + e.ThreadID
+		if seen[key] || !q.Match(e) {
+			continue
+		}
+		seen[key] = true
+		item := execItem{
+			"id":         e.ThreadID,
+			"thread_id":  e.ThreadID,
+			"account":    e.Account,
+			"subject":    e.Subject,
+			"from":       e.From,
+			"date":       e.SentAt,
+			"message_id": e.ID,
+		}
+		if ref, err := store.GetTriageRef(e.ThreadID, e.Account); err == nil && ref != nil {
+			item["bead_id"] = ref.BeadID
+		}
+		items = append(items, item)
+		if limit > 0 && len(items) == limit {
+			break
+		}
+	}
+	return items, q.Unsupported, nil
+}
+
+// readyItems returns exec items for ready beads: {id} is the bead, and the
+// thread fields come from its first triage cross-reference.
+func readyItems(issues []beads.Issue) []execItem {
+	items := make([]execItem, 0, len(issues))
+	for _, issue := range issues {
+		item := execItem{
+			"id":       issue.ID,
+			"bead_id":  issue.ID,
+			"title":    issue.Title,
+			"priority": beads.PriorityFromBeads(issue.Priority),
+		}
+		if refs, err := store.TriageRefsByBead(issue.ID); err == nil && len(refs) > 0 {
+			item["thread_id"], item["account"] = refs[0].ThreadID, refs[0].Account
+			if info, err := store.ThreadInfo(refs[0].ThreadID, refs[0].Account); err == nil {
+				item["subject"], item["from"], item["date"] = info.Subject, info.From, info.LatestDate
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func init() {
+	execCmd.Flags().StringVar(&execFilter, "filter", "", "Gmail-style search selecting the threads (required)")
+	execCmd.Flags().StringVar(&execAccount, "account", "", "Only threads in this account")
+	execCmd.Flags().IntVarP(&execLimit, "limit", "n", 0, "Run for at most N threads (0 = all)")
+	rootCmd.AddCommand(execCmd)
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	"github.com/daviddao/mailbeads/internal/quotes"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var exportNotesDir string
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export triaged mail to other tools",
+}
+
+// exportNoteResult is one note in mb export notes --json.
+type exportNoteResult struct {
+	ThreadID string `json:"thread_id"`
+	Account  string `json:"account"`
+	BeadID   string `json:"bead_id"`
+	Path     string `json:"path"`
+	// Status is "created", "updated", or "unchanged".
+	Status string `json:"status"`
+}
+
+var exportNotesCmd = &cobra.Command{
+	Use:   "notes --dir DIR",
+	Short: "Write a markdown note per triaged thread, e.g. into an Obsidian vault",
+	Long: `Write one markdown note per triaged thread into DIR, for Obsidian or any
+markdown knowledge base.
+
+Each note has YAML frontmatter (title, thread, account, bead ID, priority,
+status, action, category, due date, participants, date, Gmail link, and
+an "email" tag) and a body with the bead's suggestion as a summary and
+the thread's messages, quoted replies stripped. Bodies not yet synced
+(mb sync --no-body) show their snippet; nothing is fetched.
+
+Run it again to bring the notes up to date: notes are found by their
+thread_id, so renaming them is fine; only notes whose content changed are
+rewritten; and notes for threads closed since the last run get their
+final status. mb owns the frontmatter and the part of the body between
+the "mailbeads:begin" and "mailbeads:end" comments — anything you write
+outside them is kept.
+
+Examples:
+  mb export notes --dir ~/vault/Email
+  mb export notes --dir ~/vault/Email --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportNotesDir == "" {
+			return codedErrorf(codeInvalidArgument, "--dir is required")
+		}
+		if err := os.MkdirAll(exportNotesDir, 0o755); err != nil {
+			return err
+		}
+		existing, err := indexNotes(exportNotesDir)
+		if err != nil {
+			return err
+		}
+
+		refs, err := store.AllTriageRefs()
+		if err != nil {
+			return fmt.Errorf("list triage refs: %w", err)
+		}
+		issues := make(map[string]beads.Issue)
+		if beads.Available() {
+			if list, err := beads.List([]string{"email", "triage"}, "", 0); err == nil {
+				for _, issue := range list {
+					issues[issue.ID] = issue
+				}
+			}
+		}
+
+		// Threads to export: every triaged thread (one per bead, as
+		// duplicates in other accounts share it), then notes left open by
+		// an earlier run whose thread has since been closed.
+		var targets []*types.TriageRef
+		seenBead := make(map[string]bool)
+		seenThread := make(map[string]bool)
+		for _, ref := range refs {
+			if seenBead[ref.BeadID] {
+				continue
+			}
+			seenBead[ref.BeadID] = true
+			seenThread[noteKey(ref.Account, ref.ThreadID)] = true
+			targets = append(targets, ref)
+		}
+		for key, n := range existing {
+			if seenThread[key] || n.meta.Status == "closed" || n.meta.BeadID == "" {
+				continue
+			}
+			targets = append(targets, &types.TriageRef{ThreadID: n.meta.ThreadID, Account: n.meta.Account, BeadID: n.meta.BeadID})
+		}
+
+		var results []exportNoteResult
+		counts := make(map[string]int)
+		for _, ref := range targets {
+			var bead *beads.Issue
+			if issue, ok := issues[ref.BeadID]; ok {
+				bead = &issue
+			} else if beads.Available() {
+				bead, _ = beads.Show(ref.BeadID) // nil if deleted
+			}
+			path := ""
+			var old []byte
+			if n, ok := existing[noteKey(ref.Account, ref.ThreadID)]; ok {
+				path, old = n.path, n.content
+			}
+			content, name, err := threadNote(ref, bead, old)
+			if err != nil {
+				display.ErrorMsg("%s: %v", ref.ThreadID, err)
+				continue
+			}
+			res := exportNoteResult{ThreadID: ref.ThreadID, Account: ref.Account, BeadID: ref.BeadID, Status: "unchanged"}
+			switch {
+			case path == "":
+				path = filepath.Join(exportNotesDir, name)
+				res.Status = "created"
+			case !bytes.Equal(old, content):
+				res.Status = "updated"
+			}
+			if res.Status != "unchanged" {
+				if err := os.WriteFile(path, content, 0o644); err != nil {
+					return err
+				}
+			}
+			res.Path = path
+			counts[res.Status]++
+			results = append(results, res)
+		}
+
+		if jsonOutput {
+			if results == nil {
+				results = []exportNoteResult{}
+			}
+			return writeOutput(cmd.OutOrStdout(), results)
+		}
+		if !quietFlag {
+			display.SuccessMsg("Notes in %s: %d created, %d updated, %d unchanged",
+				exportNotesDir, counts["created"], counts["updated"], counts["unchanged"])
+		}
+		return nil
+	},
+}
+
+const (
+	noteBegin = "<!-- mailbeads:begin -->"
+	noteEnd   = "<!-- mailbeads:end -->"
+)
+
+// noteMeta is a note's frontmatter.
+type noteMeta struct {
+	Title        string   `yaml:"title"`
+	ThreadID     string   `yaml:"thread_id"`
+	Account      string   `yaml:"account"`
+	BeadID       string   `yaml:"bead_id,omitempty"`
+	Priority     string   `yaml:"priority,omitempty"`
+	Status       string   `yaml:"status,omitempty"`
+	Action       string   `yaml:"action,omitempty"`
+	Category     string   `yaml:"category,omitempty"`
+	Due          string   `yaml:"due,omitempty"`
+	Participants []string `yaml:"participants,omitempty"`
+	Date         string   `yaml:"date,omitempty"`
+	URL          string   `yaml:"url,omitempty"`
+	Tags         []string `yaml:"tags,omitempty"`
+}
+
+// exportedNote is a note found in the export directory.
+type exportedNote struct {
+	path    string
+	content []byte
+	meta    noteMeta
+}
+
+func noteKey(account, threadID string) string {
+	return account + "\x00" + threadID
+}
+
+// indexNotes returns the notes in dir written by mb export notes, keyed by
+// account and thread.
+func indexNotes(dir string) (map[string]exportedNote, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	notes := make(map[string]exportedNote)
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		front, _, ok := splitFrontmatter(content)
+		if !ok {
+			continue
+		}
+		var meta noteMeta
+		if yaml.Unmarshal(front, &meta) != nil || meta.ThreadID == "" || !bytes.Contains(content, []byte(noteBegin)) {
+			continue
+		}
+		notes[noteKey(meta.Account, meta.ThreadID)] = exportedNote{path: path, content: content, meta: meta}
+	}
+	return notes, nil
+}
+
+// splitFrontmatter splits a note into its YAML frontmatter and the rest.
+func splitFrontmatter(content []byte) (front, body []byte, ok bool) {
+	rest, found := bytes.CutPrefix(content, []byte("---\n"))
+	if !found {
+		return nil, content, false
+	}
+	front, body, found = bytes.Cut(rest, []byte("\n---\n"))
+	if !found {
+		return nil, content, false
+	}
+	return append(front, '\n'), body, true
+}
+
+// threadNote renders the note for a triaged thread, keeping whatever the
+// user wrote outside the generated section of old. It also returns the
+// file name for a new note.
+func threadNote(ref *types.TriageRef, bead *beads.Issue, old []byte) ([]byte, string, error) {
+	emails, err := store.ThreadEmails(ref.ThreadID, ref.Account)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch emails: %w", err)
+	}
+	if len(emails) == 0 {
+		return nil, "", fmt.Errorf("no emails found in %s", ref.Account)
+	}
+	subject := emails[0].Subject
+	if subject == "" {
+		subject = "(no subject)"
+	}
+	latest := emails[len(emails)-1]
+
+	meta := noteMeta{
+		Title:        subject,
+		ThreadID:     ref.ThreadID,
+		Account:      ref.Account,
+		BeadID:       ref.BeadID,
+		Participants: participants(emails),
+		URL:          gmail.ThreadURL(ref.Account, ref.ThreadID),
+		Tags:         []string{"email"},
+	}
+	if t, err := time.Parse(time.RFC3339, latest.SentAt); err == nil {
+		meta.Date = t.Local().Format("2006-01-02")
+	}
+	if bead != nil {
+		meta.Priority = beads.PriorityFromBeads(bead.Priority)
+		meta.Status = bead.Status
+		meta.Action = bead.Title
+		meta.Category = beads.Category(*bead)
+		meta.Due = bead.DueAt
+	}
+	front, err := yaml.Marshal(meta)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n# %s\n\n", noteBegin, subject)
+	if bead != nil {
+		fmt.Fprintf(&b, "**%s** — %s priority, %s", bead.Title, meta.Priority, bead.Status)
+		if bead.DueAt != "" {
+			fmt.Fprintf(&b, ", due %s", bead.DueAt)
+		}
+		fmt.Fprintf(&b, " · [Open in Gmail](%s)\n\n", meta.URL)
+		if bead.Description != "" {
+			fmt.Fprintf(&b, "## Summary\n\n%s\n\n", strings.TrimSpace(bead.Description))
+		}
+	} else {
+		fmt.Fprintf(&b, "[Open in Gmail](%s)\n\n", meta.URL)
+	}
+	b.WriteString("## Messages\n")
+	for _, e := range emails {
+		body := e.Body
+		if body == "" {
+			body = e.Snippet
+		}
+		body = quotes.Strip(htmltext.Readable(body), quotes.Options{
+			KeepSignatures: cfg.Show.KeepSignatures,
+			Markers:        cfg.Show.QuoteMarkers,
+		})
+		when := e.SentAt
+		if t, err := time.Parse(time.RFC3339, e.SentAt); err == nil {
+			when = t.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(&b, "\n### %s — %s\n", e.From, when)
+		if body = strings.TrimSpace(body); body != "" {
+			fmt.Fprintf(&b, "\n%s\n", body)
+		}
+	}
+	b.WriteString(noteEnd + "\n")
+
+	// Keep the user's text around the generated section.
+	before, after := "", "\n"
+	if _, body, ok := splitFrontmatter(old); ok {
+		if pre, rest, found := strings.Cut(string(body), noteBegin); found {
+			before = pre
+			if _, post, found := strings.Cut(rest, noteEnd+"\n"); found {
+				after = post
+			}
+		}
+	}
+
+	content := "---\n" + string(front) + "---\n" + before + b.String() + after
+	return []byte(content), noteFileName(subject, ref.ThreadID), nil
+}
+
+// participants lists the distinct addresses a thread's messages were from,
+// to, or copied to, in order of appearance.
+func participants(emails []*types.Email) []string {
+	var out []string
+	for _, p := range threadParticipants(emails, "") {
+		out = append(out, p.Address)
+	}
+	return out
+}
+
+// noteFileName names a new note after its subject, with the thread ID to
+// keep names unique.
+func noteFileName(subject, threadID string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|#^[]`, r) || r < ' ' {
+			return ' '
+		}
+		return r
+	}, subject)
+	name = strings.Join(strings.Fields(name), " ")
+	if r := []rune(name); len(r) > 80 {
+		name = strings.TrimSpace(string(r[:80]))
+	}
+	return fmt.Sprintf("%s (%s).md", name, threadID)
+}
+
+func init() {
+	exportNotesCmd.Flags().StringVar(&exportNotesDir, "dir", "", "Directory to write the notes to, e.g. an Obsidian vault folder (required)")
+	exportCmd.AddCommand(exportNotesCmd)
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	"github.com/daviddao/mailbeads/internal/quotes"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var exportHTMLOut string
+
+var exportHTMLCmd = &cobra.Command{
+	Use:   "html [--out FILE]",
+	Short: "Write the triage state as a single self-contained HTML page",
+	Long: `Write the open email beads, grouped by priority, and the untriaged
+threads to one HTML file with its styles inlined, to share with someone who
+doesn't have mb installed.
+
+Each item shows its action, bead ID, status, due date, and category, the
+suggestion as a summary, and its thread: subject, sender, message count,
+the latest message with quoted replies stripped, and a Gmail link (which
+only opens for someone with access to the mailbox). Nothing is fetched:
+bodies not yet synced show their snippet.
+
+Without --out the page is written to stdout.
+
+Examples:
+  mb export html --out inbox.html
+  mb export html > /tmp/inbox.html`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !beads.Available() {
+			return errBDMissing
+		}
+		page, err := inboxPage(time.Now())
+		if err != nil {
+			return err
+		}
+		if exportHTMLOut == "" {
+			return writeInboxHTML(cmd.OutOrStdout(), page)
+		}
+		f, err := os.Create(exportHTMLOut)
+		if err != nil {
+			return err
+		}
+		if err := writeInboxHTML(f, page); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		if !quietFlag {
+			display.SuccessMsg("Wrote %d items and %d untriaged threads to %s", page.Items, len(page.Untriaged), exportHTMLOut)
+		}
+		return nil
+	},
+}
+
+// htmlPage is the data of the exported page.
+type htmlPage struct {
+	Generated string
+	Items     int
+	Groups    []htmlGroup
+	Untriaged []*types.Thread
+}
+
+// htmlGroup is the open beads of one priority.
+type htmlGroup struct {
+	Priority string
+	Items    []htmlItem
+}
+
+// htmlItem is an open bead and its thread.
+type htmlItem struct {
+	ID, Title, Status, Due, Category, Summary string
+	Subject, From, Latest, Excerpt, URL       string
+	Emails                                    int
+}
+
+// inboxPage gathers the open email beads and untriaged threads.
+func inboxPage(now time.Time) (*htmlPage, error) {
+	issues, err := beads.List([]string{"email", "triage"}, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("query beads: %w", err)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].DueAt != issues[j].DueAt {
+			return issues[j].DueAt == "" || (issues[i].DueAt != "" && issues[i].DueAt < issues[j].DueAt)
+		}
+		return issues[i].ID < issues[j].ID
+	})
+
+	page := &htmlPage{Generated: now.Format("Mon Jan 2, 2006 15:04")}
+	byPriority := make(map[string][]htmlItem)
+	for _, issue := range issues {
+		if issue.Status == "closed" {
+			continue
+		}
+		item, err := inboxItem(issue)
+		if err != nil {
+			return nil, err
+		}
+		p := beads.PriorityFromBeads(issue.Priority)
+		byPriority[p] = append(byPriority[p], item)
+		page.Items++
+	}
+	for _, p := range priorityOrder {
+		if len(byPriority[p]) > 0 {
+			page.Groups = append(page.Groups, htmlGroup{Priority: p, Items: byPriority[p]})
+		}
+	}
+
+	page.Untriaged, err = store.UntriagedThreads("", 0)
+	if err != nil {
+		return nil, fmt.Errorf("query untriaged: %w", err)
+	}
+	return page, nil
+}
+
+// inboxItem describes a bead and the latest message of its thread.
+func inboxItem(issue beads.Issue) (htmlItem, error) {
+	item := htmlItem{
+		ID:       issue.ID,
+		Title:    issue.Title,
+		Status:   issue.Status,
+		Due:      issue.DueAt,
+		Category: beads.Category(issue),
+		Summary:  strings.TrimSpace(issue.Description),
+	}
+	refs, err := store.TriageRefsByBead(issue.ID)
+	if err != nil {
+		return item, fmt.Errorf("query triage refs: %w", err)
+	}
+	if len(refs) == 0 {
+		return item, nil
+	}
+	ref := refs[0]
+	emails, err := store.ThreadEmails(ref.ThreadID, ref.Account)
+	if err != nil {
+		return item, fmt.Errorf("fetch emails: %w", err)
+	}
+	item.URL = gmail.ThreadURL(ref.Account, ref.ThreadID)
+	if len(emails) == 0 {
+		return item, nil
+	}
+	latest := emails[len(emails)-1]
+	item.Subject, item.From, item.Emails = emails[0].Subject, latest.From, len(emails)
+	item.Latest = htmlTime(latest.SentAt)
+	body := latest.Body
+	if body == "" {
+		body = latest.Snippet
+	}
+	body = quotes.Strip(htmltext.Readable(body), quotes.Options{
+		KeepSignatures: cfg.Show.KeepSignatures,
+		Markers:        cfg.Show.QuoteMarkers,
+	})
+	item.Excerpt = display.Truncate(strings.Join(strings.Fields(body), " "), 400)
+	return item, nil
+}
+
+var inboxTemplate = template.Must(template.New("inbox").Funcs(template.FuncMap{
+	"when": htmlTime,
+}).Parse(`<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Inbox — {{.Generated}}</title>
+<style>
+body { font: 15px/1.45 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 860px; margin: 2em auto; padding: 0 1em; color: #1f2328; }
+h1 { font-size: 1.5em; margin-bottom: 0; }
+.meta, .sub { color: #656d76; font-size: .9em; }
+h2 { font-size: 1.15em; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; margin-top: 2em; }
+.item { border: 1px solid #d0d7de; border-left-width: 4px; border-radius: 6px; padding: .7em 1em; margin: .8em 0; }
+.high { border-left-color: #cf222e; } .medium { border-left-color: #bf8700; } .low { border-left-color: #1a7f37; } .spam { border-left-color: #8c959f; }
+.title { font-weight: 600; }
+code { font-size: .85em; background: #f6f8fa; padding: .1em .3em; border-radius: 4px; }
+.summary { margin: .4em 0; white-space: pre-wrap; }
+.excerpt { margin: .4em 0 0; padding-left: .8em; border-left: 3px solid #d0d7de; color: #424a53; }
+table { border-collapse: collapse; width: 100%; }
+td { padding: .3em .5em; border-bottom: 1px solid #eaeef2; vertical-align: top; }
+a { color: #0969da; }
+</style></head>
+<body>
+<h1>Inbox</h1>
+<p class="meta">Generated {{.Generated}} · {{.Items}} open items · {{len .Untriaged}} untriaged threads</p>
+{{range .Groups}}{{$priority := .Priority}}
+<h2>{{.Priority}} priority ({{len .Items}})</h2>
+{{range .Items}}<div class="item {{$priority}}">
+<div class="title">{{.Title}}</div>
+<div class="sub"><code>{{.ID}}</code> · {{.Status}}{{if .Due}} · due {{.Due}}{{end}}{{if .Category}} · {{.Category}}{{end}}</div>
+{{if .Summary}}<div class="summary">{{.Summary}}</div>{{end}}
+{{if .Subject}}<div class="sub">✉ {{.Subject}} — {{.From}} · {{.Emails}} messages · {{.Latest}}{{if .URL}} · <a href="{{.URL}}">Open in Gmail</a>{{end}}</div>{{end}}
+{{if .Excerpt}}<p class="excerpt">{{.Excerpt}}</p>{{end}}
+</div>
+{{end}}{{else}}
+<p><em>No open items.</em></p>
+{{end}}
+<h2>Untriaged ({{len .Untriaged}})</h2>
+{{if .Untriaged}}<table>
+{{range .Untriaged}}<tr><td>{{.Subject}}</td><td class="sub">{{.From}}</td><td class="sub">{{when .LatestDate}}</td></tr>
+{{end}}</table>{{else}}<p><em>Nothing to triage.</em></p>{{end}}
+</body></html>
+`))
+
+// htmlTime formats an RFC 3339 time absolutely, as a shared page may be
+// read long after it was written.
+func htmlTime(s string) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return t.Local().Format("Jan 2, 2006 15:04")
+}
+
+// writeInboxHTML renders page.
+func writeInboxHTML(w io.Writer, page *htmlPage) error {
+	return inboxTemplate.Execute(w, page)
+}
+
+func init() {
+	exportHTMLCmd.Flags().StringVar(&exportHTMLOut, "out", "", "File to write (default: stdout)")
+	exportCmd.AddCommand(exportHTMLCmd)
+}
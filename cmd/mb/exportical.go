@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/ical"
+	"github.com/spf13/cobra"
+)
+
+var exportICalOut string
+
+var exportICalCmd = &cobra.Command{
+	Use:   "ical [--out FILE]",
+	Short: "Write an iCalendar feed of due dates and snooze wake-ups",
+	Long: `Write an .ics calendar of open email beads, so their deadlines show up
+in your normal calendar alongside meetings.
+
+Each bead with a due date (mb triage --due) is an all-day event on that
+day, titled "Due: ACTION". Each deferred (snoozed) bead is an event when
+it wakes up, titled "Snoozed: ACTION" — all-day if it was deferred to a
+date, 15 minutes long if to a time. Events carry the bead's priority,
+suggestion, and a link to the thread, and keep their UID across runs, so
+re-importing the file updates events instead of duplicating them.
+
+Without --out the calendar is written to stdout. To subscribe instead of
+importing, run mb serve: it serves the same feed at /calendar.ics.
+
+Examples:
+  mb export ical --out ~/Calendars/mailbeads.ics
+  mb export ical > mailbeads.ics`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !beads.Available() {
+			return errBDMissing
+		}
+		cal, n, err := beadCalendar()
+		if err != nil {
+			return err
+		}
+		if exportICalOut == "" {
+			_, err := fmt.Fprint(cmd.OutOrStdout(), cal)
+			return err
+		}
+		if err := os.WriteFile(exportICalOut, []byte(cal), 0o644); err != nil {
+			return err
+		}
+		if !quietFlag {
+			display.SuccessMsg("Wrote %d events to %s", n, exportICalOut)
+		}
+		return nil
+	},
+}
+
+// beadCalendar renders the due dates and wake-ups of open email beads as
+// an iCalendar feed, and returns it with its number of events.
+func beadCalendar() (string, int, error) {
+	issues, err := beads.List([]string{"email", "triage"}, "", 0)
+	if err != nil {
+		return "", 0, fmt.Errorf("query beads: %w", err)
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].ID < issues[j].ID })
+
+	var events []ical.Event
+	var stamp time.Time
+	for _, issue := range issues {
+		if issue.Status == "closed" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, issue.UpdatedAt); err == nil && t.After(stamp) {
+			stamp = t
+		}
+		desc := beadEventDescription(issue)
+		if due, ok := parseBeadDue(issue.DueAt); ok {
+			events = append(events, ical.Event{
+				UID:         issue.ID + "-due@mailbeads",
+				Summary:     "Due: " + issue.Title,
+				Description: desc,
+				Start:       startOfDay(due),
+				AllDay:      true,
+			})
+		}
+		if wake, ok := parseBeadDue(issue.DeferUntil); ok {
+			e := ical.Event{
+				UID:         issue.ID + "-snooze@mailbeads",
+				Summary:     "Snoozed: " + issue.Title,
+				Description: desc,
+				Start:       wake,
+			}
+			if wake.Equal(startOfDay(wake)) {
+				e.AllDay = true
+			} else {
+				e.End = wake.Add(15 * time.Minute)
+			}
+			events = append(events, e)
+		}
+	}
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	return ical.Encode("mailbeads", stamp, events), len(events), nil
+}
+
+// beadEventDescription describes a bead in a calendar event.
+func beadEventDescription(issue beads.Issue) string {
+	lines := []string{fmt.Sprintf("%s — %s priority", issue.ID, beads.PriorityFromBeads(issue.Priority))}
+	if d := strings.TrimSpace(issue.Description); d != "" {
+		lines = append(lines, "", d)
+	}
+	account, threadID := beads.NoteField(issue.Notes, "account"), beads.ThreadIDFromRef(issue.ExternalRef)
+	if account != "" && threadID != "" {
+		lines = append(lines, "", gmail.ThreadURL(account, threadID))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// serveCalendar serves beadCalendar for calendar apps to subscribe to.
+func serveCalendar(w http.ResponseWriter, r *http.Request) {
+	if !beads.Available() {
+		http.Error(w, errBDMissing.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	cal, _, err := beadCalendar()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	fmt.Fprint(w, cal)
+}
+
+func init() {
+	exportICalCmd.Flags().StringVar(&exportICalOut, "out", "", "Write the calendar to this file (default: stdout)")
+	exportCmd.AddCommand(exportICalCmd)
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/actions"
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	"github.com/daviddao/mailbeads/internal/llm"
+	"github.com/daviddao/mailbeads/internal/quotes"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	extractAccount  string
+	extractUseLLM   bool
+	extractCreate   bool
+	extractPriority string
+)
+
+// actionItem is an action item found in one message of a thread.
+type actionItem struct {
+	actions.Item
+	EmailID string `json:"email_id"`
+	From    string `json:"from"`
+	// BeadID is the child issue created for the item with --create.
+	BeadID string `json:"bead_id,omitempty"`
+	// Existing is set when --create found a child issue for the item
+	// already and skipped it.
+	Existing bool `json:"existing,omitempty"`
+}
+
+type extractOutput struct {
+	ThreadID string       `json:"thread_id"`
+	Account  string       `json:"account"`
+	Subject  string       `json:"subject"`
+	Source   string       `json:"source"` // "heuristic" or "llm"
+	ParentID string       `json:"parent_id,omitempty"`
+	Items    []actionItem `json:"items"`
+}
+
+// actionItemCategory labels child issues created by mb extract --create.
+const actionItemCategory = "action-item"
+
+var extractCmd = &cobra.Command{
+	Use:   "extract THREAD_ID",
+	Short: "Extract asks, questions, and deadlines from a thread",
+	Long: `Extract explicit action items from a thread: requests ("Could you send
+the contract?"), direct questions, and deadlines ("by Friday", resolved to a
+date relative to when the message was sent). Quoted replies are ignored.
+
+By default a regex pass finds the items. --llm sends the thread to the model
+configured under "llm" in .mailbeads/config.json instead.
+
+--create turns each item into a child issue of the thread's bead (the thread
+must be triaged), labelled action-item and due on the item's deadline.
+Items that already have a child issue with the same title are skipped, so
+re-running is safe.
+
+Examples:
+  mb extract 19abc123
+  mb extract "contract renewal" --create
+  mb extract 19abc123 --llm --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if extractPriority != "" && !types.IsValidPriority(extractPriority) {
+			return fmt.Errorf("invalid priority %q (must be: high, medium, low, spam)", extractPriority)
+		}
+
+		threadID, account, err := resolveThread(args[0], extractAccount)
+		if err != nil {
+			return err
+		}
+		emails, err := store.ThreadEmails(threadID, account)
+		if err != nil {
+			return fmt.Errorf("fetch emails: %w", err)
+		}
+		if len(emails) == 0 {
+			return fmt.Errorf("no emails found for thread %q in %s", threadID, account)
+		}
+		fetchMissingBodies(emails)
+		for _, e := range emails {
+			e.Body = quotes.Strip(htmltext.Readable(e.Body), quotes.Options{Markers: cfg.Show.QuoteMarkers})
+		}
+
+		out := extractOutput{
+			ThreadID: threadID,
+			Account:  account,
+			Subject:  emails[0].Subject,
+			Source:   "heuristic",
+			Items:    []actionItem{},
+		}
+		if extractUseLLM {
+			out.Source = "llm"
+			out.Items, err = llmActionItems(emails)
+			if err != nil {
+				return err
+			}
+		} else {
+			out.Items = heuristicActionItems(emails)
+		}
+
+		if extractCreate && len(out.Items) > 0 {
+			if err := createActionItems(&out); err != nil {
+				return err
+			}
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+
+		if len(out.Items) == 0 {
+			fmt.Println("No action items found.")
+			return nil
+		}
+		fmt.Printf("Action items in %q (%d):\n\n", out.Subject, len(out.Items))
+		for _, it := range out.Items {
+			due := ""
+			if it.Due != "" {
+				due = "  " + display.Bold.Render("due "+it.Due)
+			}
+			fmt.Printf("  %-8s %s%s\n", display.Dim.Render(it.Kind), it.Text, due)
+			meta := display.Truncate(it.From, 40)
+			switch {
+			case it.Existing:
+				meta += "  " + it.BeadID + " (exists)"
+			case it.BeadID != "":
+				meta += "  → " + it.BeadID
+			}
+			fmt.Printf("  %-8s %s\n", "", display.Muted.Render(meta))
+		}
+		if out.ParentID != "" {
+			fmt.Printf("\nChild issues of %s.\n", out.ParentID)
+		}
+		return nil
+	},
+}
+
+// heuristicActionItems runs the regex extractor over each message, keeping
+// the first occurrence of items repeated across messages.
+func heuristicActionItems(emails []*types.Email) []actionItem {
+	items := []actionItem{}
+	seen := make(map[string]bool)
+	for _, e := range emails {
+		sent, err := time.Parse(time.RFC3339, e.SentAt)
+		if err != nil {
+			sent = time.Now()
+		}
+		for _, it := range actions.Extract(e.Body, sent.Local()) {
+			key := strings.ToLower(it.Text)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			items = append(items, actionItem{Item: it, EmailID: e.ID, From: e.From})
+		}
+	}
+	return items
+}
+
+// llmActionItems asks the configured LLM for the thread's action items.
+func llmActionItems(emails []*types.Email) ([]actionItem, error) {
+	var b strings.Builder
+	b.WriteString(`Extract the explicit action items from this email thread: requests made
+of the reader, direct questions awaiting an answer, and deadlines. Reply with
+only a JSON array of objects with these fields:
+  "kind": "ask", "question", or "deadline"
+  "text": the item as one short imperative sentence
+  "due": deadline as YYYY-MM-DD, or "" if none
+  "email_id": id of the message the item comes from
+Reply with [] if there are none.
+
+`)
+	for _, e := range emails {
+		fmt.Fprintf(&b, "--- email_id: %s\nFrom: %s\nDate: %s\n\n%s\n\n", e.ID, e.From, e.SentAt, e.Body)
+	}
+
+	reply, err := completeLLM(b.String())
+	if err != nil {
+		return nil, err
+	}
+	var raw []struct {
+		Kind    string `json:"kind"`
+		Text    string `json:"text"`
+		Due     string `json:"due"`
+		EmailID string `json:"email_id"`
+	}
+	if err := json.Unmarshal([]byte(llm.JSONBlock(reply)), &raw); err != nil {
+		return nil, fmt.Errorf("parse llm reply: %w", err)
+	}
+
+	from := make(map[string]string, len(emails))
+	for _, e := range emails {
+		from[e.ID] = e.From
+	}
+	items := []actionItem{}
+	for _, r := range raw {
+		text := strings.TrimSpace(r.Text)
+		if text == "" {
+			continue
+		}
+		switch r.Kind {
+		case actions.KindAsk, actions.KindQuestion, actions.KindDeadline:
+		default:
+			r.Kind = actions.KindAsk
+		}
+		if _, err := time.Parse("2006-01-02", r.Due); err != nil {
+			r.Due = ""
+		}
+		items = append(items, actionItem{
+			Item:    actions.Item{Kind: r.Kind, Text: text, Due: r.Due},
+			EmailID: r.EmailID,
+			From:    from[r.EmailID],
+		})
+	}
+	return items, nil
+}
+
+// createActionItems creates a child issue of the thread's bead for each
+// item that doesn't have one yet.
+func createActionItems(out *extractOutput) error {
+	if !beads.Available() {
+		return errBDMissing
+	}
+	ref, err := store.GetTriageRef(out.ThreadID, out.Account)
+	if err == nil && ref == nil {
+		ref, err = store.DuplicateTriageRef(out.ThreadID, out.Account)
+	}
+	if err != nil {
+		return fmt.Errorf("fetch triage ref: %w", err)
+	}
+	if ref == nil {
+		return fmt.Errorf("thread %q is not triaged — run mb triage first so action items have a parent", out.ThreadID)
+	}
+	out.ParentID = ref.BeadID
+
+	priority := extractPriority
+	if priority == "" {
+		priority = types.PriorityMedium
+		if parent, err := beads.Show(ref.BeadID); err == nil {
+			priority = beads.PriorityFromBeads(parent.Priority)
+		}
+	}
+
+	existing, err := beads.List([]string{"email", "triage", actionItemCategory}, "", 0)
+	if err != nil {
+		return fmt.Errorf("query beads: %w", err)
+	}
+	byTitle := make(map[string]string)
+	for _, issue := range existing {
+		if beads.ThreadIDFromRef(issue.ExternalRef) == out.ThreadID {
+			byTitle[strings.ToLower(issue.Title)] = issue.ID
+		}
+	}
+
+	notes := fmt.Sprintf("account=%s thread=%s parent=%s", out.Account, out.ThreadID, ref.BeadID)
+	for i := range out.Items {
+		it := &out.Items[i]
+		title := display.Truncate(it.Text, 120)
+		if id, ok := byTitle[strings.ToLower(title)]; ok {
+			it.BeadID, it.Existing = id, true
+			continue
+		}
+		desc := fmt.Sprintf("%s from %s in %q", it.Kind, it.From, out.Subject)
+		issue, err := beads.Create(title, desc, notes, beads.PriorityToBeads(priority),
+			actionItemCategory, ref.BeadID, it.Due, nil, out.ThreadID)
+		if err != nil {
+			display.ErrorMsg("create action item %q: %v", title, err)
+			continue
+		}
+		it.BeadID = issue.ID
+		byTitle[strings.ToLower(title)] = issue.ID
+		audit("extract", out.ThreadID, out.Account, issue.ID, fmt.Sprintf("parent=%s %s", ref.BeadID, it.Kind))
+	}
+	return nil
+}
+
+func init() {
+	extractCmd.Flags().StringVar(&extractAccount, "account", "", "Account the thread belongs to")
+	extractCmd.Flags().BoolVar(&extractUseLLM, "llm", false, "Extract with the configured LLM instead of heuristics")
+	extractCmd.Flags().BoolVar(&extractCreate, "create", false, "Create a child beads issue for each item")
+	extractCmd.Flags().StringVar(&extractPriority, "priority", "", "Priority of created issues (default: the parent's)")
+	rootCmd.AddCommand(extractCmd)
+}
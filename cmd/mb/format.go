@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/spf13/cobra"
+)
+
+// listFormat is the --format value shared by listing commands.
+var listFormat string
+
+// addFormatFlag registers --format on a listing command.
+func addFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&listFormat, "format", "",
+		"Output format: csv, tsv, ndjson, or template=GO_TEMPLATE (default: table)")
+}
+
+// formatNDJSON is the --format value for one JSON object per line. Large
+// listings stream it as rows are read instead of building one array.
+const formatNDJSON = "ndjson"
+
+// templateFuncs are available in --format template=... templates.
+var templateFuncs = template.FuncMap{
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"join":     strings.Join,
+	"truncate": func(n int, s string) string { return display.Truncate(s, n) },
+	"pad":      func(n int, s string) string { return fmt.Sprintf("%-*s", n, s) },
+	"ago":      display.TimeAgo,
+	"date":     display.FormatTime,
+	"account":  display.AccountName,
+	"priority": beads.PriorityFromBeads,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// writeList writes a listing in the --format style. items is what the
+// command would encode for --json (usually a slice); templates and ndjson
+// lines are written once per element, with fields named as in the Go types
+// (e.g. {{.ThreadID}}). header and rows are the CSV/TSV columns.
+func writeList(w io.Writer, format string, items any, header []string, rows [][]string) error {
+	if text, ok := strings.CutPrefix(format, "template="); ok {
+		return writeTemplate(w, text, items)
+	}
+	if format == formatNDJSON {
+		enc := json.NewEncoder(w)
+		for _, item := range listElements(items) {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	cw := csv.NewWriter(w)
+	switch format {
+	case "csv":
+	case "tsv":
+		cw.Comma = '\t'
+	default:
+		return fmt.Errorf("invalid --format %q (must be: csv, tsv, ndjson, template=...)", format)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// listElements returns the elements of items, or items alone if it is not
+// a slice.
+func listElements(items any) []any {
+	var values []any
+	v := reflect.ValueOf(items)
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, v.Index(i).Interface())
+		}
+	} else {
+		values = append(values, items)
+	}
+	return values
+}
+
+// writeTemplate executes a template for each element of items (or once, if
+// items is not a slice). Each result ends with a newline, as in git log
+// --pretty=tformat.
+func writeTemplate(w io.Writer, text string, items any) error {
+	// Let shells pass escapes without $'...' quoting.
+	text = strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(text)
+	tmpl, err := template.New("format").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("parse --format template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, val := range listElements(items) {
+		buf.Reset()
+		if err := tmpl.Execute(&buf, val); err != nil {
+			return fmt.Errorf("execute --format template: %w", err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
+	"slices"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/daviddao/mailbeads/internal/auth"
 	"github.com/daviddao/mailbeads/internal/db"
 	"github.com/daviddao/mailbeads/internal/display"
 	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/mailquery"
+	"github.com/daviddao/mailbeads/internal/provider"
 	msync "github.com/daviddao/mailbeads/internal/sync"
+	"github.com/daviddao/mailbeads/internal/types"
 	"github.com/spf13/cobra"
+	gm "google.golang.org/api/gmail/v1"
 )
 
 var (
@@ -20,76 +27,192 @@ var (
 	gmailCredentials string
 	gmailMaxResults  int
 	gmailFormat      string
+	gmailAll         bool
+	gmailPageToken   string
+
+	// Structured search filters, composed into the query by searchQuery.
+	gmailFrom          string
+	gmailTo            string
+	gmailSubject       string
+	gmailAfter         string
+	gmailBefore        string
+	gmailHasAttachment bool
+	gmailUnread        bool
+)
+
+// Page sizes for mb gmail search --all. Gmail returns at most 500 IDs per
+// page; the cap keeps a broad query from fetching a whole mailbox.
+const (
+	gmailSearchPageSize = 500
+	gmailSearchAllCap   = 2000
 )
 
+// gmailSearchOutput is the --json output of mb gmail search.
+type gmailSearchOutput struct {
+	Messages []gmail.MessageSummary `json:"messages"`
+	// NextPageTokens maps each account with more results to the token
+	// to pass to --page-token for the next page.
+	NextPageTokens map[string]string `json:"next_page_tokens,omitempty"`
+	// CachedAccounts were searched in the local database instead of Gmail,
+	// because of --offline or because Gmail was unreachable.
+	CachedAccounts []string `json:"cached_accounts,omitempty"`
+	// IgnoredTerms are query terms the local search doesn't support.
+	IgnoredTerms []string `json:"ignored_terms,omitempty"`
+}
+
 // gmailCmd is the parent command for Gmail operations.
 var gmailCmd = &cobra.Command{
 	Use:   "gmail",
-	Short: "Gmail operations (search, read)",
+	Short: "Gmail operations (search, read, thread, labels)",
 	Long:  "Search and read Gmail messages using native Go API calls.",
 }
 
 // gmailSearchCmd replaces search_emails.py.
 var gmailSearchCmd = &cobra.Command{
-	Use:   "search QUERY",
+	Use:   "search [QUERY]",
 	Short: "Search Gmail messages",
 	Long: `Search Gmail messages matching a query.
 
 Uses the same query syntax as Gmail's search box.
-Searches across both accounts by default, or use --account to search one.`,
+Searches across both accounts by default, or use --account to search one.
+
+--from, --to, --subject, --after, --before, --has-attachment, and --unread
+add the matching operators to the query, so scripts needn't build Gmail
+syntax themselves. Values with spaces are quoted; dates are YYYY-MM-DD,
+YYYY/MM/DD, today, or yesterday. The QUERY argument is optional when a
+filter flag is given.
+
+Each account returns one page of up to --max-results messages. When there
+are more, the next page token is printed (next_page_tokens in --json
+output, keyed by account); pass it back with --page-token and --account to
+continue. --all follows page tokens until the results run out or reach
+2000 per account.
+
+If Gmail can't be reached for an account (no network, expired token), the
+search falls back to mail already synced to .mailbeads/mail.db and says so.
+With the global --offline flag only the local cache is searched. The local search understands
+from:, to:, cc:, subject:, label:, is:, in:, after:, before:, newer_than:,
+older_than:, quoted phrases, and -negation; other terms are ignored.`,
 	Example: `  mb gmail search "from:someone@example.com"
   mb gmail search "subject:urgent is:unread" -n 20
   mb gmail search "after:2024/01/01 has:attachment"
-  mb gmail search "newer_than:7d" --account user@example.com`,
-	Args: cobra.ExactArgs(1),
+  mb gmail search "newer_than:7d" --account user@example.com
+  mb gmail search "label:receipts" --all --json
+  mb gmail search "label:receipts" --account user@example.com --page-token TOKEN
+  mb gmail search --from boss@example.com --after 2024-01-01 --unread
+  mb gmail search invoice --subject "Q3 report" --has-attachment`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		query := args[0]
+		query, err := searchQuery(args)
+		if err != nil {
+			return err
+		}
 		ctx := context.Background()
-		root := db.FindProjectRoot()
-		if root == "" {
-			return fmt.Errorf("could not find project root (no .git directory)")
+		if gmailPageToken != "" && gmailAccount == "" {
+			return codedErrorf(codeInvalidArgument, "--page-token belongs to one account's results, specify --account")
+		}
+		out := gmailSearchOutput{Messages: []gmail.MessageSummary{}}
+		limit := gmailMaxResults
+		if gmailAll {
+			limit = gmailSearchAllCap
 		}
 
-		accounts := resolveAccounts(root, gmailAccount)
-		if len(accounts) == 0 {
-			return fmt.Errorf("no accounts found — add account directories with credentials.json to the project root")
+		// fallback searches the cache for account ("" for all) after
+		// Gmail failed with err, or reports err if there's no cache.
+		fallback := func(account string, err error) error {
+			if store == nil {
+				return err
+			}
+			if err != nil && !quietFlag {
+				fmt.Fprintf(cmd.ErrOrStderr(), "  ! %s — %v; using local cache\n", account, err)
+			}
+			results, ignored, err := cachedSearch(query, account, limit)
+			if err != nil {
+				return err
+			}
+			out.Messages = append(out.Messages, results...)
+			out.IgnoredTerms = ignored
+			if account == "" {
+				out.CachedAccounts = store.Accounts()
+			} else {
+				out.CachedAccounts = append(out.CachedAccounts, account)
+			}
+			return nil
+		}
+
+		root := db.FindProjectRoot()
+		var accounts []string
+		if root != "" {
+			accounts = resolveAccounts(root, gmailAccount)
+		}
+		switch {
+		case offlineFlag:
+			if store == nil {
+				return codedErrorf(codeNoDB, "--offline needs a mailbeads database — run 'mb init' and 'mb sync' first")
+			}
+			if err := fallback(gmailAccount, nil); err != nil {
+				return err
+			}
+			accounts = nil
+		case root == "":
+			if err := fallback(gmailAccount, fmt.Errorf("could not find project root (no .git directory)")); err != nil {
+				return err
+			}
+		case len(accounts) == 0:
+			if err := fallback(gmailAccount, fmt.Errorf("no accounts found — add account directories with credentials.json to the project root")); err != nil {
+				return err
+			}
 		}
 
-		var allResults []gmail.MessageSummary
 		for _, account := range accounts {
 			credPath := resolveCredentials(root, account, gmailCredentials)
 			svc, err := auth.LoadGmailService(ctx, credPath)
 			if err != nil {
-				if !quietFlag {
+				if err := fallback(account, err); err != nil && !quietFlag {
 					fmt.Fprintf(cmd.ErrOrStderr(), "  ! %s — %v, skipping\n", account, err)
 				}
 				continue
 			}
 
-			results, err := gmail.Search(svc, query, int64(gmailMaxResults))
-			if err != nil {
-				if !quietFlag {
+			results, next, err := searchPages(svc, query)
+			if err != nil && len(results) == 0 {
+				if err := fallback(account, err); err != nil && !quietFlag {
 					fmt.Fprintf(cmd.ErrOrStderr(), "  ! %s — search failed: %v\n", account, err)
 				}
 				continue
 			}
+			if err != nil && !quietFlag {
+				fmt.Fprintf(cmd.ErrOrStderr(), "  ! %s — search failed: %v\n", account, err)
+			}
 
-			allResults = append(allResults, results...)
+			out.Messages = append(out.Messages, results...)
+			if next != "" {
+				if out.NextPageTokens == nil {
+					out.NextPageTokens = make(map[string]string)
+				}
+				out.NextPageTokens[account] = next
+			}
 		}
 
 		if jsonOutput {
-			enc := json.NewEncoder(cmd.OutOrStdout())
-			enc.SetIndent("", "  ")
-			return enc.Encode(allResults)
+			return writeOutput(cmd.OutOrStdout(), out)
 		}
 
-		if len(allResults) == 0 {
+		if len(out.Messages) == 0 {
 			fmt.Fprintf(cmd.OutOrStdout(), "No messages found matching: %s\n", query)
 			return nil
 		}
 
-		fmt.Fprintf(cmd.OutOrStdout(), "Found %d message(s) matching: %s\n\n", len(allResults), query)
-		for i, msg := range allResults {
+		fmt.Fprintf(cmd.OutOrStdout(), "Found %d message(s) matching: %s\n", len(out.Messages), query)
+		if len(out.CachedAccounts) > 0 {
+			note := "from the local cache: " + strings.Join(out.CachedAccounts, ", ")
+			if len(out.IgnoredTerms) > 0 {
+				note += " (ignored: " + strings.Join(out.IgnoredTerms, " ") + ")"
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), display.Dim.Render(note))
+		}
+		fmt.Fprintln(cmd.OutOrStdout())
+		for i, msg := range out.Messages {
 			fmt.Fprintf(cmd.OutOrStdout(), "[%d] ID: %s\n", i+1, msg.ID)
 			fmt.Fprintf(cmd.OutOrStdout(), "    From: %s\n", msg.From)
 			fmt.Fprintf(cmd.OutOrStdout(), "    Subject: %s\n", msg.Subject)
@@ -100,10 +223,106 @@ Searches across both accounts by default, or use --account to search one.`,
 			}
 			fmt.Fprintf(cmd.OutOrStdout(), "    Preview: %s\n\n", snippet)
 		}
+		for _, account := range accounts {
+			if next, ok := out.NextPageTokens[account]; ok {
+				fmt.Fprintf(cmd.OutOrStdout(), "More results for %s: --account %s --page-token %s\n", account, account, next)
+			}
+		}
 		return nil
 	},
 }
 
+// searchQuery joins the QUERY argument and the filter flags into one Gmail
+// query.
+func searchQuery(args []string) (string, error) {
+	var terms []string
+	if len(args) > 0 && strings.TrimSpace(args[0]) != "" {
+		terms = append(terms, strings.TrimSpace(args[0]))
+	}
+	for _, f := range []struct{ op, value string }{
+		{"from", gmailFrom},
+		{"to", gmailTo},
+		{"subject", gmailSubject},
+	} {
+		if v := strings.TrimSpace(f.value); v != "" {
+			terms = append(terms, f.op+":"+quoteTerm(v))
+		}
+	}
+	for _, f := range []struct{ op, value string }{
+		{"after", gmailAfter},
+		{"before", gmailBefore},
+	} {
+		if f.value == "" {
+			continue
+		}
+		d, err := searchDate(f.value)
+		if err != nil {
+			return "", codedErrorf(codeInvalidArgument, "--%s: %v", f.op, err)
+		}
+		terms = append(terms, f.op+":"+d)
+	}
+	if gmailHasAttachment {
+		terms = append(terms, "has:attachment")
+	}
+	if gmailUnread {
+		terms = append(terms, "is:unread")
+	}
+	if len(terms) == 0 {
+		return "", codedErrorf(codeInvalidArgument, "give a QUERY or at least one filter flag (--from, --to, --subject, --after, --before, --has-attachment, --unread)")
+	}
+	return strings.Join(terms, " "), nil
+}
+
+// quoteTerm quotes an operator value containing spaces or parentheses.
+func quoteTerm(v string) string {
+	if strings.ContainsAny(v, " \t()") {
+		return `"` + strings.ReplaceAll(v, `"`, "") + `"`
+	}
+	return v
+}
+
+// searchDate converts a date flag to Gmail's YYYY/MM/DD form.
+func searchDate(s string) (string, error) {
+	now := time.Now()
+	switch strings.ToLower(s) {
+	case "today":
+		return now.Format("2006/01/02"), nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1).Format("2006/01/02"), nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006/01/02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006/01/02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q (use YYYY-MM-DD, YYYY/MM/DD, today, or yesterday)", s)
+}
+
+// searchPages runs the search for one account: a single page starting at
+// --page-token, or with --all every page up to gmailSearchAllCap results.
+// It returns the token for the page after the last one fetched, along with
+// any results fetched before an error.
+func searchPages(svc *gm.Service, query string) ([]gmail.MessageSummary, string, error) {
+	if !gmailAll {
+		return gmail.SearchPage(svc, query, int64(gmailMaxResults), gmailPageToken)
+	}
+	var results []gmail.MessageSummary
+	token := gmailPageToken
+	for {
+		size := min(gmailSearchPageSize, gmailSearchAllCap-len(results))
+		page, next, err := gmail.SearchPage(svc, query, int64(size), token)
+		if err != nil {
+			// Keep what was fetched; the token resumes from the failed page.
+			return results, token, err
+		}
+		results = append(results, page...)
+		token = next
+		if token == "" || len(results) >= gmailSearchAllCap {
+			return results, token, nil
+		}
+	}
+}
+
 // gmailReadCmd replaces read_email.py.
 var gmailReadCmd = &cobra.Command{
 	Use:   "read MESSAGE_ID",
@@ -111,37 +330,82 @@ var gmailReadCmd = &cobra.Command{
 	Long: `Read the full content of a Gmail message.
 
 Fetches the complete message including headers, body, labels, and attachments.
-Automatically detects which account the message belongs to.`,
+Automatically detects which account the message belongs to.
+
+If Gmail can't be reached and the message was synced, it is shown from the
+local cache instead (without attachment details). With the global --offline
+flag only the cache is read.
+
+--format raw prints the original RFC 822 source as Gmail received it
+(all headers, including Received and DKIM-Signature, and the undecoded
+MIME parts), e.g. to save as an .eml file. With --json it is the "raw"
+string field. Messages synced with mb sync --raw fall back to the stored
+source.`,
 	Example: `  mb gmail read 18d5a7b3c4e5f6a7
   mb gmail read 18d5a7b3c4e5f6a7 --format full
+  mb gmail read 18d5a7b3c4e5f6a7 --format raw > message.eml
   mb gmail read 18d5a7b3c4e5f6a7 --json
-  mb gmail read 18d5a7b3c4e5f6a7 --account user@example.com`,
+  mb gmail read 18d5a7b3c4e5f6a7 --account user@example.com
+  mb gmail read 18d5a7b3c4e5f6a7 --offline`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		messageID := args[0]
 		ctx := context.Background()
+		switch gmailFormat {
+		case "basic", "full":
+		case "raw":
+			return readRawMessage(cmd, messageID)
+		default:
+			return codedErrorf(codeInvalidArgument, "invalid --format %q (must be: basic, full, raw)", gmailFormat)
+		}
+		includeFull := gmailFormat == "full"
+
+		var cached *types.Email
+		if store != nil {
+			cached, _ = store.GetEmail(messageID)
+		}
+		if offlineFlag {
+			if store == nil {
+				return codedErrorf(codeNoDB, "--offline needs a mailbeads database — run 'mb init' and 'mb sync' first")
+			}
+			if cached == nil {
+				return fmt.Errorf("message %s is not in the local cache", messageID)
+			}
+			return outputCachedReadResult(cmd, cached, includeFull)
+		}
+
 		root := db.FindProjectRoot()
 		if root == "" {
+			if cached != nil {
+				return outputCachedReadResult(cmd, cached, includeFull)
+			}
 			return fmt.Errorf("could not find project root (no .git directory)")
 		}
 
 		accounts := resolveAccounts(root, gmailAccount)
-		if len(accounts) == 0 {
+		if len(accounts) == 0 && cached == nil {
 			return fmt.Errorf("no accounts found — add account directories with credentials.json to the project root")
 		}
-		includeFull := gmailFormat == "full"
+		if cached != nil && gmailAccount == "" {
+			// Ask the account the cache says has it first.
+			accounts = slices.DeleteFunc(accounts, func(a string) bool { return a == cached.Account })
+			accounts = append([]string{cached.Account}, accounts...)
+		}
 
 		// Try each account until we find the message.
+		var lastErr error
 		for _, account := range accounts {
 			credPath := resolveCredentials(root, account, gmailCredentials)
 			svc, err := auth.LoadGmailService(ctx, credPath)
 			if err != nil {
+				lastErr = err
 				continue
 			}
 
 			if includeFull {
 				msg, err := gmail.ReadFullWithAttachments(svc, messageID)
 				if err != nil {
+					lastErr = err
 					continue // Try next account.
 				}
 				return outputReadResult(cmd, msg, account)
@@ -149,20 +413,385 @@ Automatically detects which account the message belongs to.`,
 
 			msg, err := gmail.ReadFull(svc, messageID)
 			if err != nil {
+				lastErr = err
 				continue // Try next account.
 			}
 			return outputBasicReadResult(cmd, msg, account)
 		}
 
+		if cached != nil {
+			if lastErr != nil && !quietFlag {
+				fmt.Fprintf(cmd.ErrOrStderr(), "  ! %v; using local cache\n", lastErr)
+			}
+			return outputCachedReadResult(cmd, cached, includeFull)
+		}
 		return fmt.Errorf("message %s not found in any account", messageID)
 	},
 }
 
+// readRawMessage prints a message's RFC 822 source from the first account
+// that has it, falling back to the copy stored by mb sync --raw.
+func readRawMessage(cmd *cobra.Command, messageID string) error {
+	var cached *types.Email
+	var stored []byte
+	if store != nil {
+		cached, _ = store.GetEmail(messageID)
+		stored, _ = store.RawSource(messageID)
+	}
+	output := func(msg *gmail.RawMessage) error {
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), msg)
+		}
+		_, err := io.WriteString(cmd.OutOrStdout(), msg.Raw)
+		return err
+	}
+	outputStored := func() error {
+		return output(&gmail.RawMessage{ID: cached.ID, ThreadID: cached.ThreadID, Raw: string(stored)})
+	}
+
+	if offlineFlag {
+		if stored == nil {
+			return errOffline("--format raw for a message not synced with --raw")
+		}
+		return outputStored()
+	}
+	root := db.FindProjectRoot()
+	var accounts []string
+	if root != "" {
+		accounts = resolveAccounts(root, gmailAccount)
+	}
+	if cached != nil && gmailAccount == "" {
+		accounts = slices.DeleteFunc(accounts, func(a string) bool { return a == cached.Account })
+		accounts = append([]string{cached.Account}, accounts...)
+	}
+
+	ctx := context.Background()
+	var lastErr error
+	for _, account := range accounts {
+		svc, err := auth.LoadGmailService(ctx, resolveCredentials(root, account, gmailCredentials))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		msg, err := gmail.ReadRaw(svc, messageID)
+		if err != nil {
+			lastErr = err
+			continue // Try next account.
+		}
+		return output(msg)
+	}
+
+	if stored != nil {
+		if lastErr != nil && !quietFlag {
+			fmt.Fprintf(cmd.ErrOrStderr(), "  ! %v; using the stored source\n", lastErr)
+		}
+		return outputStored()
+	}
+	switch {
+	case root == "":
+		return fmt.Errorf("could not find project root (no .git directory)")
+	case len(accounts) == 0:
+		return fmt.Errorf("no accounts found — add account directories with credentials.json to the project root")
+	}
+	return fmt.Errorf("message %s not found in any account", messageID)
+}
+
+// gmailThreadOutput is the --json output of mb gmail thread.
+type gmailThreadOutput struct {
+	ThreadID string               `json:"thread_id"`
+	Account  string               `json:"account"`
+	Messages []*gmail.FullMessage `json:"messages"`
+	Cached   bool                 `json:"cached,omitempty"`
+}
+
+var gmailThreadCmd = &cobra.Command{
+	Use:   "thread THREAD_ID",
+	Short: "Read every message in a Gmail thread",
+	Long: `Read all messages in a Gmail thread, oldest first, with a single API call.
+
+Automatically detects which account the thread belongs to. If Gmail can't
+be reached and the thread was synced, the synced messages are shown from
+the local cache instead. With the global --offline flag only the cache is
+read.`,
+	Example: `  mb gmail thread 18d5a7b3c4e5f6a7
+  mb gmail thread 18d5a7b3c4e5f6a7 --json
+  mb gmail thread 18d5a7b3c4e5f6a7 --account user@example.com`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		threadID := args[0]
+		ctx := context.Background()
+
+		var cachedAccount string
+		if store != nil {
+			if accounts, err := store.ThreadAccounts(threadID); err == nil && len(accounts) > 0 {
+				cachedAccount = accounts[0]
+				if gmailAccount != "" {
+					cachedAccount = ""
+					if slices.Contains(accounts, gmailAccount) {
+						cachedAccount = gmailAccount
+					}
+				}
+			}
+		}
+		if offlineFlag {
+			if store == nil {
+				return codedErrorf(codeNoDB, "--offline needs a mailbeads database — run 'mb init' and 'mb sync' first")
+			}
+			if cachedAccount == "" {
+				return codedErrorf(codeThreadNotFound, "thread %s is not in the local cache", threadID)
+			}
+			return outputCachedThread(cmd, threadID, cachedAccount)
+		}
+
+		root := db.FindProjectRoot()
+		if root == "" {
+			if cachedAccount != "" {
+				return outputCachedThread(cmd, threadID, cachedAccount)
+			}
+			return fmt.Errorf("could not find project root (no .git directory)")
+		}
+
+		accounts := resolveAccounts(root, gmailAccount)
+		if len(accounts) == 0 && cachedAccount == "" {
+			return fmt.Errorf("no accounts found — add account directories with credentials.json to the project root")
+		}
+		if cachedAccount != "" && gmailAccount == "" {
+			// Ask the account the cache says has it first.
+			accounts = slices.DeleteFunc(accounts, func(a string) bool { return a == cachedAccount })
+			accounts = append([]string{cachedAccount}, accounts...)
+		}
+
+		// Try each account until we find the thread.
+		var lastErr error
+		for _, account := range accounts {
+			credPath := resolveCredentials(root, account, gmailCredentials)
+			svc, err := auth.LoadGmailService(ctx, credPath)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			messages, err := gmail.ReadThread(svc, threadID)
+			if err != nil {
+				lastErr = err
+				continue // Try next account.
+			}
+			return outputThread(cmd, gmailThreadOutput{ThreadID: threadID, Account: account, Messages: messages})
+		}
+
+		if cachedAccount != "" {
+			if lastErr != nil && !quietFlag {
+				fmt.Fprintf(cmd.ErrOrStderr(), "  ! %v; using local cache\n", lastErr)
+			}
+			return outputCachedThread(cmd, threadID, cachedAccount)
+		}
+		return codedErrorf(codeThreadNotFound, "thread %s not found in any account", threadID)
+	},
+}
+
+// outputCachedThread prints a thread's synced messages from the local
+// database in the same shape as a Gmail thread read, marked as cached.
+func outputCachedThread(cmd *cobra.Command, threadID, account string) error {
+	emails, err := store.ThreadEmails(threadID, account)
+	if err != nil {
+		return fmt.Errorf("fetch emails: %w", err)
+	}
+	out := gmailThreadOutput{ThreadID: threadID, Account: account, Messages: []*gmail.FullMessage{}, Cached: true}
+	for _, e := range emails {
+		out.Messages = append(out.Messages, cachedFullMessage(e))
+	}
+	if err := outputThread(cmd, out); err != nil || jsonOutput {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), display.Dim.Render("(from the local cache)"))
+	return nil
+}
+
+func outputThread(cmd *cobra.Command, out gmailThreadOutput) error {
+	if jsonOutput {
+		return writeOutput(cmd.OutOrStdout(), out)
+	}
+
+	w := cmd.OutOrStdout()
+	subject := "(no messages)"
+	if len(out.Messages) > 0 {
+		subject = out.Messages[0].Subject
+	}
+	fmt.Fprintf(w, "Thread: %s\n", subject)
+	fmt.Fprintf(w, "Account: %s\n", display.AccountLabel(out.Account))
+	fmt.Fprintf(w, "Messages: %d\n", len(out.Messages))
+	for _, msg := range out.Messages {
+		fmt.Fprintf(w, "\n%s\n\n", strings.Repeat("=", 60))
+		fmt.Fprintf(w, "From: %s\n", msg.From)
+		fmt.Fprintf(w, "To: %s\n", msg.To)
+		if msg.CC != "" {
+			fmt.Fprintf(w, "Cc: %s\n", msg.CC)
+		}
+		fmt.Fprintf(w, "Date: %s\n", msg.Date)
+		fmt.Fprintf(w, "ID: %s\n\n", msg.ID)
+		fmt.Fprintf(w, "%s\n", strings.TrimRight(msg.Body, "\n"))
+	}
+	return nil
+}
+
+// gmailLabelsOutput is one account's entry in mb gmail labels --json.
+type gmailLabelsOutput struct {
+	Account string        `json:"account"`
+	Labels  []gmail.Label `json:"labels"`
+}
+
+var gmailLabelsCmd = &cobra.Command{
+	Use:   "labels",
+	Short: "List Gmail labels with IDs and message counts",
+	Long: `List each account's Gmail labels with their IDs and message and thread
+counts, system labels first.
+
+Synced emails store label IDs, so user labels appear as IDs like
+Label_123 in --json output and label filters; this maps them to names.`,
+	Example: `  mb gmail labels
+  mb gmail labels --account user@example.com
+  mb gmail labels --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		if offlineFlag {
+			return errOffline("mb gmail labels")
+		}
+		root := db.FindProjectRoot()
+		if root == "" {
+			return fmt.Errorf("could not find project root (no .git directory)")
+		}
+		accounts := resolveAccounts(root, gmailAccount)
+		if len(accounts) == 0 {
+			return fmt.Errorf("no accounts found — add account directories with credentials.json to the project root")
+		}
+
+		var results []gmailLabelsOutput
+		for _, account := range accounts {
+			svc, err := auth.LoadGmailService(ctx, resolveCredentials(root, account, gmailCredentials))
+			if err != nil {
+				return fmt.Errorf("%s: %w", account, err)
+			}
+			labels, err := gmail.ListLabels(svc)
+			if err != nil {
+				return fmt.Errorf("%s: %w", account, err)
+			}
+			results = append(results, gmailLabelsOutput{Account: account, Labels: labels})
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), results)
+		}
+		w := cmd.OutOrStdout()
+		for i, r := range results {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, display.Bold.Render(display.AccountLabel(r.Account)))
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "  NAME\tID\tMESSAGES\tUNREAD")
+			for _, l := range r.Labels {
+				fmt.Fprintf(tw, "  %s\t%s\t%d\t%d\n", l.Name, l.ID, l.MessagesTotal, l.MessagesUnread)
+			}
+			tw.Flush()
+		}
+		return nil
+	},
+}
+
+// cachedSearch runs a Gmail query against the local database for account
+// ("" for all), newest first. It also returns the terms it had to ignore.
+func cachedSearch(query, account string, limit int) ([]gmail.MessageSummary, []string, error) {
+	q := mailquery.Parse(query, time.Now())
+	emails, err := store.SearchEmails(strings.Join(q.Words(), " "), account, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("search cache: %w", err)
+	}
+	results := []gmail.MessageSummary{}
+	for _, e := range emails {
+		if !q.Match(e) {
+			continue
+		}
+		results = append(results, gmail.MessageSummary{
+			ID:        e.ID,
+			ThreadID:  e.ThreadID,
+			MessageID: e.MessageID,
+			From:      e.From,
+			To:        e.To,
+			CC:        e.CC,
+			Subject:   e.Subject,
+			Date:      e.Date,
+			Snippet:   e.Snippet,
+			Labels:    splitLabels(e.Labels),
+		})
+		if limit > 0 && len(results) == limit {
+			break
+		}
+	}
+	return results, q.Unsupported, nil
+}
+
+// outputCachedReadResult prints a message from the local database in the
+// same shape as a Gmail read, marked as cached. Attachment metadata isn't
+// cached, so --format full shows none.
+func outputCachedReadResult(cmd *cobra.Command, e *types.Email, includeFull bool) error {
+	msg := cachedFullMessage(e)
+	if jsonOutput {
+		return writeOutput(cmd.OutOrStdout(), struct {
+			*gmail.FullMessage
+			Cached bool `json:"cached"`
+		}{msg, true})
+	}
+	var err error
+	if includeFull {
+		err = outputReadResult(cmd, &gmail.FullMessageWithAttachments{FullMessage: *msg}, e.Account)
+	} else {
+		err = outputBasicReadResult(cmd, msg, e.Account)
+	}
+	if err != nil {
+		return err
+	}
+	note := "(from the local cache)"
+	if e.BodyMissing {
+		note = "(from the local cache — body not synced, showing the snippet)"
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), display.Dim.Render(note))
+	return nil
+}
+
+// cachedFullMessage converts a stored email to the shape of a Gmail read.
+// Messages synced without a body show their snippet.
+func cachedFullMessage(e *types.Email) *gmail.FullMessage {
+	msg := &gmail.FullMessage{
+		ID:             e.ID,
+		ThreadID:       e.ThreadID,
+		MessageID:      e.MessageID,
+		From:           e.From,
+		To:             e.To,
+		CC:             e.CC,
+		Subject:        e.Subject,
+		Date:           e.Date,
+		Body:           e.Body,
+		Labels:         splitLabels(e.Labels),
+		Snippet:        e.Snippet,
+		AttachmentText: e.AttachmentText,
+	}
+	if e.BodyMissing {
+		msg.Body = e.Snippet
+	}
+	return msg
+}
+
+// splitLabels splits a stored comma-separated label list.
+func splitLabels(labels string) []string {
+	if labels == "" {
+		return nil
+	}
+	return strings.Split(labels, ",")
+}
+
 func outputReadResult(cmd *cobra.Command, msg *gmail.FullMessageWithAttachments, account string) error {
 	if jsonOutput {
-		enc := json.NewEncoder(cmd.OutOrStdout())
-		enc.SetIndent("", "  ")
-		return enc.Encode(msg)
+		return writeOutput(cmd.OutOrStdout(), msg)
 	}
 
 	w := cmd.OutOrStdout()
@@ -191,9 +820,7 @@ func outputReadResult(cmd *cobra.Command, msg *gmail.FullMessageWithAttachments,
 
 func outputBasicReadResult(cmd *cobra.Command, msg *gmail.FullMessage, account string) error {
 	if jsonOutput {
-		enc := json.NewEncoder(cmd.OutOrStdout())
-		enc.SetIndent("", "  ")
-		return enc.Encode(msg)
+		return writeOutput(cmd.OutOrStdout(), msg)
 	}
 
 	w := cmd.OutOrStdout()
@@ -213,12 +840,19 @@ func outputBasicReadResult(cmd *cobra.Command, msg *gmail.FullMessage, account s
 	return nil
 }
 
-// resolveAccounts returns the list of accounts to operate on.
+// resolveAccounts returns the list of accounts to operate on: the given
+// one, or every discovered account served by Gmail.
 func resolveAccounts(root, account string) []string {
 	if account != "" {
 		return []string{account}
 	}
-	return msync.DiscoverAccounts(root)
+	var accounts []string
+	for _, a := range msync.DiscoverAccounts(cfg, root) {
+		if provider.NameFor(cfg, a) == provider.Default {
+			accounts = append(accounts, a)
+		}
+	}
+	return accounts
 }
 
 // resolveCredentials returns the credentials path for an account.
@@ -229,19 +863,57 @@ func resolveCredentials(root, account, explicit string) string {
 	return filepath.Join(root, account, "credentials.json")
 }
 
+// accountService returns an authenticated Gmail service for an account,
+// using its credentials.json in the project root.
+func accountService(ctx context.Context, account string) (*gm.Service, error) {
+	root := db.FindProjectRoot()
+	if root == "" {
+		return nil, fmt.Errorf("could not find project root (no .git directory)")
+	}
+	svc, err := auth.LoadGmailService(ctx, resolveCredentials(root, account, gmailCredentials))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", account, err)
+	}
+	return svc, nil
+}
+
+// accountProvider returns the mail provider serving an account.
+func accountProvider(account string) (provider.MailProvider, provider.Account, error) {
+	root := db.FindProjectRoot()
+	if root == "" {
+		return nil, provider.Account{}, fmt.Errorf("could not find project root (no .git directory)")
+	}
+	p, acct, err := provider.For(cfg, root, account)
+	if err != nil {
+		return nil, acct, fmt.Errorf("%s: %w", account, err)
+	}
+	return p, acct, nil
+}
+
 func init() {
 	// Gmail parent flags.
 	gmailCmd.PersistentFlags().StringVar(&gmailAccount, "account", "", "Gmail account to use (default: all accounts)")
 	gmailCmd.PersistentFlags().StringVar(&gmailCredentials, "credentials", "", "Path to credentials.json")
 
 	// Search flags.
-	gmailSearchCmd.Flags().IntVarP(&gmailMaxResults, "max-results", "n", 10, "Maximum results to return")
+	gmailSearchCmd.Flags().IntVarP(&gmailMaxResults, "max-results", "n", 10, "Maximum results per page")
+	gmailSearchCmd.Flags().BoolVar(&gmailAll, "all", false, "Follow page tokens for all results (up to 2000 per account)")
+	gmailSearchCmd.Flags().StringVar(&gmailPageToken, "page-token", "", "Continue from a next page token (needs --account)")
+	gmailSearchCmd.Flags().StringVar(&gmailFrom, "from", "", "Only messages from this sender (from:)")
+	gmailSearchCmd.Flags().StringVar(&gmailTo, "to", "", "Only messages to this recipient (to:)")
+	gmailSearchCmd.Flags().StringVar(&gmailSubject, "subject", "", "Only messages with this in the subject (subject:)")
+	gmailSearchCmd.Flags().StringVar(&gmailAfter, "after", "", "Only messages after this date (after:)")
+	gmailSearchCmd.Flags().StringVar(&gmailBefore, "before", "", "Only messages before this date (before:)")
+	gmailSearchCmd.Flags().BoolVar(&gmailHasAttachment, "has-attachment", false, "Only messages with attachments (has:attachment)")
+	gmailSearchCmd.Flags().BoolVar(&gmailUnread, "unread", false, "Only unread messages (is:unread)")
 
 	// Read flags.
-	gmailReadCmd.Flags().StringVarP(&gmailFormat, "format", "f", "basic", "Output format: basic or full")
+	gmailReadCmd.Flags().StringVarP(&gmailFormat, "format", "f", "basic", "Output format: basic, full, or raw (RFC 822 source)")
 
 	// Wire up.
 	gmailCmd.AddCommand(gmailSearchCmd)
 	gmailCmd.AddCommand(gmailReadCmd)
+	gmailCmd.AddCommand(gmailThreadCmd)
+	gmailCmd.AddCommand(gmailLabelsCmd)
 	rootCmd.AddCommand(gmailCmd)
 }
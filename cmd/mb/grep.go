@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	"github.com/daviddao/mailbeads/internal/mailquery"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	grepPatterns    []string
+	grepAccount     string
+	grepAfter       string
+	grepBefore      string
+	grepIgnoreCase  bool
+	grepContext     int
+	grepAttachments bool
+	grepLimit       int
+)
+
+// grepMatch is one matching line of a message body.
+type grepMatch struct {
+	ThreadID string `json:"thread_id"`
+	Account  string `json:"account"`
+	EmailID  string `json:"email_id"`
+	From     string `json:"from"`
+	Subject  string `json:"subject"`
+	Date     string `json:"date"`
+	// Line is the 1-based line number in the readable body (or, for
+	// "attachment" matches, the extracted attachment text).
+	Line   int      `json:"line"`
+	In     string   `json:"in"`
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// errGrepLimit stops the scan once --limit messages have matched.
+var errGrepLimit = errors.New("grep limit reached")
+
+var grepCmd = &cobra.Command{
+	Use:   "grep [-e PATTERN]... [PATTERN]",
+	Short: "Search cached message bodies with regular expressions",
+	Long: `Scan the bodies of locally cached emails for lines matching a regular
+expression (Go RE2 syntax), newest message first, and print each matching
+line with its message ID, thread ID, and line number. Several -e patterns
+match a line if any of them does.
+
+HTML bodies are matched as the readable text mb show prints. Messages
+synced with --no-body only have their snippet; run mb sync without it, or
+mb show the thread, to cache the full body. With --attachments, text
+extracted from PDF, DOCX, and plain-text attachments is scanned too.
+
+--after and --before take dates as Gmail does (2024-01-31 or 2024/01/31);
+-C prints lines of context around each match.
+
+Examples:
+  mb grep -e 'invoice #[0-9]+' --account work --after 2024-01-01
+  mb grep -i 'wire transfer|iban' -C 2
+  mb grep -e 'PO-[0-9]{5}' --format ndjson | jq -r .thread_id`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(grepPatterns) > 0 {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		re, err := grepRegexp(append(grepPatterns, args...))
+		if err != nil {
+			return err
+		}
+		var dates []string
+		for _, d := range []struct{ op, value string }{{"after", grepAfter}, {"before", grepBefore}} {
+			if d.value == "" {
+				continue
+			}
+			if len(mailquery.Parse(d.op+":"+d.value, time.Time{}).Unsupported) > 0 {
+				return codedErrorf(codeInvalidArgument, "invalid --%s date %q (use YYYY-MM-DD)", d.op, d.value)
+			}
+			dates = append(dates, d.op+":"+d.value)
+		}
+		q := mailquery.Parse(strings.Join(dates, " "), time.Now())
+
+		// Emails are scanned as they are read; matches are streamed with
+		// --format ndjson and collected otherwise.
+		var matches []grepMatch
+		emit := func(m grepMatch) error {
+			matches = append(matches, m)
+			return nil
+		}
+		var bw *bufio.Writer
+		if listFormat == formatNDJSON && !jsonOutput {
+			bw = bufio.NewWriter(cmd.OutOrStdout())
+			enc := json.NewEncoder(bw)
+			emit = func(m grepMatch) error { return enc.Encode(m) }
+		}
+
+		matched := 0
+		err = store.SearchEmailsFunc("", grepAccount, 0, func(e *types.Email) error {
+			if !q.Match(e) {
+				return nil
+			}
+			found := grepEmail(e, re)
+			if len(found) == 0 {
+				return nil
+			}
+			for _, m := range found {
+				if err := emit(m); err != nil {
+					return err
+				}
+			}
+			if matched++; grepLimit > 0 && matched >= grepLimit {
+				return errGrepLimit
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errGrepLimit) {
+			return fmt.Errorf("scan emails: %w", err)
+		}
+		if bw != nil {
+			return bw.Flush()
+		}
+
+		if jsonOutput {
+			if matches == nil {
+				matches = []grepMatch{}
+			}
+			return writeOutput(cmd.OutOrStdout(), matches)
+		}
+		if listFormat != "" {
+			rows := make([][]string, 0, len(matches))
+			for _, m := range matches {
+				rows = append(rows, []string{m.ThreadID, m.Account, m.EmailID, m.From, m.Subject, m.Date,
+					m.In, strconv.Itoa(m.Line), m.Text})
+			}
+			return writeList(cmd.OutOrStdout(), listFormat, matches,
+				[]string{"thread_id", "account", "email_id", "from", "subject", "date", "in", "line", "text"}, rows)
+		}
+
+		if len(matches) == 0 {
+			fmt.Println("No matches.")
+			return nil
+		}
+		// printed is the last line shown of the current message and
+		// source, so overlapping context isn't repeated.
+		last, printed := "", 0
+		for _, m := range matches {
+			if !strings.HasPrefix(last, m.EmailID+"|") {
+				if last != "" {
+					fmt.Println()
+				}
+				fmt.Printf("%s  %s  %s\n",
+					display.Dim.Render(m.EmailID),
+					display.Bold.Render(display.Truncate(m.Subject, 60)),
+					display.Muted.Render(display.Truncate(m.From, 30)+"  "+display.FormatTime(m.Date)),
+				)
+				fmt.Printf("%s\n", display.Dim.Render("thread "+m.ThreadID+" ("+display.AccountLabel(m.Account)+")"))
+			}
+			if key := m.EmailID + "|" + m.In; key != last {
+				last, printed = key, 0
+			}
+			prefix := ""
+			if m.In != "body" {
+				prefix = m.In + " "
+			}
+			for i, l := range m.Before {
+				if n := m.Line - len(m.Before) + i; n > printed {
+					fmt.Printf("  %s  %s\n", display.Dim.Render(fmt.Sprintf("%s%5d-", prefix, n)), l)
+					printed = n
+				}
+			}
+			if m.Line > printed {
+				fmt.Printf("  %s  %s\n", display.Dim.Render(fmt.Sprintf("%s%5d:", prefix, m.Line)), highlightMatches(m.Text, re))
+				printed = m.Line
+			}
+			for i, l := range m.After {
+				if n := m.Line + 1 + i; n > printed {
+					fmt.Printf("  %s  %s\n", display.Dim.Render(fmt.Sprintf("%s%5d-", prefix, n)), l)
+					printed = n
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// grepRegexp compiles patterns into one regular expression matching any
+// of them.
+func grepRegexp(patterns []string) (*regexp.Regexp, error) {
+	parts := make([]string, len(patterns))
+	for i, p := range patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return nil, codedErrorf(codeInvalidArgument, "invalid pattern %q: %v", p, err)
+		}
+		parts[i] = "(?:" + p + ")"
+	}
+	expr := strings.Join(parts, "|")
+	if grepIgnoreCase {
+		expr = "(?i)" + expr
+	}
+	return regexp.MustCompile(expr), nil
+}
+
+// grepEmail returns the lines of e's body (and attachment text, with
+// --attachments) that match re.
+func grepEmail(e *types.Email, re *regexp.Regexp) []grepMatch {
+	body := htmltext.Readable(e.Body)
+	if body == "" {
+		body = e.Snippet
+	}
+	sources := []struct{ name, text string }{{"body", body}}
+	if grepAttachments && e.AttachmentText != "" {
+		sources = append(sources, struct{ name, text string }{"attachment", e.AttachmentText})
+	}
+
+	var matches []grepMatch
+	for _, src := range sources {
+		lines := strings.Split(strings.ReplaceAll(src.text, "\r\n", "\n"), "\n")
+		for i, line := range lines {
+			if !re.MatchString(line) {
+				continue
+			}
+			m := grepMatch{
+				ThreadID: e.ThreadID,
+				Account:  e.Account,
+				EmailID:  e.ID,
+				From:     e.From,
+				Subject:  e.Subject,
+				Date:     e.SentAt,
+				Line:     i + 1,
+				In:       src.name,
+				Text:     line,
+			}
+			if grepContext > 0 {
+				m.Before = lines[max(0, i-grepContext):i]
+				m.After = lines[i+1 : min(len(lines), i+1+grepContext)]
+			}
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// highlightMatches renders the parts of line matched by re in bold.
+func highlightMatches(line string, re *regexp.Regexp) string {
+	return re.ReplaceAllStringFunc(line, func(s string) string {
+		return display.Bold.Render(s)
+	})
+}
+
+func init() {
+	grepCmd.Flags().StringArrayVarP(&grepPatterns, "regexp", "e", nil, "Pattern to match (repeatable; a line matching any is printed)")
+	grepCmd.Flags().StringVar(&grepAccount, "account", "", "Only messages in this account")
+	grepCmd.Flags().StringVar(&grepAfter, "after", "", "Only messages sent on or after this date")
+	grepCmd.Flags().StringVar(&grepBefore, "before", "", "Only messages sent before this date")
+	grepCmd.Flags().BoolVarP(&grepIgnoreCase, "ignore-case", "i", false, "Match case-insensitively")
+	grepCmd.Flags().IntVarP(&grepContext, "context", "C", 0, "Lines of context to print around each match")
+	grepCmd.Flags().BoolVar(&grepAttachments, "attachments", false, "Also scan text extracted from attachments")
+	grepCmd.Flags().IntVarP(&grepLimit, "limit", "n", 0, "Stop after this many matching messages (0 = all)")
+	addFormatFlag(grepCmd)
+	rootCmd.AddCommand(grepCmd)
+}
@@ -1,8 +1,8 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/daviddao/mailbeads/internal/beads"
@@ -14,14 +14,37 @@ var (
 	inboxAccount  string
 	inboxPriority string
 	inboxAll      bool
+	inboxGroupBy  string
 )
 
+// inboxGroup is one section of mb inbox --group-by.
+type inboxGroup struct {
+	Group string        `json:"group"`
+	Count int           `json:"count"`
+	Items []beads.Issue `json:"items"`
+}
+
 var inboxCmd = &cobra.Command{
 	Use:   "inbox",
 	Short: "List pending triage items from beads, sorted by priority",
+	Long: `List pending triage items from beads, sorted by priority.
+
+Use --group-by to split the list into sections by category, account, or
+priority. With --json, grouped output is a list of {group, count, items}.
+
+Examples:
+  mb inbox
+  mb inbox --group-by category
+  mb inbox --group-by account --json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		switch inboxGroupBy {
+		case "", "category", "account", "priority":
+		default:
+			return fmt.Errorf("invalid --group-by %q (must be: category, account, priority)", inboxGroupBy)
+		}
+
 		if !beads.Available() {
-			return fmt.Errorf("bd (beads) CLI not found on PATH")
+			return errBDMissing
 		}
 
 		labels := []string{"email", "triage"}
@@ -58,10 +81,39 @@ var inboxCmd = &cobra.Command{
 			issues = filtered
 		}
 
+		var groups []inboxGroup
+		if inboxGroupBy != "" {
+			groups = groupIssues(issues, inboxGroupBy)
+		}
+
 		if jsonOutput {
-			enc := json.NewEncoder(cmd.OutOrStdout())
-			enc.SetIndent("", "  ")
-			return enc.Encode(issues)
+			if groups != nil {
+				return writeOutput(cmd.OutOrStdout(), groups)
+			}
+			return writeOutput(cmd.OutOrStdout(), issues)
+		}
+
+		if listFormat != "" {
+			header := []string{"bead_id", "priority", "status", "title", "thread_id", "due_at", "created_at"}
+			row := func(issue beads.Issue) []string {
+				return []string{
+					issue.ID, beads.PriorityFromBeads(issue.Priority), issue.Status, issue.Title,
+					beads.ThreadIDFromRef(issue.ExternalRef), issue.DueAt, issue.CreatedAt,
+				}
+			}
+			var rows [][]string
+			if groups == nil {
+				for _, issue := range issues {
+					rows = append(rows, row(issue))
+				}
+				return writeList(cmd.OutOrStdout(), listFormat, issues, header, rows)
+			}
+			for _, g := range groups {
+				for _, issue := range g.Items {
+					rows = append(rows, append([]string{g.Group}, row(issue)...))
+				}
+			}
+			return writeList(cmd.OutOrStdout(), listFormat, groups, append([]string{"group"}, header...), rows)
 		}
 
 		if len(issues) == 0 {
@@ -75,22 +127,102 @@ var inboxCmd = &cobra.Command{
 		}
 		fmt.Printf("Inbox (%d %s):\n\n", len(issues), label)
 
-		for _, issue := range issues {
-			pri := beads.PriorityFromBeads(issue.Priority)
-			fmt.Printf("  %s %s  %s  %s\n",
-				display.PriorityDot(pri),
-				display.Dim.Render(issue.ID),
-				display.PriorityLabel(pri),
-				display.Dim.Render(issue.Title),
-			)
+		if groups == nil {
+			printInboxItems(issues)
+			return nil
+		}
+		for i, g := range groups {
+			if i > 0 {
+				fmt.Println()
+			}
+			display.Header(fmt.Sprintf("%s (%d)", groupTitle(inboxGroupBy, g.Group), g.Count))
+			printInboxItems(g.Items)
 		}
 		return nil
 	},
 }
 
+func printInboxItems(issues []beads.Issue) {
+	for _, issue := range issues {
+		pri := beads.PriorityFromBeads(issue.Priority)
+		account := ""
+		if a := beads.NoteField(issue.Notes, "account"); a != "" {
+			account = display.AccountLabel(a) + "  "
+		}
+		fmt.Printf("  %s %s  %s  %s%s  %s\n",
+			display.PriorityDot(pri),
+			display.Dim.Render(issue.ID),
+			display.PriorityLabel(pri),
+			account,
+			display.Dim.Render(issue.Title),
+			display.Muted.Render(display.FormatTime(issue.CreatedAt)),
+		)
+	}
+}
+
+// groupIssues splits issues into sections by category, account, or
+// priority, keeping their order within each section. Priority sections run
+// high to spam; the others are alphabetical with the catch-all group last.
+func groupIssues(issues []beads.Issue, by string) []inboxGroup {
+	index := make(map[string]int)
+	var groups []inboxGroup
+	for _, issue := range issues {
+		var key string
+		switch by {
+		case "category":
+			key = beads.Category(issue)
+			if key == "" {
+				key = "uncategorized"
+			}
+		case "account":
+			key = beads.NoteField(issue.Notes, "account")
+			if key == "" {
+				key = "unknown"
+			}
+		case "priority":
+			key = beads.PriorityFromBeads(issue.Priority)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, inboxGroup{Group: key})
+		}
+		groups[i].Items = append(groups[i].Items, issue)
+		groups[i].Count++
+	}
+
+	rank := func(g inboxGroup) string {
+		if by == "priority" {
+			return beads.PriorityToBeads(g.Group)
+		}
+		if g.Group == "uncategorized" || g.Group == "unknown" {
+			return "\xff"
+		}
+		return g.Group
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return rank(groups[i]) < rank(groups[j])
+	})
+	return groups
+}
+
+// groupTitle is the section heading for a group.
+func groupTitle(by, group string) string {
+	switch by {
+	case "priority":
+		return display.PriorityDot(group) + " " + strings.ToUpper(group)
+	case "account":
+		return display.AccountLabel(group)
+	}
+	return group
+}
+
 func init() {
 	inboxCmd.Flags().StringVar(&inboxAccount, "account", "", "Filter by account (partial match)")
 	inboxCmd.Flags().StringVar(&inboxPriority, "priority", "", "Filter by priority")
 	inboxCmd.Flags().BoolVar(&inboxAll, "all", false, "Include closed/dismissed")
+	inboxCmd.Flags().StringVar(&inboxGroupBy, "group-by", "", "Group into sections: category, account, priority")
+	addFormatFlag(inboxCmd)
 	rootCmd.AddCommand(inboxCmd)
 }
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	"github.com/daviddao/mailbeads/internal/links"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	linksAccount string
+	linksKind    string
+)
+
+// threadLink is one distinct URL found in a thread.
+type threadLink struct {
+	URL   string `json:"url"`
+	Kind  string `json:"kind"`
+	Host  string `json:"host"`
+	Count int    `json:"count"`
+	// EmailID and From identify the first message that contained the link.
+	EmailID string `json:"email_id"`
+	From    string `json:"from"`
+	SentAt  string `json:"sent_at"`
+}
+
+var linksCmd = &cobra.Command{
+	Use:   "links THREAD_ID",
+	Short: "List the URLs in a thread, deduped and classified",
+	Long: `List the distinct URLs found in a thread's message bodies.
+
+Each link is classified as doc, pr, issue, calendar, meeting, unsubscribe, or
+other, and deduped ignoring utm_* tracking parameters. Links are listed in the
+order they first appear, with the message that contained them.
+
+Examples:
+  mb links 19abc123
+  mb links "design review" --kind doc
+  mb links 19abc123 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch linksKind {
+		case "", links.KindDoc, links.KindPR, links.KindIssue, links.KindCalendar,
+			links.KindMeeting, links.KindUnsubscribe, links.KindOther:
+		default:
+			return fmt.Errorf("invalid --kind %q (must be: doc, pr, issue, calendar, meeting, unsubscribe, other)", linksKind)
+		}
+
+		threadID, account, err := resolveThread(args[0], linksAccount)
+		if err != nil {
+			return err
+		}
+		emails, err := store.ThreadEmails(threadID, account)
+		if err != nil {
+			return fmt.Errorf("fetch emails: %w", err)
+		}
+		if len(emails) == 0 {
+			return fmt.Errorf("no emails found for thread %q in %s", threadID, account)
+		}
+		fetchMissingBodies(emails)
+
+		found := threadLinks(emails)
+		if linksKind != "" {
+			filtered := found[:0]
+			for _, l := range found {
+				if l.Kind == linksKind {
+					filtered = append(filtered, l)
+				}
+			}
+			found = filtered
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), found)
+		}
+
+		if listFormat != "" {
+			rows := make([][]string, 0, len(found))
+			for _, l := range found {
+				rows = append(rows, []string{
+					l.URL, l.Kind, l.Host, strconv.Itoa(l.Count), l.EmailID, l.From, l.SentAt,
+				})
+			}
+			return writeList(cmd.OutOrStdout(), listFormat, found,
+				[]string{"url", "kind", "host", "count", "email_id", "from", "sent_at"}, rows)
+		}
+
+		if len(found) == 0 {
+			fmt.Println("No links found.")
+			return nil
+		}
+
+		fmt.Printf("Links in %q (%d):\n\n", emails[0].Subject, len(found))
+		for _, l := range found {
+			fmt.Printf("  %-11s %s\n", display.Dim.Render(l.Kind), l.URL)
+			fmt.Printf("  %-11s %s\n", "", display.Muted.Render(
+				display.Truncate(l.From, 40)+"  "+display.FormatTime(l.SentAt)))
+		}
+		return nil
+	},
+}
+
+// threadLinks collects the distinct links in a thread's bodies, in order of
+// first appearance.
+func threadLinks(emails []*types.Email) []threadLink {
+	index := make(map[string]int)
+	result := []threadLink{}
+	for _, e := range emails {
+		body := htmltext.Readable(e.Body)
+		if body == "" {
+			body = e.Snippet
+		}
+		for _, raw := range links.Extract(body) {
+			u := links.Normalize(raw)
+			if i, ok := index[u]; ok {
+				result[i].Count++
+				continue
+			}
+			index[u] = len(result)
+			var host string
+			if parsed, err := url.Parse(u); err == nil {
+				host = parsed.Host
+			}
+			result = append(result, threadLink{
+				URL:     u,
+				Kind:    links.Classify(u),
+				Host:    host,
+				Count:   1,
+				EmailID: e.ID,
+				From:    e.From,
+				SentAt:  e.SentAt,
+			})
+		}
+	}
+	return result
+}
+
+// threadCodeLinks collects the distinct pull requests and issues a
+// thread's bodies link to, in order of first appearance.
+func threadCodeLinks(emails []*types.Email) []*types.CodeLink {
+	var result []*types.CodeLink
+	seen := make(map[string]bool)
+	for _, e := range emails {
+		body := htmltext.Readable(e.Body)
+		if body == "" {
+			body = e.Snippet
+		}
+		for _, raw := range links.Extract(body) {
+			if c := links.Code(raw); c != nil && !seen[c.URL] {
+				seen[c.URL] = true
+				result = append(result, c)
+			}
+		}
+	}
+	return result
+}
+
+func init() {
+	linksCmd.Flags().StringVar(&linksAccount, "account", "", "Account the thread belongs to")
+	linksCmd.Flags().StringVar(&linksKind, "kind", "", "Only show one kind: doc, pr, issue, calendar, meeting, unsubscribe, other")
+	addFormatFlag(linksCmd)
+	rootCmd.AddCommand(linksCmd)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var llmTestProvider string
+
+// llmTestPrompt asks for a reply short enough to be cheap on any backend.
+const llmTestPrompt = "Reply with the single word OK."
+
+// llmTestOutput is the result of mb llm test.
+type llmTestOutput struct {
+	Provider  string   `json:"provider,omitempty"`
+	Type      string   `json:"type"`
+	Model     string   `json:"model,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Command   []string `json:"command,omitempty"`
+	Local     bool     `json:"local"`
+	LatencyMS int64    `json:"latency_ms"`
+	Reply     string   `json:"reply"`
+}
+
+var llmCmd = &cobra.Command{
+	Use:   "llm",
+	Short: "Check the LLM backend used by AI features",
+	Long: `Check the language-model backend that mb auto-triage, mb extract --llm,
+and mb draft-reply use, configured under "llm" in .mailbeads/config.json:
+a command that reads the prompt on stdin, or a provider API.
+
+  {
+    "llm": {
+      "provider": "claude",
+      "providers": {
+        "claude": {"type": "anthropic", "model": "claude-sonnet-4-5", "max_tokens": 2048},
+        "gpt": {"type": "openai", "model": "gpt-4o-mini", "api_key_env": "MB_OPENAI_KEY"},
+        "azure": {"model": "my-deployment", "url": "https://RESOURCE.openai.azure.com"},
+        "ollama": {"model": "llama3.1"}
+      }
+    }
+  }
+
+type is anthropic, openai, azure, or ollama, and defaults to the
+provider's name. API keys are read from api_key_env, by default
+$ANTHROPIC_API_KEY, $OPENAI_API_KEY, or $AZURE_OPENAI_API_KEY; ollama
+needs none. provider picks the one to use; it defaults to command when
+set, else to the only provider.
+
+An ollama provider (at $OLLAMA_HOST or http://localhost:11434 unless url
+says otherwise) keeps mail on this machine. "local_only": true makes
+that a guarantee: any provider whose url isn't a loopback address, and
+any command, is refused. Local backends also work under --offline.
+context_tokens sets ollama's context window (default 8192); its own
+smaller default would cut long threads short.`,
+}
+
+var llmTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test prompt to the LLM backend",
+	Long: `Send a short prompt to the configured LLM backend, or to the provider
+named by --provider, and print the reply and how long it took. A missing
+API key, an unknown model, or an unreachable server fails with the
+backend's error.
+
+Examples:
+  mb llm test
+  mb llm test --provider ollama
+  mb llm test --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		llmCfg := cfg.LLM
+		if llmTestProvider != "" {
+			if _, ok := llmCfg.Providers[llmTestProvider]; !ok {
+				return codedErrorf(codeInvalidArgument, "LLM provider %q is not in llm.providers", llmTestProvider)
+			}
+			llmCfg.Provider = llmTestProvider
+		}
+		backend, err := llm.Select(llmCfg)
+		if err != nil {
+			return err
+		}
+		if offlineFlag && !backend.Local() {
+			return errOffline("the LLM backend")
+		}
+
+		start := time.Now()
+		reply, err := backend.Complete(llmTestPrompt)
+		if err != nil {
+			return err
+		}
+		latency := time.Since(start)
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), llmTestOutput{
+				Provider:  backend.Name,
+				Type:      backend.Type,
+				Model:     backend.Model,
+				URL:       backend.URL,
+				Command:   backend.Command,
+				Local:     backend.Local(),
+				LatencyMS: latency.Milliseconds(),
+				Reply:     reply,
+			})
+		}
+		where := ""
+		if backend.Local() {
+			where = " (local)"
+		}
+		display.SuccessMsg("%s%s replied in %s", backend, where, latency.Round(time.Millisecond))
+		if !quietFlag {
+			fmt.Printf("  %s %s\n", display.Dim.Render("reply:"), display.Truncate(reply, 200))
+		}
+		return nil
+	},
+}
+
+// completeLLM sends prompt to the configured LLM backend. In offline mode
+// only a local backend is used, since any other may be a remote API.
+func completeLLM(prompt string) (string, error) {
+	backend, err := llm.Select(cfg.LLM)
+	if err != nil {
+		return "", err
+	}
+	if offlineFlag && !backend.Local() {
+		return "", errOffline("the LLM backend")
+	}
+	return backend.Complete(prompt)
+}
+
+func init() {
+	llmTestCmd.Flags().StringVar(&llmTestProvider, "provider", "", "Test this entry of llm.providers instead of the configured backend")
+	llmCmd.AddCommand(llmTestCmd)
+	rootCmd.AddCommand(llmCmd)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logSince  time.Duration
+	logAction string
+	logActor  string
+	logThread string
+	logBead   string
+	logLimit  int
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the audit log of mutating mb actions",
+	Long: `Show the audit log: every triage, update, done, dismiss, undo, sync,
+unsubscribe, RSVP, duplicate link, and migration, newest first.
+
+Each entry records when it happened, the actor, the full command line, and
+the thread and bead it touched. The actor is $MB_ACTOR if set (e.g. the
+name of an agent driving mb), otherwise the OS user.
+
+Examples:
+  mb log
+  mb log --since 24h --action dismiss
+  mb log --bead bd-a3f8
+  MB_ACTOR=triage-agent mb triage 19abc123 --action "FYI"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f := types.AuditFilter{
+			Action:   logAction,
+			Actor:    logActor,
+			ThreadID: logThread,
+			BeadID:   logBead,
+			Limit:    logLimit,
+		}
+		if logSince > 0 {
+			f.Since = time.Now().Add(-logSince).UTC().Format(time.RFC3339)
+		}
+		entries, err := store.AuditLog(f)
+		if err != nil {
+			return fmt.Errorf("read audit log: %w", err)
+		}
+
+		if jsonOutput {
+			if entries == nil {
+				entries = []*types.AuditEntry{}
+			}
+			return writeOutput(cmd.OutOrStdout(), entries)
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No audit entries.")
+			return nil
+		}
+		for _, e := range entries {
+			target := e.BeadID
+			if e.ThreadID != "" {
+				target = strings.TrimSpace(e.ThreadID + " " + target)
+			}
+			fmt.Printf("  %-12s %-12s %-10s %-24s %s\n",
+				display.Muted.Render(display.FormatTime(e.At)),
+				display.Dim.Render(display.Truncate(e.Actor, 12)),
+				e.Action,
+				target,
+				display.Dim.Render(e.Detail),
+			)
+		}
+		return nil
+	},
+}
+
+// auditActor identifies who is running mb: $MB_ACTOR, else the OS user.
+func auditActor() string {
+	if a := os.Getenv("MB_ACTOR"); a != "" {
+		return a
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "unknown"
+}
+
+// auditCommand is the current command line, with arguments containing
+// spaces quoted.
+func auditCommand() string {
+	parts := []string{"mb"}
+	for _, a := range os.Args[1:] {
+		if strings.ContainsAny(a, " \t\n\"'") {
+			a = fmt.Sprintf("%q", a)
+		}
+		parts = append(parts, a)
+	}
+	return strings.Join(parts, " ")
+}
+
+// audit appends an entry to the audit log. Failures are reported but never
+// fail the action being logged.
+func audit(action, threadID, account, beadID, detail string) {
+	if store == nil {
+		return
+	}
+	if err := store.AppendAudit(auditEntry(action, threadID, account, beadID, detail)); err != nil {
+		display.ErrorMsg("audit log: %v", err)
+	}
+}
+
+// auditEntry is an audit log entry for an action of this command.
+func auditEntry(action, threadID, account, beadID, detail string) *types.AuditEntry {
+	return &types.AuditEntry{
+		Actor:    auditActor(),
+		Command:  auditCommand(),
+		Action:   action,
+		ThreadID: threadID,
+		Account:  account,
+		BeadID:   beadID,
+		Detail:   detail,
+	}
+}
+
+func init() {
+	logCmd.Flags().DurationVar(&logSince, "since", 0, "Only entries within this window (e.g. 24h)")
+	logCmd.Flags().StringVar(&logAction, "action", "", "Filter by action (triage, update, done, dismiss, undo, sync, ...)")
+	logCmd.Flags().StringVar(&logActor, "actor", "", "Filter by actor")
+	logCmd.Flags().StringVar(&logThread, "thread", "", "Filter by thread ID")
+	logCmd.Flags().StringVar(&logBead, "bead", "", "Filter by bead ID")
+	logCmd.Flags().IntVar(&logLimit, "limit", 50, "Maximum entries to show (0 for all)")
+	rootCmd.AddCommand(logCmd)
+}
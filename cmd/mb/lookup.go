@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// threadIDPattern matches Gmail thread IDs (hex). Arguments that look like
+// one are never fuzzy-matched.
+var threadIDPattern = regexp.MustCompile(`^[0-9a-f]{12,20}$`)
+
+// resolveThread resolves a THREAD_ID argument to a thread and its account.
+// An argument that is not a known thread ID is matched against thread
+// subjects and senders; if several threads match equally well the error
+// lists them so the caller can pick one.
+func resolveThread(arg, explicit string) (threadID, account string, err error) {
+	accounts, err := store.ThreadAccounts(arg)
+	if err != nil {
+		return "", "", fmt.Errorf("lookup thread: %w", err)
+	}
+	if len(accounts) > 0 || threadIDPattern.MatchString(arg) {
+		account, err := resolveThreadAccount(arg, explicit)
+		return arg, account, err
+	}
+
+	threads, err := store.Threads(explicit)
+	if err != nil {
+		return "", "", fmt.Errorf("search threads: %w", err)
+	}
+	matches := fuzzyThreads(threads, arg)
+	switch {
+	case len(matches) == 0:
+		return "", "", codedErrorf(codeThreadNotFound, "no thread ID or subject/sender matching %q", arg)
+	case len(matches) == 1 || matches[0].score > matches[1].score:
+		return matches[0].ThreadID, matches[0].Account, nil
+	}
+
+	var b strings.Builder
+	var candidates []map[string]string
+	fmt.Fprintf(&b, "%q matches %d threads, use a thread ID:\n", arg, len(matches))
+	for i, m := range matches {
+		if i == 10 {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(matches)-10)
+			break
+		}
+		fmt.Fprintf(&b, "  %-16s %-10s %-50s  %s\n", m.ThreadID, display.AccountName(m.Account),
+			display.Truncate(m.Subject, 50), display.Dim.Render(display.Truncate(m.From, 30)))
+		candidates = append(candidates, map[string]string{
+			"thread_id": m.ThreadID, "account": m.Account, "subject": m.Subject, "from": m.From,
+		})
+	}
+	ambiguous := codedErrorf(codeAmbiguousThread, "%s", strings.TrimRight(b.String(), "\n"))
+	ambiguous.Details = map[string]any{"query": arg, "matches": candidates}
+	return "", "", ambiguous
+}
+
+type threadMatch struct {
+	*types.Thread
+	score int
+}
+
+// fuzzyThreads returns threads whose subject or sender contains every word
+// of query (case-insensitive), best first. A word matches a subject word
+// exactly (3 points), as a substring of the subject (2), or of the sender (1).
+// Ties keep the most recent thread first.
+func fuzzyThreads(threads []*types.Thread, query string) []threadMatch {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	var matches []threadMatch
+	for _, t := range threads {
+		subject := strings.ToLower(t.Subject)
+		from := strings.ToLower(t.From)
+		subjectWords := make(map[string]bool)
+		for _, w := range strings.FieldsFunc(subject, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127)
+		}) {
+			subjectWords[w] = true
+		}
+
+		score := 0
+		for _, w := range words {
+			switch {
+			case subjectWords[w]:
+				score += 3
+			case strings.Contains(subject, w):
+				score += 2
+			case strings.Contains(from, w):
+				score++
+			default:
+				score = -1
+			}
+			if score < 0 {
+				break
+			}
+		}
+		if score > 0 {
+			matches = append(matches, threadMatch{Thread: t, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	return matches
+}
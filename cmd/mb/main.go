@@ -2,12 +2,21 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/daviddao/mailbeads/internal/auth"
+	"github.com/daviddao/mailbeads/internal/config"
 	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -15,10 +24,20 @@ import (
 var Version = "dev"
 
 var (
-	dbPath     string
-	jsonOutput bool
-	quietFlag  bool
-	store      *db.DB
+	dbPath      string
+	seedFile    string
+	jsonOutput  bool
+	quietFlag   bool
+	noColorFlag bool
+	offlineFlag bool
+	logLevel    string
+	logFormat   string
+	logFile     string
+	store       db.Store
+	cfg         = config.Default()
+	// mbDir is the .mailbeads directory: config.json, templates, and the
+	// classifier live there even when the database is on a server.
+	mbDir string
 )
 
 var rootCmd = &cobra.Command{
@@ -26,13 +45,36 @@ var rootCmd = &cobra.Command{
 	Short: "mb - Email inbox triage for AI agents",
 	Long:  "Mailbeads: sync Gmail, triage threads, track dependencies. Inspired by beads.",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		display.SetColor(display.ColorEnabled(noColorFlag))
+		if os.Getenv("MB_OFFLINE") != "" {
+			offlineFlag = true
+		}
+		auth.SetOffline(offlineFlag)
+		level := logLevel
+		if level == "" && quietFlag {
+			level = "warn"
+		}
+		if _, err := logging.Setup(logging.Options{Level: level, Format: logFormat, File: logFile}); err != nil {
+			return codedErrorf(codeInvalidArgument, "%v", err)
+		}
+		// Scripts reading structured output get the error object alone.
+		cmd.SilenceUsage = jsonRequested()
+		if err := resolveOutputFormat(); err != nil {
+			return err
+		}
+
 		// Skip DB for commands that don't need it
 		name := cmd.Name()
 		switch name {
 		case "init", "help", "version", "quickstart", "onboard":
 			return nil
-		case "search", "read":
-			// Gmail subcommands don't need the DB
+		case "search", "read", "thread":
+			// Gmail subcommands only use the DB as an offline fallback.
+			if cmd.Parent() != nil && cmd.Parent().Name() == "gmail" {
+				openOptionalStore()
+				return nil
+			}
+		case "labels":
 			if cmd.Parent() != nil && cmd.Parent().Name() == "gmail" {
 				return nil
 			}
@@ -41,35 +83,17 @@ var rootCmd = &cobra.Command{
 			return nil
 		case "prime":
 			// Prime works without DB (just no live stats)
-			path := dbPath
-			if path == "" {
-				path = db.DiscoverDB()
-			}
-			if path != "" {
-				var err error
-				store, err = db.Open(path)
-				if err != nil {
-					store = nil // continue without DB
-				}
-			}
+			openOptionalStore()
 			return nil
 		}
 
-		// Discover database
-		path := dbPath
-		if path == "" {
-			path = db.DiscoverDB()
-		}
-		if path == "" {
-			return fmt.Errorf("no mailbeads database found — run 'mb init' first")
+		if err := openStore(); err != nil {
+			return err
 		}
-
-		var err error
-		store, err = db.Open(path)
-		if err != nil {
-			return fmt.Errorf("open database: %w", err)
+		if cfg.Gmail.RequestsPerSecond > 0 {
+			gmail.SetRequestsPerSecond(cfg.Gmail.RequestsPerSecond)
 		}
-		return nil
+		return applyDisplayConfig(cfg.Display)
 	},
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
 		if store != nil {
@@ -112,6 +136,127 @@ var initCmd = &cobra.Command{
 	},
 }
 
+// openStore loads the config and opens the database: --db if given (a
+// file path or a DSN), else the storage DSN from config.json, else
+// .mailbeads/mail.db. A DSN is a PostgreSQL or libsql server, which
+// --offline rules out. With --db :memory: the config still comes from the
+// project's .mailbeads directory, if there is one.
+func openStore() error {
+	target := dbPath
+	if target != "" && target != db.MemoryPath && !db.IsDSN(target) {
+		mbDir = filepath.Dir(target)
+	} else {
+		mbDir = db.DiscoverDir()
+	}
+	if mbDir != "" {
+		loaded, err := config.Load(mbDir)
+		if err != nil {
+			return err
+		}
+		cfg = loaded
+	}
+	if target == "" && seedFile != "" {
+		// Seeding without --db is scratch work; keep it off the project's
+		// database.
+		target = db.MemoryPath
+	}
+	if target == "" {
+		target = cfg.Storage.DSN
+	}
+	if target == "" && mbDir != "" {
+		if _, err := os.Stat(filepath.Join(mbDir, "mail.db")); err == nil {
+			target = filepath.Join(mbDir, "mail.db")
+		}
+	}
+	if target == "" {
+		return codedErrorf(codeNoDB, "no mailbeads database found — run 'mb init' first")
+	}
+	if offlineFlag && db.IsDSN(target) {
+		return errOffline("the shared database")
+	}
+
+	s, err := db.Open(target)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	store = s
+	if seedFile != "" {
+		return seedStore(seedFile)
+	}
+	return nil
+}
+
+// openOptionalStore opens the database if there is one, leaving store nil
+// otherwise, for commands that work without it.
+func openOptionalStore() {
+	if err := openStore(); err != nil {
+		store = nil // continue without DB
+	}
+}
+
+// applyDisplayConfig sets the display time zone and date style.
+func applyDisplayConfig(dc config.DisplayConfig) error {
+	if dc.Timezone != "" {
+		loc, err := time.LoadLocation(dc.Timezone)
+		if err != nil {
+			return fmt.Errorf("config display.timezone: %w", err)
+		}
+		time.Local = loc
+	}
+	opts := display.DateOptions{}
+	switch dc.Dates {
+	case "", "relative":
+	case "absolute":
+		opts.Absolute = true
+	default:
+		return fmt.Errorf("config display.dates: %q (must be: relative, absolute)", dc.Dates)
+	}
+	switch dc.Clock {
+	case "", "24h":
+	case "12h":
+		opts.Clock12 = true
+	default:
+		return fmt.Errorf("config display.clock: %q (must be: 24h, 12h)", dc.Clock)
+	}
+	display.SetDateOptions(opts)
+
+	if err := display.ApplyTheme(dc.Theme); err != nil {
+		return fmt.Errorf("config display.theme: %w", err)
+	}
+
+	accounts := make(map[string]display.AccountStyle, len(dc.Accounts))
+	for key, a := range dc.Accounts {
+		accounts[key] = display.AccountStyle{Label: a.Label, Color: a.Color, Icon: a.Icon}
+	}
+	if err := display.SetAccountStyles(accounts); err != nil {
+		return fmt.Errorf("config display.accounts: %w", err)
+	}
+	return nil
+}
+
+// resolveThreadAccount returns the account a thread belongs to. If explicit
+// is set it is returned as-is; otherwise the thread must exist in exactly one
+// account.
+func resolveThreadAccount(threadID, explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	accounts, err := store.ThreadAccounts(threadID)
+	if err != nil {
+		return "", fmt.Errorf("lookup thread: %w", err)
+	}
+	switch len(accounts) {
+	case 0:
+		return "", codedErrorf(codeThreadNotFound, "thread %q not found", threadID)
+	case 1:
+		return accounts[0], nil
+	default:
+		err := codedErrorf(codeAmbiguousAccount, "thread exists in multiple accounts (%v), specify --account", accounts)
+		err.Details = map[string]any{"thread_id": threadID, "accounts": accounts}
+		return "", err
+	}
+}
+
 // ensureGitignore adds .mailbeads/ to .gitignore if not already present.
 func ensureGitignore(root string) {
 	gitignorePath := filepath.Join(root, ".gitignore")
@@ -157,16 +302,55 @@ func ensureGitignore(root string) {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "", "Database path (default: auto-discover .mailbeads/mail.db)")
-	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "", "Database path, :memory:, or postgres:// / libsql:// DSN (default: storage.dsn from config, else auto-discover .mailbeads/mail.db)")
+	rootCmd.PersistentFlags().StringVar(&seedFile, "seed", "", "Load an mbox or JSON seed file into the database first (with no --db, into a scratch in-memory one)")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format (alias for --output json)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", "Structured output format: json, yaml, or table")
 	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "Suppress non-essential output")
+	rootCmd.PersistentFlags().BoolVar(&noColorFlag, "no-color", false, "Disable colored output (also: NO_COLOR env var)")
+	rootCmd.PersistentFlags().BoolVar(&offlineFlag, "offline", false, "Make no network calls: read the local cache only, skip Gmail and remote LLMs (also: MB_OFFLINE env var)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Diagnostics to log: debug, info, warn, or error (default: info; warn with --quiet)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "Log format: console, text, or json (default: console; text with --log-file)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append logs to this file instead of stderr")
 
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(initCmd)
 }
 
+// jsonRequested reports whether --json or --output was given. It also
+// checks the raw arguments, since flags aren't parsed when they are bad.
+func jsonRequested() bool {
+	if jsonOutput || outputFormat != "" {
+		return true
+	}
+	return slices.ContainsFunc(os.Args[1:], func(arg string) bool {
+		return arg == "--json" || arg == "-o" || strings.HasPrefix(arg, "--output") || strings.HasPrefix(arg, "-o=")
+	})
+}
+
 func main() {
+	// Errors are printed here rather than by cobra so --json can report
+	// them as a JSON object with a stable code.
+	rootCmd.SilenceErrors = true
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		cmd.SilenceUsage = jsonRequested()
+		return &cliError{Code: codeInvalidArgument, Err: err}
+	})
+	logging.Setup(logging.Options{})
 	if err := rootCmd.Execute(); err != nil {
+		var done *reportedError
+		switch {
+		case errors.As(err, &done):
+			// The command's output already describes the failure.
+		case jsonRequested():
+			writeStructuredError(os.Stdout, err)
+		default:
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		if logFile != "" {
+			code, _ := errorCode(err)
+			slog.Error(err.Error(), "code", code)
+		}
 		os.Exit(1)
 	}
 }
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/daviddao/mailbeads/internal/beads"
@@ -23,7 +22,7 @@ updates the cross-reference.
 Use --dry-run to preview what would be created without making changes.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if !beads.Available() {
-			return fmt.Errorf("bd (beads) CLI not found on PATH — install from https://beads.sh")
+			return errBDMissing
 		}
 
 		legacyRefs, err := store.LegacyTriageRefs()
@@ -63,6 +62,7 @@ Use --dry-run to preview what would be created without making changes.`,
 				beads.PriorityToBeads("medium"), // Default to medium.
 				"",                              // No category.
 				"",                              // No epic.
+				"",                              // No due date.
 				nil,                             // No extra labels.
 				ref.ThreadID,
 			)
@@ -77,6 +77,7 @@ Use --dry-run to preview what would be created without making changes.`,
 				continue
 			}
 
+			audit("migrate", ref.ThreadID, ref.Account, issue.ID, "from "+ref.BeadID)
 			display.SuccessMsg("Migrated %s -> %s %q", ref.ThreadID, issue.ID, info.Subject)
 		}
 
@@ -86,9 +87,7 @@ Use --dry-run to preview what would be created without making changes.`,
 		}
 
 		if jsonOutput {
-			enc := json.NewEncoder(cmd.OutOrStdout())
-			enc.SetIndent("", "  ")
-			return enc.Encode(map[string]int{"migrated": len(legacyRefs)})
+			return writeOutput(cmd.OutOrStdout(), map[string]int{"migrated": len(legacyRefs)})
 		}
 
 		return nil
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	muteAccount string
+	muteList    bool
+)
+
+// muteResult reports one thread of mb mute or unmute.
+type muteResult struct {
+	ThreadID string `json:"thread_id"`
+	Account  string `json:"account"`
+	Muted    bool   `json:"muted"`
+	// Changed is false when the thread already was (or wasn't) muted.
+	Changed bool `json:"changed"`
+}
+
+var muteCmd = &cobra.Command{
+	Use:   "mute THREAD_ID [THREAD_ID...]",
+	Short: "Silence a noisy thread",
+	Long: `Mute threads that are pure noise, such as a long reply-all chain.
+
+A muted thread is left out of mb untriaged, and new mail on it neither adds
+a "new email activity" comment to its bead nor sends webhook
+notifications. It is still synced, stored, and found by mb search and mb
+show. mb unmute restores it; activity that arrived while muted is then
+reported on its bead at the next sync.
+
+THREAD_ID may also be words from the subject or sender, as in mb show.
+With --list, the muted threads are listed instead.
+
+Examples:
+  mb mute 19abc123
+  mb mute "all-hands logistics"
+  mb mute --list`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if muteList {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if muteList {
+			return listMuted(cmd)
+		}
+		return setMuted(cmd, args, true)
+	},
+}
+
+var unmuteCmd = &cobra.Command{
+	Use:   "unmute THREAD_ID [THREAD_ID...]",
+	Short: "Unmute threads silenced with mb mute",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setMuted(cmd, args, false)
+	},
+}
+
+// setMuted mutes or unmutes each thread argument.
+func setMuted(cmd *cobra.Command, args []string, mute bool) error {
+	results := make([]muteResult, 0, len(args))
+	for _, arg := range args {
+		threadID, account, err := resolveThread(arg, muteAccount)
+		if err != nil {
+			return err
+		}
+		change := store.UnmuteThread
+		action := "unmute"
+		if mute {
+			change, action = store.MuteThread, "mute"
+		}
+		changed, err := change(threadID, account)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", action, threadID, err)
+		}
+		if changed {
+			audit(action, threadID, account, "", "")
+		}
+		results = append(results, muteResult{ThreadID: threadID, Account: account, Muted: mute, Changed: changed})
+	}
+
+	if jsonOutput {
+		return writeOutput(cmd.OutOrStdout(), results)
+	}
+	for _, r := range results {
+		switch {
+		case r.Changed && r.Muted:
+			display.SuccessMsg("Muted %s (%s)", r.ThreadID, display.AccountLabel(r.Account))
+		case r.Changed:
+			display.SuccessMsg("Unmuted %s (%s)", r.ThreadID, display.AccountLabel(r.Account))
+		case r.Muted:
+			fmt.Printf("%s %s (%s) is already muted\n", display.Dim.Render("·"), r.ThreadID, display.AccountLabel(r.Account))
+		default:
+			fmt.Printf("%s %s (%s) is not muted\n", display.Dim.Render("·"), r.ThreadID, display.AccountLabel(r.Account))
+		}
+	}
+	return nil
+}
+
+// listMuted prints the muted threads.
+func listMuted(cmd *cobra.Command) error {
+	all, err := store.MutedThreads()
+	if err != nil {
+		return fmt.Errorf("query muted: %w", err)
+	}
+	threads := []*types.Thread{}
+	for _, t := range all {
+		if muteAccount == "" || t.Account == muteAccount {
+			threads = append(threads, t)
+		}
+	}
+
+	if jsonOutput {
+		return writeOutput(cmd.OutOrStdout(), threads)
+	}
+	if listFormat != "" {
+		rows := make([][]string, 0, len(threads))
+		for _, t := range threads {
+			rows = append(rows, []string{t.ThreadID, t.Account, t.Subject, t.From, strconv.Itoa(t.EmailCount), t.LatestDate})
+		}
+		return writeList(cmd.OutOrStdout(), listFormat, threads,
+			[]string{"thread_id", "account", "subject", "from", "email_count", "latest_date"}, rows)
+	}
+	if len(threads) == 0 {
+		fmt.Println("No muted threads.")
+		return nil
+	}
+	fmt.Printf("Muted threads (%d):\n\n", len(threads))
+	for _, t := range threads {
+		fmt.Printf("  %-16s %s %s %6d %s\n",
+			display.Truncate(t.ThreadID, 16),
+			display.PadRight(display.AccountLabel(t.Account), 12),
+			display.PadRight(display.Truncate(t.Subject, 40), 40),
+			t.EmailCount,
+			display.Dim.Render(display.FormatTime(t.LatestDate)),
+		)
+	}
+	return nil
+}
+
+func init() {
+	muteCmd.Flags().StringVar(&muteAccount, "account", "", "Account the threads belong to (with --list, filter by account)")
+	muteCmd.Flags().BoolVar(&muteList, "list", false, "List muted threads")
+	unmuteCmd.Flags().StringVar(&muteAccount, "account", "", "Account the threads belong to")
+	addFormatFlag(muteCmd)
+	rootCmd.AddCommand(muteCmd, unmuteCmd)
+}
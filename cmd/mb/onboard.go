@@ -1,12 +1,34 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/daviddao/mailbeads/internal/db"
 	"github.com/daviddao/mailbeads/internal/display"
 	"github.com/spf13/cobra"
 )
 
+var (
+	onboardInstall bool
+	onboardDryRun  bool
+)
+
+// Markers around the section mb onboard --install writes to AGENTS.md, so
+// re-running it replaces the section instead of appending another copy.
+const (
+	agentsMDBegin = "<!-- BEGIN mb onboard -->"
+	agentsMDEnd   = "<!-- END mb onboard -->"
+)
+
+// primeHookCommand is the session-start hook registered in
+// .claude/settings.json.
+const primeHookCommand = "mb prime"
+
 const agentsMDSnippet = `## Email Inbox Triage
 
 This project uses **mb (mailbeads)** for email inbox triage.
@@ -30,8 +52,23 @@ var onboardCmd = &cobra.Command{
 
 This outputs a small snippet that points to 'mb prime' for full
 workflow context. This approach keeps AGENTS.md lean while mb prime
-provides dynamic, always-current workflow details.`,
-	Run: func(cmd *cobra.Command, args []string) {
+provides dynamic, always-current workflow details.
+
+With --install, mb writes the snippet to AGENTS.md in the project root
+itself and registers 'mb prime' as a SessionStart hook in
+.claude/settings.json, so agents get workflow context at the start of every
+session. Both steps are idempotent: the AGENTS.md section is replaced in
+place (a snippet pasted from mb onboard is taken over, an edited one left
+alone) and the hook is only added once, leaving the rest of settings.json
+as it was. Use --dry-run to preview the changes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if onboardInstall {
+			return runOnboardInstall(cmd)
+		}
+		if onboardDryRun {
+			return fmt.Errorf("--dry-run only applies with --install")
+		}
+
 		b := display.Bold.Render
 		a := display.Success.Render
 
@@ -49,9 +86,321 @@ provides dynamic, always-current workflow details.`,
 		fmt.Println("   • AGENTS.md only needs this minimal pointer, not full instructions")
 		fmt.Println()
 		fmt.Printf("%s\n\n", display.Success.Render("This keeps AGENTS.md lean while mb prime provides up-to-date workflow details."))
+		fmt.Printf("Run %s to write it and register the session hook automatically.\n\n", a("mb onboard --install"))
+		return nil
 	},
 }
 
+// onboardChange is one file mb onboard --install touches.
+type onboardChange struct {
+	Path   string `json:"path"`
+	Action string `json:"action"` // "create", "update", or "unchanged"
+	Detail string `json:"detail"`
+}
+
+func runOnboardInstall(cmd *cobra.Command) error {
+	root := db.FindProjectRoot()
+	if root == "" {
+		var err error
+		if root, err = os.Getwd(); err != nil {
+			return err
+		}
+	}
+
+	agentsPath := filepath.Join(root, "AGENTS.md")
+	agentsOld, err := readOptional(agentsPath)
+	if err != nil {
+		return err
+	}
+	agentsNew, agentsChange := installAgentsSection(agentsOld)
+	agentsChange.Path = agentsPath
+
+	settingsPath := filepath.Join(root, ".claude", "settings.json")
+	settingsOld, err := readOptional(settingsPath)
+	if err != nil {
+		return err
+	}
+	settingsNew, settingsChange, err := installPrimeHook(settingsOld)
+	if err != nil {
+		return fmt.Errorf("%s: %w", settingsPath, err)
+	}
+	settingsChange.Path = settingsPath
+
+	if !onboardDryRun {
+		if agentsChange.Action != "unchanged" {
+			if err := os.WriteFile(agentsPath, agentsNew, 0o644); err != nil {
+				return fmt.Errorf("write AGENTS.md: %w", err)
+			}
+		}
+		if settingsChange.Action != "unchanged" {
+			if err := os.MkdirAll(filepath.Dir(settingsPath), 0o755); err != nil {
+				return fmt.Errorf("create .claude: %w", err)
+			}
+			if err := os.WriteFile(settingsPath, settingsNew, 0o644); err != nil {
+				return fmt.Errorf("write settings: %w", err)
+			}
+		}
+	}
+
+	changes := []onboardChange{agentsChange, settingsChange}
+	if jsonOutput {
+		return writeOutput(cmd.OutOrStdout(), struct {
+			DryRun  bool            `json:"dry_run"`
+			Changes []onboardChange `json:"changes"`
+		}{onboardDryRun, changes})
+	}
+	if quietFlag {
+		return nil
+	}
+
+	for _, c := range changes {
+		rel, err := filepath.Rel(root, c.Path)
+		if err != nil {
+			rel = c.Path
+		}
+		verb := map[string]string{"create": "Created", "update": "Updated", "unchanged": "Unchanged"}[c.Action]
+		if onboardDryRun && c.Action != "unchanged" {
+			verb = "Would " + c.Action
+		}
+		fmt.Printf("  %-14s %s  %s\n", verb, display.Bold.Render(rel), display.Dim.Render(c.Detail))
+	}
+	if onboardDryRun {
+		fmt.Println()
+		fmt.Println(display.Dim.Render("(dry run — nothing written)"))
+	}
+	return nil
+}
+
+// readOptional returns the contents of path, or nil if it doesn't exist.
+func readOptional(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// agentsMDIntro is the line that identifies an mb snippet pasted into
+// AGENTS.md by hand, without the markers.
+const agentsMDIntro = "This project uses **mb (mailbeads)** for email inbox triage."
+
+// installAgentsSection returns old with the mb section added, or replaced
+// in place if it is already there. A snippet pasted from plain mb onboard
+// is wrapped in the markers; an edited copy of one is left alone.
+func installAgentsSection(old []byte) ([]byte, onboardChange) {
+	section := agentsMDBegin + "\n" + agentsMDSnippet + "\n" + agentsMDEnd + "\n"
+	text := string(old)
+
+	if start := strings.Index(text, agentsMDBegin); start >= 0 {
+		if end := strings.Index(text[start:], agentsMDEnd); end >= 0 {
+			end += start + len(agentsMDEnd)
+			if end < len(text) && text[end] == '\n' {
+				end++
+			}
+			if text[start:end] == section {
+				return old, onboardChange{Action: "unchanged", Detail: "mb section is up to date"}
+			}
+			updated := text[:start] + section + text[end:]
+			return []byte(updated), onboardChange{Action: "update", Detail: "refreshed mb section"}
+		}
+	}
+	if start := strings.Index(text, agentsMDSnippet); start >= 0 {
+		end := start + len(agentsMDSnippet)
+		if end < len(text) && text[end] == '\n' {
+			end++
+		}
+		updated := text[:start] + section + text[end:]
+		return []byte(updated), onboardChange{Action: "update", Detail: "marked existing mb section"}
+	}
+	if strings.Contains(text, agentsMDIntro) {
+		return old, onboardChange{Action: "unchanged", Detail: "has an edited mb snippet without markers; left as is"}
+	}
+
+	if len(old) == 0 {
+		return []byte(section), onboardChange{Action: "create", Detail: "added mb section"}
+	}
+	sep := "\n"
+	if !strings.HasSuffix(text, "\n") {
+		sep = "\n\n"
+	}
+	return []byte(text + sep + section), onboardChange{Action: "update", Detail: "appended mb section"}
+}
+
+// claudeHookEntry is a SessionStart entry in Claude Code settings.
+type claudeHookEntry struct {
+	Matcher string       `json:"matcher"`
+	Hooks   []claudeHook `json:"hooks"`
+}
+
+type claudeHook struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+}
+
+// installPrimeHook returns the Claude Code settings in old with an
+// "mb prime" SessionStart hook added. The hook is inserted into the text
+// as it is, so the other settings keep their order and formatting.
+func installPrimeHook(old []byte) ([]byte, onboardChange, error) {
+	text := old
+	if len(bytes.TrimSpace(text)) == 0 {
+		text = []byte("{\n}\n")
+	}
+	var settings map[string]any
+	if err := json.Unmarshal(text, &settings); err != nil {
+		return nil, onboardChange{}, fmt.Errorf("parse settings: %w", err)
+	}
+
+	// Add to the innermost of hooks.SessionStart that exists.
+	path, key := []string{"hooks", "SessionStart"}, ""
+	var value any = claudeHookEntry{Hooks: []claudeHook{{Type: "command", Command: primeHookCommand}}}
+	hooks, ok := settings["hooks"].(map[string]any)
+	switch {
+	case settings["hooks"] == nil:
+		path, key = nil, "hooks"
+		value = map[string][]any{"SessionStart": {value}}
+	case !ok:
+		return nil, onboardChange{}, fmt.Errorf("hooks is not an object")
+	case hooks["SessionStart"] == nil:
+		path, key = path[:1], "SessionStart"
+		value = []any{value}
+	}
+	if sessionStart, ok := hooks["SessionStart"].([]any); ok {
+		for _, entry := range sessionStart {
+			m, _ := entry.(map[string]any)
+			inner, _ := m["hooks"].([]any)
+			for _, h := range inner {
+				if hm, _ := h.(map[string]any); hm != nil && hm["command"] == primeHookCommand {
+					return old, onboardChange{Action: "unchanged", Detail: "SessionStart hook already registered"}, nil
+				}
+			}
+		}
+	} else if key == "" {
+		return nil, onboardChange{}, fmt.Errorf("hooks.SessionStart is not an array")
+	}
+
+	data, err := insertJSONMember(text, path, key, value)
+	if err != nil {
+		return nil, onboardChange{}, fmt.Errorf("edit settings: %w", err)
+	}
+	action := "update"
+	if old == nil {
+		action = "create"
+	}
+	return data, onboardChange{Action: action, Detail: "registered SessionStart hook: " + primeHookCommand}, nil
+}
+
+// insertJSONMember adds value as the last member of the object (as key)
+// or array (with key "") at path in data, indented to match the
+// surrounding text, or on the same line if data is all on one. The rest of
+// data is unchanged.
+func insertJSONMember(data []byte, path []string, key string, value any) ([]byte, error) {
+	end, empty, err := jsonContainerEnd(data, path)
+	if err != nil {
+		return nil, err
+	}
+	newline, unit, sep := "\n", jsonIndentUnit(data), ": "
+	if !bytes.Contains(bytes.TrimSpace(data), []byte("\n")) {
+		newline, unit, sep = "", "", ":"
+	}
+	depth := strings.Repeat(unit, len(path))
+
+	var member bytes.Buffer
+	enc := json.NewEncoder(&member)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(depth+unit, unit)
+	if key != "" {
+		if err := enc.Encode(key); err != nil {
+			return nil, err
+		}
+		member.Truncate(member.Len() - 1)
+		member.WriteString(sep)
+	}
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	member.Truncate(member.Len() - 1)
+
+	// Insert after the last member (or the opening delimiter), keeping
+	// whatever precedes the closing one.
+	last := len(bytes.TrimRight(data[:end], " \t\r\n"))
+	var out bytes.Buffer
+	out.Write(data[:last])
+	if empty {
+		fmt.Fprintf(&out, "%s%s%s%s%s", newline, depth+unit, member.Bytes(), newline, depth)
+	} else {
+		fmt.Fprintf(&out, ",%s%s%s", newline, depth+unit, member.Bytes())
+		out.Write(data[last:end])
+	}
+	out.Write(data[end:])
+	if !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+// jsonContainerEnd returns the offset of the closing brace or bracket of
+// the object or array at path (object keys from the top level) in data,
+// and whether it has no members.
+func jsonContainerEnd(data []byte, path []string) (end int, empty bool, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	found := false
+	var walk func(depth int, onPath bool) error
+	walk = func(depth int, onPath bool) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			return nil
+		}
+		members := 0
+		for ; dec.More(); members++ {
+			next := false
+			if delim == '{' {
+				k, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				next = onPath && depth < len(path) && k == path[depth]
+			}
+			if err := walk(depth+1, next); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		if onPath && depth == len(path) {
+			end, empty, found = int(dec.InputOffset())-1, members == 0, true
+		}
+		return nil
+	}
+	if err := walk(0, true); err != nil {
+		return 0, false, err
+	}
+	if !found {
+		return 0, false, fmt.Errorf("%s not found", strings.Join(path, "."))
+	}
+	return end, empty, nil
+}
+
+// jsonIndentUnit guesses the indentation of JSON text from its first
+// indented line, defaulting to two spaces.
+func jsonIndentUnit(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		if indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]; indent != "" {
+			return indent
+		}
+	}
+	return "  "
+}
+
 func init() {
+	onboardCmd.Flags().BoolVar(&onboardInstall, "install", false, "Write the AGENTS.md section and register the mb prime session hook")
+	onboardCmd.Flags().BoolVar(&onboardDryRun, "dry-run", false, "With --install, show what would change without writing")
 	rootCmd.AddCommand(onboardCmd)
 }
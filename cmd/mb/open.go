@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/spf13/cobra"
+)
+
+var (
+	openAccount string
+	openPrint   bool
+)
+
+type openOutput struct {
+	ThreadID string `json:"thread_id"`
+	Account  string `json:"account"`
+	URL      string `json:"url"`
+}
+
+var openCmd = &cobra.Command{
+	Use:   "open THREAD_ID",
+	Short: "Open a thread in Gmail in the default browser",
+	Long: `Open an email thread in the Gmail web UI.
+
+Builds the Gmail URL for the thread (switching to the right account) and
+opens it in the default browser. Use --print to only output the URL.
+
+Examples:
+  mb open 19abc123
+  mb open 19abc123 --print
+  mb open 19abc123 --print --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		threadID, account, err := resolveThread(args[0], openAccount)
+		if err != nil {
+			return err
+		}
+
+		url := gmail.ThreadURL(account, threadID)
+
+		// JSON output implies --print: agents want the URL, not a browser window.
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), openOutput{ThreadID: threadID, Account: account, URL: url})
+		}
+		if openPrint {
+			fmt.Fprintln(cmd.OutOrStdout(), url)
+			return nil
+		}
+
+		if err := openBrowser(url); err != nil {
+			return fmt.Errorf("open browser: %w (use --print to get the URL)", err)
+		}
+		if !quietFlag {
+			display.SuccessMsg("Opened %s in browser", threadID)
+		}
+		return nil
+	},
+}
+
+// openBrowser opens a URL with the platform's default handler.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+func init() {
+	openCmd.Flags().StringVar(&openAccount, "account", "", "Specify account")
+	openCmd.Flags().BoolVar(&openPrint, "print", false, "Print the URL instead of opening a browser")
+	rootCmd.AddCommand(openCmd)
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"gopkg.in/yaml.v3"
+)
+
+// Structured output formats for --output. --json is an alias for
+// --output json.
+const (
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+)
+
+// outputFormat is the --output value, resolved by resolveOutputFormat.
+var outputFormat string
+
+// resolveOutputFormat validates --output and reconciles it with --json.
+// Commands check jsonOutput to take their structured-output path, so any
+// --output format sets it; writeOutput then renders in the chosen format.
+func resolveOutputFormat() error {
+	switch outputFormat {
+	case "":
+		if jsonOutput {
+			outputFormat = outputJSON
+		}
+	case outputJSON, outputYAML, outputTable:
+		if jsonOutput && outputFormat != outputJSON {
+			return codedErrorf(codeInvalidArgument, "--json conflicts with --output %s", outputFormat)
+		}
+		jsonOutput = true
+	default:
+		return codedErrorf(codeInvalidArgument, "invalid --output %q (must be: json, yaml, table)", outputFormat)
+	}
+	return nil
+}
+
+// writeOutput writes v, the value a command would encode for --json, in
+// the --output format.
+func writeOutput(w io.Writer, v any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	if outputFormat != outputYAML && outputFormat != outputTable {
+		_, err := buf.WriteTo(w)
+		return err
+	}
+
+	// JSON is valid YAML, so decoding it as a YAML node keeps the field
+	// order of the Go types for both renderings.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		return err
+	}
+	root := doc.Content[0]
+	if outputFormat == outputTable {
+		return writeTable(w, root)
+	}
+	blockStyle(root)
+	ye := yaml.NewEncoder(w)
+	ye.SetIndent(2)
+	if err := ye.Encode(root); err != nil {
+		return err
+	}
+	return ye.Close()
+}
+
+// blockStyle clears the flow and quoting styles inherited from JSON so the
+// YAML encoder picks its usual block layout and quotes only when needed.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	if n.Kind == yaml.ScalarNode && strings.Contains(n.Value, "\n") {
+		n.Style = yaml.LiteralStyle
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// tableCellWidth caps table cells; longer values (bodies) are truncated.
+const tableCellWidth = 60
+
+// writeTable renders a node as aligned columns: a list of objects as one
+// row per object, an object as key/value lines followed by a table for each
+// field holding a list of objects.
+func writeTable(w io.Writer, n *yaml.Node) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	switch {
+	case isObjectList(n):
+		writeRows(tw, n)
+	case n.Kind == yaml.MappingNode:
+		var lists []int
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if v := n.Content[i+1]; isObjectList(v) && len(v.Content) > 0 {
+				lists = append(lists, i)
+				continue
+			}
+			fmt.Fprintf(tw, "%s:\t%s\n", strings.ToUpper(n.Content[i].Value), tableCell(n.Content[i+1]))
+		}
+		for _, i := range lists {
+			tw.Flush()
+			fmt.Fprintf(w, "\n%s\n", strings.ToUpper(n.Content[i].Value))
+			writeRows(tw, n.Content[i+1])
+		}
+	case n.Kind == yaml.SequenceNode:
+		for _, c := range n.Content {
+			fmt.Fprintln(tw, tableCell(c))
+		}
+	default:
+		fmt.Fprintln(tw, tableCell(n))
+	}
+	return tw.Flush()
+}
+
+// isObjectList reports whether n is a list whose items are all objects.
+func isObjectList(n *yaml.Node) bool {
+	if n.Kind != yaml.SequenceNode {
+		return false
+	}
+	for _, c := range n.Content {
+		if c.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// writeRows writes a list of objects with one column per field, in order of
+// first appearance across all rows.
+func writeRows(tw *tabwriter.Writer, list *yaml.Node) {
+	var cols []string
+	seen := make(map[string]bool)
+	rows := make([]map[string]*yaml.Node, len(list.Content))
+	for r, obj := range list.Content {
+		rows[r] = make(map[string]*yaml.Node)
+		for i := 0; i+1 < len(obj.Content); i += 2 {
+			key := obj.Content[i].Value
+			rows[r][key] = obj.Content[i+1]
+			if !seen[key] {
+				seen[key] = true
+				cols = append(cols, key)
+			}
+		}
+	}
+
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(cols))
+		for i, c := range cols {
+			if v, ok := row[c]; ok {
+				cells[i] = tableCell(v)
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+}
+
+// tableCell renders a value on one line: scalars as-is, lists of scalars
+// comma-separated, anything nested as compact JSON.
+func tableCell(n *yaml.Node) string {
+	var s string
+	switch {
+	case n.Kind == yaml.ScalarNode:
+		if n.Tag == "!!null" {
+			return ""
+		}
+		s = n.Value
+	case n.Kind == yaml.SequenceNode && !hasNested(n):
+		parts := make([]string, len(n.Content))
+		for i, c := range n.Content {
+			parts[i] = c.Value
+		}
+		s = strings.Join(parts, ",")
+	default:
+		var v any
+		if err := n.Decode(&v); err == nil {
+			b, _ := json.Marshal(v)
+			s = string(b)
+		}
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	return display.Truncate(s, tableCellWidth)
+}
+
+func hasNested(n *yaml.Node) bool {
+	for _, c := range n.Content {
+		if c.Kind != yaml.ScalarNode {
+			return true
+		}
+	}
+	return false
+}
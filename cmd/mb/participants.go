@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var participantsAccount string
+
+var participantsCmd = &cobra.Command{
+	Use:   "participants THREAD_ID",
+	Short: "List everyone on a thread",
+	Long: `List the distinct people on a thread's From, To, and Cc lines, in order
+of first appearance, with the headers each appeared in and how many of the
+thread's messages they sent. The account's own address is marked (you).
+
+mb untriaged --json and mb show --json include the same list as
+"participants".
+
+Examples:
+  mb participants 19abc123
+  mb participants "offsite planning" --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		threadID, account, err := resolveThread(args[0], participantsAccount)
+		if err != nil {
+			return err
+		}
+		emails, err := store.ThreadEmails(threadID, account)
+		if err != nil {
+			return fmt.Errorf("fetch emails: %w", err)
+		}
+		if len(emails) == 0 {
+			return fmt.Errorf("no emails found for thread %q in %s", threadID, account)
+		}
+		people := threadParticipants(emails, account)
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), people)
+		}
+		if listFormat != "" {
+			rows := make([][]string, 0, len(people))
+			for _, p := range people {
+				rows = append(rows, []string{
+					p.Address, p.Name, strings.Join(p.Roles, ","), strconv.Itoa(p.Sent), strconv.FormatBool(p.Self),
+				})
+			}
+			return writeList(cmd.OutOrStdout(), listFormat, people,
+				[]string{"address", "name", "roles", "sent", "self"}, rows)
+		}
+
+		fmt.Printf("Participants in %q (%d):\n\n", emails[len(emails)-1].Subject, len(people))
+		for _, p := range people {
+			who := p.Address
+			if p.Name != "" {
+				who = p.Name + " <" + p.Address + ">"
+			}
+			if p.Self {
+				who += display.Dim.Render(" (you)")
+			}
+			detail := strings.Join(p.Roles, ", ")
+			if p.Sent > 0 {
+				detail += fmt.Sprintf(" · sent %d", p.Sent)
+			}
+			fmt.Printf("  %s  %s\n", display.PadRight(who, 48), display.Dim.Render(detail))
+		}
+		return nil
+	},
+}
+
+// threadParticipants returns everyone on the From, To, and Cc lines of a
+// thread's messages, deduped by address, in order of first appearance.
+// account marks the account's own address as Self.
+func threadParticipants(emails []*types.Email, account string) []*types.Participant {
+	var out []*types.Participant
+	index := make(map[string]*types.Participant)
+	add := func(list, role string, sent bool) {
+		for _, a := range db.ParseAddressList(list) {
+			addr := a.Address
+			p := index[addr]
+			if p == nil {
+				p = &types.Participant{Address: addr, Self: strings.EqualFold(addr, account)}
+				index[addr] = p
+				out = append(out, p)
+			}
+			if p.Name == "" {
+				p.Name = a.Name
+			}
+			if !slices.Contains(p.Roles, role) {
+				p.Roles = append(p.Roles, role)
+			}
+			if sent {
+				p.Sent++
+			}
+		}
+	}
+	for _, e := range emails {
+		add(e.From, "from", true)
+		add(e.To, "to", false)
+		add(e.CC, "cc", false)
+	}
+	return out
+}
+
+func init() {
+	participantsCmd.Flags().StringVar(&participantsAccount, "account", "", "Account the thread belongs to")
+	addFormatFlag(participantsCmd)
+	rootCmd.AddCommand(participantsCmd)
+}
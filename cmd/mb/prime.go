@@ -1,12 +1,31 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"text/template"
 
 	"github.com/spf13/cobra"
 )
 
+// primeTemplateFile, inside .mailbeads/, replaces the built-in prime output.
+const primeTemplateFile = "prime.md.tmpl"
+
+// primeData is the data available to prime.md.tmpl. Counts are zero when no
+// database was found.
+type primeData struct {
+	Full      bool     // --full was passed
+	Emails    int      // emails in the local cache
+	Threads   int      // distinct threads
+	Triaged   int      // threads with a triage ref
+	Untriaged int      // threads still needing triage
+	Accounts  []string // accounts with synced mail
+	Default   string   // the built-in output for this mode
+}
+
 var (
 	primeFullMode bool
 )
@@ -21,15 +40,81 @@ Two modes:
 - --full:  Complete workflow reference with examples (~80 lines)
 
 Designed for Claude Code hooks and agent session start to provide
-context about the email triage workflow.`,
+context about the email triage workflow.
+
+To tailor the output to your team's conventions, create
+.mailbeads/prime.md.tmpl. It is a Go text/template with live stats:
+
+  {{.Emails}} {{.Threads}} {{.Triaged}} {{.Untriaged}}  counts from the local DB
+  {{.Accounts}}                                      synced accounts
+  {{.Full}}                                          true with --full
+  {{.Default}}                                       the built-in output
+
+For example, append house rules to the built-in text:
+
+  {{.Default}}
+  ## Team rules
+  - Anything from @bigcustomer.com is high priority`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if primeFullMode {
-			return outputFullContext(cmd.OutOrStdout())
+		text, err := primeContext(primeFullMode)
+		if err != nil {
+			return err
 		}
-		return outputBriefContext(cmd.OutOrStdout())
+		_, err = io.WriteString(cmd.OutOrStdout(), text)
+		return err
 	},
 }
 
+// primeContext returns the prime output for the given mode: the custom
+// template if .mailbeads/prime.md.tmpl exists, the built-in text otherwise.
+func primeContext(full bool) (string, error) {
+	var def bytes.Buffer
+	var err error
+	if full {
+		err = outputFullContext(&def)
+	} else {
+		err = outputBriefContext(&def)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if store != nil {
+		path := filepath.Join(mbDir, primeTemplateFile)
+		if src, err := os.ReadFile(path); err == nil {
+			var out bytes.Buffer
+			if err := outputPrimeTemplate(&out, path, string(src), full, def.String()); err != nil {
+				return "", err
+			}
+			return out.String(), nil
+		} else if !os.IsNotExist(err) {
+			return "", fmt.Errorf("read %s: %w", path, err)
+		}
+	}
+	return def.String(), nil
+}
+
+// outputPrimeTemplate renders a custom prime template with live stats.
+func outputPrimeTemplate(w io.Writer, path, src string, full bool, def string) error {
+	tmpl, err := template.New(primeTemplateFile).Funcs(templateFuncs).Parse(src)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	data := primeData{
+		Full:      full,
+		Emails:    store.EmailCount(),
+		Threads:   store.ThreadCount(),
+		Triaged:   store.TriagedCount(),
+		Untriaged: store.UntriagedCount(),
+		Accounts:  store.Accounts(),
+		Default:   def,
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("render %s: %w", path, err)
+	}
+	return nil
+}
+
 func init() {
 	primeCmd.Flags().BoolVar(&primeFullMode, "full", false, "Output full workflow reference (for new agents)")
 	rootCmd.AddCommand(primeCmd)
@@ -71,6 +156,8 @@ Triage state is stored in beads (.beads/) — mb creates beads issues via bd.
 - done/dismiss take bead IDs (e.g., cowork-abc), not triage IDs
 - ` + "`--epic`" + ` links triage to a beads epic via parent-child dependency
 - Accounts are auto-discovered from */credentials.json in the project root
+- Threads with ` + "`auth_warnings`" + ` (failed SPF/DKIM/DMARC, spoofed display name) are likely phishing
+- Untriaged threads may carry ` + "`suggested_category`" + `; pass it as ` + "`--category`" + ` unless it's wrong
 
 Run ` + "`mb prime --full`" + ` for complete workflow reference with examples.
 `
@@ -145,6 +232,12 @@ Required: THREAD_ID (positional), --action
 Auto-detected: --account (if thread in only one account), subject, sender
 Optional: --priority (default: medium), --suggestion, --agent-notes, --category, --epic, --from
 
+Many threads at once — one JSON object per decision, same fields as the flags:
+` + "```bash" + `
+echo '[{"thread_id":"19abc","action":"Reply","priority":"high"},
+       {"thread_id":"19def","action":"FYI","priority":"low"}]' | mb triage --batch --json
+` + "```" + `
+
 ### Step 5: Manage inbox
 ` + "```bash" + `
 mb inbox --json                      # Open beads issues with email label
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	"github.com/daviddao/mailbeads/internal/quotes"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	promptAccount   string
+	promptMaxTokens int
+	promptTask      string
+	promptNoPrime   bool
+)
+
+// defaultPromptTask closes the prompt when --task isn't given.
+const defaultPromptTask = `Triage this thread: decide its priority (high, medium, low, or spam), the
+concrete next action for the reader, and whether it belongs under an existing
+epic. Answer with the mb triage command to run.`
+
+type promptOutput struct {
+	ThreadID string `json:"thread_id"`
+	Account  string `json:"account"`
+	Subject  string `json:"subject"`
+	// Tokens is the estimated size of Prompt (about four characters per token).
+	Tokens int `json:"tokens"`
+	// Omitted counts the oldest messages dropped to fit --max-tokens.
+	Omitted   int    `json:"omitted"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Prompt    string `json:"prompt"`
+}
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt THREAD_ID",
+	Short: "Print a ready-to-paste LLM prompt for a thread",
+	Long: `Print a self-contained LLM prompt for a thread: the triage instructions
+from mb prime, the participants, the linked bead's state, and every message
+with HTML converted to text and quoted replies stripped.
+
+--max-tokens keeps the prompt within a budget (estimated at four characters
+per token). The oldest messages are dropped first; if even the latest one
+doesn't fit, its body is cut short. The instructions, participants, and
+triage state are always included. --task replaces the closing request,
+which by default asks for a triage decision.
+
+Examples:
+  mb prompt 19abc123 | pbcopy
+  mb prompt "contract renewal" --max-tokens 4000
+  mb prompt 19abc123 --task "Summarize what was agreed" --no-prime`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if promptMaxTokens < 0 {
+			return codedErrorf(codeInvalidArgument, "--max-tokens must be 0 or more")
+		}
+		threadID, account, err := resolveThread(args[0], promptAccount)
+		if err != nil {
+			return err
+		}
+		emails, err := store.ThreadEmails(threadID, account)
+		if err != nil {
+			return fmt.Errorf("fetch emails: %w", err)
+		}
+		if len(emails) == 0 {
+			return fmt.Errorf("no emails found for thread %q in %s", threadID, account)
+		}
+		fetchMissingBodies(emails)
+
+		var bead *beads.Issue
+		if ref, err := store.GetTriageRef(threadID, account); err == nil && ref != nil && beads.Available() {
+			bead, _ = beads.Show(ref.BeadID)
+		}
+
+		instructions := ""
+		if !promptNoPrime {
+			if instructions, err = primeContext(false); err != nil {
+				return err
+			}
+		}
+		task := promptTask
+		if task == "" {
+			task = defaultPromptTask
+		}
+
+		out := promptOutput{ThreadID: threadID, Account: account, Subject: emails[0].Subject}
+		out.Prompt, out.Omitted, out.Truncated = buildPrompt(emails, account, bead, instructions, task, promptMaxTokens)
+		out.Tokens = estimateTokens(out.Prompt)
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+		fmt.Fprint(cmd.OutOrStdout(), out.Prompt)
+		return nil
+	},
+}
+
+// estimateTokens approximates the token count of s for budgeting. Four
+// characters per token is close enough for English mail across models.
+func estimateTokens(s string) int {
+	return (len([]rune(s)) + 3) / 4
+}
+
+// buildPrompt assembles the prompt. With a budget, messages are kept from
+// the newest backwards until the next one would overflow it; it returns the
+// number of messages dropped and whether the newest had to be cut short.
+func buildPrompt(emails []*types.Email, account string, bead *beads.Issue, instructions, task string, budget int) (string, int, bool) {
+	var head strings.Builder
+	if instructions != "" {
+		head.WriteString("# Instructions\n\n")
+		head.WriteString(strings.TrimSpace(instructions))
+		head.WriteString("\n\n")
+	}
+	fmt.Fprintf(&head, "# Thread: %s\n\n", emails[0].Subject)
+	fmt.Fprintf(&head, "- Thread ID: %s\n- Account: %s\n- Messages: %d\n", emails[0].ThreadID, account, len(emails))
+	head.WriteString("\n## Participants\n\n")
+	for _, p := range threadParticipants(emails, account) {
+		if p.Name != "" {
+			fmt.Fprintf(&head, "- %s <%s>\n", p.Name, p.Address)
+		} else {
+			fmt.Fprintf(&head, "- %s\n", p.Address)
+		}
+	}
+	head.WriteString("\n## Triage state\n\n")
+	if bead == nil {
+		head.WriteString("Not triaged yet.\n")
+	} else {
+		fmt.Fprintf(&head, "- Bead: %s (%s, priority %s)\n", bead.ID, bead.Status, beads.PriorityFromBeads(bead.Priority))
+		fmt.Fprintf(&head, "- Action: %s\n", bead.Title)
+		if bead.Description != "" {
+			fmt.Fprintf(&head, "- Description: %s\n", bead.Description)
+		}
+		if bead.DueAt != "" {
+			fmt.Fprintf(&head, "- Due: %s\n", bead.DueAt)
+		}
+		if len(bead.Labels) > 0 {
+			fmt.Fprintf(&head, "- Labels: %s\n", strings.Join(bead.Labels, ", "))
+		}
+	}
+	tail := "# Task\n\n" + strings.TrimSpace(task) + "\n"
+
+	messages := make([]string, len(emails))
+	for i, e := range emails {
+		messages[i] = promptMessage(e, promptBody(e))
+	}
+
+	// The omission note is reserved up front so adding it can't overflow.
+	const noteReserve = 20
+	remaining := budget - estimateTokens(head.String()+tail) - noteReserve - estimateTokens("\n## Messages (oldest first)\n\n")
+	first, truncated := 0, false
+	if budget > 0 {
+		first = len(messages)
+		for first > 0 && estimateTokens(messages[first-1]) <= remaining {
+			remaining -= estimateTokens(messages[first-1])
+			first--
+		}
+		if first == len(messages) {
+			// Not even the latest message fits: keep it, cut short.
+			last := emails[len(emails)-1]
+			header := estimateTokens(promptMessage(last, ""))
+			keep := max(remaining-header, 0) * 4
+			body := promptBody(last)
+			if r := []rune(body); keep < len(r) {
+				body = strings.TrimSpace(string(r[:keep]) + " […]")
+			}
+			messages[len(messages)-1] = promptMessage(last, body)
+			first, truncated = len(messages)-1, true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(head.String())
+	b.WriteString("\n## Messages (oldest first)\n\n")
+	if first > 0 {
+		fmt.Fprintf(&b, "[%d earlier message(s) omitted to fit the token budget]\n\n", first)
+	}
+	for _, m := range messages[first:] {
+		b.WriteString(m)
+	}
+	b.WriteString(tail)
+	return b.String(), first, truncated
+}
+
+// promptBody is e's readable body without quoted replies.
+func promptBody(e *types.Email) string {
+	body := quotes.Strip(htmltext.Readable(e.Body), quotes.Options{Markers: cfg.Show.QuoteMarkers})
+	if body == "" {
+		body = e.Snippet
+	}
+	return strings.TrimSpace(body)
+}
+
+func promptMessage(e *types.Email, body string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### From: %s\nDate: %s\n", e.From, e.SentAt)
+	if e.To != "" {
+		fmt.Fprintf(&b, "To: %s\n", e.To)
+	}
+	if e.CC != "" {
+		fmt.Fprintf(&b, "Cc: %s\n", e.CC)
+	}
+	fmt.Fprintf(&b, "\n%s\n\n", body)
+	return b.String()
+}
+
+func init() {
+	promptCmd.Flags().StringVar(&promptAccount, "account", "", "Account the thread belongs to")
+	promptCmd.Flags().IntVar(&promptMaxTokens, "max-tokens", 0, "Token budget; drops the oldest messages first (0 = no limit)")
+	promptCmd.Flags().StringVar(&promptTask, "task", "", "Closing request for the model (default: a triage decision)")
+	promptCmd.Flags().BoolVar(&promptNoPrime, "no-prime", false, "Leave out the mb prime triage instructions")
+	rootCmd.AddCommand(promptCmd)
+}
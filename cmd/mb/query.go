@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/spf13/cobra"
+)
+
+// maxQueryRows caps --limit, so a query can't dump the whole database.
+const maxQueryRows = 10000
+
+var queryLimit int
+
+var queryCmd = &cobra.Command{
+	Use:   "query SQL",
+	Short: "Run a read-only SQL query against the local database",
+	Long: `Run an ad-hoc SQL query against the mailbeads database, for analysis the
+other commands don't cover. SQL is a single SELECT, WITH, EXPLAIN, or
+VALUES statement, or - to read it from stdin.
+
+The query runs with the database in read-only mode (SQLite's query_only,
+PostgreSQL's read-only transactions), so INSERT, UPDATE, DELETE, and DDL
+fail even when hidden in a WITH clause. At most --limit rows are returned
+(default 100, at most 10000), and a query is cancelled after 30 seconds.
+That makes it safe to hand to agents.
+
+The tables are described at the top of internal/db/schema.go: emails,
+triage, triage_log, addresses, senders, events, and so on.
+
+Examples:
+  mb query "SELECT account, COUNT(*) AS n FROM emails GROUP BY account"
+  mb query "SELECT from_addr, subject FROM emails WHERE is_read = 0" --json
+  mb query - --format csv < report.sql`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if queryLimit < 1 || queryLimit > maxQueryRows {
+			return codedErrorf(codeInvalidArgument, "--limit must be between 1 and %d", maxQueryRows)
+		}
+		sql := args[0]
+		if sql == "-" {
+			data, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("read stdin: %w", err)
+			}
+			sql = string(data)
+		}
+
+		result, err := store.ReadOnlyQuery(sql, queryLimit)
+		if err != nil {
+			return codedErrorf(codeInvalidArgument, "query: %v", err)
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), result)
+		}
+		if result.Truncated && !quietFlag {
+			fmt.Fprintf(os.Stderr, "%s\n", display.Dim.Render(fmt.Sprintf("(first %d rows; raise --limit for more)", queryLimit)))
+		}
+		rows := make([][]string, 0, len(result.Rows))
+		for _, r := range result.Rows {
+			row := make([]string, len(result.Columns))
+			for i, c := range result.Columns {
+				row[i] = queryValue(r[c])
+			}
+			rows = append(rows, row)
+		}
+		if listFormat != "" {
+			return writeList(cmd.OutOrStdout(), listFormat, result.Rows, result.Columns, rows)
+		}
+
+		if len(rows) == 0 {
+			fmt.Println("(no rows)")
+			return nil
+		}
+		widths := make([]int, len(result.Columns))
+		for i, c := range result.Columns {
+			widths[i] = len(c)
+			for _, row := range rows {
+				row[i] = display.Truncate(strings.Join(strings.Fields(row[i]), " "), 40)
+				widths[i] = max(widths[i], len(row[i]))
+			}
+		}
+		cells := make([]string, len(result.Columns))
+		for i, c := range result.Columns {
+			cells[i] = display.PadRight(display.Bold.Render(c), widths[i])
+		}
+		fmt.Println(strings.TrimRight(strings.Join(cells, "  "), " "))
+		for _, row := range rows {
+			for i, v := range row {
+				cells[i] = display.PadRight(v, widths[i])
+			}
+			fmt.Println(strings.TrimRight(strings.Join(cells, "  "), " "))
+		}
+		noun := "rows"
+		if len(rows) == 1 {
+			noun = "row"
+		}
+		fmt.Println(display.Dim.Render(fmt.Sprintf("(%d %s)", len(rows), noun)))
+		return nil
+	},
+}
+
+// queryValue formats a column value for tabular output; NULL is empty.
+func queryValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+func init() {
+	queryCmd.Flags().IntVarP(&queryLimit, "limit", "n", 100, fmt.Sprintf("Max rows to return (at most %d)", maxQueryRows))
+	addFormatFlag(queryCmd)
+	rootCmd.AddCommand(queryCmd)
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/daviddao/mailbeads/internal/beads"
@@ -9,14 +8,32 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var readyAccount string
+var (
+	readyAccount string
+	readyExec    string
+)
 
 var readyCmd = &cobra.Command{
 	Use:   "ready",
 	Short: "List actionable triage items from beads (open, no blockers)",
+	Long: `List actionable triage items from beads (open, no blockers).
+
+With --exec, run a command through sh once per item instead of listing
+them, with {field} placeholders standing for the item's fields: {id} and
+{bead_id} (the bead), {title}, {priority}, and from its email thread
+{thread_id}, {account}, {subject}, {from}, and {date}. Values reach the
+command only as environment variables ($MB_SUBJECT, ...), never as shell
+text. See mb exec.
+
+  mb ready --exec 'my-script {id} {thread_id}'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if !beads.Available() {
-			return fmt.Errorf("bd (beads) CLI not found on PATH")
+			return errBDMissing
+		}
+		if readyExec != "" {
+			if err := checkExecTemplate(readyExec); err != nil {
+				return err
+			}
 		}
 
 		labels := []string{"email", "triage"}
@@ -25,10 +42,12 @@ var readyCmd = &cobra.Command{
 			return fmt.Errorf("query beads: %w", err)
 		}
 
+		if readyExec != "" {
+			return runExec(cmd.OutOrStdout(), []string{readyExec}, readyItems(issues))
+		}
+
 		if jsonOutput {
-			enc := json.NewEncoder(cmd.OutOrStdout())
-			enc.SetIndent("", "  ")
-			return enc.Encode(issues)
+			return writeOutput(cmd.OutOrStdout(), issues)
 		}
 
 		if len(issues) == 0 {
@@ -52,5 +71,6 @@ var readyCmd = &cobra.Command{
 
 func init() {
 	readyCmd.Flags().StringVar(&readyAccount, "account", "", "Filter by account")
+	readyCmd.Flags().StringVar(&readyExec, "exec", "", "Run this command per item, with {id}, {thread_id}, ... substituted")
 	rootCmd.AddCommand(readyCmd)
 }
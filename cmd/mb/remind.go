@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/notify"
+)
+
+// remindersFile records the reminders already fired, so restarting mb
+// watch doesn't repeat them.
+const remindersFile = "reminders.json"
+
+// defaultDueTime is when a reminder fires on the day of a date-only due
+// date.
+const defaultDueTime = "09:00"
+
+// reminder is a due date or snooze wake-up of an open bead.
+type reminder struct {
+	issue  beads.Issue
+	reason string // due or snooze_expired
+	value  string // the due date or defer time it fires for
+	at     time.Time
+}
+
+// key identifies a reminder in remindersFile. A new due date or snooze
+// makes a new key, so it fires again.
+func (r reminder) key() string {
+	return r.issue.ID + " " + r.reason + " " + r.value
+}
+
+// pendingReminders returns the reminders of open beads in issues that are
+// due by now, oldest first.
+func pendingReminders(issues map[string]beads.Issue, now time.Time) ([]reminder, error) {
+	dueTime := cfg.Notify.Reminders.DueTime
+	if dueTime == "" {
+		dueTime = defaultDueTime
+	}
+	clock, err := time.Parse("15:04", dueTime)
+	if err != nil {
+		return nil, fmt.Errorf(`config notify.reminders.due_time: %q (want "HH:MM")`, dueTime)
+	}
+
+	var out []reminder
+	for _, issue := range issues {
+		if issue.Status == "closed" {
+			continue
+		}
+		if due, ok := parseBeadDue(issue.DueAt); ok {
+			if len(issue.DueAt) == len("2006-01-02") {
+				due = startOfDay(due).Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute)
+			}
+			out = append(out, reminder{issue, "due", issue.DueAt, due})
+		}
+		if wake, ok := parseBeadDue(issue.DeferUntil); ok {
+			out = append(out, reminder{issue, "snooze_expired", issue.DeferUntil, wake})
+		}
+	}
+	pending := out[:0]
+	for _, r := range out {
+		if !r.at.After(now) {
+			pending = append(pending, r)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].at.Before(pending[j].at) })
+	return pending, nil
+}
+
+// fireReminders emits a reminder event for each due date reached and
+// snooze expired among issues, once per due date or snooze, and delivers
+// it to the targets in notify.reminders (only the desktop under
+// --offline).
+func fireReminders(issues map[string]beads.Issue, emit func(watchEvent)) {
+	// An empty snapshot means bd is missing or failed; don't mistake it
+	// for every bead being closed.
+	if len(issues) == 0 || mbDir == "" {
+		return
+	}
+	pending, err := pendingReminders(issues, time.Now())
+	if err != nil {
+		emit(watchEvent{Type: "error", Error: err.Error()})
+		return
+	}
+
+	path := filepath.Join(mbDir, remindersFile)
+	fired := make(map[string]string)
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &fired)
+	}
+	changed := false
+
+	// --offline keeps reminders on this machine: desktop notifications
+	// still show, but webhooks are skipped.
+	remindCfg, skipWebhooks := cfg, false
+	if offlineFlag && cfg.Notify.Reminders.Webhooks && len(cfg.Notify.Webhooks) > 0 {
+		c := *cfg
+		c.Notify.Reminders.Webhooks = false
+		remindCfg, skipWebhooks = &c, true
+	}
+
+	current := make(map[string]bool)
+	for _, r := range pending {
+		current[r.key()] = true
+		if fired[r.key()] != "" {
+			continue
+		}
+		fired[r.key()] = time.Now().UTC().Format(time.RFC3339)
+		changed = true
+
+		threadID := beads.ThreadIDFromRef(r.issue.ExternalRef)
+		account := beads.NoteField(r.issue.Notes, "account")
+		priority := beads.PriorityFromBeads(r.issue.Priority)
+		emit(watchEvent{
+			Type:     "reminder",
+			Account:  account,
+			ThreadID: threadID,
+			BeadID:   r.issue.ID,
+			Title:    r.issue.Title,
+			Priority: priority,
+			Reason:   r.reason,
+			Due:      r.value,
+		})
+		ev := notify.Event{
+			Event:    "reminder",
+			ThreadID: threadID,
+			Account:  account,
+			Priority: priority,
+			BeadID:   r.issue.ID,
+			Title:    r.issue.Title,
+			Reason:   r.reason,
+			Due:      r.value,
+		}
+		if account != "" && threadID != "" {
+			ev.URL = gmail.ThreadURL(account, threadID)
+		}
+		if err := notify.Remind(remindCfg, ev); err != nil {
+			emit(watchEvent{Type: "error", BeadID: r.issue.ID, Error: err.Error()})
+		}
+		if skipWebhooks {
+			emit(watchEvent{Type: "error", BeadID: r.issue.ID, Error: "reminder webhooks skipped: --offline is set"})
+		}
+	}
+	// Forget reminders whose bead closed or whose date changed.
+	for key := range fired {
+		if !current[key] {
+			delete(fired, key)
+			changed = true
+		}
+	}
+
+	if changed {
+		data, err := json.MarshalIndent(fired, "", "  ")
+		if err == nil {
+			err = os.WriteFile(path, data, 0o644)
+		}
+		if err != nil {
+			emit(watchEvent{Type: "error", Error: fmt.Sprintf("record reminders: %v", err)})
+		}
+	}
+}
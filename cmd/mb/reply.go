@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/provider"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replyAccount       string
+	replyTemplate      string
+	replyBody          string
+	replyDryRun        bool
+	replyListTemplates bool
+)
+
+// templatesDir is where reply templates live, inside .mailbeads/.
+const templatesDir = "templates"
+
+// replyVars are the fields available to reply templates.
+type replyVars struct {
+	SenderName      string // display name, or the address if there is none
+	SenderFirstName string // first word of the name, or the address's local part
+	SenderEmail     string
+	Subject         string // original subject without Re:/Fwd: prefixes
+	Account         string // the account replying
+	Date            string // when the message being answered was sent
+	Today           string
+}
+
+type replyOutput struct {
+	ThreadID string `json:"thread_id"`
+	Account  string `json:"account"`
+	To       string `json:"to"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body"`
+	Template string `json:"template,omitempty"`
+	SentID   string `json:"sent_id,omitempty"`
+	BeadID   string `json:"bead_id,omitempty"`
+	DryRun   bool   `json:"dry_run,omitempty"`
+}
+
+var replyCmd = &cobra.Command{
+	Use:   "reply THREAD_ID",
+	Short: "Reply to a thread from a canned template or text",
+	Long: `Reply to the latest message in a thread from the account that received it.
+
+--template NAME renders .mailbeads/templates/NAME (or NAME.txt, NAME.md,
+NAME.tmpl) as a Go text/template with these fields:
+
+  {{.SenderName}}       Display name of the person being answered
+  {{.SenderFirstName}}  First word of their name
+  {{.SenderEmail}}      Their address
+  {{.Subject}}          Thread subject without Re:/Fwd:
+  {{.Account}}          Your account
+  {{.Date}}             When their message was sent
+  {{.Today}}            Today's date
+
+--body sends literal text instead. Use --dry-run to see the rendered reply
+without sending. If the thread is triaged, the reply is noted on its bead.
+
+Examples:
+  mb reply --list-templates
+  mb reply 19abc123 --template decline-meeting --dry-run
+  mb reply "lunch next week" --body "Thursday works, see you then."`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if replyListTemplates {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := filepath.Join(mbDir, templatesDir)
+		if replyListTemplates {
+			return listReplyTemplates(cmd, dir)
+		}
+		if (replyTemplate == "") == (replyBody == "") {
+			return fmt.Errorf("give exactly one of --template or --body")
+		}
+
+		threadID, account, err := resolveThread(args[0], replyAccount)
+		if err != nil {
+			return err
+		}
+		emails, err := store.ThreadEmails(threadID, account)
+		if err != nil {
+			return fmt.Errorf("fetch emails: %w", err)
+		}
+		if len(emails) == 0 {
+			return fmt.Errorf("no emails found for thread %q in %s", threadID, account)
+		}
+
+		parent, to := replyTarget(emails, account)
+		out := replyOutput{
+			ThreadID: threadID,
+			Account:  account,
+			To:       to,
+			Subject:  replySubject(parent.Subject),
+			Body:     strings.TrimSpace(replyBody) + "\n",
+			Template: replyTemplate,
+			DryRun:   replyDryRun,
+		}
+		if replyTemplate != "" {
+			out.Body, err = renderReplyTemplate(dir, replyTemplate, newReplyVars(parent, to, account))
+			if err != nil {
+				return err
+			}
+		}
+
+		if !replyDryRun {
+			p, acct, err := accountProvider(account)
+			if err != nil {
+				return err
+			}
+			out.SentID, err = p.Send(acct, &provider.Outgoing{
+				From:     account,
+				To:       to,
+				Subject:  out.Subject,
+				Body:     out.Body,
+				ThreadID: threadID,
+				Headers:  replyHeaders(parent),
+			})
+			if err != nil {
+				return err
+			}
+
+			how := "text"
+			if replyTemplate != "" {
+				how = "template " + replyTemplate
+			}
+			if ref, err := store.GetTriageRef(threadID, account); err == nil && ref != nil && beads.Available() {
+				out.BeadID = ref.BeadID
+				if err := beads.Comment(ref.BeadID, fmt.Sprintf("Replied to %s (%s)", to, how)); err != nil {
+					display.ErrorMsg("comment on %s: %v", ref.BeadID, err)
+				}
+			}
+			audit("reply", threadID, account, out.BeadID, how+" to "+to)
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+
+		if replyDryRun {
+			fmt.Printf("From: %s\nTo: %s\nSubject: %s\n\n%s", account, to, out.Subject, out.Body)
+			fmt.Println()
+			fmt.Println(display.Dim.Render("(dry run — not sent)"))
+			return nil
+		}
+		display.SuccessMsg("Replied to %s: %s", to, out.Subject)
+		if out.BeadID != "" {
+			fmt.Printf("  Recorded on %s\n", out.BeadID)
+		}
+		return nil
+	},
+}
+
+// replyTarget picks the message to answer — the latest one not sent by
+// account — and the address to send to. If account wrote every message, the
+// reply goes to the recipients of the latest one.
+func replyTarget(emails []*types.Email, account string) (*types.Email, string) {
+	for i := len(emails) - 1; i >= 0; i-- {
+		e := emails[i]
+		if addr, _ := db.ParseSender(e.From); !strings.EqualFold(addr, account) {
+			return e, e.From
+		}
+	}
+	latest := emails[len(emails)-1]
+	return latest, latest.To
+}
+
+// replyHeaders returns the In-Reply-To and References headers that thread a
+// reply to parent in the recipient's mail client.
+func replyHeaders(parent *types.Email) map[string]string {
+	extra := map[string]string{}
+	if parent.MessageID != "" {
+		extra["In-Reply-To"] = parent.MessageID
+		extra["References"] = parent.MessageID
+	}
+	return extra
+}
+
+// replySubject prefixes subject with "Re: " unless it already has it.
+func replySubject(subject string) string {
+	if strings.HasPrefix(strings.ToLower(subject), "re:") {
+		return subject
+	}
+	return "Re: " + subject
+}
+
+// bareSubject strips any Re:/Fwd: prefixes from subject.
+func bareSubject(subject string) string {
+	for {
+		s := strings.TrimSpace(subject)
+		lower := strings.ToLower(s)
+		trimmed := false
+		for _, p := range []string{"re:", "fwd:", "fw:", "aw:", "wg:"} {
+			if strings.HasPrefix(lower, p) {
+				subject = s[len(p):]
+				trimmed = true
+				break
+			}
+		}
+		if !trimmed {
+			return s
+		}
+	}
+}
+
+func newReplyVars(parent *types.Email, to, account string) replyVars {
+	addr, name := db.ParseSender(to)
+	var first string
+	if f := strings.Fields(name); len(f) > 0 {
+		first = f[0]
+	} else {
+		// No display name: use the address's local part, e.g. "bob".
+		name = addr
+		first, _, _ = strings.Cut(addr, "@")
+		first, _, _ = strings.Cut(first, ".")
+	}
+	date := parent.SentAt
+	if t, err := time.Parse(time.RFC3339, parent.SentAt); err == nil {
+		date = t.Local().Format("Mon Jan 2, 2006")
+	}
+	return replyVars{
+		SenderName:      name,
+		SenderFirstName: first,
+		SenderEmail:     addr,
+		Subject:         bareSubject(parent.Subject),
+		Account:         account,
+		Date:            date,
+		Today:           time.Now().Format("Mon Jan 2, 2006"),
+	}
+}
+
+// findReplyTemplate returns the path of the template called name in dir.
+func findReplyTemplate(dir, name string) (string, error) {
+	if strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+	for _, ext := range []string{"", ".txt", ".md", ".tmpl"} {
+		path := filepath.Join(dir, name+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("template %q not found in %s (see mb reply --list-templates)", name, dir)
+}
+
+// renderReplyTemplate executes the named template with vars.
+func renderReplyTemplate(dir, name string, vars replyVars) (string, error) {
+	path, err := findReplyTemplate(dir, name)
+	if err != nil {
+		return "", err
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read template: %w", err)
+	}
+	tmpl, err := template.New(name).Parse(string(src))
+	if err != nil {
+		return "", fmt.Errorf("parse template %s: %w", path, err)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("render template %s: %w", path, err)
+	}
+	return strings.TrimSpace(b.String()) + "\n", nil
+}
+
+// listReplyTemplates prints the templates available in dir.
+func listReplyTemplates(cmd *cobra.Command, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read templates: %w", err)
+	}
+	names := []string{}
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))
+	}
+	sort.Strings(names)
+
+	if jsonOutput {
+		return writeOutput(cmd.OutOrStdout(), names)
+	}
+	if len(names) == 0 {
+		fmt.Printf("No reply templates. Add one to %s, e.g. decline-meeting.txt.\n", dir)
+		return nil
+	}
+	fmt.Printf("Reply templates (%s):\n\n", display.Dim.Render(dir))
+	for _, n := range names {
+		fmt.Printf("  %s\n", n)
+	}
+	return nil
+}
+
+func init() {
+	replyCmd.Flags().StringVar(&replyAccount, "account", "", "Account the thread belongs to")
+	replyCmd.Flags().StringVarP(&replyTemplate, "template", "t", "", "Template name from .mailbeads/templates/")
+	replyCmd.Flags().StringVar(&replyBody, "body", "", "Reply text (instead of a template)")
+	replyCmd.Flags().BoolVar(&replyDryRun, "dry-run", false, "Print the reply without sending")
+	replyCmd.Flags().BoolVar(&replyListTemplates, "list-templates", false, "List available templates")
+	rootCmd.AddCommand(replyCmd)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportWeek  bool
+	reportSince time.Duration
+)
+
+type reportOutput struct {
+	GeneratedAt string                    `json:"generated_at"`
+	Since       string                    `json:"since"`
+	Accounts    map[string]*reportAccount `json:"accounts"`
+	TopSenders  []*types.Contact          `json:"top_senders"`
+	Priorities  map[string]int            `json:"priorities"`
+	Done        int                       `json:"done"`
+	Dismissed   int                       `json:"dismissed"`
+	High        []beads.Issue             `json:"high_priority"`
+}
+
+// reportAccount counts one account's triage activity in the window.
+type reportAccount struct {
+	Triaged   int `json:"triaged"`
+	Done      int `json:"done"`
+	Dismissed int `json:"dismissed"`
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize triage activity over the past week as markdown",
+	Long: `Summarize what happened in your inbox over a period, as markdown for sharing.
+
+Sections: threads handled per account, top senders, the priority mix of
+triaged threads, done vs dismissed, and outstanding high-priority items.
+Triage activity comes from the local triage log; priorities and open items
+come from beads.
+
+Examples:
+  mb report --week
+  mb report --since 72h
+  mb report --week --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		window := reportSince
+		if reportWeek {
+			window = 7 * 24 * time.Hour
+		}
+		now := time.Now()
+		since := now.Add(-window).UTC().Format(time.RFC3339)
+
+		entries, err := store.TriageLog(since)
+		if err != nil {
+			return fmt.Errorf("read triage log: %w", err)
+		}
+		senders, err := store.TopSenders(since, 10)
+		if err != nil {
+			return fmt.Errorf("query senders: %w", err)
+		}
+
+		out := reportOutput{
+			GeneratedAt: now.UTC().Format(time.RFC3339),
+			Since:       since,
+			Accounts:    make(map[string]*reportAccount),
+			TopSenders:  senders,
+			Priorities:  make(map[string]int),
+			High:        []beads.Issue{},
+		}
+
+		priority := make(map[string]int)
+		if beads.Available() {
+			labels := []string{"email", "triage"}
+			if all, err := beads.List(labels, "", 0); err == nil {
+				for _, issue := range all {
+					priority[issue.ID] = issue.Priority
+				}
+			}
+			if open, err := beads.List(labels, "open", 0); err == nil {
+				for _, issue := range open {
+					if issue.Priority <= 1 {
+						out.High = append(out.High, issue)
+					}
+				}
+			}
+		}
+
+		for _, e := range entries {
+			acc := out.Accounts[e.Account]
+			if acc == nil {
+				acc = &reportAccount{}
+				out.Accounts[e.Account] = acc
+			}
+			if e.TriagedAt >= since {
+				acc.Triaged++
+				if p, ok := priority[e.BeadID]; ok {
+					out.Priorities[beads.PriorityFromBeads(p)]++
+				}
+			}
+			if e.ClosedAt >= since {
+				switch e.Outcome {
+				case "done":
+					acc.Done++
+					out.Done++
+				case "dismissed":
+					acc.Dismissed++
+					out.Dismissed++
+				}
+			}
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+		return writeReportMarkdown(cmd.OutOrStdout(), &out, now.Add(-window), now)
+	},
+}
+
+func writeReportMarkdown(w io.Writer, r *reportOutput, from, to time.Time) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Mail report — %s to %s\n\n", from.Format("Jan 2"), to.Format("Jan 2, 2006"))
+
+	b.WriteString("## Threads handled\n\n")
+	if len(r.Accounts) == 0 {
+		b.WriteString("_No triage activity._\n")
+	} else {
+		b.WriteString("| Account | Triaged | Done | Dismissed |\n| --- | ---: | ---: | ---: |\n")
+		accounts := make([]string, 0, len(r.Accounts))
+		for acc := range r.Accounts {
+			accounts = append(accounts, acc)
+		}
+		sort.Strings(accounts)
+		for _, acc := range accounts {
+			a := r.Accounts[acc]
+			fmt.Fprintf(&b, "| %s | %d | %d | %d |\n", display.AccountName(acc), a.Triaged, a.Done, a.Dismissed)
+		}
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Top senders\n\n")
+	if len(r.TopSenders) == 0 {
+		b.WriteString("_No mail received._\n")
+	}
+	for i, c := range r.TopSenders {
+		name := c.Address
+		if c.Name != "" {
+			name = fmt.Sprintf("%s <%s>", c.Name, c.Address)
+		}
+		fmt.Fprintf(&b, "%d. %s — %d messages in %d threads\n", i+1, name, c.MessageCount, c.ThreadCount)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Priority distribution\n\n")
+	total := 0
+	for _, n := range r.Priorities {
+		total += n
+	}
+	if total == 0 {
+		b.WriteString("_Nothing triaged._\n")
+	}
+	for _, p := range []string{"high", "medium", "low", "spam"} {
+		if n := r.Priorities[p]; n > 0 {
+			fmt.Fprintf(&b, "- %s: %d (%d%%)\n", p, n, n*100/total)
+		}
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Done vs dismissed\n\n")
+	if closed := r.Done + r.Dismissed; closed == 0 {
+		b.WriteString("_Nothing closed._\n")
+	} else {
+		fmt.Fprintf(&b, "%d done, %d dismissed (%d%% done)\n", r.Done, r.Dismissed, r.Done*100/closed)
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "## Outstanding high priority (%d)\n\n", len(r.High))
+	if len(r.High) == 0 {
+		b.WriteString("_Nothing high priority._\n")
+	}
+	for _, issue := range r.High {
+		fmt.Fprintf(&b, "- `%s` %s — open since %s\n", issue.ID, issue.Title, display.FormatTime(issue.CreatedAt))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func init() {
+	reportCmd.Flags().BoolVar(&reportWeek, "week", false, "Report on the past 7 days (the default window)")
+	reportCmd.Flags().DurationVar(&reportSince, "since", 7*24*time.Hour, "Report on activity within this window")
+	rootCmd.AddCommand(reportCmd)
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reviewAccount string
+	reviewList    bool
+)
+
+// reviewExcerptLines caps how much of the latest message mb review shows.
+const reviewExcerptLines = 12
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Accept, edit, or reject queued auto-triage suggestions",
+	Long: `Step through the queued triage suggestions, oldest first: those of
+mb auto-triage --review, and automated or mb triage --confidence
+decisions too unsure to apply (below triage.review_below). Each shows
+the thread — subject, sender, and the latest message with quoted replies
+stripped — and the proposed priority, action, suggestion, category, and
+due date, with its confidence and source (llm, rule:NAME, or triage),
+then waits for a key and Enter:
+
+  a  accept: triage the thread as proposed (as mb triage would)
+  e  edit: change fields one by one, then decide again
+  r  reject: drop the suggestion; the thread stays untriaged
+  s  skip: leave it queued for next time
+  q  quit
+
+Only accepting writes to beads. Rejected threads aren't suggested again
+by mb auto-triage unless named explicitly. Suggestions for threads
+triaged in the meantime are dropped.
+
+With --list (or --json, --format), the queue is printed instead.
+
+Examples:
+  mb review
+  mb review --account work
+  mb review --list --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		suggestions, err := pendingSuggestions()
+		if err != nil {
+			return err
+		}
+		if reviewList || jsonOutput || listFormat != "" {
+			return printSuggestionQueue(cmd, suggestions)
+		}
+		if len(suggestions) == 0 {
+			fmt.Println("No suggestions to review.")
+			return nil
+		}
+		if !beads.Available() {
+			return errBDMissing
+		}
+
+		in := bufio.NewScanner(cmd.InOrStdin())
+		accepted, rejected, skipped := 0, 0, 0
+	review:
+		for i, s := range suggestions {
+			if ref, err := store.GetTriageRef(s.ThreadID, s.Account); err == nil && ref != nil {
+				store.DeleteSuggestion(s.ThreadID, s.Account)
+				fmt.Println(display.Dim.Render(fmt.Sprintf("%s was triaged as %s in the meantime; suggestion dropped", s.ThreadID, ref.BeadID)))
+				continue
+			}
+			fmt.Println()
+			printReviewThread(i+1, len(suggestions), s)
+			for {
+				printSuggestion(s)
+				fmt.Print(display.Bold.Render("[a]ccept  [e]dit  [r]eject  [s]kip  [q]uit") + " > ")
+				if !in.Scan() {
+					fmt.Println()
+					break review
+				}
+				switch strings.ToLower(strings.TrimSpace(in.Text())) {
+				case "a", "accept":
+					if err := acceptSuggestion(s); err != nil {
+						display.ErrorMsg("%v", err)
+						continue
+					}
+					accepted++
+				case "e", "edit":
+					if !editSuggestion(in, s) {
+						break review
+					}
+					continue
+				case "r", "reject":
+					if _, err := store.RejectSuggestion(s.ThreadID, s.Account); err != nil {
+						display.ErrorMsg("reject: %v", err)
+						continue
+					}
+					audit("reject_suggestion", s.ThreadID, s.Account, "", triageDetail(suggestionRequest(s)))
+					fmt.Println(display.Dim.Render("Rejected"))
+					rejected++
+				case "s", "skip":
+					skipped++
+				case "q", "quit":
+					break review
+				default:
+					fmt.Println("Type a, e, r, s, or q.")
+					continue
+				}
+				break
+			}
+		}
+
+		if !quietFlag {
+			left := len(suggestions) - accepted - rejected
+			fmt.Printf("\nAccepted %d, rejected %d, skipped %d; %d left in the queue\n", accepted, rejected, skipped, max(left, 0))
+		}
+		return nil
+	},
+}
+
+// pendingSuggestions returns the queued suggestions, filtered by
+// --account.
+func pendingSuggestions() ([]*types.Suggestion, error) {
+	all, err := store.Suggestions(true)
+	if err != nil {
+		return nil, fmt.Errorf("query suggestions: %w", err)
+	}
+	suggestions := []*types.Suggestion{}
+	for _, s := range all {
+		if reviewAccount == "" || s.Account == reviewAccount {
+			suggestions = append(suggestions, s)
+		}
+	}
+	return suggestions, nil
+}
+
+func printSuggestionQueue(cmd *cobra.Command, suggestions []*types.Suggestion) error {
+	if jsonOutput {
+		return writeOutput(cmd.OutOrStdout(), suggestions)
+	}
+	if listFormat != "" {
+		rows := make([][]string, 0, len(suggestions))
+		for _, s := range suggestions {
+			confidence := ""
+			if s.Confidence != nil {
+				confidence = strconv.FormatFloat(*s.Confidence, 'f', -1, 64)
+			}
+			rows = append(rows, []string{s.ThreadID, s.Account, s.Priority, s.Action, confidence, s.Subject, s.From, s.Source, s.CreatedAt})
+		}
+		return writeList(cmd.OutOrStdout(), listFormat, suggestions,
+			[]string{"thread_id", "account", "priority", "action", "confidence", "subject", "from", "source", "created_at"}, rows)
+	}
+	if len(suggestions) == 0 {
+		fmt.Println("No suggestions to review.")
+		return nil
+	}
+	fmt.Printf("Suggestions to review (%d):\n\n", len(suggestions))
+	for _, s := range suggestions {
+		fmt.Printf("  %-16s %s %-6s %4s %s %s\n",
+			display.Truncate(s.ThreadID, 16),
+			display.PadRight(display.AccountLabel(s.Account), 12),
+			s.Priority,
+			suggestionConfidence(s),
+			display.PadRight(display.Truncate(s.Action, 40), 40),
+			display.Dim.Render(display.Truncate(s.Subject, 40)),
+		)
+	}
+	return nil
+}
+
+// printReviewThread prints the thread a suggestion is for and an excerpt
+// of its latest message.
+func printReviewThread(n, total int, s *types.Suggestion) {
+	fmt.Printf("%s %s\n", display.Dim.Render(fmt.Sprintf("[%d/%d]", n, total)), display.Bold.Render(s.Subject))
+	emails, err := store.ThreadEmails(s.ThreadID, s.Account)
+	if err != nil || len(emails) == 0 {
+		fmt.Printf("  %s\n", display.Dim.Render(s.ThreadID+" ("+display.AccountLabel(s.Account)+")"))
+		return
+	}
+	fetchMissingBodies(emails[len(emails)-1:])
+	latest := emails[len(emails)-1]
+	fmt.Printf("  %s\n", display.Muted.Render(fmt.Sprintf("%s · %d message(s) · %s · %s (%s)",
+		latest.From, len(emails), display.FormatTime(latest.SentAt), s.ThreadID, display.AccountLabel(s.Account))))
+	lines := strings.Split(promptBody(latest), "\n")
+	if len(lines) > reviewExcerptLines {
+		lines = append(lines[:reviewExcerptLines], "[…]")
+	}
+	fmt.Println()
+	for _, l := range lines {
+		fmt.Printf("    %s\n", l)
+	}
+	fmt.Println()
+}
+
+// printSuggestion prints the proposed triage decision.
+func printSuggestion(s *types.Suggestion) {
+	source := s.Source
+	if c := suggestionConfidence(s); c != "" {
+		source = c + " confidence, " + source
+	}
+	fmt.Printf("  %s [%s] %s  %s\n", display.Bold.Render("Proposed:"), s.Priority, s.Action, display.Dim.Render("("+source+")"))
+	for _, f := range []struct{ label, value string }{
+		{"suggestion", s.Suggestion},
+		{"category", s.Category},
+		{"due", s.Due},
+		{"notes", s.AgentNotes},
+	} {
+		if f.value != "" {
+			fmt.Printf("    %s %s\n", display.Dim.Render(f.label+":"), f.value)
+		}
+	}
+}
+
+// suggestionConfidence formats a suggestion's confidence, if it has one.
+func suggestionConfidence(s *types.Suggestion) string {
+	if s.Confidence == nil {
+		return ""
+	}
+	return confidenceLabel(*s.Confidence)
+}
+
+// acceptSuggestion triages the thread as suggested and drops the
+// suggestion from the queue. The bead keeps the suggestion's confidence
+// and notes that a person accepted it.
+func acceptSuggestion(s *types.Suggestion) error {
+	req := suggestionRequest(s)
+	req.AgentNotes = strings.TrimSpace(req.AgentNotes + " Accepted in mb review.")
+	if err := req.validate(); err != nil {
+		return err
+	}
+	out, err := applyTriage(req)
+	if err != nil {
+		return err
+	}
+	if _, err := store.DeleteSuggestion(s.ThreadID, s.Account); err != nil {
+		display.ErrorMsg("remove suggestion: %v", err)
+	}
+	verb := "Updated"
+	if out.Created {
+		verb = "Triaged"
+	}
+	display.SuccessMsg("%s %s [%s] %q", verb, out.BeadID, out.Priority, out.Action)
+	return nil
+}
+
+// editSuggestion prompts for each field of s, keeping it on Enter and
+// clearing an optional one on "-", and saves the result to the queue. It
+// returns false at the end of input.
+func editSuggestion(in *bufio.Scanner, s *types.Suggestion) bool {
+	edited := *s
+	fmt.Println(display.Dim.Render("  Enter keeps a value, - clears an optional one."))
+	for _, f := range []struct {
+		label    string
+		value    *string
+		optional bool
+	}{
+		{"Priority", &edited.Priority, false},
+		{"Action", &edited.Action, false},
+		{"Suggestion", &edited.Suggestion, true},
+		{"Category", &edited.Category, true},
+		{"Due", &edited.Due, true},
+	} {
+		fmt.Printf("  %s [%s]: ", f.label, *f.value)
+		if !in.Scan() {
+			fmt.Println()
+			return false
+		}
+		switch v := strings.TrimSpace(in.Text()); {
+		case v == "":
+		case v == "-" && f.optional:
+			*f.value = ""
+		default:
+			*f.value = v
+		}
+	}
+
+	req := suggestionRequest(&edited)
+	if err := req.validate(); err != nil {
+		display.ErrorMsg("%v; keeping the previous values", err)
+		return true
+	}
+	edited.Priority, edited.Category, edited.Due = req.Priority, req.Category, req.Due
+	*s = edited
+	if err := store.QueueSuggestions([]*types.Suggestion{s}); err != nil {
+		display.ErrorMsg("save edit: %v", err)
+	}
+	return true
+}
+
+// requestSuggestion queues a triage decision from source as a suggestion.
+func requestSuggestion(req triageRequest, source string) *types.Suggestion {
+	return &types.Suggestion{
+		ThreadID:   req.ThreadID,
+		Account:    req.Account,
+		Priority:   req.Priority,
+		Action:     req.Action,
+		Suggestion: req.Suggestion,
+		AgentNotes: req.AgentNotes,
+		Category:   req.Category,
+		Due:        req.Due,
+		Confidence: req.Confidence,
+		Source:     source,
+	}
+}
+
+// suggestionRequest is the triage decision a suggestion proposes.
+func suggestionRequest(s *types.Suggestion) triageRequest {
+	return triageRequest{
+		ThreadID:   s.ThreadID,
+		Account:    s.Account,
+		Priority:   s.Priority,
+		Action:     s.Action,
+		Suggestion: s.Suggestion,
+		AgentNotes: s.AgentNotes,
+		Category:   s.Category,
+		Due:        s.Due,
+		Confidence: s.Confidence,
+	}
+}
+
+func init() {
+	reviewCmd.Flags().StringVar(&reviewAccount, "account", "", "Only suggestions for threads in this account")
+	reviewCmd.Flags().BoolVar(&reviewList, "list", false, "Print the queue instead of reviewing it")
+	addFormatFlag(reviewCmd)
+	rootCmd.AddCommand(reviewCmd)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/ical"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rsvpUID     string
+	rsvpComment string
+)
+
+type rsvpOutput struct {
+	MessageID string `json:"message_id"`
+	ThreadID  string `json:"thread_id"`
+	Account   string `json:"account"`
+	UID       string `json:"uid"`
+	Summary   string `json:"summary"`
+	PartStat  string `json:"partstat"`
+	SentID    string `json:"sent_id"`
+	BeadID    string `json:"bead_id,omitempty"`
+}
+
+// rsvpVerbs maps each RSVP subcommand to its iTIP participation status and
+// the subject prefix calendar clients use for replies.
+var rsvpVerbs = []struct {
+	verb, partstat, prefix string
+}{
+	{"accept", "ACCEPTED", "Accepted"},
+	{"decline", "DECLINED", "Declined"},
+	{"tentative", "TENTATIVE", "Tentative"},
+}
+
+// newRSVPCmd builds the accept/decline/tentative subcommands of mb calendar.
+func newRSVPCmd(verb, partstat, prefix string) *cobra.Command {
+	return &cobra.Command{
+		Use:   verb + " MESSAGE_ID",
+		Short: fmt.Sprintf("Reply %s to a meeting invite", strings.ToLower(partstat)),
+		Long: fmt.Sprintf(`Send an iTIP REPLY with PARTSTAT=%s for the invite in MESSAGE_ID.
+
+The reply is sent from the invited account to the organizer, in the same
+Gmail thread, so the organizer's calendar updates your attendance. If the
+thread is triaged, the RSVP is recorded as a comment on the linked bead.
+
+Examples:
+  mb calendar %s 18d5a7b3c4e5f6a7
+  mb calendar %s 18d5a7b3c4e5f6a7 --comment "Running 5 min late"`, partstat, verb, verb),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRSVP(cmd, args[0], partstat, prefix)
+		},
+	}
+}
+
+func runRSVP(cmd *cobra.Command, messageID, partstat, prefix string) error {
+	email, err := store.GetEmail(messageID)
+	if err != nil {
+		return fmt.Errorf("lookup message: %w", err)
+	}
+	if email == nil {
+		return fmt.Errorf("message %q not found", messageID)
+	}
+
+	events, err := store.EmailEvents(messageID)
+	if err != nil {
+		return fmt.Errorf("fetch events: %w", err)
+	}
+	var ev *types.CalendarEvent
+	for _, e := range events {
+		if rsvpUID == "" || e.UID == rsvpUID {
+			ev = e
+			break
+		}
+	}
+	if ev == nil {
+		return fmt.Errorf("message %q has no calendar invite (try 'mb calendar --rescan')", messageID)
+	}
+
+	organizer := ""
+	name := ""
+	for _, e := range ical.Parse(ev.ICS).Events {
+		if e.UID != ev.UID {
+			continue
+		}
+		organizer = e.Organizer.Email
+		for _, a := range e.Attendees {
+			if strings.EqualFold(a.Email, email.Account) {
+				name = a.Name
+			}
+		}
+	}
+	if organizer == "" {
+		return fmt.Errorf("invite %q has no organizer to reply to", ev.Summary)
+	}
+
+	reply := ical.Reply(ev.ICS, ev.UID, email.Account, name, partstat)
+	if reply == "" {
+		return fmt.Errorf("could not build reply for invite %q", ev.UID)
+	}
+
+	body := fmt.Sprintf("%s has %s this invitation: %s", email.Account, strings.ToLower(partstat), ev.Summary)
+	if rsvpComment != "" {
+		body += "\n\n" + rsvpComment
+	}
+	extra := map[string]string{}
+	if email.MessageID != "" {
+		extra["In-Reply-To"] = email.MessageID
+		extra["References"] = email.MessageID
+	}
+
+	svc, err := accountService(context.Background(), email.Account)
+	if err != nil {
+		return err
+	}
+	sentID, err := gmail.SendCalendarReply(svc, email.ThreadID, email.Account, organizer,
+		prefix+": "+ev.Summary, body, reply, extra)
+	if err != nil {
+		return err
+	}
+
+	out := rsvpOutput{
+		MessageID: messageID,
+		ThreadID:  email.ThreadID,
+		Account:   email.Account,
+		UID:       ev.UID,
+		Summary:   ev.Summary,
+		PartStat:  partstat,
+		SentID:    sentID,
+	}
+
+	// Record the RSVP on the linked bead.
+	if ref, err := store.GetTriageRef(email.ThreadID, email.Account); err == nil && ref != nil && beads.Available() {
+		out.BeadID = ref.BeadID
+		note := fmt.Sprintf("RSVP %s: %s (%s)", strings.ToLower(partstat), ev.Summary, eventWhen(ev))
+		if err := beads.Comment(ref.BeadID, note); err != nil {
+			display.ErrorMsg("comment on %s: %v", ref.BeadID, err)
+		}
+	}
+
+	audit("rsvp", email.ThreadID, email.Account, out.BeadID, strings.ToLower(partstat)+": "+ev.Summary)
+
+	if jsonOutput {
+		return writeOutput(cmd.OutOrStdout(), out)
+	}
+
+	display.SuccessMsg("%s %q (%s) — reply sent to %s", prefix, ev.Summary, eventWhen(ev), organizer)
+	if out.BeadID != "" {
+		fmt.Printf("  Recorded on %s\n", out.BeadID)
+	}
+	return nil
+}
+
+func init() {
+	for _, v := range rsvpVerbs {
+		c := newRSVPCmd(v.verb, v.partstat, v.prefix)
+		c.Flags().StringVar(&rsvpUID, "uid", "", "Event UID (if the message has several invites)")
+		c.Flags().StringVar(&rsvpComment, "comment", "", "Note to include in the reply")
+		calendarCmd.AddCommand(c)
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/auth"
+	"github.com/daviddao/mailbeads/internal/classify"
+	"github.com/daviddao/mailbeads/internal/config"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/notify"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+	"google.golang.org/api/googleapi"
+)
+
+var (
+	rulesAccount string
+	rulesDryRun  bool
+)
+
+// ruleSummary is one rule in mb rules --json.
+type ruleSummary struct {
+	config.TriageRule
+	// Filter is the Gmail filter mb rules push creates, if the rule has one.
+	Filter *gmail.Filter `json:"filter,omitempty"`
+	// LocalOnly explains why the rule can't run as a Gmail filter.
+	LocalOnly string `json:"local_only,omitempty"`
+}
+
+// rulePushResult reports what mb rules push did with one rule in one
+// account. Rules that can't become filters are reported once, without an
+// account.
+type rulePushResult struct {
+	Rule     string        `json:"rule"`
+	Account  string        `json:"account,omitempty"`
+	Status   string        `json:"status"` // created, would_create, exists, skipped
+	FilterID string        `json:"filter_id,omitempty"`
+	Filter   *gmail.Filter `json:"filter,omitempty"`
+	Reason   string        `json:"reason,omitempty"`
+}
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "List auto-triage rules",
+	Long: `List the auto-triage rules from the "triage" section of
+.mailbeads/config.json, and the Gmail filter each would become.
+
+A rule gives mail matching all of its conditions a fixed priority:
+
+  {
+    "triage": {
+      "rules": [
+        {"name": "promos", "senders": ["@deals.example.com"], "priority": "spam"},
+        {"name": "phishing", "subject_contains": ["verify your account"], "priority": "spam"},
+        {"name": "learned-spam", "predicted": "spam", "priority": "spam"},
+        {"name": "newsletters", "bulk": true, "priority": "low"}
+      ]
+    }
+  }
+
+Conditions are senders (full address, "@domain", or substring),
+subject_contains, and accounts, as in notification rules; predicted: the
+priority the local classifier trained by mb train assigns the thread; and
+bulk: true, which matches only newsletters and list mail (List-Id,
+Precedence: bulk, or one-click unsubscribe headers, tagged at sync).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateTriageRules(cfg.Triage.Rules); err != nil {
+			return err
+		}
+		summaries := make([]ruleSummary, 0, len(cfg.Triage.Rules))
+		for _, r := range cfg.Triage.Rules {
+			s := ruleSummary{TriageRule: r}
+			if f, reason := ruleFilter(r); reason != "" {
+				s.LocalOnly = reason
+			} else {
+				s.Filter = &f
+			}
+			summaries = append(summaries, s)
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), summaries)
+		}
+		if len(summaries) == 0 {
+			fmt.Println("No triage rules configured. Add them under \"triage\": {\"rules\": [...]} in .mailbeads/config.json.")
+			return nil
+		}
+		for _, s := range summaries {
+			fmt.Printf("%s  %s\n", display.PriorityLabel(s.Priority), display.Bold.Render(s.Name))
+			if len(s.Senders) > 0 {
+				fmt.Printf("  senders:  %s\n", strings.Join(s.Senders, ", "))
+			}
+			if len(s.SubjectContains) > 0 {
+				fmt.Printf("  subject:  %s\n", strings.Join(s.SubjectContains, ", "))
+			}
+			if len(s.Accounts) > 0 {
+				fmt.Printf("  accounts: %s\n", strings.Join(s.Accounts, ", "))
+			}
+			if s.Predicted != "" {
+				fmt.Printf("  predicted: %s\n", s.Predicted)
+			}
+			if s.Bulk {
+				fmt.Printf("  bulk:     only newsletters and list mail\n")
+			}
+			if s.Filter != nil {
+				fmt.Printf("  gmail:    %s\n", describeFilter(*s.Filter))
+			} else {
+				fmt.Printf("  gmail:    %s\n", display.Dim.Render("local only — "+s.LocalOnly))
+			}
+		}
+		return nil
+	},
+}
+
+var rulesPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Install spam rules as Gmail filters",
+	Long: `Translate auto-triage rules into server-side Gmail filters, so matching
+mail never reaches the inbox and never enters the sync window.
+
+Only spam rules with sender or subject conditions can run in Gmail; their
+filters skip the inbox (archive). Other rules are reported as skipped. A
+rule's accounts condition limits which accounts get its filter. Filters
+that already exist are left alone, so push is safe to rerun.
+
+Creating filters needs the gmail.settings.basic scope. Accounts authorized
+before mailbeads requested it must re-consent: delete the account's
+token.json and run mb sync.
+
+Examples:
+  mb rules push --dry-run
+  mb rules push --account me@work.com
+  mb rules push --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateTriageRules(cfg.Triage.Rules); err != nil {
+			return err
+		}
+		if offlineFlag {
+			return errOffline("mb rules push")
+		}
+		root := db.FindProjectRoot()
+		if root == "" {
+			return fmt.Errorf("could not find project root (no .git directory)")
+		}
+		accounts := resolveAccounts(root, rulesAccount)
+		if len(accounts) == 0 {
+			return fmt.Errorf("no accounts found — add account directories with credentials.json to the project root")
+		}
+
+		results := []rulePushResult{}
+		type pushable struct {
+			rule   config.TriageRule
+			filter gmail.Filter
+		}
+		var todo []pushable
+		for _, r := range cfg.Triage.Rules {
+			f, reason := ruleFilter(r)
+			if reason != "" {
+				results = append(results, rulePushResult{Rule: r.Name, Status: "skipped", Reason: reason})
+				continue
+			}
+			todo = append(todo, pushable{r, f})
+		}
+
+		ctx := context.Background()
+		for _, account := range accounts {
+			var applicable []pushable
+			for _, p := range todo {
+				if appliesTo(p.rule.Accounts, account) {
+					applicable = append(applicable, p)
+				}
+			}
+			if len(applicable) == 0 {
+				continue
+			}
+
+			svc, err := auth.LoadGmailService(ctx, filepath.Join(root, account, "credentials.json"))
+			if err != nil {
+				return fmt.Errorf("%s: %w", account, err)
+			}
+			existing, err := gmail.ListFilters(svc)
+			if err != nil {
+				return fmt.Errorf("%s: %w", account, scopeHint(err, root, account))
+			}
+
+			for _, p := range applicable {
+				res := rulePushResult{Rule: p.rule.Name, Account: account, Filter: &p.filter}
+				for _, e := range existing {
+					if e.Same(p.filter) {
+						res.Status, res.FilterID = "exists", e.ID
+						break
+					}
+				}
+				switch {
+				case res.Status != "":
+				case rulesDryRun:
+					res.Status = "would_create"
+				default:
+					id, err := gmail.CreateFilter(svc, p.filter)
+					if err != nil {
+						return fmt.Errorf("%s: rule %q: %w", account, p.rule.Name, scopeHint(err, root, account))
+					}
+					res.Status, res.FilterID = "created", id
+					existing = append(existing, p.filter)
+					audit("rules-push", "", account, "", fmt.Sprintf("rule=%q filter=%s", p.rule.Name, id))
+				}
+				results = append(results, res)
+			}
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), results)
+		}
+		for _, r := range results {
+			switch r.Status {
+			case "created":
+				display.SuccessMsg("%s: created filter for %q (%s)", display.AccountName(r.Account), r.Rule, describeFilter(*r.Filter))
+			case "would_create":
+				fmt.Printf("  [dry-run] %s: would create filter for %q (%s)\n", display.AccountName(r.Account), r.Rule, describeFilter(*r.Filter))
+			case "exists":
+				fmt.Printf("  %s: filter for %q already exists\n", display.AccountName(r.Account), r.Rule)
+			case "skipped":
+				fmt.Println(display.Dim.Render(fmt.Sprintf("  skipped %q: %s", r.Rule, r.Reason)))
+			}
+		}
+		if len(results) == 0 {
+			fmt.Println("No triage rules apply to these accounts.")
+		}
+		return nil
+	},
+}
+
+// validateTriageRules checks that every rule is named and has a valid
+// priority, and a valid predicted priority if it has one.
+func validateTriageRules(rules []config.TriageRule) error {
+	for i, r := range rules {
+		if r.Name == "" {
+			return fmt.Errorf("config triage.rules[%d]: missing name", i)
+		}
+		if !types.IsValidPriority(r.Priority) {
+			return fmt.Errorf("config triage rule %q: invalid priority %q (must be: high, medium, low, spam)", r.Name, r.Priority)
+		}
+		if r.Predicted != "" && !types.IsValidPriority(r.Predicted) {
+			return fmt.Errorf("config triage rule %q: invalid predicted %q (must be: high, medium, low, spam)", r.Name, r.Predicted)
+		}
+	}
+	return nil
+}
+
+// matchTriageRule returns the first triage rule that matches a thread by
+// its first message, and how sure the match is: 1, or for a predicted
+// rule the classifier's probability (model may be nil, and then predicted
+// rules never match). Rules without a condition besides accounts match
+// nothing, so a typo can't triage everything.
+func matchTriageRule(t *types.Thread, first *types.Email, model *classify.Model) (*config.TriageRule, float64) {
+	for i, r := range cfg.Triage.Rules {
+		if len(r.Senders) == 0 && len(r.SubjectContains) == 0 && r.Predicted == "" && !r.Bulk {
+			continue
+		}
+		if r.Bulk && !t.IsBulk {
+			continue
+		}
+		if len(r.Senders) > 0 && !notify.MatchSender(r.Senders, first.From) {
+			continue
+		}
+		if len(r.SubjectContains) > 0 && !slices.ContainsFunc(r.SubjectContains, func(s string) bool {
+			return s != "" && strings.Contains(strings.ToLower(first.Subject), strings.ToLower(s))
+		}) {
+			continue
+		}
+		if !appliesTo(r.Accounts, t.Account) {
+			continue
+		}
+		confidence := 1.0
+		if r.Predicted != "" {
+			var predicted string
+			if predicted, confidence = model.Predict(first); predicted != r.Predicted {
+				continue
+			}
+		}
+		return &cfg.Triage.Rules[i], confidence
+	}
+	return nil, 0
+}
+
+// ruleFilter translates a rule into a Gmail filter, or explains why it
+// can't be one. Gmail has no notion of bead priorities, so only spam rules
+// translate: their filter skips the inbox.
+func ruleFilter(r config.TriageRule) (gmail.Filter, string) {
+	if r.Priority != types.PrioritySpam {
+		return gmail.Filter{}, "only spam rules can run as Gmail filters"
+	}
+	if r.Predicted != "" {
+		return gmail.Filter{}, "predicted conditions need the local classifier"
+	}
+	if r.Bulk {
+		return gmail.Filter{}, "bulk conditions use headers only mb sync checks"
+	}
+	f := gmail.Filter{
+		From:         orTerms(r.Senders),
+		Subject:      orTerms(r.SubjectContains),
+		RemoveLabels: []string{"INBOX"},
+	}
+	if f.From == "" && f.Subject == "" {
+		return gmail.Filter{}, "no sender or subject condition for Gmail to match"
+	}
+	return f, ""
+}
+
+// orTerms joins values into a Gmail OR expression, quoting phrases.
+func orTerms(values []string) string {
+	var terms []string
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if strings.ContainsAny(v, " \t") {
+			v = `"` + strings.ReplaceAll(v, `"`, "") + `"`
+		}
+		terms = append(terms, v)
+	}
+	return strings.Join(terms, " OR ")
+}
+
+// appliesTo reports whether a rule's or policy's accounts condition admits
+// account. Entries match as substrings, as in notification rules.
+func appliesTo(accounts []string, account string) bool {
+	if len(accounts) == 0 {
+		return true
+	}
+	for _, a := range accounts {
+		if a != "" && strings.Contains(strings.ToLower(account), strings.ToLower(a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeFilter renders a filter as Gmail shows it: criteria, then action.
+func describeFilter(f gmail.Filter) string {
+	var parts []string
+	if f.From != "" {
+		parts = append(parts, "from:("+f.From+")")
+	}
+	if f.Subject != "" {
+		parts = append(parts, "subject:("+f.Subject+")")
+	}
+	action := "skip inbox"
+	if len(f.RemoveLabels) == 0 || f.RemoveLabels[0] != "INBOX" {
+		action = "labels +" + strings.Join(f.AddLabels, ",") + " -" + strings.Join(f.RemoveLabels, ",")
+	}
+	return strings.Join(parts, " ") + " → " + action
+}
+
+// scopeHint adds re-consent instructions to a Gmail permission error,
+// which means the account's token predates the settings scope.
+func scopeHint(err error, root, account string) error {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) && gerr.Code == http.StatusForbidden {
+		return fmt.Errorf("%w — the token lacks the gmail.settings.basic scope; delete %s and run mb sync to re-authorize",
+			err, filepath.Join(root, account, "token.json"))
+	}
+	return err
+}
+
+func init() {
+	rulesPushCmd.Flags().StringVar(&rulesAccount, "account", "", "Only push to this account (default: all accounts)")
+	rulesPushCmd.Flags().BoolVar(&rulesDryRun, "dry-run", false, "Show the filters that would be created without creating them")
+	rulesCmd.AddCommand(rulesPushCmd)
+	rootCmd.AddCommand(rulesCmd)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/config"
+	"github.com/daviddao/mailbeads/internal/schedule"
+)
+
+// scheduledJob is a job from the config's schedule with its parsed spec.
+type scheduledJob struct {
+	config.ScheduledJob
+	spec *schedule.Spec
+}
+
+// loadSchedule parses the jobs in the config's schedule.
+func loadSchedule() ([]scheduledJob, error) {
+	var jobs []scheduledJob
+	for i, job := range cfg.Schedule {
+		spec, err := schedule.Parse(job)
+		if err != nil {
+			label := fmt.Sprintf("schedule[%d]", i)
+			if job.Name != "" {
+				label += " (" + job.Name + ")"
+			}
+			return nil, codedErrorf(codeInvalidArgument, "config %s: %v", label, err)
+		}
+		if job.Name == "" {
+			job.Name = job.Args[0]
+		}
+		jobs = append(jobs, scheduledJob{job, spec})
+	}
+	return jobs, nil
+}
+
+// startSchedule runs each job on its schedule, in the project root, until
+// ctx is done; cancelling interrupts running jobs. wg tracks the job
+// loops. A job never overlaps its own previous run.
+func startSchedule(ctx context.Context, jobs []scheduledJob, root string, wg *sync.WaitGroup) {
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			next := job.spec.Next(time.Now())
+			if job.spec.Interval() {
+				next = time.Now()
+			}
+			for {
+				timer := time.NewTimer(time.Until(next))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+				runJob(ctx, job, root)
+				next = job.spec.Next(time.Now())
+			}
+		}()
+		slog.Info("Scheduled "+job.Name, "args", strings.Join(job.Args, " "), "when", job.spec.String())
+	}
+}
+
+// runJob runs one job as a child mb process with the same database,
+// offline mode, and logging, prefixing its output with the job name.
+func runJob(ctx context.Context, job scheduledJob, root string) {
+	exe, err := os.Executable()
+	if err != nil {
+		slog.Error("schedule failed", "job", job.Name, "err", err)
+		return
+	}
+	args := []string{"--no-color"}
+	if dbPath != "" {
+		args = append(args, "--db", dbPath)
+	}
+	if offlineFlag {
+		args = append(args, "--offline")
+	}
+	for _, f := range [][2]string{{"--log-level", logLevel}, {"--log-format", logFormat}, {"--log-file", logFile}} {
+		if f[1] != "" {
+			args = append(args, f[0], f[1])
+		}
+	}
+	args = append(args, job.Args...)
+
+	cmd := exec.CommandContext(ctx, exe, args...)
+	cmd.Dir = root
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = 10 * time.Second
+	// Child diagnostics are already formatted (and maybe bound for the log
+	// file) by its own logger; only its output needs the job's name.
+	logw := &prefixWriter{prefix: job.Name + ": ", w: os.Stderr}
+	defer logw.Flush()
+	cmd.Stderr = os.Stderr
+	var out bytes.Buffer
+	if job.Output != "" {
+		cmd.Stdout = &out
+	} else {
+		cmd.Stdout = logw
+	}
+
+	start := time.Now()
+	slog.Info(job.Name+" started", "job", job.Name)
+	err = cmd.Run()
+	logw.Flush()
+	if err != nil {
+		slog.Error(job.Name+" failed", "job", job.Name, "err", err)
+		return
+	}
+	if job.Output != "" {
+		path := job.Output
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+			slog.Error(job.Name+" failed", "job", job.Name, "err", err)
+			return
+		}
+	}
+	slog.Info(job.Name+" done", "job", job.Name, "took", time.Since(start).Round(time.Millisecond).String())
+}
+
+// prefixWriter writes each complete line to w with a prefix.
+type prefixWriter struct {
+	prefix string
+	w      io.Writer
+	mu     sync.Mutex
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf[:i])
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes a trailing partial line.
+func (p *prefixWriter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.buf) > 0 {
+		fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf)
+		p.buf = nil
+	}
+}
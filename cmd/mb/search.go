@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchAccount string
+	searchLimit   int
+)
+
+// searchResult is one matching email in mb search output.
+type searchResult struct {
+	ThreadID  string   `json:"thread_id"`
+	Account   string   `json:"account"`
+	EmailID   string   `json:"email_id"`
+	From      string   `json:"from"`
+	Subject   string   `json:"subject"`
+	Date      string   `json:"date"`
+	MatchedIn []string `json:"matched_in"`
+	Excerpt   string   `json:"excerpt,omitempty"`
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search QUERY...",
+	Short: "Search synced emails, including attachment text",
+	Long: `Search the local email cache. Every word of the query must appear in the
+subject, sender, body, or text extracted from PDF, DOCX, and plain-text
+attachments during sync. Matching is case-insensitive substring matching.
+
+from:X, to:X, and cc:X match people on that header, and with:X on any of
+them, using the parsed addresses rather than the raw header: X with an @
+is an exact address (so "Bob <bob@x.com>" and "bob@x.com" both match
+from:bob@x.com), anything else part of an address or display name.
+
+Attachment text is only extracted for emails synced with bodies; emails
+cached by older versions of mb or by 'mb sync --no-body' match on headers
+and body alone.
+
+--format ndjson streams one JSON result per line as emails are read, so
+large exports start at once and don't build up in memory.
+
+Examples:
+  mb search "purchase order 4471"
+  mb search invoice --account work --json
+  mb search with:carol@example.com budget
+  mb search invoice -n 0 --format ndjson | jq -r .email_id`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query := strings.Join(args, " ")
+		var words []string
+		for _, w := range strings.Fields(strings.ToLower(query)) {
+			if _, _, ok := db.AddressTerm(w); !ok {
+				words = append(words, w)
+			}
+		}
+
+		if listFormat == formatNDJSON && !jsonOutput {
+			// Stream: emails (with bodies) are matched and written one at
+			// a time instead of all being loaded first.
+			bw := bufio.NewWriter(cmd.OutOrStdout())
+			enc := json.NewEncoder(bw)
+			err := store.SearchEmailsFunc(query, searchAccount, searchLimit, func(e *types.Email) error {
+				return enc.Encode(matchEmail(e, words))
+			})
+			if err != nil {
+				return fmt.Errorf("search emails: %w", err)
+			}
+			return bw.Flush()
+		}
+
+		emails, err := store.SearchEmails(query, searchAccount, searchLimit)
+		if err != nil {
+			return fmt.Errorf("search emails: %w", err)
+		}
+
+		results := make([]searchResult, 0, len(emails))
+		for _, e := range emails {
+			results = append(results, matchEmail(e, words))
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), results)
+		}
+
+		if listFormat != "" {
+			rows := make([][]string, 0, len(results))
+			for _, r := range results {
+				rows = append(rows, []string{
+					r.ThreadID, r.Account, r.EmailID, r.From, r.Subject, r.Date,
+					strings.Join(r.MatchedIn, ","), r.Excerpt,
+				})
+			}
+			return writeList(cmd.OutOrStdout(), listFormat, results,
+				[]string{"thread_id", "account", "email_id", "from", "subject", "date", "matched_in", "excerpt"}, rows)
+		}
+
+		if len(results) == 0 {
+			fmt.Printf("No emails match %q.\n", query)
+			return nil
+		}
+
+		fmt.Printf("Matches for %q (%d):\n\n", query, len(results))
+		for _, r := range results {
+			fmt.Printf("  %s  %s  %s\n",
+				display.Dim.Render(display.Truncate(r.ThreadID, 16)),
+				display.AccountLabel(r.Account),
+				display.Bold.Render(display.Truncate(r.Subject, 60)),
+			)
+			fmt.Printf("    %s  %s\n",
+				display.Truncate(r.From, 40),
+				display.Muted.Render(display.FormatTime(r.Date)),
+			)
+			if r.Excerpt != "" {
+				fmt.Printf("    %s %s\n",
+					display.Muted.Render(strings.Join(r.MatchedIn, ",")+":"),
+					display.Dim.Render(r.Excerpt),
+				)
+			}
+		}
+		return nil
+	},
+}
+
+// matchEmail records which fields of e contain a query word and quotes the
+// text around the first hit in the body, attachments, or snippet.
+func matchEmail(e *types.Email, words []string) searchResult {
+	r := searchResult{
+		ThreadID: e.ThreadID,
+		Account:  e.Account,
+		EmailID:  e.ID,
+		From:     e.From,
+		Subject:  e.Subject,
+		Date:     e.SentAt,
+	}
+	fields := []struct{ name, text string }{
+		{"subject", e.Subject},
+		{"from", e.From},
+		{"body", e.Body},
+		{"attachment", e.AttachmentText},
+		{"snippet", e.Snippet},
+	}
+	for _, f := range fields {
+		lower := strings.ToLower(f.text)
+		at := -1
+		for _, w := range words {
+			if i := strings.Index(lower, w); i >= 0 && (at < 0 || i < at) {
+				at = i
+			}
+		}
+		if at < 0 {
+			continue
+		}
+		r.MatchedIn = append(r.MatchedIn, f.name)
+		if r.Excerpt == "" && f.name != "subject" && f.name != "from" {
+			r.Excerpt = excerpt(f.text, at, 80)
+		}
+	}
+	return r
+}
+
+// excerpt returns about width bytes of text centered on offset at, on one
+// line, with ellipses where it was cut.
+func excerpt(text string, at, width int) string {
+	start := min(max(at-width/2, 0), len(text))
+	end := min(start+width, len(text))
+	for start > 0 && start < len(text) && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	s := strings.Join(strings.Fields(text[start:end]), " ")
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(text) {
+		s += "…"
+	}
+	return s
+}
+
+func init() {
+	searchCmd.Flags().StringVar(&searchAccount, "account", "", "Filter by account")
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Max results (0 = no limit)")
+	addFormatFlag(searchCmd)
+	rootCmd.AddCommand(searchCmd)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/mbox"
+)
+
+// seedStore loads --seed into the open store: a .json file holding a
+// db.Seed, or else an mbox, whose messages without a Delivered-To header
+// are filed under the file's base name.
+func seedStore(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return codedErrorf(codeInvalidArgument, "--seed: %v", err)
+	}
+	defer f.Close()
+
+	var seed db.Seed
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.NewDecoder(f).Decode(&seed); err != nil {
+			return codedErrorf(codeInvalidArgument, "--seed: parse %s: %v", path, err)
+		}
+	} else {
+		account := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		seed.Emails, err = mbox.Read(f, account)
+		if err != nil {
+			return codedErrorf(codeInvalidArgument, "--seed: read mbox %s: %v", path, err)
+		}
+	}
+	if _, err := store.Seed(&seed); err != nil {
+		return fmt.Errorf("--seed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	mbv1 "github.com/daviddao/mailbeads/api/mailbeads/v1"
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	msync "github.com/daviddao/mailbeads/internal/sync"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	serveGRPCAddr  string
+	serveHTTPAddr  string
+	serveEventPoll time.Duration
+	serveToken     string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the mailbeads API over gRPC, with a live event stream and scheduled jobs",
+	Long: `Serve the core workflow — Sync, ListUntriaged, GetThread, Triage, Close —
+as a gRPC service, for agents and services that would rather call typed
+RPCs than shell out to mb. The schema is api/mailbeads/v1/mailbeads.proto;
+server reflection is enabled, so grpcurl works without it.
+
+RPCs behave like the matching commands (mb sync, mb untriaged, mb show,
+mb triage, mb done / mb dismiss): triage goes through beads and hooks, and
+lands in the undo journal and audit log. Requests are handled one at a
+time. Errors carry gRPC status codes: InvalidArgument for bad or ambiguous
+arguments, NotFound for unknown threads, FailedPrecondition when bd is
+missing or a hook rejects a triage, Unavailable for Sync under --offline.
+
+An HTTP server (--http-addr) streams changes as server-sent events at
+GET /events, so a web UI or agent can react without polling: new emails,
+triaged threads, triage changes, closed beads, and reminders for due and
+snoozed beads, checked every --poll.
+Each event is named by its type and carries the mb watch --events object
+as data; ?types=email,bead_closed limits the stream. No history is
+replayed on connect. Nothing is synced by the stream itself: call Sync, or
+run mb sync / mb watch alongside.
+
+GET /calendar.ics serves the due dates and snooze wake-ups of open
+beads as an iCalendar feed (see mb export ical), for calendar apps to
+subscribe to.
+
+  event: triaged
+  data: {"type":"triaged","time":"...","bead_id":"bd-12","thread_id":"...","title":"...","priority":"high"}
+
+The HTTP server also serves the mb web dashboard at / and the JSON REST
+API under /api/v1 it is built on; see mb web --help for the endpoints
+and how they refuse requests from other web pages.
+
+mb serve also runs the recurring jobs in the config's "schedule", so one
+process handles all periodic work. Each job is an mb command run in the
+project root, every interval (starting at startup) or daily at a local
+time, optionally on some weekdays only; a job never overlaps itself, and
+its output goes to this log or, with "output", replaces a file:
+
+  "schedule": [
+    {"name": "sync", "args": ["sync"], "every": "10m"},
+    {"name": "digest", "args": ["digest", "--format", "html"], "at": "08:00", "output": "digest.html"},
+    {"name": "notes", "args": ["export", "notes", "--dir", "/home/me/vault/Email"], "at": "03:00", "days": ["sun"]}
+  ]
+
+Both servers listen on 127.0.0.1 by default. With --token (or
+MB_SERVE_TOKEN), every RPC must send "authorization: Bearer TOKEN"
+metadata, and every HTTP request the same header or ?token=TOKEN (for
+browsers' EventSource and calendar apps); set one before listening
+on another interface.
+
+Examples:
+  mb serve
+  mb serve --grpc-addr :7420 --token "$(cat .mailbeads/serve-token)"
+  grpcurl -plaintext localhost:7420 mailbeads.v1.Mailbeads/ListUntriaged
+  curl -N localhost:7421/events`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token := serveToken
+		if token == "" {
+			token = os.Getenv("MB_SERVE_TOKEN")
+		}
+
+		if serveEventPoll < time.Second {
+			return fmt.Errorf("--poll must be at least 1s")
+		}
+		jobs, err := loadSchedule()
+		if err != nil {
+			return err
+		}
+
+		lis, err := net.Listen("tcp", serveGRPCAddr)
+		if err != nil {
+			return fmt.Errorf("listen: %w", err)
+		}
+		api := &apiServer{root: db.FindProjectRoot()}
+		srv := newGRPCServer(api, token)
+		errc := make(chan error, 2)
+		go func() { errc <- srv.Serve(lis) }()
+
+		var httpSrv *http.Server
+		hub := newEventHub()
+		stop := make(chan struct{})
+		if serveHTTPAddr != "" {
+			httpLis, err := net.Listen("tcp", serveHTTPAddr)
+			if err != nil {
+				srv.Stop()
+				return fmt.Errorf("listen: %w", err)
+			}
+			httpSrv = &http.Server{Handler: newHTTPMux(api, hub, token), ReadHeaderTimeout: 10 * time.Second}
+			go func() { errc <- httpSrv.Serve(httpLis) }()
+			go hub.poll(serveEventPoll, &api.mu, stop)
+			slog.Info("Serving events", "url", "http://"+httpLis.Addr().String()+"/events")
+		}
+
+		jobsCtx, stopJobs := context.WithCancel(context.Background())
+		var jobsWG sync.WaitGroup
+		startSchedule(jobsCtx, jobs, api.root, &jobsWG)
+
+		slog.Info("Serving gRPC (Ctrl-C to stop)", "addr", lis.Addr().String())
+		if token == "" {
+			slog.Warn("no --token set: any local process can call the API")
+		}
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		select {
+		case <-sig:
+		case err = <-errc:
+		}
+		close(stop)
+		stopJobs()
+		hub.close()
+		if httpSrv != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			httpSrv.Shutdown(ctx)
+		}
+		srv.GracefulStop()
+		jobsWG.Wait()
+		return err
+	},
+}
+
+// newGRPCServer returns a gRPC server exposing api, with bearer-token auth
+// if token is set.
+func newGRPCServer(api *apiServer, token string) *grpc.Server {
+	var interceptors []grpc.UnaryServerInterceptor
+	if token != "" {
+		interceptors = append(interceptors, tokenAuth(token))
+	}
+	interceptors = append(interceptors, api.serialize)
+
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	mbv1.RegisterMailbeadsServer(srv, api)
+	reflection.Register(srv)
+	return srv
+}
+
+// tokenAuth rejects calls without "authorization: Bearer token" metadata.
+func tokenAuth(token string) grpc.UnaryServerInterceptor {
+	want := []byte("Bearer " + token)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, v := range md.Get("authorization") {
+			if subtle.ConstantTimeCompare([]byte(v), want) == 1 {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+	}
+}
+
+// apiServer implements the Mailbeads service on the command's store and
+// config.
+type apiServer struct {
+	mbv1.UnimplementedMailbeadsServer
+	root string
+	// mu serializes calls: handlers share the store, the journal, and bd
+	// the way one mb command at a time does.
+	mu sync.Mutex
+}
+
+func (s *apiServer) serialize(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return handler(ctx, req)
+}
+
+func (s *apiServer) Sync(ctx context.Context, req *mbv1.SyncRequest) (*mbv1.SyncResponse, error) {
+	if offlineFlag {
+		return nil, grpcError(errOffline("Sync"))
+	}
+	if s.root == "" {
+		return nil, status.Error(codes.FailedPrecondition, "could not find project root (no .git directory)")
+	}
+	accounts := msync.DiscoverAccounts(cfg, s.root)
+	if req.Account != "" {
+		accounts = []string{req.Account}
+	}
+	if len(accounts) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "no accounts found")
+	}
+	summary, err := syncAccounts(s.root, accounts, msync.Options{
+		Full:        req.Full,
+		IncludeSpam: req.IncludeSpam,
+		HeadersOnly: req.HeadersOnly,
+		StoreRaw:    cfg.Sync.StoreRaw,
+		Quiet:       true,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &mbv1.SyncResponse{TotalNew: int32(summary.TotalNew), TotalInDb: int32(summary.TotalInDB)}
+	for _, r := range summary.Accounts {
+		resp.Accounts = append(resp.Accounts, &mbv1.AccountSyncResult{
+			Account:   r.Account,
+			Fetched:   int32(r.Fetched),
+			Skipped:   int32(r.Skipped),
+			Commented: int32(r.Commented),
+			Notified:  int32(r.Notified),
+			Error:     r.Error,
+		})
+	}
+	return resp, nil
+}
+
+func (s *apiServer) ListUntriaged(ctx context.Context, req *mbv1.ListUntriagedRequest) (*mbv1.ListUntriagedResponse, error) {
+	limit := int(req.Limit)
+	if limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+	if limit == 0 {
+		limit = 50
+	}
+	threads, err := store.UntriagedThreads(req.Account, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "query untriaged: %v", err)
+	}
+	model, err := loadClassifier()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &mbv1.ListUntriagedResponse{}
+	for _, t := range threads {
+		if err := annotateThread(t, model); err != nil {
+			return nil, grpcError(err)
+		}
+		resp.Threads = append(resp.Threads, threadProto(t))
+	}
+	return resp, nil
+}
+
+func (s *apiServer) GetThread(ctx context.Context, req *mbv1.GetThreadRequest) (*mbv1.GetThreadResponse, error) {
+	if req.ThreadId == "" {
+		return nil, status.Error(codes.InvalidArgument, "thread_id is required")
+	}
+	threadID, account, err := resolveThread(req.ThreadId, req.Account)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	emails, err := store.ThreadEmails(threadID, account)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fetch emails: %v", err)
+	}
+	if len(emails) == 0 {
+		return nil, status.Errorf(codes.NotFound, "no emails found for thread %q in %s", threadID, account)
+	}
+	fetchMissingBodies(emails)
+
+	info, err := store.ThreadInfo(threadID, account)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fetch thread: %v", err)
+	}
+	info.AuthWarnings = threadAuthWarnings(emails)
+	info.SuggestedCategory = suggestCategory(emails)
+	if info.TriageRef, err = store.GetTriageRef(threadID, account); err == nil && info.TriageRef == nil {
+		info.TriageRef, err = store.DuplicateTriageRef(threadID, account)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fetch triage ref: %v", err)
+	}
+
+	resp := &mbv1.GetThreadResponse{Thread: threadProto(info)}
+	for _, e := range emails {
+		resp.Emails = append(resp.Emails, emailProto(e))
+	}
+	return resp, nil
+}
+
+func (s *apiServer) Triage(ctx context.Context, req *mbv1.TriageRequest) (*mbv1.TriageResponse, error) {
+	if !beads.Available() {
+		return nil, grpcError(errBDMissing)
+	}
+	if req.ThreadId == "" {
+		return nil, status.Error(codes.InvalidArgument, "thread_id is required")
+	}
+	tr := triageRequest{
+		Priority:   req.Priority,
+		Action:     req.Action,
+		Suggestion: req.Suggestion,
+		AgentNotes: req.AgentNotes,
+		Category:   req.Category,
+		Epic:       req.Epic,
+		Due:        req.Due,
+	}
+	var err error
+	tr.ThreadID, tr.Account, err = resolveThread(req.ThreadId, req.Account)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if err := tr.validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	out, err := applyTriage(tr)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &mbv1.TriageResponse{
+		ThreadId:         out.ThreadID,
+		Account:          out.Account,
+		BeadId:           out.BeadID,
+		Action:           out.Action,
+		Priority:         out.Priority,
+		Subject:          out.Subject,
+		Due:              out.Due,
+		Created:          out.Created,
+		LinkedDuplicates: int32(out.Linked),
+	}, nil
+}
+
+func (s *apiServer) Close(ctx context.Context, req *mbv1.CloseRequest) (*mbv1.CloseResponse, error) {
+	if !beads.Available() {
+		return nil, grpcError(errBDMissing)
+	}
+	if req.BeadId == "" {
+		return nil, status.Error(codes.InvalidArgument, "bead_id is required")
+	}
+	outcome, reason, op := "done", "done", types.OpDone
+	switch req.Outcome {
+	case mbv1.CloseRequest_OUTCOME_UNSPECIFIED, mbv1.CloseRequest_OUTCOME_DONE:
+	case mbv1.CloseRequest_OUTCOME_DISMISSED:
+		outcome, reason, op = "dismissed", "dismissed — spam/irrelevant", types.OpDismiss
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown outcome %d", req.Outcome)
+	}
+	if err := beads.Close(req.BeadId, reason); err != nil {
+		return nil, status.Errorf(codes.Internal, "close %s: %v", req.BeadId, err)
+	}
+	if err := closeTriage(req.BeadId, op); err != nil {
+		display.ErrorMsg("record %s: %v", req.BeadId, err)
+	}
+	return &mbv1.CloseResponse{BeadId: req.BeadId, Outcome: outcome}, nil
+}
+
+// grpcError converts a command error to a gRPC status by its error code.
+func grpcError(err error) error {
+	code, _ := errorCode(err)
+	c := codes.Internal
+	switch code {
+	case codeInvalidArgument, codeAmbiguousAccount, codeAmbiguousThread:
+		c = codes.InvalidArgument
+	case codeThreadNotFound:
+		c = codes.NotFound
+	case codeBDMissing, codeHookRejected, codeLLMNotConfigured:
+		c = codes.FailedPrecondition
+	case codeOffline:
+		c = codes.Unavailable
+	}
+	return status.Error(c, err.Error())
+}
+
+func threadProto(t *types.Thread) *mbv1.Thread {
+	pt := &mbv1.Thread{
+		ThreadId:            t.ThreadID,
+		Account:             t.Account,
+		Subject:             t.Subject,
+		Sender:              t.From,
+		EmailCount:          int32(t.EmailCount),
+		LatestDate:          t.LatestDate,
+		AuthWarnings:        t.AuthWarnings,
+		PredictedPriority:   t.PredictedPriority,
+		PredictedConfidence: t.PredictedConfidence,
+		SuggestedCategory:   t.SuggestedCategory,
+	}
+	if t.TriageRef != nil {
+		pt.BeadId = t.TriageRef.BeadID
+	}
+	return pt
+}
+
+func emailProto(e *types.Email) *mbv1.Email {
+	pe := &mbv1.Email{
+		Id:          e.ID,
+		ThreadId:    e.ThreadID,
+		Account:     e.Account,
+		MessageId:   e.MessageID,
+		Sender:      e.From,
+		To:          e.To,
+		Cc:          e.CC,
+		Subject:     e.Subject,
+		Snippet:     e.Snippet,
+		Body:        htmltext.Readable(e.Body),
+		BodyMissing: e.BodyMissing,
+		SentAt:      e.SentAt,
+		IsRead:      e.IsRead != 0,
+	}
+	for _, label := range strings.Split(e.Labels, ",") {
+		if label != "" {
+			pe.Labels = append(pe.Labels, label)
+		}
+	}
+	return pe
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveGRPCAddr, "grpc-addr", "127.0.0.1:7420", "Address for the gRPC server")
+	serveCmd.Flags().StringVar(&serveHTTPAddr, "http-addr", "127.0.0.1:7421", `Address for the HTTP events stream, REST API, and dashboard ("" to disable)`)
+	serveCmd.Flags().DurationVar(&serveEventPoll, "poll", 5*time.Second, "How often to check for changes to stream")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Require this bearer token on every call (default $MB_SERVE_TOKEN)")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serviceName  string
+	serviceEnv   []string
+	servicePrint bool
+)
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Run mb watch or mb serve as a background service",
+	Long: `Install, inspect, and remove a background service that runs mb for this
+project: a systemd user unit on Linux, a launchd agent on macOS. It starts
+at login, runs in the project root, and restarts 10s after a crash.
+
+The service is named after the project directory (mailbeads-DIR), so
+several projects can each have one; --name overrides it.`,
+}
+
+// serviceResult is the --json output of mb service install and uninstall.
+type serviceResult struct {
+	Name string   `json:"name"`
+	Path string   `json:"path"`
+	Args []string `json:"args,omitempty"`
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install [-- MB_ARGS...]",
+	Short: "Install and start the background service",
+	Long: `Install and start a service running mb with MB_ARGS (default: watch) in
+the project root, now and at every login. Installing again replaces it.
+
+The service gets this shell's PATH, so it finds bd, plus each --env
+variable: --env NAME copies its current value, --env NAME=VALUE sets one.
+Values are written to the unit file (mode 0600), so pass secrets such as
+MB_SERVE_TOKEN this way only on a machine you trust. Logs go to the
+journal on Linux (journalctl --user -u NAME) and to
+.mailbeads/service.log on macOS.
+
+--print writes the unit file or plist to stdout without installing it.
+
+Examples:
+  mb service install                               # mb watch
+  mb service install -- watch --interval 5m
+  mb service install --env MB_SERVE_TOKEN -- serve # scheduled jobs, API, events
+  mb service install --print`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := service.Supported(); err != nil {
+			return err
+		}
+		root := db.FindProjectRoot()
+		if root == "" {
+			return fmt.Errorf("could not find project root (no .git directory)")
+		}
+		if len(args) == 0 {
+			args = []string{"watch"}
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+
+		env := map[string]string{"PATH": os.Getenv("PATH")}
+		for _, e := range serviceEnv {
+			k, v, ok := strings.Cut(e, "=")
+			if !ok {
+				if v, ok = os.LookupEnv(k); !ok {
+					return codedErrorf(codeInvalidArgument, "--env %s: not set in this environment (use --env %s=VALUE)", k, k)
+				}
+			}
+			env[k] = v
+		}
+		dir := mbDir
+		if dir == "" {
+			dir = filepath.Join(root, ".mailbeads")
+		}
+		spec := service.Spec{
+			Name:    serviceNameFor(root),
+			Exe:     exe,
+			Args:    args,
+			Dir:     root,
+			Env:     env,
+			LogPath: filepath.Join(dir, "service.log"),
+		}
+
+		if servicePrint {
+			fmt.Fprint(cmd.OutOrStdout(), service.Render(spec))
+			return nil
+		}
+		path, err := service.Install(spec)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), serviceResult{Name: spec.Name, Path: path, Args: args})
+		}
+		display.SuccessMsg("Installed %s: mb %s", spec.Name, strings.Join(args, " "))
+		fmt.Printf("  %s\n", display.Dim.Render(path))
+		return nil
+	},
+}
+
+var serviceStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the background service is installed and running",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := db.FindProjectRoot()
+		if root == "" && serviceName == "" {
+			return fmt.Errorf("could not find project root (no .git directory)")
+		}
+		st, err := service.Query(serviceNameFor(root))
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), st)
+		}
+		switch {
+		case !st.Installed:
+			fmt.Printf("%s is not installed (mb service install)\n", st.Name)
+		case st.Running:
+			display.SuccessMsg("%s is running", st.Name)
+		default:
+			display.ErrorMsg("%s is installed but not running", st.Name)
+		}
+		if st.Detail != "" && !quietFlag {
+			fmt.Printf("\n%s", st.Detail)
+		}
+		return nil
+	},
+}
+
+var serviceUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop and remove the background service",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := db.FindProjectRoot()
+		if root == "" && serviceName == "" {
+			return fmt.Errorf("could not find project root (no .git directory)")
+		}
+		name := serviceNameFor(root)
+		path, err := service.Uninstall(name)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), serviceResult{Name: name, Path: path})
+		}
+		display.SuccessMsg("Uninstalled %s", name)
+		return nil
+	},
+}
+
+var unsafeServiceChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// serviceNameFor returns --name, or a name derived from the project root.
+func serviceNameFor(root string) string {
+	if serviceName != "" {
+		return serviceName
+	}
+	base := strings.Trim(unsafeServiceChars.ReplaceAllString(filepath.Base(root), "-"), "-.")
+	if base == "" {
+		return "mailbeads"
+	}
+	return "mailbeads-" + base
+}
+
+func init() {
+	serviceCmd.PersistentFlags().StringVar(&serviceName, "name", "", "Service name (default: mailbeads-<project directory>)")
+	serviceInstallCmd.Flags().StringArrayVar(&serviceEnv, "env", nil, "Pass an environment variable: NAME (current value) or NAME=VALUE (repeatable)")
+	serviceInstallCmd.Flags().BoolVar(&servicePrint, "print", false, "Print the unit file or plist instead of installing it")
+	serviceCmd.AddCommand(serviceInstallCmd, serviceStatusCmd, serviceUninstallCmd)
+	rootCmd.AddCommand(serviceCmd)
+}
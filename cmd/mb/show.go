@@ -1,50 +1,72 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/daviddao/mailbeads/internal/beads"
 	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	"github.com/daviddao/mailbeads/internal/mailauth"
+	"github.com/daviddao/mailbeads/internal/quotes"
+	msync "github.com/daviddao/mailbeads/internal/sync"
 	"github.com/daviddao/mailbeads/internal/types"
 	"github.com/spf13/cobra"
 )
 
 var (
-	showAccount string
-	showNoBody  bool
+	showAccount     string
+	showNoBody      bool
+	showStripQuotes bool
+	showRender      bool
+	showMerged      bool
 )
 
 type showOutput struct {
-	ThreadID  string           `json:"thread_id"`
-	Account   string           `json:"account"`
-	Subject   string           `json:"subject"`
-	Emails    []*types.Email   `json:"emails"`
-	TriageRef *types.TriageRef `json:"triage_ref,omitempty"`
-	Bead      *beads.Issue     `json:"bead,omitempty"`
+	ThreadID string         `json:"thread_id"`
+	Account  string         `json:"account"`
+	Subject  string         `json:"subject"`
+	Emails   []*types.Email `json:"emails"`
+	// Participants is everyone on the thread's From, To, and Cc lines.
+	Participants []*types.Participant   `json:"participants"`
+	Events       []*types.CalendarEvent `json:"events,omitempty"`
+	// CodeLinks are the GitHub/GitLab pull requests and issues the
+	// thread links to.
+	CodeLinks []*types.CodeLink `json:"code_links,omitempty"`
+	// Duplicates are threads in other accounts holding the same messages.
+	Duplicates []*types.Thread  `json:"duplicates,omitempty"`
+	TriageRef  *types.TriageRef `json:"triage_ref,omitempty"`
+	Bead       *beads.Issue     `json:"bead,omitempty"`
+	// Muted is set for threads muted with mb mute.
+	Muted bool `json:"muted,omitempty"`
 }
 
 var showCmd = &cobra.Command{
 	Use:   "show THREAD_ID",
 	Short: "Display thread detail with emails and linked beads issue",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		threadID := args[0]
+	Long: `Display a thread's emails and its linked beads issue.
 
-		account := showAccount
-		if account == "" {
-			accounts, err := store.ThreadAccounts(threadID)
-			if err != nil {
-				return fmt.Errorf("lookup thread: %w", err)
-			}
-			switch len(accounts) {
-			case 0:
-				return fmt.Errorf("thread %q not found", threadID)
-			case 1:
-				account = accounts[0]
-			default:
-				return fmt.Errorf("thread exists in multiple accounts (%v), specify --account", accounts)
-			}
+THREAD_ID may also be words from the subject or sender; if several threads
+match equally well they are listed so you can pick one by ID.
+
+Bodies missing locally (after mb sync --no-body) are fetched from Gmail and
+cached. If that fails, the snippet is shown instead.
+
+Each message's SPF, DKIM, and DMARC results (from Gmail's
+Authentication-Results header) are included in --json output as "auth",
+and failures, or a From display name showing a different address than the
+sender's, are flagged as warnings.
+
+Examples:
+  mb show 19abc123
+  mb show "quarterly numbers"
+  mb show 19abc123 --strip-quotes --render`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		threadID, account, err := resolveThread(args[0], showAccount)
+		if err != nil {
+			return err
 		}
 
 		emails, err := store.ThreadEmails(threadID, account)
@@ -55,10 +77,51 @@ var showCmd = &cobra.Command{
 			return fmt.Errorf("no emails found for thread %q in %s", threadID, account)
 		}
 
+		duplicates, err := store.DuplicateThreads(threadID, account)
+		if err != nil {
+			return fmt.Errorf("fetch duplicate threads: %w", err)
+		}
+		if showMerged {
+			for _, dup := range duplicates {
+				more, err := store.ThreadEmails(dup.ThreadID, dup.Account)
+				if err != nil {
+					return fmt.Errorf("fetch emails: %w", err)
+				}
+				emails = mergeEmails(emails, more)
+			}
+		}
+		if !showNoBody {
+			fetchMissingBodies(emails)
+		}
+		if !cmd.Flags().Changed("strip-quotes") {
+			showStripQuotes = cfg.Show.StripQuotes
+		}
+		for _, e := range emails {
+			e.Auth = mailauth.Check(e.From, e.AuthResults)
+			e.Body = htmltext.Readable(e.Body)
+			if showStripQuotes {
+				e.Body = quotes.Strip(e.Body, quotes.Options{
+					KeepSignatures: cfg.Show.KeepSignatures,
+					Markers:        cfg.Show.QuoteMarkers,
+				})
+			}
+		}
+
 		triageRef, err := store.GetTriageRef(threadID, account)
 		if err != nil {
 			return fmt.Errorf("fetch triage ref: %w", err)
 		}
+		if triageRef == nil {
+			triageRef, err = store.DuplicateTriageRef(threadID, account)
+			if err != nil {
+				return fmt.Errorf("fetch triage ref: %w", err)
+			}
+		}
+
+		events, err := store.ThreadEvents(threadID, account)
+		if err != nil {
+			return fmt.Errorf("fetch events: %w", err)
+		}
 
 		// Fetch beads issue if triaged.
 		var bead *beads.Issue
@@ -68,51 +131,93 @@ var showCmd = &cobra.Command{
 
 		if jsonOutput {
 			out := showOutput{
-				ThreadID:  threadID,
-				Account:   account,
-				Subject:   emails[0].Subject,
-				Emails:    emails,
-				TriageRef: triageRef,
-				Bead:      bead,
+				ThreadID:     threadID,
+				Account:      account,
+				Subject:      emails[0].Subject,
+				Emails:       emails,
+				Participants: threadParticipants(emails, account),
+				Events:       events,
+				CodeLinks:    threadCodeLinks(emails),
+				Duplicates:   duplicates,
+				TriageRef:    triageRef,
+				Bead:         bead,
+				Muted:        store.IsMuted(threadID, account),
 			}
-			enc := json.NewEncoder(cmd.OutOrStdout())
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
+			return writeOutput(cmd.OutOrStdout(), out)
 		}
 
 		// Pretty output
 		fmt.Printf("Thread: %s (%s)\n", threadID, display.AccountLabel(account))
 		fmt.Printf("Subject: %s\n", display.Bold.Render(emails[0].Subject))
-		fmt.Printf("Emails: %d messages\n\n", len(emails))
-
-		for i, e := range emails {
-			var connector string
-			switch {
-			case len(emails) == 1:
-				connector = "──"
-			case i == 0:
-				connector = "┌─"
-			case i == len(emails)-1:
-				connector = "└─"
-			default:
-				connector = "├─"
+		fmt.Printf("Emails: %d messages\n", len(emails))
+		if store.IsMuted(threadID, account) {
+			fmt.Printf("Muted: %s\n", display.Dim.Render("yes (mb unmute to restore)"))
+		}
+		if len(duplicates) > 0 {
+			var also []string
+			for _, dup := range duplicates {
+				also = append(also, fmt.Sprintf("%s (%s)", dup.ThreadID, display.AccountLabel(dup.Account)))
 			}
+			label := "Also in"
+			if showMerged {
+				label = "Merged with"
+			}
+			fmt.Printf("%s: %s\n", label, display.Dim.Render(strings.Join(also, ", ")))
+		}
+		fmt.Println()
 
-			body := ""
-			if !showNoBody {
-				body = e.Body
-				if body == "" {
-					body = e.Snippet
+		if showRender {
+			printRenderedEmails(emails)
+		} else {
+			for i, e := range emails {
+				var connector string
+				switch {
+				case len(emails) == 1:
+					connector = "──"
+				case i == 0:
+					connector = "┌─"
+				case i == len(emails)-1:
+					connector = "└─"
+				default:
+					connector = "├─"
 				}
-			}
 
-			display.EmailTree(connector, e.From, e.Date, body)
-			if i < len(emails)-1 {
-				fmt.Println(display.Muted.Render("  │"))
+				body := ""
+				if !showNoBody {
+					body = e.Body
+					if body == "" {
+						body = e.Snippet
+					}
+				}
+
+				display.EmailTree(connector, e.From, e.SentAt, body)
+				prefix := "  │  "
+				if connector == "└─" {
+					prefix = "     "
+				}
+				printAuthWarnings(prefix, e)
+				if i < len(emails)-1 {
+					fmt.Println(display.Muted.Render("  │"))
+				}
 			}
 		}
 
 		fmt.Println()
+		for _, ev := range events {
+			fmt.Printf("  Invite: %s  %s\n", display.Bold.Render(ev.Summary), display.Dim.Render(eventWhen(ev)))
+			if ev.Organizer != "" {
+				fmt.Printf("    Organizer: %s\n", ev.Organizer)
+			}
+			if ev.Attendees != "" {
+				fmt.Printf("    Attendees: %s\n", display.Dim.Render(display.Truncate(ev.Attendees, 100)))
+			}
+			if ev.Location != "" {
+				fmt.Printf("    Location: %s\n", ev.Location)
+			}
+		}
+		if len(events) > 0 {
+			fmt.Println()
+		}
 		if bead != nil {
 			pri := beads.PriorityFromBeads(bead.Priority)
 			fmt.Printf("  Bead: %s %s\n", bead.ID, display.TriageBadge(pri, bead.Title))
@@ -123,6 +228,9 @@ var showCmd = &cobra.Command{
 				fmt.Printf("  Notes: %s\n", display.Dim.Render(bead.Notes))
 			}
 			fmt.Printf("  Status: %s\n", bead.Status)
+			if bead.DueAt != "" {
+				fmt.Printf("  Due: %s\n", bead.DueAt)
+			}
 		} else if triageRef != nil {
 			fmt.Printf("  Bead: %s %s\n", triageRef.BeadID, display.Dim.Render("(not found in beads)"))
 		} else {
@@ -133,8 +241,103 @@ var showCmd = &cobra.Command{
 	},
 }
 
+// mergeEmails appends emails from a duplicate thread, skipping messages
+// already present (same RFC Message-ID), and keeps the result in date order.
+func mergeEmails(emails, more []*types.Email) []*types.Email {
+	seen := make(map[string]bool)
+	for _, e := range emails {
+		if e.MessageID != "" {
+			seen[e.MessageID] = true
+		}
+	}
+	for _, e := range more {
+		if e.MessageID != "" && seen[e.MessageID] {
+			continue
+		}
+		emails = append(emails, e)
+	}
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].SentAt < emails[j].SentAt
+	})
+	return emails
+}
+
+// fetchMissingBodies downloads and caches the bodies of emails stored by a
+// headers-only sync. Failures are reported and leave the email as is, so
+// callers fall back to the snippet. In offline mode nothing is fetched.
+func fetchMissingBodies(emails []*types.Email) {
+	fetchBodies(emails, true)
+}
+
+// fetchBodies is fetchMissingBodies; without cache, the bodies (and any
+// invites in them) are kept in memory only, for dry runs.
+func fetchBodies(emails []*types.Email, cache bool) {
+	if offlineFlag {
+		return
+	}
+	failed := make(map[string]bool)
+	for _, e := range emails {
+		if !e.BodyMissing || failed[e.Account] {
+			continue
+		}
+		p, acct, err := accountProvider(e.Account)
+		if err != nil {
+			display.ErrorMsg("fetch body: %v", err)
+			failed[e.Account] = true
+			continue
+		}
+		full, err := p.Read(acct, e.ID)
+		if err != nil {
+			display.ErrorMsg("fetch body of %s: %v", e.ID, err)
+			continue
+		}
+		body, attachments := full.Email.Body, full.Email.AttachmentText
+		e.Body, e.BodyMissing, e.AttachmentText = body, false, attachments
+		if !cache {
+			continue
+		}
+		if err := store.SetEmailBody(e.ID, body, attachments); err != nil {
+			display.ErrorMsg("cache body of %s: %v", e.ID, err)
+		}
+		if full.ICS != "" {
+			msync.RecordInvites(store, e, full.ICS)
+		}
+	}
+}
+
+// printAuthWarnings prints an email's spoofing warnings under it.
+func printAuthWarnings(prefix string, e *types.Email) {
+	if e.Auth == nil {
+		return
+	}
+	for _, w := range e.Auth.Warnings {
+		fmt.Printf("%s%s\n", display.Muted.Render(prefix), display.ErrStyle.Render("⚠ "+w))
+	}
+}
+
+// printRenderedEmails prints every message in full, separated by rules,
+// with bodies formatted by display.RenderBody.
+func printRenderedEmails(emails []*types.Email) {
+	width := display.TermWidth()
+	for i, e := range emails {
+		fmt.Println(display.MessageSeparator(i+1, len(emails), e.From, e.SentAt, width))
+		printAuthWarnings("", e)
+		if showNoBody {
+			continue
+		}
+		body := e.Body
+		if body == "" {
+			body = e.Snippet
+		}
+		fmt.Printf("\n%s\n\n", display.RenderBody(body, width))
+	}
+}
+
 func init() {
 	showCmd.Flags().StringVar(&showAccount, "account", "", "Specify account")
 	showCmd.Flags().BoolVar(&showNoBody, "no-body", false, "Hide email bodies")
+	showCmd.Flags().BoolVar(&showMerged, "merged", false, "Merge in the same messages delivered to other accounts")
+	showCmd.Flags().BoolVar(&showRender, "render", false, "Render full message bodies with wrapping, styled links, and separators")
+	showCmd.Flags().BoolVar(&showStripQuotes, "strip-quotes", false, "Strip quoted replies and signatures from bodies (default from config)")
 	rootCmd.AddCommand(showCmd)
 }
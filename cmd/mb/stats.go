@@ -1,11 +1,16 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/daviddao/mailbeads/internal/beads"
 	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
 	"github.com/spf13/cobra"
 )
 
@@ -16,8 +21,51 @@ type statsOutput struct {
 	TotalEmail int                     `json:"total_emails"`
 	Threads    int                     `json:"threads"`
 	BeadsOpen  int                     `json:"beads_open,omitempty"`
+	Latency    latencyStats            `json:"latency"`
+	Trend      []trendDay              `json:"trend,omitempty"`
 }
 
+// latencyStats reports how long threads wait, in seconds: from the first
+// email's arrival until triage, and from triage until done/dismiss.
+type latencyStats struct {
+	ToTriage percentiles `json:"to_triage"`
+	ToDone   percentiles `json:"to_done"`
+}
+
+type percentiles struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50_seconds"`
+	P95   float64 `json:"p95_seconds"`
+}
+
+func newPercentiles(values []float64) percentiles {
+	p := percentiles{Count: len(values)}
+	if len(values) == 0 {
+		return p
+	}
+	sort.Float64s(values)
+	p.P50 = percentile(values, 50)
+	p.P95 = percentile(values, 95)
+	return p
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, pct float64) float64 {
+	rank := int(math.Ceil(pct / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// trendDay summarizes one local calendar day of stats_history snapshots.
+type trendDay struct {
+	Date      string `json:"date"`
+	NewEmails int    `json:"new_emails"`
+	Triaged   int    `json:"triaged"`
+	Backlog   int    `json:"backlog"`
+	Untriaged int    `json:"untriaged"`
+}
+
+var statsTrend string
+
 type accountStats struct {
 	Count    int    `json:"count"`
 	LastSync string `json:"last_sync,omitempty"`
@@ -26,7 +74,36 @@ type accountStats struct {
 var statsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Show inbox statistics",
+	Long: `Show inbox statistics.
+
+Triage latency (p50/p95) covers the time from a thread's first email to its
+triage, and from triage to mb done/dismiss. It spans all history, or the
+--trend window when given.
+
+Every sync records a snapshot of the inbox counts. --trend shows them per
+day over a window: new emails, threads triaged, and the backlog (untriaged
+plus triaged-but-open threads) at the end of the day.
+
+Examples:
+  mb stats
+  mb stats --trend 30d
+  mb stats --trend 2w --json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		var trend []trendDay
+		since := ""
+		if statsTrend != "" {
+			days, err := parseDays(statsTrend)
+			if err != nil {
+				return err
+			}
+			since = time.Now().AddDate(0, 0, -days).UTC().Format(time.RFC3339)
+			history, err := store.StatsHistory(since)
+			if err != nil {
+				return fmt.Errorf("read stats history: %w", err)
+			}
+			trend = trendDays(history)
+		}
+
 		accounts := store.Accounts()
 		emailStats := make(map[string]accountStats)
 		totalEmails := 0
@@ -37,6 +114,12 @@ var statsCmd = &cobra.Command{
 			totalEmails += count
 		}
 
+		toTriage, toDone, err := store.TriageLatencies(since)
+		if err != nil {
+			return fmt.Errorf("read triage log: %w", err)
+		}
+		latency := latencyStats{ToTriage: newPercentiles(toTriage), ToDone: newPercentiles(toDone)}
+
 		untriaged := store.UntriagedCount()
 		triaged := store.TriagedCount()
 		threads := store.ThreadCount()
@@ -50,18 +133,50 @@ var statsCmd = &cobra.Command{
 			}
 		}
 
+		out := statsOutput{
+			Emails:     emailStats,
+			Untriaged:  untriaged,
+			Triaged:    triaged,
+			TotalEmail: totalEmails,
+			Threads:    threads,
+			BeadsOpen:  beadsOpen,
+			Latency:    latency,
+			Trend:      trend,
+		}
+
 		if jsonOutput {
-			out := statsOutput{
-				Emails:     emailStats,
-				Untriaged:  untriaged,
-				Triaged:    triaged,
-				TotalEmail: totalEmails,
-				Threads:    threads,
-				BeadsOpen:  beadsOpen,
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+
+		if listFormat != "" && statsTrend != "" {
+			rows := make([][]string, 0, len(trend))
+			for _, d := range trend {
+				rows = append(rows, []string{d.Date, strconv.Itoa(d.NewEmails), strconv.Itoa(d.Triaged),
+					strconv.Itoa(d.Backlog), strconv.Itoa(d.Untriaged)})
+			}
+			return writeList(cmd.OutOrStdout(), listFormat, trend,
+				[]string{"date", "new_emails", "triaged", "backlog", "untriaged"}, rows)
+		}
+
+		if listFormat != "" {
+			rows := [][]string{}
+			for _, acc := range accounts {
+				s := emailStats[acc]
+				rows = append(rows, []string{"emails", acc, strconv.Itoa(s.Count), s.LastSync})
 			}
-			enc := json.NewEncoder(cmd.OutOrStdout())
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
+			rows = append(rows,
+				[]string{"total_emails", "", strconv.Itoa(totalEmails), ""},
+				[]string{"threads", "", strconv.Itoa(threads), ""},
+				[]string{"triaged", "", strconv.Itoa(triaged), ""},
+				[]string{"untriaged", "", strconv.Itoa(untriaged), ""},
+				[]string{"beads_open", "", strconv.Itoa(beadsOpen), ""},
+				[]string{"to_triage_p50_seconds", "", strconv.Itoa(int(latency.ToTriage.P50)), ""},
+				[]string{"to_triage_p95_seconds", "", strconv.Itoa(int(latency.ToTriage.P95)), ""},
+				[]string{"to_done_p50_seconds", "", strconv.Itoa(int(latency.ToDone.P50)), ""},
+				[]string{"to_done_p95_seconds", "", strconv.Itoa(int(latency.ToDone.P95)), ""},
+			)
+			return writeList(cmd.OutOrStdout(), listFormat, out,
+				[]string{"metric", "account", "value", "last_sync"}, rows)
 		}
 
 		display.Header("Mailbeads Statistics")
@@ -72,10 +187,10 @@ var statsCmd = &cobra.Command{
 			s := emailStats[acc]
 			syncInfo := ""
 			if s.LastSync != "" {
-				syncInfo = fmt.Sprintf("(last sync: %s)", display.TimeAgo(s.LastSync))
+				syncInfo = fmt.Sprintf("(last sync: %s)", display.FormatTime(s.LastSync))
 			}
-			fmt.Printf("    %-28s %4d emails  %s\n",
-				display.AccountLabel(acc), s.Count, display.Dim.Render(syncInfo))
+			fmt.Printf("    %s %4d emails  %s\n",
+				display.PadRight(display.AccountLabel(acc), 28), s.Count, display.Dim.Render(syncInfo))
 		}
 		fmt.Println()
 
@@ -87,11 +202,116 @@ var statsCmd = &cobra.Command{
 		}
 		fmt.Println()
 
+		if latency.ToTriage.Count > 0 || latency.ToDone.Count > 0 {
+			fmt.Println("  Latency            p50      p95")
+			printLatency("Arrival → triage", latency.ToTriage)
+			printLatency("Triage → done", latency.ToDone)
+			fmt.Println()
+		}
+
 		fmt.Printf("  Total: %d emails across %d threads\n", totalEmails, threads)
+
+		if statsTrend != "" {
+			fmt.Println()
+			printTrend(trend)
+		}
 		return nil
 	},
 }
 
+func printLatency(label string, p percentiles) {
+	if p.Count == 0 {
+		fmt.Printf("    %-16s %s\n", label, display.Dim.Render("—"))
+		return
+	}
+	fmt.Printf("    %-16s %-8s %-8s %s\n", label, formatSeconds(p.P50), formatSeconds(p.P95),
+		display.Dim.Render(fmt.Sprintf("(%d threads)", p.Count)))
+}
+
+// formatSeconds renders a duration compactly: 45s, 12m, 3h20m, 2d4h.
+func formatSeconds(s float64) string {
+	d := time.Duration(s) * time.Second
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
+
+// parseDays parses a --trend window: "30d", "4w", or a bare number of days.
+func parseDays(s string) (int, error) {
+	n, unit := s, 1
+	switch {
+	case strings.HasSuffix(s, "d"):
+		n = strings.TrimSuffix(s, "d")
+	case strings.HasSuffix(s, "w"):
+		n, unit = strings.TrimSuffix(s, "w"), 7
+	}
+	days, err := strconv.Atoi(n)
+	if err != nil || days <= 0 {
+		return 0, fmt.Errorf("invalid window %q (use e.g. 30d, 4w)", s)
+	}
+	return days * unit, nil
+}
+
+// trendDays buckets snapshots by local date. New emails compare the day's
+// last snapshot to the previous day's (or to the first snapshot, for the
+// first day in the window).
+func trendDays(history []*types.StatsSnapshot) []trendDay {
+	var days []trendDay
+	prevEmails := -1
+	if len(history) > 0 {
+		prevEmails = history[0].Emails
+	}
+	for _, s := range history {
+		t, err := time.Parse(time.RFC3339, s.TakenAt)
+		if err != nil {
+			continue
+		}
+		date := t.Local().Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			if len(days) > 0 {
+				prevEmails += days[len(days)-1].NewEmails
+			}
+			days = append(days, trendDay{Date: date})
+		}
+		d := &days[len(days)-1]
+		d.NewEmails = max(s.Emails-prevEmails, 0)
+		d.Triaged += s.NewTriaged
+		d.Backlog = s.Untriaged + s.Triaged
+		d.Untriaged = s.Untriaged
+	}
+	return days
+}
+
+func printTrend(trend []trendDay) {
+	fmt.Println("  Trend")
+	if len(trend) == 0 {
+		fmt.Println(display.Dim.Render("    No history yet — snapshots are recorded on each mb sync."))
+		return
+	}
+	fmt.Printf("    %-10s  %6s  %7s  %7s\n", "Date", "Emails", "Triaged", "Backlog")
+	prev := trend[0].Backlog
+	for _, d := range trend {
+		change := ""
+		switch delta := d.Backlog - prev; {
+		case delta > 0:
+			change = display.ErrStyle.Render(fmt.Sprintf("+%d", delta))
+		case delta < 0:
+			change = display.Success.Render(fmt.Sprintf("%d", delta))
+		}
+		fmt.Printf("    %-10s  %6d  %7d  %7d %s\n", d.Date, d.NewEmails, d.Triaged, d.Backlog, change)
+		prev = d.Backlog
+	}
+}
+
 func init() {
+	statsCmd.Flags().StringVar(&statsTrend, "trend", "", "Show daily history over a window (e.g. 30d, 4w)")
+	addFormatFlag(statsCmd)
 	rootCmd.AddCommand(statsCmd)
 }
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/daviddao/mailbeads/internal/beads"
@@ -123,9 +122,7 @@ Examples:
 				ActionItems: actionItems,
 				SyncState:   syncStates,
 			}
-			enc := json.NewEncoder(cmd.OutOrStdout())
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
+			return writeOutput(cmd.OutOrStdout(), out)
 		}
 
 		// Terminal display
@@ -137,10 +134,10 @@ Examples:
 		for _, s := range syncStates {
 			syncInfo := ""
 			if s.LastSync != "" {
-				syncInfo = fmt.Sprintf("(last sync: %s)", display.TimeAgo(s.LastSync))
+				syncInfo = fmt.Sprintf("(last sync: %s)", display.FormatTime(s.LastSync))
 			}
-			fmt.Printf("    %-28s %4d emails  %s\n",
-				display.AccountLabel(s.Account), s.Emails, display.Dim.Render(syncInfo))
+			fmt.Printf("    %s %4d emails  %s\n",
+				display.PadRight(display.AccountLabel(s.Account), 28), s.Emails, display.Dim.Render(syncInfo))
 		}
 		fmt.Printf("    %s\n", display.Dim.Render(fmt.Sprintf("%d emails across %d threads", totalEmails, threads)))
 		fmt.Println()
@@ -1,11 +1,12 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/daviddao/mailbeads/internal/db"
 	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/hooks"
+	"github.com/daviddao/mailbeads/internal/notify"
 	msync "github.com/daviddao/mailbeads/internal/sync"
 	"github.com/daviddao/mailbeads/internal/types"
 	"github.com/spf13/cobra"
@@ -15,23 +16,96 @@ var (
 	syncFull        bool
 	syncAccount     string
 	syncIncludeSpam bool
+	syncNoBody      bool
+	syncRaw         bool
+	syncDryRun      bool
+	syncQuery       string
+	syncMax         int
 )
 
 var syncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Fetch emails from Gmail into the local database",
-	Long:  "Sync emails from all discovered Gmail accounts into the mailbeads database.",
+	Long: `Sync emails from all discovered Gmail accounts into the mailbeads database.
+
+Each account is synced through its mail provider: Gmail unless
+"accounts": {"ADDRESS": {"provider": "NAME"}} in .mailbeads/config.json
+names another one registered in this build.
+
+With --no-body, only headers and snippets are stored, skipping the full
+message fetch — much faster for large inboxes. Listings and triage work as
+usual; mb show fetches and caches a missing body when it is first needed.
+
+With --raw (or "sync": {"store_raw": true} in .mailbeads/config.json), the
+original RFC 822 source of each new message is stored too, gzip-compressed.
+It costs one more API call per message, and keeps the mail re-parseable and
+exportable losslessly with mb eml. --no-body takes precedence.
+
+Examples:
+  mb sync
+  mb sync --no-body --account me@work.com
+  mb sync --max 50
+  mb sync --query "from:billing@stripe.com newer_than:90d"
+
+With --query, the automatic window is replaced by a search in the mail
+provider's syntax (Gmail's for Gmail accounts), and exactly the matching
+messages are synced, in any folder, for a targeted backfill of one
+correspondent or topic. The next plain sync resumes after the newest
+stored message, so run one before a query that reaches past it, or mail
+in between is skipped.
+
+With --max N, at most N new messages are fetched per account, newest
+first, which keeps a sync bounded after a long absence. The older ones
+are not picked up by later syncs, which resume after the newest stored
+message; backfill them with --query if needed.
+
+With --dry-run, each account's new messages are listed (from the search
+metadata, without fetching bodies) but nothing is stored, commented on,
+notified, or hooked.
+
+A plain sync first asks the provider whether the mailbox changed since the
+account's last one (for Gmail, one history ID lookup) and stops there if
+not. Otherwise only the IDs in the window are listed, and metadata and
+bodies are read for the new messages alone, so a quiet inbox costs a
+couple of API calls however much mail it holds. Labels and read state
+changed in Gmail since then (starring, archiving, reading) are applied to
+the stored messages.
+
+After the accounts are synced, the auto-dismiss policies in the config
+take matching untriaged threads out of mb untriaged (see mb dismissed).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if offlineFlag {
+			return errOffline("mb sync")
+		}
+		if syncMax < 0 {
+			return codedErrorf(codeInvalidArgument, "--max must be positive")
+		}
+		if syncQuery != "" && (syncFull || syncIncludeSpam) {
+			return codedErrorf(codeInvalidArgument, "--query replaces the sync window; it can't be combined with --full or --include-spam")
+		}
 		root := db.FindProjectRoot()
 		if root == "" {
 			return fmt.Errorf("could not find project root (no .git directory)")
 		}
 
-		if !quietFlag {
+		// Progress lines would corrupt structured output on stdout.
+		quiet := quietFlag || jsonOutput
+		if !quiet {
 			mode := ""
 			if syncFull {
 				mode = " (full 72h)"
 			}
+			if syncQuery != "" {
+				mode = " (query)"
+			}
+			if syncNoBody {
+				mode += " (headers only)"
+			} else if syncRaw || cfg.Sync.StoreRaw {
+				mode += " (with raw source)"
+			}
+			if syncDryRun {
+				mode += " (dry run)"
+			}
 			fmt.Printf("Syncing emails%s...\n", mode)
 		}
 
@@ -39,40 +113,109 @@ var syncCmd = &cobra.Command{
 		if syncAccount != "" {
 			accounts = []string{syncAccount}
 		} else {
-			accounts = msync.DiscoverAccounts(root)
+			accounts = msync.DiscoverAccounts(cfg, root)
 		}
 		if len(accounts) == 0 {
-			return fmt.Errorf("no accounts found — add account directories with credentials.json to the project root")
+			return fmt.Errorf("no accounts found — add account directories with credentials.json to the project root, or configure accounts in .mailbeads/config.json")
 		}
 
-		summary := &types.SyncSummary{}
-		for _, account := range accounts {
-			result, err := msync.SyncAccount(store, root, account, syncFull, syncIncludeSpam, quietFlag)
-			if err != nil {
-				return err
-			}
-			summary.Accounts = append(summary.Accounts, *result)
-			summary.TotalNew += result.Fetched
+		summary, err := syncAccounts(root, accounts, msync.Options{
+			Full:        syncFull,
+			IncludeSpam: syncIncludeSpam,
+			Query:       syncQuery,
+			Max:         syncMax,
+			HeadersOnly: syncNoBody,
+			StoreRaw:    syncRaw || cfg.Sync.StoreRaw,
+			DryRun:      syncDryRun,
+			Quiet:       quiet,
+		})
+		if err != nil {
+			return err
 		}
-		summary.TotalInDB = store.EmailCount()
 
 		if jsonOutput {
-			enc := json.NewEncoder(cmd.OutOrStdout())
-			enc.SetIndent("", "  ")
-			return enc.Encode(summary)
+			return writeOutput(cmd.OutOrStdout(), summary)
 		}
 
 		if !quietFlag {
 			fmt.Println()
+			if syncDryRun {
+				n := 0
+				for _, r := range summary.Accounts {
+					n += len(r.WouldFetch)
+				}
+				fmt.Println(display.Dim.Render(fmt.Sprintf("(dry run — %d new emails would be synced, nothing written)", n)))
+				return nil
+			}
 			display.SuccessMsg("Done! %d new emails synced. Total in DB: %d", summary.TotalNew, summary.TotalInDB)
+			if n := len(summary.AutoDismissed); n > 0 {
+				fmt.Printf("  %s Auto-dismissed %d thread(s): %s %s\n", display.Dim.Render("✗"), n,
+					dismissalSummary(summary.AutoDismissed), display.Dim.Render("(mb dismissed to review, mb undo to restore)"))
+			}
 		}
 		return nil
 	},
 }
 
+// syncAccounts syncs each account in turn, sending webhook notifications for
+// threads that received new mail. A dry run only lists the new mail.
+func syncAccounts(root string, accounts []string, opts msync.Options) (*types.SyncSummary, error) {
+	quiet := opts.Quiet
+	summary := &types.SyncSummary{DryRun: opts.DryRun}
+	for _, account := range accounts {
+		result, err := msync.SyncAccount(store, cfg, root, account, opts)
+		if err != nil {
+			return nil, err
+		}
+		if opts.DryRun {
+			summary.Accounts = append(summary.Accounts, *result)
+			continue
+		}
+		if len(result.Threads) > 0 && notify.Enabled(cfg) {
+			sent, err := notify.NewThreads(store, cfg, account, result.Threads)
+			result.Notified = len(sent)
+			if err != nil && !quiet {
+				display.ErrorMsg("notify: %v", err)
+			}
+		}
+		if result.Fetched > 0 {
+			audit("sync", "", account, "", fmt.Sprintf("%d new emails", result.Fetched))
+		}
+		summary.Accounts = append(summary.Accounts, *result)
+		summary.TotalNew += result.Fetched
+	}
+	summary.TotalInDB = store.EmailCount()
+	if opts.DryRun {
+		return summary, nil
+	}
+	dismissed, err := autoDismiss(false)
+	if err != nil && !quiet {
+		display.ErrorMsg("auto-dismiss: %v", err)
+	}
+	summary.AutoDismissed = dismissed
+	if err := store.SnapshotStats(); err != nil && !quiet {
+		display.ErrorMsg("record stats: %v", err)
+	}
+	if err := hooks.Run(mbDir, hooks.PostSync, postSyncHook{hooks.PostSync, summary}); err != nil {
+		display.ErrorMsg("%v", err)
+	}
+	return summary, nil
+}
+
+// postSyncHook is the post-sync hook's payload: the sync summary.
+type postSyncHook struct {
+	Event string `json:"event"`
+	*types.SyncSummary
+}
+
 func init() {
 	syncCmd.Flags().BoolVar(&syncFull, "full", false, "Force full 72h re-scan")
 	syncCmd.Flags().StringVar(&syncAccount, "account", "", "Sync single account")
+	syncCmd.Flags().BoolVar(&syncNoBody, "no-body", false, "Store only headers and snippets, skipping message bodies")
+	syncCmd.Flags().BoolVar(&syncRaw, "raw", false, "Also store each new message's compressed RFC 822 source")
+	syncCmd.Flags().StringVar(&syncQuery, "query", "", "Sync exactly the messages matching this search instead of the automatic window")
+	syncCmd.Flags().IntVar(&syncMax, "max", 0, "Fetch at most N new emails per account, newest first (default: no limit)")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "List the new emails without fetching or storing them")
 	syncCmd.Flags().BoolVar(&syncIncludeSpam, "include-spam", false, "Sync all mail (not just inbox) — includes spam, trash, sent, drafts")
 	rootCmd.AddCommand(syncCmd)
 }
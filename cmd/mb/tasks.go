@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/auth"
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	msync "github.com/daviddao/mailbeads/internal/sync"
+	"github.com/daviddao/mailbeads/internal/tasks"
+	"github.com/spf13/cobra"
+)
+
+var tasksCmd = &cobra.Command{
+	Use:   "tasks",
+	Short: "Mirror email beads into a personal task manager",
+}
+
+// taskSyncResult is one mirrored bead in mb tasks sync --json.
+type taskSyncResult struct {
+	BeadID string `json:"bead_id"`
+	TaskID string `json:"task_id"`
+	// Status is "created" or "completed".
+	Status string `json:"status"`
+}
+
+var tasksSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Add pending email beads to Todoist or Google Tasks, and complete closed ones",
+	Long: `Mirror pending email beads into the task manager set in
+.mailbeads/config.json, for working from a todo app instead of a terminal.
+
+Each open bead without a task gets one, titled with its action and with
+its priority and due date; the notes hold the suggestion and a link to
+the thread. The task ID is recorded in the bead's notes (todoist=ID or
+google=ID), so running it again adds only new beads. Beads closed since
+— by mb done, mb dismiss, or bd close — get their task completed. mb done
+and mb dismiss complete the task right away when a provider is set; this
+catches up on the rest, e.g. from cron or a post-sync hook.
+
+Todoist reads its API token from $TODOIST_API_TOKEN:
+
+  "tasks": {"provider": "todoist", "todoist": {"project": "Email", "labels": ["email"]}}
+
+Google Tasks uses an account's OAuth token, which needs the tasks scope —
+accounts authorized before it was added must delete their token.json and
+run mb sync again:
+
+  "tasks": {"provider": "google", "google": {"account": "me@gmail.com", "list": "Email"}}
+
+Completing a task in the todo app does not close the bead.
+
+Examples:
+  mb tasks sync
+  mb tasks sync --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cfg.Tasks.Provider == "" {
+			return codedErrorf(codeInvalidArgument, `no task manager configured — set "tasks": {"provider": "todoist"} or "google" in .mailbeads/config.json`)
+		}
+		if offlineFlag {
+			return errOffline("mb tasks sync")
+		}
+		if !beads.Available() {
+			return errBDMissing
+		}
+		m, err := taskManager()
+		if err != nil {
+			return err
+		}
+
+		labels := []string{"email", "triage"}
+		pending, err := beads.List(labels, "", 0)
+		if err != nil {
+			return fmt.Errorf("list beads: %w", err)
+		}
+		closed, err := beads.List(labels, "closed", 0)
+		if err != nil {
+			return fmt.Errorf("list closed beads: %w", err)
+		}
+
+		results := []taskSyncResult{}
+		seen := make(map[string]bool)
+		for _, issue := range append(pending, closed...) {
+			if seen[issue.ID] {
+				continue
+			}
+			seen[issue.ID] = true
+			taskID := beads.NoteField(issue.Notes, m.Name())
+			switch {
+			case issue.Status != "closed" && taskID == "":
+				id, err := m.Create(beadTask(issue))
+				if err != nil {
+					display.ErrorMsg("%s: %v", issue.ID, err)
+					continue
+				}
+				notes := beads.SetNoteField(issue.Notes, m.Name(), id)
+				if err := beads.Update(issue.ID, map[string]string{"notes": notes}); err != nil {
+					display.ErrorMsg("record task %s on %s: %v", id, issue.ID, err)
+				}
+				audit("task", "", "", issue.ID, fmt.Sprintf("%s %s created", m.Name(), id))
+				results = append(results, taskSyncResult{BeadID: issue.ID, TaskID: id, Status: "created"})
+			case issue.Status == "closed" && taskID != "":
+				if err := completeTask(m, &issue); err != nil {
+					display.ErrorMsg("%s: %v", issue.ID, err)
+					continue
+				}
+				results = append(results, taskSyncResult{BeadID: issue.ID, TaskID: taskID, Status: "completed"})
+			}
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), results)
+		}
+		if !quietFlag {
+			created := 0
+			for _, r := range results {
+				if r.Status == "created" {
+					created++
+				}
+			}
+			display.SuccessMsg("%s: %d tasks created, %d completed", m.Name(), created, len(results)-created)
+		}
+		return nil
+	},
+}
+
+// taskManager connects to the configured task manager.
+func taskManager() (tasks.Manager, error) {
+	switch cfg.Tasks.Provider {
+	case "todoist":
+		return tasks.NewTodoist(cfg.Tasks.Todoist)
+	case "google":
+		root := db.FindProjectRoot()
+		if root == "" {
+			return nil, fmt.Errorf("could not find project root (no .git directory)")
+		}
+		account := cfg.Tasks.Google.Account
+		if account == "" {
+			accounts := msync.DiscoverAccounts(cfg, root)
+			if len(accounts) != 1 {
+				return nil, fmt.Errorf(`config tasks.google.account: pick the Google account to use (found %d accounts)`, len(accounts))
+			}
+			account = accounts[0]
+		}
+		ctx := context.Background()
+		svc, err := auth.LoadTasksService(ctx, resolveCredentials(root, account, ""))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", account, err)
+		}
+		return tasks.NewGoogle(ctx, svc, cfg.Tasks.Google.List)
+	default:
+		return nil, fmt.Errorf(`config tasks.provider: %q (must be: "todoist", "google", or empty)`, cfg.Tasks.Provider)
+	}
+}
+
+// beadTask describes a bead as a task.
+func beadTask(issue beads.Issue) tasks.Task {
+	var notes []string
+	if d := strings.TrimSpace(issue.Description); d != "" {
+		notes = append(notes, d)
+	}
+	account, threadID := beads.NoteField(issue.Notes, "account"), beads.ThreadIDFromRef(issue.ExternalRef)
+	if account != "" && threadID != "" {
+		notes = append(notes, "Email: "+gmail.ThreadURL(account, threadID))
+	}
+	notes = append(notes, "Bead: "+issue.ID)
+	due := issue.DueAt
+	if len(due) > len("2006-01-02") {
+		due = due[:len("2006-01-02")]
+	}
+	return tasks.Task{
+		Title:    issue.Title,
+		Notes:    strings.Join(notes, "\n\n"),
+		Due:      due,
+		Priority: beads.PriorityFromBeads(issue.Priority),
+	}
+}
+
+// completeTask completes the task mirroring a closed bead and drops it
+// from the bead's notes.
+func completeTask(m tasks.Manager, issue *beads.Issue) error {
+	taskID := beads.NoteField(issue.Notes, m.Name())
+	if err := m.Complete(taskID); err != nil {
+		return err
+	}
+	notes := beads.RemoveNoteField(issue.Notes, m.Name())
+	if err := beads.Update(issue.ID, map[string]string{"notes": notes}); err != nil {
+		return fmt.Errorf("unrecord task %s: %w", taskID, err)
+	}
+	audit("task", "", "", issue.ID, fmt.Sprintf("%s %s completed", m.Name(), taskID))
+	return nil
+}
+
+// closeMirroredTask completes the task of a bead mb just closed, if tasks
+// are mirrored. Failures are reported, not returned: mb tasks sync
+// completes it later.
+func closeMirroredTask(beadID string) {
+	if cfg.Tasks.Provider == "" || offlineFlag {
+		return
+	}
+	issue, err := beads.Show(beadID)
+	if err != nil || beads.NoteField(issue.Notes, cfg.Tasks.Provider) == "" {
+		return
+	}
+	m, err := taskManager()
+	if err == nil {
+		err = completeTask(m, issue)
+	}
+	if err != nil {
+		display.ErrorMsg("complete task for %s: %v", beadID, err)
+	}
+}
+
+func init() {
+	tasksCmd.AddCommand(tasksSyncCmd)
+	rootCmd.AddCommand(tasksCmd)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/classify"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// classifierFile, inside .mailbeads/, holds the model trained by mb train.
+const classifierFile = "classifier.json"
+
+// trainOutput is the result of mb train --json.
+type trainOutput struct {
+	Path     string         `json:"path"`
+	Examples int            `json:"examples"`
+	Classes  map[string]int `json:"classes"`
+	Ready    bool           `json:"ready"`
+}
+
+var trainCmd = &cobra.Command{
+	Use:   "train",
+	Short: "Train the local priority classifier on triage history",
+	Long: `Train a naive Bayes classifier on your own triage history, so mb
+untriaged can predict each thread's priority without an LLM call.
+
+Every thread that was triaged and later closed (mb done or mb dismiss) is
+an example: the first message's sender, domain, subject, and opening
+words, labeled with the priority the bead had. Priorities recorded before
+mailbeads tracked them are looked up with bd when it is installed.
+
+The model is saved to .mailbeads/classifier.json. It predicts once it has
+at least 10 examples across two or more priorities; rerun mb train as your
+history grows.
+
+Examples:
+  mb train
+  mb untriaged`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := store.TriageLog("")
+		if err != nil {
+			return fmt.Errorf("read triage log: %w", err)
+		}
+
+		var examples []classify.Example
+		for _, e := range entries {
+			if e.ClosedAt == "" {
+				continue
+			}
+			if e.Priority == "" {
+				e.Priority = backfillPriority(e)
+			}
+			if e.Priority == "" {
+				continue
+			}
+			emails, err := store.ThreadEmails(e.ThreadID, e.Account)
+			if err != nil {
+				return fmt.Errorf("fetch emails: %w", err)
+			}
+			if len(emails) == 0 {
+				continue
+			}
+			examples = append(examples, classify.Example{Email: emails[0], Priority: e.Priority})
+		}
+
+		model := classify.Train(examples)
+		path := filepath.Join(mbDir, classifierFile)
+		if err := model.Save(path); err != nil {
+			return fmt.Errorf("save classifier: %w", err)
+		}
+		audit("train", "", "", "", fmt.Sprintf("examples=%d", model.Examples()))
+
+		out := trainOutput{Path: path, Examples: model.Examples(), Classes: model.Docs, Ready: model.Ready()}
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+		if quietFlag {
+			return nil
+		}
+		display.SuccessMsg("Trained on %d closed thread(s) → %s", out.Examples, path)
+		for _, p := range types.ValidPriorities {
+			if n := out.Classes[p]; n > 0 {
+				fmt.Printf("  %s %d\n", display.PriorityLabel(p), n)
+			}
+		}
+		if !out.Ready {
+			fmt.Println(display.Dim.Render(fmt.Sprintf(
+				"  Not enough history to predict yet (need %d examples across two priorities).", classify.MinExamples)))
+		}
+		return nil
+	},
+}
+
+// backfillPriority looks up the priority of a triage log entry that
+// predates priority tracking, and records it. It returns "" if bd is
+// unavailable or the bead is gone.
+func backfillPriority(e *types.TriageLogEntry) string {
+	if !beads.Available() {
+		return ""
+	}
+	issue, err := beads.Show(e.BeadID)
+	if err != nil {
+		return ""
+	}
+	p := beads.PriorityFromBeads(issue.Priority)
+	if !types.IsValidPriority(p) {
+		return ""
+	}
+	if err := store.SetTriagePriority(e.BeadID, p); err != nil {
+		display.ErrorMsg("record priority of %s: %v", e.BeadID, err)
+	}
+	return p
+}
+
+// loadClassifier returns the model saved by mb train, or nil if there is
+// none or it can't predict yet.
+func loadClassifier() (*classify.Model, error) {
+	m, err := classify.Load(filepath.Join(mbDir, classifierFile))
+	if err != nil {
+		return nil, fmt.Errorf("load classifier: %w", err)
+	}
+	if !m.Ready() {
+		return nil, nil
+	}
+	return m, nil
+}
+
+func init() {
+	rootCmd.AddCommand(trainCmd)
+}
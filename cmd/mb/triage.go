@@ -1,11 +1,16 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/daviddao/mailbeads/internal/beads"
 	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/hooks"
+	"github.com/daviddao/mailbeads/internal/triage"
 	"github.com/daviddao/mailbeads/internal/types"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +24,11 @@ var (
 	triageCategory   string
 	triageFrom       string
 	triageEpic       string
+	triageDue        string
+	triageLinks      bool
+	triageConfidence float64
+	triageBatch      bool
+	triageDryRun     bool
 )
 
 type triageOutput struct {
@@ -28,7 +38,24 @@ type triageOutput struct {
 	Action   string `json:"action"`
 	Priority string `json:"priority"`
 	Subject  string `json:"subject"`
+	Due      string `json:"due,omitempty"`
 	Created  bool   `json:"created"`
+	Linked   int    `json:"linked_duplicates,omitempty"`
+	// Epic is the epic the bead is linked to; with --epic auto, EpicName is
+	// the name it was chosen by and EpicCreated reports a new epic.
+	Epic        string `json:"epic,omitempty"`
+	EpicName    string `json:"epic_name,omitempty"`
+	EpicCreated bool   `json:"epic_created,omitempty"`
+	// CodeLinks are the pull requests and issues --link-detected added to
+	// the bead's description.
+	CodeLinks []*types.CodeLink `json:"code_links,omitempty"`
+	// Confidence is the decision's own estimate; below triage.review_below
+	// the decision is Queued for mb review instead of applied.
+	Confidence *float64 `json:"confidence,omitempty"`
+	Queued     bool     `json:"queued_for_review,omitempty"`
+	// Changes lists, in a dry run, the bead fields an update would change.
+	Changes []string `json:"changes,omitempty"`
+	DryRun  bool     `json:"dry_run,omitempty"`
 }
 
 var triageCmd = &cobra.Command{
@@ -40,144 +67,791 @@ The triage decision (priority, action, notes) is stored as a beads issue via
 the bd CLI. The local mailbeads database only keeps a cross-reference so
 mb untriaged / mb show can look up whether a thread has been triaged.
 
+THREAD_ID may also be words from the subject or sender, as in mb show.
+
+When .mailbeads/config.json defines "triage": {"categories": [...]},
+--category must name one of them (case-insensitive).
+
 Examples:
   mb triage 19abc123 --action "Reply with agenda" --priority high
   mb triage 19abc123 --action "FYI" --suggestion "No response needed"
-  mb triage 19abc123 --action "Review PR" --epic bd-a3f8`,
-	Args: cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		threadID := args[0]
+  mb triage "renewal contract" --action "Sign and return" --priority high
+  mb triage 19abc123 --action "Review PR" --epic bd-a3f8
+  mb triage 19abc123 --action "Pay invoice" --category billing --epic auto
+  mb triage 19abc123 --action "Review PR" --link-detected
+  mb triage 19abc123 --action "Send contract" --due 2024-06-01
+  mb triage 19abc123 --action "Follow up" --due +3d
+  mb triage --batch < decisions.json
+
+With --batch, stdin holds a JSON array (or newline-delimited objects) of
+decisions with the same fields as the flags: thread_id, action, priority,
+suggestion, agent_notes, category, from, epic, due, link_detected,
+confidence, account. The batch is all or nothing: every item is
+validated before any is applied, the local records of all items are
+written in one transaction, and if any item fails the beads already
+written are reverted (new ones closed, updated ones restored). bd has no
+batch command, so each bead still takes one bd call. Each item gets its
+own result.
+
+With --link-detected, the GitHub and GitLab pull requests and issues linked
+from the thread's messages are appended to the bead's description under
+"Linked:" (skipping any already there), so the bead leads to the PR.
 
+With --epic auto, the bead is linked to an open beads epic named after the
+category, or after the sender's domain if there is no category (the full
+address for personal mail such as gmail.com). The epic is created, labeled
+email, the first time it is needed; mb epics lists them.
+
+With --confidence, the decision records how sure its maker (an agent, a
+model, a rule) is, from 0 to 1, in the bead's notes. Below
+"triage": {"review_below": ...} in the config (default 0.7) it is queued
+for mb review instead of applied, so a doubtful guess doesn't silently
+become a task.
+
+With --dry-run, the decision is validated and the bead it would create, or
+the fields of the existing bead it would change, are printed; nothing is
+written and the pre-triage hook doesn't run.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if triageBatch {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if !beads.Available() {
-			return fmt.Errorf("bd (beads) CLI not found on PATH — install from https://beads.sh")
+			return errBDMissing
 		}
 
-		if triageAccount == "" {
-			accounts, err := store.ThreadAccounts(threadID)
-			if err != nil {
-				return fmt.Errorf("lookup thread: %w", err)
-			}
-			switch len(accounts) {
-			case 0:
-				return fmt.Errorf("thread %q not found in emails", threadID)
-			case 1:
-				triageAccount = accounts[0]
-			default:
-				return fmt.Errorf("thread exists in multiple accounts (%v), specify --account", accounts)
-			}
+		if triageBatch {
+			return runTriageBatch(cmd)
+		}
+
+		req := triageRequest{
+			Account:      triageAccount,
+			Priority:     triagePriority,
+			Action:       triageAction,
+			Suggestion:   triageSuggestion,
+			AgentNotes:   triageAgentNotes,
+			Category:     triageCategory,
+			From:         triageFrom,
+			Epic:         triageEpic,
+			Due:          triageDue,
+			LinkDetected: triageLinks,
+		}
+		if cmd.Flags().Changed("confidence") {
+			req.Confidence = &triageConfidence
+		}
+		var err error
+		req.ThreadID, req.Account, err = resolveThread(args[0], triageAccount)
+		if err != nil {
+			return err
+		}
+		if req.Action == "" {
+			return codedErrorf(codeInvalidArgument, "--action is required")
+		}
+		if err := req.validate(); err != nil {
+			return err
 		}
 
-		if triageAction == "" {
-			return fmt.Errorf("--action is required")
+		apply := triageOrQueue
+		if triageDryRun {
+			apply = previewTriage
+		}
+		out, err := apply(req)
+		if err != nil {
+			return err
 		}
 
-		if triagePriority != "" && !types.IsValidPriority(triagePriority) {
-			return fmt.Errorf("invalid priority %q (must be: high, medium, low, spam)", triagePriority)
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+		if out.DryRun {
+			printTriagePreview("", out)
+			return nil
 		}
-		if triagePriority == "" {
-			triagePriority = types.PriorityMedium
+		if out.Queued {
+			display.SuccessMsg("Queued [%s] %q for review (confidence %s, below %s)", out.Priority, out.Action,
+				confidenceLabel(*out.Confidence), confidenceLabel(reviewThreshold()))
+			fmt.Println("  Run mb review to accept, edit, or reject it.")
+			return nil
 		}
 
-		// Get thread info from emails table.
-		info, err := store.ThreadInfo(threadID, triageAccount)
+		verb := "Updated"
+		if out.Created {
+			verb = "Triaged"
+		}
+		display.SuccessMsg("%s %s [%s] %q", verb, out.BeadID, out.Priority, out.Action)
+		if out.Epic != "" {
+			fmt.Printf("  Linked to epic: %s\n", epicLabel(out))
+		}
+		if out.Due != "" {
+			fmt.Printf("  Due: %s\n", out.Due)
+		}
+		if len(out.CodeLinks) > 0 {
+			fmt.Printf("  Linked: %s\n", codeLinkRefs(out.CodeLinks))
+		}
+		if out.Linked > 0 {
+			fmt.Printf("  Linked %d duplicate thread(s) in other accounts\n", out.Linked)
+		}
+		return nil
+	},
+}
+
+// triageRequest is one triage decision, from flags or a --batch item.
+type triageRequest struct {
+	ThreadID   string `json:"thread_id"`
+	Account    string `json:"account,omitempty"`
+	Priority   string `json:"priority,omitempty"`
+	Action     string `json:"action"`
+	Suggestion string `json:"suggestion,omitempty"`
+	AgentNotes string `json:"agent_notes,omitempty"`
+	Category   string `json:"category,omitempty"`
+	From       string `json:"from,omitempty"`
+	Epic       string `json:"epic,omitempty"`
+	Due        string `json:"due,omitempty"`
+	// LinkDetected adds the thread's PR and issue links to the description.
+	LinkDetected bool `json:"link_detected,omitempty"`
+	// Confidence (0–1) is how sure whoever made the decision is of it.
+	Confidence *float64 `json:"confidence,omitempty"`
+}
+
+// validate checks the request and normalizes its priority and due date.
+func (r *triageRequest) validate() error {
+	if r.Action == "" {
+		return fmt.Errorf("action is required")
+	}
+	if r.Priority != "" && !types.IsValidPriority(r.Priority) {
+		return fmt.Errorf("priority %q is invalid (must be: high, medium, low, spam)", r.Priority)
+	}
+	if r.Priority == "" {
+		r.Priority = types.PriorityMedium
+	}
+	if r.Confidence != nil && (*r.Confidence < 0 || *r.Confidence > 1) {
+		return fmt.Errorf("confidence %v is out of range (must be between 0 and 1)", *r.Confidence)
+	}
+	category, err := canonicalCategory(r.Category)
+	if err != nil {
+		return err
+	}
+	r.Category = category
+	if r.Due != "" {
+		due, err := parseDueDate(r.Due)
 		if err != nil {
-			return fmt.Errorf("thread %q not found in %s", threadID, triageAccount)
+			return err
 		}
+		r.Due = due
+	}
+	return nil
+}
+
+// preTriageHook is the pre-triage hook's payload: the decision, the
+// thread it applies to, and the bead it will update, if any.
+type preTriageHook struct {
+	Event string `json:"event"`
+	triageRequest
+	Subject string `json:"subject"`
+	From    string `json:"from"`
+	BeadID  string `json:"bead_id,omitempty"`
+}
+
+// stagedTriage is a triage decision whose bead is written (or that is to
+// be queued for review) but whose local records aren't yet.
+type stagedTriage struct {
+	out *triageOutput
+	rec *types.TriageRecord
+}
+
+// applyTriage creates or updates the beads issue for a validated request and
+// records the triage cross-reference.
+func applyTriage(req triageRequest) (*triageOutput, error) {
+	return applyStaged(stageTriage(req))
+}
+
+// applyStaged records a single staged decision.
+func applyStaged(s *stagedTriage, err error) (*triageOutput, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err := recordTriages([]*stagedTriage{s}); err != nil {
+		return nil, err
+	}
+	return s.out, nil
+}
+
+// stageTriage creates or updates the beads issue for a validated request,
+// leaving the local records to recordTriages.
+func stageTriage(req triageRequest) (*stagedTriage, error) {
+	threadID, account := req.ThreadID, req.Account
+
+	// Get thread info from emails table.
+	info, err := store.ThreadInfo(threadID, account)
+	if err != nil {
+		return nil, fmt.Errorf("thread %q not found in %s", threadID, account)
+	}
+
+	from := req.From
+	if from == "" {
+		from = info.From
+	}
+
+	existing, err := existingTriage(threadID, account)
+	if err != nil {
+		return nil, err
+	}
+
+	hook := preTriageHook{Event: hooks.PreTriage, triageRequest: req, Subject: info.Subject, From: from}
+	if existing != nil {
+		hook.BeadID = existing.BeadID
+	}
+	if err := hooks.Run(mbDir, hooks.PreTriage, hook); err != nil {
+		return nil, codedErrorf(codeHookRejected, "triage of %s rejected: %v", threadID, err)
+	}
+
+	var epicName string
+	var epicCreated bool
+	if req.Epic == autoEpic {
+		if epicName, err = autoEpicName(req.Category, from); err != nil {
+			return nil, err
+		}
+		if req.Epic, epicCreated, err = resolveAutoEpic(epicName); err != nil {
+			return nil, err
+		}
+	}
+	var codeLinks []*types.CodeLink
+	if req.LinkDetected {
+		if codeLinks, err = addDetectedLinks(&req, existing, false); err != nil {
+			return nil, err
+		}
+	}
+
+	// Build notes with email metadata for the beads issue.
+	notes := fmt.Sprintf("from=%s account=%s thread=%s emails=%d",
+		from, account, threadID, info.EmailCount)
+	if req.Confidence != nil {
+		notes += fmt.Sprintf(" confidence=%.2f", *req.Confidence)
+	}
+	if req.AgentNotes != "" {
+		notes += "\n\n" + req.AgentNotes
+	}
+
+	decision := triage.Decision{
+		ThreadID:    threadID,
+		Account:     account,
+		Priority:    req.Priority,
+		Title:       req.Action,
+		Description: req.Suggestion,
+		Due:         req.Due,
+		Notes:       notes,
+		Category:    req.Category,
+		Epic:        req.Epic,
+	}
+	if existing != nil {
+		decision.Bead = existing.BeadID
+	}
+	// The thread's ref (and its untriaged duplicates in other accounts)
+	// will point at the bead; the journal learns which refs that adds.
+	rec, err := triage.Stage(decision, display.ErrorMsg)
+	if err != nil {
+		return nil, err
+	}
+	rec.Audit = auditEntry(rec.Op.Op, threadID, account, rec.BeadID, triageDetail(req))
+	return &stagedTriage{
+		out: &triageOutput{
+			ThreadID:    threadID,
+			Account:     account,
+			BeadID:      rec.BeadID,
+			Action:      req.Action,
+			Priority:    req.Priority,
+			Subject:     info.Subject,
+			Due:         req.Due,
+			Created:     rec.Op.Op == types.OpTriage,
+			Epic:        req.Epic,
+			EpicName:    epicName,
+			EpicCreated: epicCreated,
+			CodeLinks:   codeLinks,
+			Confidence:  req.Confidence,
+		},
+		rec: rec,
+	}, nil
+}
+
+// recordTriages writes the local records of staged decisions in one
+// transaction. If that fails, their beads are reverted; otherwise updated
+// beads are linked to their epic.
+func recordTriages(staged []*stagedTriage) error {
+	if err := triage.Record(store, triageRecords(staged)); err != nil {
+		return err
+	}
+	for _, s := range staged {
+		s.out.Linked = s.rec.Linked
+		// A created bead got its epic from bd create; link an updated one.
+		if s.rec.Suggestion == nil && s.out.Epic != "" && !s.out.Created {
+			if err := beads.AddDep(s.out.BeadID, s.out.Epic); err != nil {
+				display.ErrorMsg("link to epic: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// revertTriages undoes the bead writes of staged decisions that won't be
+// recorded (see triage.Revert).
+func revertTriages(staged []*stagedTriage) {
+	if err := triage.Revert(triageRecords(staged)); err != nil {
+		display.ErrorMsg("%v", err)
+	}
+}
+
+func triageRecords(staged []*stagedTriage) []*types.TriageRecord {
+	recs := make([]*types.TriageRecord, len(staged))
+	for i, s := range staged {
+		recs[i] = s.rec
+	}
+	return recs
+}
 
-		from := triageFrom
+// defaultReviewBelow is the confidence below which decisions go to
+// mb review when triage.review_below isn't set.
+const defaultReviewBelow = 0.7
+
+// reviewThreshold is the confidence below which a decision is queued for
+// review instead of applied.
+func reviewThreshold() float64 {
+	if cfg.Triage.ReviewBelow > 0 {
+		return cfg.Triage.ReviewBelow
+	}
+	return defaultReviewBelow
+}
+
+// lowConfidence reports whether the decision is too unsure to apply
+// without review. Decisions without a confidence are applied.
+func (r *triageRequest) lowConfidence() bool {
+	return r.Confidence != nil && *r.Confidence < reviewThreshold()
+}
+
+// triageOrQueue applies a validated request, or queues it for mb review if
+// its confidence is low.
+func triageOrQueue(req triageRequest) (*triageOutput, error) {
+	return applyStaged(stageDecision(req))
+}
+
+// stageDecision stages a validated request, to be applied or, if its
+// confidence is low, queued.
+func stageDecision(req triageRequest) (*stagedTriage, error) {
+	if req.lowConfidence() {
+		return stageQueue(req, suggestionSourceTriage)
+	}
+	return stageTriage(req)
+}
+
+// queueTriage queues a validated request for mb review, from source,
+// instead of applying it.
+func queueTriage(req triageRequest, source string) (*triageOutput, error) {
+	return applyStaged(stageQueue(req, source))
+}
+
+// stageQueue stages a validated request to be queued for review.
+func stageQueue(req triageRequest, source string) (*stagedTriage, error) {
+	info, err := store.ThreadInfo(req.ThreadID, req.Account)
+	if err != nil {
+		return nil, fmt.Errorf("thread %q not found in %s", req.ThreadID, req.Account)
+	}
+	return &stagedTriage{
+		out: &triageOutput{
+			ThreadID:   req.ThreadID,
+			Account:    req.Account,
+			Action:     req.Action,
+			Priority:   req.Priority,
+			Subject:    info.Subject,
+			Due:        req.Due,
+			Confidence: req.Confidence,
+			Queued:     true,
+		},
+		rec: &types.TriageRecord{
+			ThreadID:   req.ThreadID,
+			Account:    req.Account,
+			Suggestion: requestSuggestion(req, source),
+			Audit:      auditEntry("queue_suggestion", req.ThreadID, req.Account, "", triageDetail(req)),
+		},
+	}, nil
+}
+
+// triageDetail describes a decision for the audit log.
+func triageDetail(req triageRequest) string {
+	detail := fmt.Sprintf("priority=%s action=%q", req.Priority, req.Action)
+	if req.Confidence != nil {
+		detail += fmt.Sprintf(" confidence=%.2f", *req.Confidence)
+	}
+	return detail
+}
+
+// confidenceLabel formats a confidence as a percentage.
+func confidenceLabel(c float64) string {
+	return fmt.Sprintf("%.0f%%", c*100)
+}
+
+// existingTriage returns the triage ref of a thread, or of the same message
+// in another account, whose bead a triage would update. It returns nil if
+// the triage would create a bead.
+func existingTriage(threadID, account string) (*types.TriageRef, error) {
+	ref, err := store.GetTriageRef(threadID, account)
+	if err != nil {
+		return nil, fmt.Errorf("check existing triage: %w", err)
+	}
+	if ref != nil {
+		return ref, nil
+	}
+	// The same message delivered to another account may already be
+	// triaged; reuse its bead instead of creating a second one.
+	ref, err = store.DuplicateTriageRef(threadID, account)
+	if err != nil {
+		return nil, fmt.Errorf("check duplicate triage: %w", err)
+	}
+	return ref, nil
+}
+
+// previewTriage reports what applyTriage would do with a validated
+// request, without doing it.
+func previewTriage(req triageRequest) (*triageOutput, error) {
+	info, err := store.ThreadInfo(req.ThreadID, req.Account)
+	if err != nil {
+		return nil, fmt.Errorf("thread %q not found in %s", req.ThreadID, req.Account)
+	}
+	existing, err := existingTriage(req.ThreadID, req.Account)
+	if err != nil {
+		return nil, err
+	}
+	out := &triageOutput{
+		ThreadID:   req.ThreadID,
+		Account:    req.Account,
+		Action:     req.Action,
+		Priority:   req.Priority,
+		Subject:    info.Subject,
+		Due:        req.Due,
+		Created:    existing == nil,
+		DryRun:     true,
+		Epic:       req.Epic,
+		Confidence: req.Confidence,
+		Queued:     req.lowConfidence(),
+	}
+	if out.Queued {
+		return out, nil
+	}
+	if req.Epic == autoEpic {
+		from := req.From
 		if from == "" {
 			from = info.From
 		}
-
-		// Check if already triaged.
-		existing, err := store.GetTriageRef(threadID, triageAccount)
+		if out.EpicName, err = autoEpicName(req.Category, from); err != nil {
+			return nil, err
+		}
+		epic, err := findEpic(out.EpicName)
 		if err != nil {
-			return fmt.Errorf("check existing triage: %w", err)
+			return nil, err
+		}
+		out.Epic, out.EpicCreated = "", epic == nil
+		if epic != nil {
+			out.Epic = epic.ID
 		}
+	}
+	if req.LinkDetected {
+		if out.CodeLinks, err = addDetectedLinks(&req, existing, true); err != nil {
+			return nil, err
+		}
+	}
+	if existing == nil {
+		return out, nil
+	}
 
-		bdPriority := beads.PriorityToBeads(triagePriority)
+	out.BeadID = existing.BeadID
+	issue, err := beads.Show(existing.BeadID)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", existing.BeadID, err)
+	}
+	if issue.Title != req.Action {
+		out.Changes = append(out.Changes, fmt.Sprintf("title: %q -> %q", issue.Title, req.Action))
+	}
+	if p := beads.PriorityFromBeads(issue.Priority); p != req.Priority {
+		out.Changes = append(out.Changes, fmt.Sprintf("priority: %s -> %s", p, req.Priority))
+	}
+	if req.Suggestion != "" && issue.Description != req.Suggestion {
+		out.Changes = append(out.Changes, fmt.Sprintf("description: %q -> %q", display.Truncate(issue.Description, 40), display.Truncate(req.Suggestion, 40)))
+	}
+	if req.Due != "" && issue.DueAt != req.Due {
+		out.Changes = append(out.Changes, fmt.Sprintf("due: %s -> %s", orNone(issue.DueAt), req.Due))
+	}
+	if out.Epic != "" || out.EpicCreated {
+		out.Changes = append(out.Changes, "epic: link "+epicLabel(out))
+	}
+	return out, nil
+}
 
-		// Build notes with email metadata for the beads issue.
-		notes := fmt.Sprintf("from=%s account=%s thread=%s emails=%d",
-			from, triageAccount, threadID, info.EmailCount)
-		if triageAgentNotes != "" {
-			notes += "\n\n" + triageAgentNotes
+// printTriagePreview prints a dry-run triage result, after label (the
+// batch index) if set.
+func printTriagePreview(label string, out *triageOutput) {
+	if out.Queued {
+		fmt.Printf("  [dry-run] %sWould queue %s [%s] %q for review (confidence %s, below %s)\n", label, out.ThreadID,
+			out.Priority, out.Action, confidenceLabel(*out.Confidence), confidenceLabel(reviewThreshold()))
+		return
+	}
+	if out.Created {
+		fmt.Printf("  [dry-run] %sWould create a bead for %s [%s] %q\n", label, out.ThreadID, out.Priority, out.Action)
+		if out.Due != "" {
+			fmt.Printf("    due: %s\n", out.Due)
+		}
+		if out.Epic != "" || out.EpicCreated {
+			fmt.Printf("    epic: %s\n", epicLabel(out))
+		}
+		if len(out.CodeLinks) > 0 {
+			fmt.Printf("    linked: %s\n", codeLinkRefs(out.CodeLinks))
 		}
+		return
+	}
+	if len(out.Changes) == 0 {
+		fmt.Printf("  [dry-run] %s%s is unchanged\n", label, out.BeadID)
+		return
+	}
+	fmt.Printf("  [dry-run] %sWould update %s\n", label, out.BeadID)
+	for _, c := range out.Changes {
+		fmt.Printf("    %s\n", c)
+	}
+}
 
-		var beadID string
-		var created bool
+// addDetectedLinks appends the PR and issue links found in the thread to
+// the request's suggestion, which becomes the bead description. When
+// updating a bead without a new suggestion, the links are appended to its
+// current description. It returns the links added. In a dry run, bodies
+// fetched to find the links aren't cached.
+func addDetectedLinks(req *triageRequest, existing *types.TriageRef, dryRun bool) ([]*types.CodeLink, error) {
+	emails, err := store.ThreadEmails(req.ThreadID, req.Account)
+	if err != nil {
+		return nil, fmt.Errorf("fetch emails: %w", err)
+	}
+	fetchBodies(emails, !dryRun)
+	description := req.Suggestion
+	if description == "" && existing != nil {
+		issue, err := beads.Show(existing.BeadID)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", existing.BeadID, err)
+		}
+		description = issue.Description
+	}
 
-		if existing != nil {
-			// Update the existing beads issue.
-			beadID = existing.BeadID
-			fields := map[string]string{
-				"title":    triageAction,
-				"priority": bdPriority,
-			}
-			if triageSuggestion != "" {
-				fields["description"] = triageSuggestion
-			}
-			if err := beads.Update(beadID, fields); err != nil {
-				return fmt.Errorf("update beads issue: %w", err)
+	var added []*types.CodeLink
+	for _, c := range threadCodeLinks(emails) {
+		if !strings.Contains(description, c.URL) {
+			added = append(added, c)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(description, "\n"))
+	if !strings.HasPrefix(description, "Linked:\n") && !strings.Contains(description, "\nLinked:\n") {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString("Linked:")
+	}
+	for _, c := range added {
+		fmt.Fprintf(&b, "\n- %s (%s)", c.URL, c.Ref)
+	}
+	req.Suggestion = b.String()
+	return added, nil
+}
+
+// codeLinkRefs lists code links by their short references.
+func codeLinkRefs(links []*types.CodeLink) string {
+	refs := make([]string, len(links))
+	for i, c := range links {
+		refs[i] = c.Ref
+	}
+	return strings.Join(refs, ", ")
+}
+
+// epicLabel describes the epic of a triage result: its ID, with the name
+// --epic auto chose it by, or the epic a dry run would create.
+func epicLabel(out *triageOutput) string {
+	switch {
+	case out.EpicName == "":
+		return out.Epic
+	case out.Epic == "":
+		return fmt.Sprintf("new epic %q", out.EpicName)
+	case out.EpicCreated:
+		return fmt.Sprintf("%s (%s, new)", out.Epic, out.EpicName)
+	}
+	return fmt.Sprintf("%s (%s)", out.Epic, out.EpicName)
+}
+
+// triageBatchResult is the outcome of one --batch item.
+type triageBatchResult struct {
+	Index    int           `json:"index"`
+	ThreadID string        `json:"thread_id"`
+	OK       bool          `json:"ok"`
+	Error    string        `json:"error,omitempty"`
+	Result   *triageOutput `json:"result,omitempty"`
+}
+
+// runTriageBatch applies triage decisions read from stdin. All items are
+// validated (and their threads resolved) before any is applied, and either
+// all are applied or none is.
+func runTriageBatch(cmd *cobra.Command) error {
+	reqs, err := readTriageBatch(cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+	if len(reqs) == 0 {
+		return fmt.Errorf("no triage decisions on stdin")
+	}
+
+	results := make([]triageBatchResult, len(reqs))
+	invalid := 0
+	seen := make(map[string]int)
+	for i := range reqs {
+		r := &reqs[i]
+		results[i] = triageBatchResult{Index: i, ThreadID: r.ThreadID}
+		err := r.validate()
+		if err == nil && r.ThreadID == "" {
+			err = fmt.Errorf("thread_id is required")
+		}
+		if err == nil {
+			r.ThreadID, r.Account, err = resolveThread(r.ThreadID, r.Account)
+			results[i].ThreadID = r.ThreadID
+		}
+		if err == nil {
+			// Later items would be staged before the earlier one's ref is
+			// recorded, and create a second bead.
+			key := r.Account + "\x00" + r.ThreadID
+			if j, ok := seen[key]; ok {
+				err = fmt.Errorf("thread_id repeats item #%d", j)
 			}
-		} else {
-			// Create a new beads issue.
-			var extraLabels []string
-			issue, err := beads.Create(
-				triageAction,
-				triageSuggestion,
-				notes,
-				bdPriority,
-				triageCategory,
-				triageEpic,
-				extraLabels,
-				threadID,
-			)
+			seen[key] = i
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+			invalid++
+		}
+	}
+
+	var batchErr error
+	switch {
+	case invalid > 0:
+		batchErr = fmt.Errorf("%d of %d decisions invalid, nothing applied", invalid, len(reqs))
+	case triageDryRun:
+		failed := 0
+		for i, r := range reqs {
+			out, err := previewTriage(r)
 			if err != nil {
-				return fmt.Errorf("create beads issue: %w", err)
+				results[i].Error = err.Error()
+				failed++
+				continue
 			}
-			beadID = issue.ID
-			created = true
+			results[i].OK = true
+			results[i].Result = out
+		}
+		if failed > 0 {
+			batchErr = fmt.Errorf("%d of %d decisions failed", failed, len(reqs))
+		}
+	default:
+		batchErr = applyTriageBatch(reqs, results)
+	}
 
-			// Store cross-reference in local DB.
-			if _, err := store.UpsertTriageRef(threadID, triageAccount, beadID); err != nil {
-				return fmt.Errorf("save triage ref: %w", err)
+	if jsonOutput {
+		if err := writeOutput(cmd.OutOrStdout(), results); err != nil {
+			return err
+		}
+		if batchErr != nil {
+			return reported(batchErr)
+		}
+		return nil
+	}
+	for _, r := range results {
+		switch {
+		case r.OK && r.Result.DryRun:
+			printTriagePreview(fmt.Sprintf("#%d ", r.Index), r.Result)
+		case r.OK && r.Result.Queued:
+			display.SuccessMsg("#%d Queued %s [%s] %q for review (confidence %s)", r.Index, r.ThreadID, r.Result.Priority, r.Result.Action, confidenceLabel(*r.Result.Confidence))
+		case r.OK:
+			verb := "Updated"
+			if r.Result.Created {
+				verb = "Triaged"
 			}
+			display.SuccessMsg("#%d %s %s [%s] %q", r.Index, verb, r.Result.BeadID, r.Result.Priority, r.Result.Action)
+		case r.Error != "":
+			display.ErrorMsg("#%d %s: %s", r.Index, r.ThreadID, r.Error)
 		}
+	}
 
-		// If --epic was specified and we just created the issue, link it.
-		if triageEpic != "" && !created {
-			// For updates, add the dep if epic changed.
-			if err := beads.AddDep(beadID, triageEpic); err != nil {
-				display.ErrorMsg("link to epic: %v", err)
-			}
+	if batchErr != nil {
+		return batchErr
+	}
+	if !quietFlag {
+		if triageDryRun {
+			fmt.Println(display.Dim.Render(fmt.Sprintf("(dry run — %d triage decisions valid, nothing written)", len(reqs))))
+		} else {
+			fmt.Printf("Applied %d triage decisions\n", len(reqs))
 		}
+	}
+	return nil
+}
 
-		if jsonOutput {
-			out := triageOutput{
-				ThreadID: threadID,
-				Account:  triageAccount,
-				BeadID:   beadID,
-				Action:   triageAction,
-				Priority: triagePriority,
-				Subject:  info.Subject,
-				Created:  created,
+// applyTriageBatch stages every decision, writing its bead, then records
+// them all in one transaction. If any decision fails, the beads already
+// written are reverted and nothing is recorded; every result says why.
+func applyTriageBatch(reqs []triageRequest, results []triageBatchResult) error {
+	staged := make([]*stagedTriage, 0, len(reqs))
+	for i, r := range reqs {
+		s, err := stageDecision(r)
+		if err != nil {
+			revertTriages(staged)
+			for j := range results {
+				results[j].Error = fmt.Sprintf("not applied: #%d failed", i)
 			}
-			enc := json.NewEncoder(cmd.OutOrStdout())
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
+			results[i].Error = err.Error()
+			return fmt.Errorf("decision #%d failed, nothing applied: %w", i, err)
+		}
+		staged = append(staged, s)
+	}
+	if err := recordTriages(staged); err != nil {
+		for j := range results {
+			results[j].Error = err.Error()
 		}
+		return fmt.Errorf("nothing applied: %w", err)
+	}
+	for i, s := range staged {
+		results[i].OK = true
+		results[i].Result = s.out
+	}
+	return nil
+}
 
-		verb := "Updated"
-		if created {
-			verb = "Triaged"
+// readTriageBatch decodes a JSON array or a stream of JSON objects (NDJSON).
+func readTriageBatch(r io.Reader) ([]triageRequest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read stdin: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var reqs []triageRequest
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := dec.Decode(&reqs); err != nil {
+			return nil, fmt.Errorf("parse batch: %w", err)
 		}
-		display.SuccessMsg("%s %s [%s] %q", verb, beadID, triagePriority, triageAction)
-		if triageEpic != "" {
-			fmt.Printf("  Linked to epic: %s\n", triageEpic)
+		return reqs, nil
+	}
+	for {
+		var req triageRequest
+		if err := dec.Decode(&req); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("parse batch item %d: %w", len(reqs), err)
 		}
-		return nil
-	},
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
 }
 
 func init() {
@@ -186,8 +860,13 @@ func init() {
 	triageCmd.Flags().StringVar(&triageAction, "action", "", "Short action phrase (required)")
 	triageCmd.Flags().StringVar(&triageSuggestion, "suggestion", "", "Detailed suggestion (stored as beads description)")
 	triageCmd.Flags().StringVar(&triageAgentNotes, "agent-notes", "", "Agent reasoning notes")
-	triageCmd.Flags().StringVar(&triageCategory, "category", "", "Category label")
+	triageCmd.Flags().StringVar(&triageCategory, "category", "", "Category label (one of triage.categories, if configured)")
 	triageCmd.Flags().StringVar(&triageFrom, "from", "", "Sender (auto-detected if omitted)")
-	triageCmd.Flags().StringVar(&triageEpic, "epic", "", "Link to a beads epic (e.g., bd-a3f8)")
+	triageCmd.Flags().StringVar(&triageEpic, "epic", "", "Link to a beads epic (e.g., bd-a3f8), or \"auto\" to pick one by category or sender domain")
+	triageCmd.Flags().Float64Var(&triageConfidence, "confidence", 0, "How sure the decision is (0-1); below triage.review_below (default 0.7) it is queued for mb review")
+	triageCmd.Flags().BoolVar(&triageLinks, "link-detected", false, "Add the GitHub/GitLab PR and issue links found in the thread to the bead description")
+	triageCmd.Flags().StringVar(&triageDue, "due", "", "Due date: YYYY-MM-DD, today, tomorrow, or +Nd")
+	triageCmd.Flags().BoolVar(&triageDryRun, "dry-run", false, "Show the bead that would be created or changed without writing anything")
+	triageCmd.Flags().BoolVar(&triageBatch, "batch", false, "Read triage decisions from stdin (JSON array or NDJSON)")
 	rootCmd.AddCommand(triageCmd)
 }
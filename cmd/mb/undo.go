@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/hooks"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var undoList bool
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the most recent triage, done, or dismiss",
+	Long: `Revert the most recent operation recorded in the local journal.
+
+  triage    closes the bead it created and removes the triage refs
+  update    restores the bead's previous title, priority, description, and
+            due date, and removes any refs the update added
+  done,
+  dismiss   reopens the bead and restores its triage refs
+  auto-dismiss
+            returns the threads a sync's auto-dismiss policies dismissed
+            to mb untriaged
+
+Run it repeatedly to step further back. Use --list to see the journal.
+
+Examples:
+  mb undo
+  mb undo --list`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if undoList {
+			ops, err := store.Operations(false, 20)
+			if err != nil {
+				return fmt.Errorf("read journal: %w", err)
+			}
+			if jsonOutput {
+				if ops == nil {
+					ops = []*types.Operation{}
+				}
+				return writeOutput(cmd.OutOrStdout(), ops)
+			}
+			if len(ops) == 0 {
+				fmt.Println("Journal is empty.")
+				return nil
+			}
+			for _, op := range ops {
+				state := ""
+				if op.UndoneAt != "" {
+					state = display.Dim.Render("(undone)")
+				}
+				fmt.Printf("  %4d  %-8s %-10s %-12s %s\n", op.ID, op.Op, op.BeadID,
+					display.FormatTime(op.At), state)
+			}
+			return nil
+		}
+
+		if !beads.Available() {
+			return errBDMissing
+		}
+		ops, err := store.Operations(true, 1)
+		if err != nil {
+			return fmt.Errorf("read journal: %w", err)
+		}
+		if len(ops) == 0 {
+			return fmt.Errorf("nothing to undo")
+		}
+		op := ops[0]
+
+		if err := undoOperation(op); err != nil {
+			return fmt.Errorf("undo %s %s: %w", op.Op, op.BeadID, err)
+		}
+		if err := store.MarkUndone(op.ID); err != nil {
+			return fmt.Errorf("update journal: %w", err)
+		}
+		audit("undo", "", "", op.BeadID, fmt.Sprintf("reverted %s (journal #%d)", op.Op, op.ID))
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), op)
+		}
+		target := op.BeadID
+		if op.Op == types.OpAutoDismiss {
+			target = fmt.Sprintf("%d thread(s)", len(op.Refs))
+		}
+		display.SuccessMsg("Undid %s of %s", op.Op, target)
+		return nil
+	},
+}
+
+// undoOperation reverts one journal entry in beads and the local store.
+func undoOperation(op *types.Operation) error {
+	switch op.Op {
+	case types.OpTriage:
+		if err := beads.Close(op.BeadID, "undone"); err != nil {
+			return err
+		}
+		return store.RemoveTriage(op.BeadID)
+	case types.OpUpdate:
+		if len(op.Previous) > 0 {
+			if err := beads.Update(op.BeadID, op.Previous); err != nil {
+				return err
+			}
+			if p, err := strconv.Atoi(op.Previous["priority"]); err == nil {
+				if err := store.SetTriagePriority(op.BeadID, beads.PriorityFromBeads(p)); err != nil {
+					return err
+				}
+			}
+		}
+		for _, r := range op.Refs {
+			if err := store.DeleteThreadTriageRef(r.ThreadID, r.Account); err != nil {
+				return err
+			}
+		}
+		return nil
+	case types.OpDone, types.OpDismiss:
+		if err := beads.Reopen(op.BeadID, "undone"); err != nil {
+			return err
+		}
+		return store.RestoreTriageRefs(op.BeadID, op.Refs)
+	case types.OpAutoDismiss:
+		for _, r := range op.Refs {
+			if _, err := store.RestoreDismissed(r.ThreadID, r.Account); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// closeTriage removes a closed bead's triage refs, recording the close in
+// the triage log and the undo journal, and completes its mirrored task.
+// op is types.OpDone or OpDismiss.
+func closeTriage(beadID, op string) error {
+	refs, err := store.TriageRefsByBead(beadID)
+	if err != nil {
+		return err
+	}
+	outcome := "done"
+	if op == types.OpDismiss {
+		outcome = "dismissed"
+	}
+	if err := store.CloseTriageRef(beadID, outcome); err != nil {
+		return err
+	}
+	entry := &types.Operation{Op: op, BeadID: beadID}
+	for _, r := range refs {
+		entry.Refs = append(entry.Refs, *r)
+	}
+	threadID, account := "", ""
+	if len(refs) > 0 {
+		threadID, account = refs[0].ThreadID, refs[0].Account
+	}
+	audit(op, threadID, account, beadID, outcome)
+	if err := store.RecordOp(entry); err != nil {
+		return err
+	}
+	closeMirroredTask(beadID)
+	hook := postDoneHook{Event: hooks.PostDone, BeadID: beadID, Outcome: outcome, Refs: refs}
+	if err := hooks.Run(mbDir, hooks.PostDone, hook); err != nil {
+		display.ErrorMsg("%v", err)
+	}
+	return nil
+}
+
+// postDoneHook is the post-done hook's payload: the closed bead and the
+// threads it was triaged from.
+type postDoneHook struct {
+	Event   string             `json:"event"`
+	BeadID  string             `json:"bead_id"`
+	Outcome string             `json:"outcome"` // done or dismissed
+	Refs    []*types.TriageRef `json:"refs"`
+}
+
+func init() {
+	undoCmd.Flags().BoolVar(&undoList, "list", false, "Show recent journal entries instead of undoing")
+	rootCmd.AddCommand(undoCmd)
+}
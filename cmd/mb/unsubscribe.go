@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	unsubAccount string
+	unsubDismiss bool
+	unsubPrint   bool
+)
+
+type unsubscribeOutput struct {
+	ThreadID string                 `json:"thread_id"`
+	Account  string                 `json:"account"`
+	Method   string                 `json:"method"`
+	Target   string                 `json:"target,omitempty"`
+	Done     bool                   `json:"done"`
+	BeadID   string                 `json:"bead_id,omitempty"`
+	Info     *gmail.UnsubscribeInfo `json:"info"`
+}
+
+var unsubscribeCmd = &cobra.Command{
+	Use:   "unsubscribe THREAD_ID",
+	Short: "Unsubscribe from a mailing list via its List-Unsubscribe header",
+	Long: `Unsubscribe from the mailing list that sent a thread.
+
+Reads the List-Unsubscribe header of the latest message in the thread and
+uses the best available method:
+  1. https one-click (RFC 8058 List-Unsubscribe-Post) — POSTs directly
+  2. mailto: — sends the unsubscribe email from your account
+  3. https link without one-click — printed for you to open
+
+If the thread is triaged, the action is recorded as a comment on the linked
+beads issue. Use --dismiss to also close the bead.
+
+Examples:
+  mb unsubscribe 19abc123
+  mb unsubscribe 19abc123 --dismiss
+  mb unsubscribe 19abc123 --print     # Show options without acting`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		threadID, account, err := resolveThread(args[0], unsubAccount)
+		if err != nil {
+			return err
+		}
+
+		emails, err := store.ThreadEmails(threadID, account)
+		if err != nil {
+			return fmt.Errorf("fetch emails: %w", err)
+		}
+		if len(emails) == 0 {
+			return fmt.Errorf("no emails found for thread %q in %s", threadID, account)
+		}
+		latest := emails[len(emails)-1]
+
+		svc, err := accountService(ctx, account)
+		if err != nil {
+			return err
+		}
+
+		info, err := gmail.GetUnsubscribe(svc, latest.ID)
+		if err != nil {
+			return err
+		}
+		if !info.Available() {
+			return fmt.Errorf("thread %q has no List-Unsubscribe header", threadID)
+		}
+
+		out := unsubscribeOutput{ThreadID: threadID, Account: account, Info: info}
+		switch {
+		case info.OneClick:
+			out.Method, out.Target = "one-click", info.HTTPS
+		case info.Mailto != "":
+			out.Method, out.Target = "mailto", info.Mailto
+		default:
+			out.Method, out.Target = "link", info.HTTPS
+		}
+
+		if !unsubPrint {
+			switch out.Method {
+			case "one-click":
+				if err := gmail.OneClickUnsubscribe(info.HTTPS); err != nil {
+					return fmt.Errorf("one-click unsubscribe: %w", err)
+				}
+				out.Done = true
+			case "mailto":
+				if err := gmail.SendMailtoUnsubscribe(svc, account, info.Mailto); err != nil {
+					return fmt.Errorf("mailto unsubscribe: %w", err)
+				}
+				out.Done = true
+			}
+		}
+
+		// Record the action on the linked bead.
+		if out.Done {
+			ref, err := store.GetTriageRef(threadID, account)
+			if err == nil && ref != nil && beads.Available() {
+				out.BeadID = ref.BeadID
+				note := fmt.Sprintf("Unsubscribed via %s: %s", out.Method, out.Target)
+				if err := beads.Comment(ref.BeadID, note); err != nil {
+					display.ErrorMsg("comment on %s: %v", ref.BeadID, err)
+				}
+				if unsubDismiss {
+					if err := beads.Close(ref.BeadID, "dismissed — unsubscribed"); err != nil {
+						display.ErrorMsg("dismiss %s: %v", ref.BeadID, err)
+					} else {
+						if err := closeTriage(ref.BeadID, types.OpDismiss); err != nil {
+							display.ErrorMsg("record %s: %v", ref.BeadID, err)
+						}
+					}
+				}
+			}
+		}
+
+		if out.Done {
+			audit("unsubscribe", threadID, account, out.BeadID, out.Method+" "+out.Target)
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+
+		switch {
+		case out.Done:
+			display.SuccessMsg("Unsubscribed from %q via %s", latest.Subject, out.Method)
+			if out.BeadID != "" {
+				fmt.Printf("  Recorded on %s\n", out.BeadID)
+			}
+		case unsubPrint:
+			fmt.Printf("Unsubscribe options for %q:\n", latest.Subject)
+			if info.HTTPS != "" {
+				oneClick := ""
+				if info.OneClick {
+					oneClick = display.Dim.Render(" (one-click)")
+				}
+				fmt.Printf("  https:  %s%s\n", info.HTTPS, oneClick)
+			}
+			if info.Mailto != "" {
+				fmt.Printf("  mailto: %s\n", info.Mailto)
+			}
+		default:
+			fmt.Printf("No one-click unsubscribe available. Open this link to unsubscribe:\n  %s\n", info.HTTPS)
+		}
+		return nil
+	},
+}
+
+func init() {
+	unsubscribeCmd.Flags().StringVar(&unsubAccount, "account", "", "Specify account")
+	unsubscribeCmd.Flags().BoolVar(&unsubDismiss, "dismiss", false, "Also dismiss the linked beads issue")
+	unsubscribeCmd.Flags().BoolVar(&unsubPrint, "print", false, "Show unsubscribe options without acting")
+	rootCmd.AddCommand(unsubscribeCmd)
+}
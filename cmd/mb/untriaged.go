@@ -3,29 +3,107 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 
+	"github.com/daviddao/mailbeads/internal/classify"
 	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/mailauth"
+	"github.com/daviddao/mailbeads/internal/types"
 	"github.com/spf13/cobra"
 )
 
 var (
 	untriagedAccount string
 	untriagedLimit   int
+	untriagedBulk    string
 )
 
 var untriagedCmd = &cobra.Command{
 	Use:   "untriaged",
 	Short: "List threads without triage entries",
+	Long: `List threads without triage entries, newest first.
+
+Threads with signs of spoofing (failed SPF/DKIM/DMARC, or a From display
+name showing a different address than the sender's) are marked with ⚠ and
+carry "auth_warnings" in --json output.
+
+Once mb train has learned from enough of your triage history, a PREDICTED
+column shows each thread's likely priority ("predicted_priority" and
+"predicted_confidence" in --json output).
+
+With a category taxonomy in .mailbeads/config.json, each thread also gets
+the first category whose hints match it (CATEGORY, "suggested_category").
+
+--json output lists everyone on each thread as "participants" (see mb
+participants).
+
+Newsletters and list mail (messages with List-Id, Precedence: bulk, or
+one-click unsubscribe headers) are tagged "is_bulk" at sync. --bulk hide
+leaves those threads out, --bulk only lists nothing else, and --bulk group
+lists them after the rest, under their own heading.
+
+--format ndjson writes one JSON thread per line as each is read; with
+-n 0 it lists every untriaged thread.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		threads, err := store.UntriagedThreads(untriagedAccount, untriagedLimit)
+		limit := untriagedLimit
+		switch untriagedBulk {
+		case "show", "group":
+		case "hide", "only":
+			// Filtered after the query, so the limit applies afterwards too.
+			limit = 0
+		default:
+			return codedErrorf(codeInvalidArgument, "invalid --bulk %q (must be: show, hide, only, group)", untriagedBulk)
+		}
+		threads, err := store.UntriagedThreads(untriagedAccount, limit)
 		if err != nil {
 			return fmt.Errorf("query untriaged: %w", err)
 		}
+		threads = filterBulk(threads, untriagedBulk)
+		if untriagedLimit > 0 && len(threads) > untriagedLimit {
+			threads = threads[:untriagedLimit]
+		}
 
-		if jsonOutput {
+		model, err := loadClassifier()
+		if err != nil {
+			return err
+		}
+		if listFormat == formatNDJSON && !jsonOutput {
+			// Write each thread as soon as it is annotated, rather than
+			// reading every thread's emails first.
 			enc := json.NewEncoder(cmd.OutOrStdout())
-			enc.SetIndent("", "  ")
-			return enc.Encode(threads)
+			for _, t := range threads {
+				if err := annotateThread(t, model); err != nil {
+					return err
+				}
+				if err := enc.Encode(t); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		for _, t := range threads {
+			if err := annotateThread(t, model); err != nil {
+				return err
+			}
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), threads)
+		}
+
+		if listFormat != "" {
+			rows := make([][]string, 0, len(threads))
+			for _, t := range threads {
+				rows = append(rows, []string{
+					t.ThreadID, t.Account, t.Subject, t.From,
+					strconv.Itoa(t.EmailCount), t.LatestDate, t.PredictedPriority, t.SuggestedCategory,
+					strconv.FormatBool(t.IsBulk),
+				})
+			}
+			return writeList(cmd.OutOrStdout(), listFormat, threads,
+				[]string{"thread_id", "account", "subject", "from", "email_count", "latest_date", "predicted_priority", "suggested_category", "is_bulk"}, rows)
 		}
 
 		if len(threads) == 0 {
@@ -34,28 +112,124 @@ var untriagedCmd = &cobra.Command{
 		}
 
 		fmt.Printf("Untriaged threads (%d):\n\n", len(threads))
-		fmt.Printf("  %-16s %-12s %-40s %6s %s\n",
+		predicted := ""
+		if model != nil {
+			predicted = " " + display.PadRight(display.Dim.Render("PREDICTED"), 14)
+		}
+		categories := len(cfg.Triage.Categories) > 0
+		category := ""
+		if categories {
+			category = " " + display.PadRight(display.Dim.Render("CATEGORY"), 12)
+		}
+		fmt.Printf("  %-16s %-12s %-40s%s%s %6s %s\n",
 			display.Dim.Render("THREAD"),
 			display.Dim.Render("ACCOUNT"),
 			display.Dim.Render("SUBJECT"),
+			predicted,
+			category,
 			display.Dim.Render("EMAILS"),
 			display.Dim.Render("LATEST"),
 		)
-		for _, t := range threads {
-			fmt.Printf("  %-16s %-12s %-40s %6d %s\n",
+		for i, t := range threads {
+			if untriagedBulk == "group" && t.IsBulk && (i == 0 || !threads[i-1].IsBulk) {
+				fmt.Printf("\n  %s\n", display.Dim.Render(fmt.Sprintf("Bulk mail (%d):", len(threads)-i)))
+			}
+			subject := display.Truncate(t.Subject, 40)
+			if len(t.AuthWarnings) > 0 {
+				subject = display.ErrStyle.Render("⚠") + " " + display.Truncate(t.Subject, 38)
+			}
+			predicted := ""
+			if model != nil {
+				label := ""
+				if t.PredictedPriority != "" {
+					label = display.PriorityLabel(t.PredictedPriority) + " " +
+						display.Dim.Render(fmt.Sprintf("%.0f%%", t.PredictedConfidence*100))
+				}
+				predicted = " " + display.PadRight(label, 14)
+			}
+			category := ""
+			if categories {
+				category = " " + display.PadRight(display.Truncate(t.SuggestedCategory, 12), 12)
+			}
+			fmt.Printf("  %-16s %s %s%s%s %6d %s\n",
 				display.Truncate(t.ThreadID, 16),
-				display.AccountLabel(t.Account),
-				display.Truncate(t.Subject, 40),
+				display.PadRight(display.AccountLabel(t.Account), 12),
+				display.PadRight(subject, 40),
+				predicted,
+				category,
 				t.EmailCount,
-				display.TimeAgo(t.LatestDate),
+				display.FormatTime(t.LatestDate),
 			)
 		}
 		return nil
 	},
 }
 
+// filterBulk applies a --bulk mode to threads: hide drops bulk threads,
+// only keeps just those, and group moves them after the rest, each group
+// still newest first.
+func filterBulk(threads []*types.Thread, mode string) []*types.Thread {
+	switch mode {
+	case "hide", "only":
+		kept := threads[:0]
+		for _, t := range threads {
+			if t.IsBulk == (mode == "only") {
+				kept = append(kept, t)
+			}
+		}
+		return kept
+	case "group":
+		slices.SortStableFunc(threads, func(a, b *types.Thread) int {
+			switch {
+			case a.IsBulk == b.IsBulk:
+				return 0
+			case b.IsBulk:
+				return -1
+			}
+			return 1
+		})
+	}
+	return threads
+}
+
+// annotateThread fills in a listed thread's spoofing warnings, predicted
+// priority (if model is non-nil), suggested category, participants, and
+// linked pull requests and issues.
+func annotateThread(t *types.Thread, model *classify.Model) error {
+	emails, err := store.ThreadEmails(t.ThreadID, t.Account)
+	if err != nil {
+		return fmt.Errorf("fetch emails: %w", err)
+	}
+	t.AuthWarnings = threadAuthWarnings(emails)
+	if model != nil && len(emails) > 0 {
+		t.PredictedPriority, t.PredictedConfidence = model.Predict(emails[0])
+	}
+	t.SuggestedCategory = suggestCategory(emails)
+	t.Participants = threadParticipants(emails, t.Account)
+	t.CodeLinks = threadCodeLinks(emails)
+	return nil
+}
+
+// threadAuthWarnings collects the distinct spoofing warnings of a thread's
+// messages.
+func threadAuthWarnings(emails []*types.Email) []string {
+	var warnings []string
+	for _, e := range emails {
+		if c := mailauth.Check(e.From, e.AuthResults); c != nil {
+			for _, w := range c.Warnings {
+				if !slices.Contains(warnings, w) {
+					warnings = append(warnings, w)
+				}
+			}
+		}
+	}
+	return warnings
+}
+
 func init() {
 	untriagedCmd.Flags().StringVar(&untriagedAccount, "account", "", "Filter by account")
-	untriagedCmd.Flags().IntVarP(&untriagedLimit, "limit", "n", 50, "Max results")
+	untriagedCmd.Flags().IntVarP(&untriagedLimit, "limit", "n", 50, "Max results (0 = no limit)")
+	untriagedCmd.Flags().StringVar(&untriagedBulk, "bulk", "show", "Newsletters and list mail: show, hide, only, or group")
+	addFormatFlag(untriagedCmd)
 	rootCmd.AddCommand(untriagedCmd)
 }
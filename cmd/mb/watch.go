@@ -0,0 +1,313 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/display"
+	msync "github.com/daviddao/mailbeads/internal/sync"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval time.Duration
+	watchEvents   bool
+	watchNoSync   bool
+	watchAccount  string
+)
+
+// watchEvent is one line of the mb watch --events stream.
+type watchEvent struct {
+	Type     string   `json:"type"` // email, triaged, triage_changed, bead_closed, reminder, error
+	Time     string   `json:"time"`
+	Account  string   `json:"account,omitempty"`
+	ThreadID string   `json:"thread_id,omitempty"`
+	EmailID  string   `json:"email_id,omitempty"`
+	BeadID   string   `json:"bead_id,omitempty"`
+	From     string   `json:"from,omitempty"`
+	Subject  string   `json:"subject,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	Priority string   `json:"priority,omitempty"`
+	Status   string   `json:"status,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+	Due      string   `json:"due,omitempty"`
+	Changes  []string `json:"changes,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// watchState is what the previous poll saw, diffed against the next one.
+type watchState struct {
+	emailRow int64
+	issues   map[string]beads.Issue
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Sync continuously and stream new mail and triage changes",
+	Long: `Run a long-lived loop that syncs mail every --interval and reports
+what changed: new emails, newly triaged threads, triage updates (priority,
+status, title, due date), and closed beads.
+
+With --events, each change is written to stdout as one JSON object per line
+(NDJSON), so dashboards and agents can subscribe by reading a single process:
+
+  {"type":"email","time":"...","account":"work","thread_id":"...","email_id":"...","from":"...","subject":"..."}
+  {"type":"triaged","time":"...","bead_id":"bd-12","thread_id":"...","title":"...","priority":"high"}
+  {"type":"triage_changed","time":"...","bead_id":"bd-12","changes":["priority: medium -> high"]}
+  {"type":"bead_closed","time":"...","bead_id":"bd-12","reason":"done"}
+  {"type":"reminder","time":"...","bead_id":"bd-12","title":"...","reason":"due","due":"2025-06-02"}
+
+Only changes after startup are reported. Stop with Ctrl-C.
+
+Reminders fire when an open bead's due date arrives (at 09:00 for a date
+without a time) or its snooze expires, instead of waiting for you to run
+mb due or mb ready. Each fires once per due date or snooze, across
+restarts; ones already past fire on the first poll. Besides the event,
+"notify": {"reminders": {"desktop": true, "webhooks": true}} in
+.mailbeads/config.json shows a desktop notification and posts to the
+notify webhooks (not under --offline, which emits an error event
+instead); "due_time": "08:00" moves the time of day.
+
+Examples:
+  mb watch                       # Human-readable, sync every minute
+  mb watch --events | my-agent   # NDJSON stream
+  mb watch --no-sync --events    # Only watch triage changes made elsewhere`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if watchInterval < time.Second {
+			return fmt.Errorf("--interval must be at least 1s")
+		}
+		if offlineFlag {
+			watchNoSync = true
+		}
+		root := db.FindProjectRoot()
+		if root == "" && !watchNoSync {
+			return fmt.Errorf("could not find project root (no .git directory)")
+		}
+
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetEscapeHTML(false)
+		emit := func(ev watchEvent) {
+			ev.Time = time.Now().UTC().Format(time.RFC3339)
+			if watchEvents {
+				enc.Encode(ev)
+				return
+			}
+			printWatchEvent(ev)
+		}
+
+		state := newWatchState()
+		if !watchEvents && !quietFlag {
+			fmt.Printf("Watching (every %s, Ctrl-C to stop)...\n", watchInterval)
+		}
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+
+		for {
+			watchPoll(root, state, emit)
+			select {
+			case <-sig:
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// watchPoll syncs (unless disabled) and emits events for everything that
+// changed since the previous poll.
+func watchPoll(root string, state *watchState, emit func(watchEvent)) {
+	if !watchNoSync {
+		accounts := msync.DiscoverAccounts(cfg, root)
+		if watchAccount != "" {
+			accounts = []string{watchAccount}
+		}
+		summary, err := syncAccounts(root, accounts, msync.Options{StoreRaw: cfg.Sync.StoreRaw, Quiet: true})
+		if err != nil {
+			emit(watchEvent{Type: "error", Error: err.Error()})
+		} else {
+			for _, r := range summary.Accounts {
+				if r.Error != "" {
+					emit(watchEvent{Type: "error", Account: r.Account, Error: r.Error})
+				}
+			}
+		}
+	}
+
+	watchChanges(state, emit)
+}
+
+// watchChanges emits events for the emails and beads that changed since
+// state was taken, and for reminders that came due, and advances state.
+func watchChanges(state *watchState, emit func(watchEvent)) {
+	emails, row, err := store.EmailsAfterRow(state.emailRow)
+	if err != nil {
+		emit(watchEvent{Type: "error", Error: fmt.Sprintf("read emails: %v", err)})
+	}
+	state.emailRow = row
+	for _, e := range emails {
+		emit(watchEvent{
+			Type:     "email",
+			Account:  e.Account,
+			ThreadID: e.ThreadID,
+			EmailID:  e.ID,
+			From:     e.From,
+			Subject:  e.Subject,
+		})
+	}
+
+	refs, issues := watchSnapshot()
+	for id, issue := range issues {
+		threadID := beads.ThreadIDFromRef(issue.ExternalRef)
+		prev, seen := state.issues[id]
+		switch {
+		case !seen && refs[id]:
+			emit(watchEvent{
+				Type:     "triaged",
+				BeadID:   id,
+				ThreadID: threadID,
+				Title:    issue.Title,
+				Priority: beads.PriorityFromBeads(issue.Priority),
+				Status:   issue.Status,
+			})
+		case !seen:
+			continue
+		case prev.Status != "closed" && issue.Status == "closed":
+			emit(watchEvent{
+				Type:     "bead_closed",
+				BeadID:   id,
+				ThreadID: threadID,
+				Title:    issue.Title,
+				Reason:   issue.CloseReason,
+			})
+		default:
+			if changes := issueChanges(prev, issue); len(changes) > 0 {
+				emit(watchEvent{
+					Type:     "triage_changed",
+					BeadID:   id,
+					ThreadID: threadID,
+					Title:    issue.Title,
+					Priority: beads.PriorityFromBeads(issue.Priority),
+					Status:   issue.Status,
+					Changes:  changes,
+				})
+			}
+		}
+	}
+	// Leave new beads without a triage ref out of the snapshot so they are
+	// reported as triaged once mb links them to a thread.
+	for id := range issues {
+		if _, seen := state.issues[id]; !seen && !refs[id] {
+			delete(issues, id)
+		}
+	}
+	state.issues = issues
+
+	fireReminders(issues, emit)
+}
+
+// newWatchState snapshots the database and beads now, so only later
+// changes are reported.
+func newWatchState() *watchState {
+	state := &watchState{emailRow: store.MaxEmailRow()}
+	_, state.issues = watchSnapshot()
+	return state
+}
+
+// watchSnapshot returns the current triage refs and email beads, keyed by
+// bead ID. Returns empty maps if beads is unavailable.
+func watchSnapshot() (map[string]bool, map[string]beads.Issue) {
+	refs := make(map[string]bool)
+	if all, err := store.AllTriageRefs(); err == nil {
+		for _, r := range all {
+			refs[r.BeadID] = true
+		}
+	}
+	issues := make(map[string]beads.Issue)
+	if beads.Available() {
+		if list, err := beads.List([]string{"email", "triage"}, "", 0); err == nil {
+			for _, issue := range list {
+				issues[issue.ID] = issue
+			}
+		}
+	}
+	return refs, issues
+}
+
+// issueChanges describes the triage-relevant differences between two
+// snapshots of the same bead.
+func issueChanges(prev, cur beads.Issue) []string {
+	var changes []string
+	if p, c := beads.PriorityFromBeads(prev.Priority), beads.PriorityFromBeads(cur.Priority); p != c {
+		changes = append(changes, fmt.Sprintf("priority: %s -> %s", p, c))
+	}
+	if prev.Status != cur.Status {
+		changes = append(changes, fmt.Sprintf("status: %s -> %s", prev.Status, cur.Status))
+	}
+	if prev.Title != cur.Title {
+		changes = append(changes, fmt.Sprintf("title: %q -> %q", prev.Title, cur.Title))
+	}
+	if prev.DueAt != cur.DueAt {
+		changes = append(changes, fmt.Sprintf("due: %s -> %s", orNone(prev.DueAt), orNone(cur.DueAt)))
+	}
+	return changes
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// printWatchEvent renders an event as a human-readable line.
+func printWatchEvent(ev watchEvent) {
+	stamp := display.Dim.Render(time.Now().Format("15:04:05"))
+	switch ev.Type {
+	case "email":
+		fmt.Printf("%s %s %s  %s  %s\n", stamp, display.Success.Render("✉"),
+			display.AccountLabel(ev.Account), display.Truncate(ev.From, 30), ev.Subject)
+	case "triaged":
+		fmt.Printf("%s %s triaged %s  %s\n", stamp, display.PriorityDot(ev.Priority),
+			display.Dim.Render(ev.BeadID), ev.Title)
+	case "triage_changed":
+		fmt.Printf("%s %s changed %s  %s %s\n", stamp, display.PriorityDot(ev.Priority),
+			display.Dim.Render(ev.BeadID), ev.Title, display.Dim.Render(fmt.Sprint(ev.Changes)))
+	case "bead_closed":
+		fmt.Printf("%s %s closed %s  %s %s\n", stamp, display.Success.Render("✓"),
+			display.Dim.Render(ev.BeadID), ev.Title, display.Dim.Render(ev.Reason))
+	case "reminder":
+		what := "due " + ev.Due
+		if ev.Reason == "snooze_expired" {
+			what = "back from snooze"
+		}
+		fmt.Printf("%s %s %s %s  %s %s\n", stamp, display.PriorityDot(ev.Priority), display.MediumStyle.Render("⏰"),
+			display.Dim.Render(ev.BeadID), ev.Title, display.Dim.Render(what))
+	case "error":
+		var attrs []any
+		if ev.Account != "" {
+			attrs = append(attrs, "account", ev.Account)
+		}
+		if ev.BeadID != "" {
+			attrs = append(attrs, "bead", ev.BeadID)
+		}
+		slog.Error(ev.Error, attrs...)
+	}
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", time.Minute, "Time between polls")
+	watchCmd.Flags().BoolVar(&watchEvents, "events", false, "Emit NDJSON events on stdout")
+	watchCmd.Flags().BoolVar(&watchNoSync, "no-sync", false, "Don't sync from Gmail; only watch the local database and beads")
+	watchCmd.Flags().StringVar(&watchAccount, "account", "", "Sync a single account")
+	rootCmd.AddCommand(watchCmd)
+}
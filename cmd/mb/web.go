@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	mbv1 "github.com/daviddao/mailbeads/api/mailbeads/v1"
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	webAddr  string
+	webOpen  bool
+	webToken string
+	webPoll  time.Duration
+)
+
+//go:embed web/index.html
+var webIndex []byte
+
+var webCmd = &cobra.Command{
+	Use:   "web",
+	Short: "Serve a web dashboard for reviewing and closing triaged mail",
+	Long: `Serve a small web UI for the human half of the agent workflow: the open
+items in the inbox, the untriaged threads, each thread's messages, and
+one-click done and dismiss. It updates live from the event stream.
+
+The dashboard is also served at / by mb serve's HTTP server, next to a
+JSON REST API it is built on, which mirrors the gRPC service:
+
+  GET  /api/v1/inbox                     open email beads
+  GET  /api/v1/untriaged?account=&limit= ListUntriaged
+  GET  /api/v1/threads/{id}?account=     GetThread
+  POST /api/v1/triage                    Triage (TriageRequest as JSON)
+  POST /api/v1/beads/{id}/done           Close as done
+  POST /api/v1/beads/{id}/dismiss        Close as dismissed
+
+Responses use the proto field names (thread_id, bead_id, ...); errors are
+{"error": "...", "code": "..."} with a matching HTTP status.
+
+POST requests must have "Content-Type: application/json" and, if the
+browser sends an Origin, come from the server's own origin, so another web
+page can't close or create beads. Without a token, requests must also
+address the server by IP address or localhost, so a DNS-rebinding page
+can't read mail through it.
+
+mb web runs only this HTTP server, on 127.0.0.1 by default. Every request
+needs "Authorization: Bearer TOKEN" or ?token=TOKEN: --token (or
+MB_SERVE_TOKEN), else one made up for the run and printed with the
+dashboard URL. Open the dashboard as /?token=TOKEN and it passes the
+token on.
+
+Examples:
+  mb web --open
+  mb web --addr 127.0.0.1:8080 --token "$(cat .mailbeads/serve-token)"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token := webToken
+		if token == "" {
+			token = os.Getenv("MB_SERVE_TOKEN")
+		}
+		if token == "" {
+			token = rand.Text()
+		}
+		if webPoll < time.Second {
+			return fmt.Errorf("--poll must be at least 1s")
+		}
+
+		lis, err := net.Listen("tcp", webAddr)
+		if err != nil {
+			return fmt.Errorf("listen: %w", err)
+		}
+		api := &apiServer{root: db.FindProjectRoot()}
+		hub := newEventHub()
+		stop := make(chan struct{})
+		srv := &http.Server{Handler: newHTTPMux(api, hub, token), ReadHeaderTimeout: 10 * time.Second}
+		errc := make(chan error, 1)
+		go func() { errc <- srv.Serve(lis) }()
+		go hub.poll(webPoll, &api.mu, stop)
+
+		url := "http://" + lis.Addr().String() + "/?token=" + neturl.QueryEscape(token)
+		slog.Info("Serving dashboard (Ctrl-C to stop)", "url", url)
+		if webOpen {
+			if err := openBrowser(url); err != nil {
+				slog.Warn("could not open a browser", "err", err)
+			}
+		}
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		select {
+		case <-sig:
+		case err = <-errc:
+		}
+		close(stop)
+		hub.close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+		return err
+	},
+}
+
+// newHTTPMux routes mb serve's and mb web's HTTP server: the event
+// stream, the calendar feed, the REST API, and the dashboard, all behind
+// token if set and httpGuard.
+func newHTTPMux(api *apiServer, hub *eventHub, token string) http.Handler {
+	mux := http.NewServeMux()
+	handle := func(pattern string, h http.Handler) {
+		if token != "" {
+			h = httpTokenAuth(token, h)
+		}
+		mux.Handle(pattern, h)
+	}
+	handle("GET /events", hub)
+	handle("GET /calendar.ics", http.HandlerFunc(serveCalendar))
+
+	handle("GET /api/v1/inbox", http.HandlerFunc(api.restInbox))
+	handle("GET /api/v1/untriaged", api.rest(func(r *http.Request) (proto.Message, error) {
+		req := &mbv1.ListUntriagedRequest{Account: r.URL.Query().Get("account")}
+		if v := r.URL.Query().Get("limit"); v != "" {
+			var n int32
+			if _, err := fmt.Sscan(v, &n); err != nil {
+				return nil, status.Error(codes.InvalidArgument, "limit must be a number")
+			}
+			req.Limit = n
+		}
+		return api.ListUntriaged(r.Context(), req)
+	}))
+	handle("GET /api/v1/threads/{id}", api.rest(func(r *http.Request) (proto.Message, error) {
+		return api.GetThread(r.Context(), &mbv1.GetThreadRequest{
+			ThreadId: r.PathValue("id"),
+			Account:  r.URL.Query().Get("account"),
+		})
+	}))
+	handle("POST /api/v1/triage", api.rest(func(r *http.Request) (proto.Message, error) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "read body: %v", err)
+		}
+		req := &mbv1.TriageRequest{}
+		if err := protojson.Unmarshal(body, req); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "parse body: %v", err)
+		}
+		return api.Triage(r.Context(), req)
+	}))
+	for name, outcome := range map[string]mbv1.CloseRequest_Outcome{
+		"done":    mbv1.CloseRequest_OUTCOME_DONE,
+		"dismiss": mbv1.CloseRequest_OUTCOME_DISMISSED,
+	} {
+		handle("POST /api/v1/beads/{id}/"+name, api.rest(func(r *http.Request) (proto.Message, error) {
+			return api.Close(r.Context(), &mbv1.CloseRequest{BeadId: r.PathValue("id"), Outcome: outcome})
+		}))
+	}
+
+	handle("GET /{$}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(webIndex)
+	}))
+	return httpGuard(token != "", mux)
+}
+
+// httpGuard keeps other web pages the user visits away from the server.
+// A POST must be JSON and, when the browser says where it comes from,
+// same-origin: a cross-site form or no-cors fetch can send neither, and a
+// cross-origin JSON fetch is stopped by CORS. Without a token, the Host
+// header must also be an IP address or localhost, since a page that
+// rebinds its own domain to 127.0.0.1 would otherwise read mail from the
+// GET endpoints.
+func httpGuard(hasToken bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hasToken && !directHost(r.Host) {
+			writeRESTError(w, status.Errorf(codes.PermissionDenied, "host %q not allowed: use an IP address or localhost, or set --token", r.Host))
+			return
+		}
+		if r.Method == http.MethodPost {
+			if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+				writeRESTError(w, status.Error(codes.InvalidArgument, "Content-Type must be application/json"))
+				return
+			}
+			if site := r.Header.Get("Sec-Fetch-Site"); site != "" && site != "same-origin" && site != "none" {
+				writeRESTError(w, status.Errorf(codes.PermissionDenied, "cross-site request (%s) refused", site))
+				return
+			}
+			if origin := r.Header.Get("Origin"); origin != "" {
+				if u, err := neturl.Parse(origin); err != nil || u.Host != r.Host {
+					writeRESTError(w, status.Errorf(codes.PermissionDenied, "cross-origin request from %s refused", origin))
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// directHost reports whether a Host header names the server by IP
+// address or as localhost, which DNS rebinding can't forge.
+func directHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	return host == "localhost" || strings.HasSuffix(host, ".localhost") || net.ParseIP(host) != nil
+}
+
+// rest adapts an RPC call to a JSON endpoint. Calls are serialized with
+// the gRPC ones, and gRPC status codes become HTTP statuses.
+func (s *apiServer) rest(call func(r *http.Request) (proto.Message, error)) http.Handler {
+	marshal := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		resp, err := call(r)
+		s.mu.Unlock()
+		if err != nil {
+			writeRESTError(w, err)
+			return
+		}
+		data, err := marshal.Marshal(resp)
+		if err != nil {
+			writeRESTError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// webInboxItem is an open bead in GET /api/v1/inbox, with its thread.
+type webInboxItem struct {
+	beads.Issue
+	Priority string `json:"mb_priority"`
+	ThreadID string `json:"thread_id,omitempty"`
+	Account  string `json:"account,omitempty"`
+}
+
+// restInbox lists the open email beads, as mb inbox --json does, with
+// the thread each came from.
+func (s *apiServer) restInbox(w http.ResponseWriter, r *http.Request) {
+	if !beads.Available() {
+		writeRESTError(w, grpcError(errBDMissing))
+		return
+	}
+	s.mu.Lock()
+	issues, err := beads.List([]string{"email", "triage"}, "open", 0)
+	s.mu.Unlock()
+	if err != nil {
+		writeRESTError(w, status.Errorf(codes.Internal, "query beads: %v", err))
+		return
+	}
+	items := make([]webInboxItem, 0, len(issues))
+	for _, issue := range issues {
+		items = append(items, webInboxItem{
+			Issue:    issue,
+			Priority: beads.PriorityFromBeads(issue.Priority),
+			ThreadID: beads.ThreadIDFromRef(issue.ExternalRef),
+			Account:  beads.NoteField(issue.Notes, "account"),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// httpStatus maps gRPC codes to HTTP statuses.
+var httpStatus = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.NotFound:           http.StatusNotFound,
+	codes.FailedPrecondition: http.StatusPreconditionFailed,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+}
+
+// writeRESTError writes err as {"error", "code"} with its HTTP status.
+func writeRESTError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	code, ok := httpStatus[st.Code()]
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": st.Message(), "code": st.Code().String()})
+}
+
+func init() {
+	webCmd.Flags().StringVar(&webAddr, "addr", "127.0.0.1:7422", "Address to serve the dashboard on")
+	webCmd.Flags().BoolVar(&webOpen, "open", false, "Open the dashboard in a browser")
+	webCmd.Flags().StringVar(&webToken, "token", "", "Require this bearer token on every request (default $MB_SERVE_TOKEN, else a random one)")
+	webCmd.Flags().DurationVar(&webPoll, "poll", 5*time.Second, "How often to check for changes to stream")
+	rootCmd.AddCommand(webCmd)
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/notify"
+	"github.com/daviddao/mailbeads/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var whoisLimit int
+
+// whoisOutput is everything mb whois knows about one person.
+type whoisOutput struct {
+	Address string `json:"address"`
+	Name    string `json:"name,omitempty"`
+	VIP     bool   `json:"vip"`
+	// VIPReason says what made them a VIP: "triage.vips" or a triage rule.
+	VIPReason string `json:"vip_reason,omitempty"`
+	// MessagesFrom, FirstSeen, and LastSeen cover mail they sent;
+	// LastContact is the latest message either way.
+	MessagesFrom   int    `json:"messages_from"`
+	FirstSeen      string `json:"first_seen,omitempty"`
+	LastSeen       string `json:"last_seen,omitempty"`
+	LastContact    string `json:"last_contact,omitempty"`
+	ThreadCount    int    `json:"thread_count"`
+	TriagedThreads int    `json:"triaged_threads"`
+	// Priorities counts their threads' triage priorities, open or closed;
+	// TypicalPriority is the most common one.
+	Priorities      map[string]int  `json:"priorities,omitempty"`
+	TypicalPriority string          `json:"typical_priority,omitempty"`
+	OpenItems       []whoisItem     `json:"open_items"`
+	Threads         []*types.Thread `json:"threads"`
+}
+
+// whoisItem is an open bead on one of the person's threads.
+type whoisItem struct {
+	BeadID   string `json:"bead_id"`
+	Title    string `json:"title"`
+	Priority string `json:"priority"`
+	Status   string `json:"status"`
+	Due      string `json:"due,omitempty"`
+	ThreadID string `json:"thread_id"`
+	Account  string `json:"account"`
+}
+
+var whoisCmd = &cobra.Command{
+	Use:   "whois ADDRESS",
+	Short: "Show everything known about a person",
+	Long: `Show what the local database knows about a person, for context before a
+meeting or a reply: how much mail they send and when they were last heard
+from, the threads they are on (as sender or recipient), the priorities
+their threads were triaged at, the beads still open on them, and whether
+they are a VIP.
+
+A VIP matches "triage": {"vips": [...]} in .mailbeads/config.json (an
+address, "@domain", or substring), or a sender-only triage rule with
+priority high.
+
+ADDRESS may also be part of a name or address known from mb contacts; if
+several people match, they are listed so you can pick one.
+
+Examples:
+  mb whois bob@example.com
+  mb whois "Bob Smith" --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		address, err := resolvePerson(args[0])
+		if err != nil {
+			return err
+		}
+		out, err := whois(address)
+		if err != nil {
+			return err
+		}
+		if out.ThreadCount == 0 && out.MessagesFrom == 0 {
+			return codedErrorf(codeInvalidArgument, "no mail from or to %s", address)
+		}
+		if whoisLimit > 0 && len(out.Threads) > whoisLimit {
+			out.Threads = out.Threads[:whoisLimit]
+		}
+
+		if jsonOutput {
+			return writeOutput(cmd.OutOrStdout(), out)
+		}
+
+		who := out.Address
+		if out.Name != "" {
+			who = out.Name + " <" + out.Address + ">"
+		}
+		fmt.Println(display.Bold.Render(who))
+		if out.VIP {
+			fmt.Printf("  %s %s\n", display.HighStyle.Render("VIP"), display.Dim.Render("("+out.VIPReason+")"))
+		}
+		fmt.Printf("  Sent you:     %d messages", out.MessagesFrom)
+		if out.LastSeen != "" {
+			fmt.Printf(", last %s", display.FormatTime(out.LastSeen))
+		}
+		fmt.Println()
+		if out.LastContact != "" {
+			fmt.Printf("  Last contact: %s\n", display.FormatTime(out.LastContact))
+		}
+		fmt.Printf("  Threads:      %d (%d triaged)\n", out.ThreadCount, out.TriagedThreads)
+		if out.TypicalPriority != "" {
+			var counts []string
+			for _, p := range priorityOrder {
+				if n := out.Priorities[p]; n > 0 {
+					counts = append(counts, fmt.Sprintf("%d %s", n, p))
+				}
+			}
+			fmt.Printf("  Priority:     usually %s %s\n", display.PriorityLabel(out.TypicalPriority),
+				display.Dim.Render("("+strings.Join(counts, ", ")+")"))
+		}
+
+		if len(out.OpenItems) > 0 {
+			fmt.Printf("\nOpen items (%d):\n", len(out.OpenItems))
+			for _, it := range out.OpenItems {
+				due := ""
+				if it.Due != "" {
+					due = display.Dim.Render("  due " + it.Due)
+				}
+				fmt.Printf("  %-12s %s %s%s\n", it.BeadID, display.PadRight(display.PriorityLabel(it.Priority), 8),
+					display.Truncate(it.Title, 60), due)
+			}
+		}
+
+		if len(out.Threads) > 0 {
+			fmt.Printf("\nRecent threads:\n")
+			for _, t := range out.Threads {
+				fmt.Printf("  %-16s %s %s  %s\n",
+					display.Truncate(t.ThreadID, 16),
+					display.PadRight(display.AccountLabel(t.Account), 12),
+					display.PadRight(display.Truncate(t.Subject, 50), 50),
+					display.Dim.Render(display.FormatTime(t.LatestDate)),
+				)
+			}
+			if out.ThreadCount > len(out.Threads) {
+				fmt.Println(display.Dim.Render(fmt.Sprintf("  ... and %d more (-n 0 for all)", out.ThreadCount-len(out.Threads))))
+			}
+		}
+		return nil
+	},
+}
+
+// priorityOrder lists mb priorities from most to least urgent.
+var priorityOrder = []string{"high", "medium", "low", "spam"}
+
+// resolvePerson turns a WHOIS argument into an address: an address (or
+// "Name <address>") as is, anything else by matching known senders.
+func resolvePerson(arg string) (string, error) {
+	if strings.Contains(arg, "@") {
+		address, _ := db.ParseSender(arg)
+		return address, nil
+	}
+	contacts, err := store.Senders(arg)
+	if err != nil {
+		return "", fmt.Errorf("query senders: %w", err)
+	}
+	switch len(contacts) {
+	case 0:
+		return "", codedErrorf(codeInvalidArgument, "no sender matching %q (try an address)", arg)
+	case 1:
+		return contacts[0].Address, nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q matches %d senders, use an address:\n", arg, len(contacts))
+	for i, c := range contacts {
+		if i == 10 {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(contacts)-10)
+			break
+		}
+		fmt.Fprintf(&b, "  %-36s %s\n", c.Address, display.Dim.Render(c.Name))
+	}
+	return "", codedErrorf(codeInvalidArgument, "%s", strings.TrimRight(b.String(), "\n"))
+}
+
+// whois assembles what the database, the triage log, and beads know about
+// address.
+func whois(address string) (*whoisOutput, error) {
+	out := &whoisOutput{Address: address, OpenItems: []whoisItem{}}
+	out.VIP, out.VIPReason = isVIP(address)
+
+	contacts, err := store.Senders(address)
+	if err != nil {
+		return nil, fmt.Errorf("query senders: %w", err)
+	}
+	for _, c := range contacts {
+		if c.Address == address {
+			out.Name, out.MessagesFrom = c.Name, c.MessageCount
+			out.FirstSeen, out.LastSeen = c.FirstSeen, c.LastSeen
+		}
+	}
+
+	threads, err := store.AddressThreads(address)
+	if err != nil {
+		return nil, fmt.Errorf("query threads: %w", err)
+	}
+	out.Threads = threads
+	out.ThreadCount = len(threads)
+	if len(threads) > 0 {
+		out.LastContact = threads[0].LatestDate
+	}
+
+	// The triage log outlives triage refs, so closed threads count too.
+	onThread := make(map[string]bool)
+	for _, t := range threads {
+		onThread[t.ThreadID+"|"+t.Account] = true
+	}
+	log, err := store.TriageLog("")
+	if err != nil {
+		return nil, fmt.Errorf("query triage log: %w", err)
+	}
+	triaged := make(map[string]bool)
+	for _, e := range log {
+		key := e.ThreadID + "|" + e.Account
+		if !onThread[key] {
+			continue
+		}
+		triaged[key] = true
+		if e.Priority != "" {
+			if out.Priorities == nil {
+				out.Priorities = make(map[string]int)
+			}
+			out.Priorities[e.Priority]++
+		}
+	}
+	for _, t := range threads {
+		if t.TriageRef != nil {
+			triaged[t.ThreadID+"|"+t.Account] = true
+		}
+	}
+	out.TriagedThreads = len(triaged)
+	for _, p := range priorityOrder {
+		if out.Priorities[p] > out.Priorities[out.TypicalPriority] {
+			out.TypicalPriority = p
+		}
+	}
+
+	if beads.Available() {
+		issues, err := beads.List([]string{"email", "triage"}, "", 0)
+		if err != nil {
+			return nil, fmt.Errorf("query beads: %w", err)
+		}
+		byID := make(map[string]beads.Issue, len(issues))
+		for _, issue := range issues {
+			byID[issue.ID] = issue
+		}
+		seen := make(map[string]bool)
+		for _, t := range threads {
+			if t.TriageRef == nil || seen[t.TriageRef.BeadID] {
+				continue
+			}
+			issue, ok := byID[t.TriageRef.BeadID]
+			if !ok || issue.Status == "closed" {
+				continue
+			}
+			seen[issue.ID] = true
+			out.OpenItems = append(out.OpenItems, whoisItem{
+				BeadID:   issue.ID,
+				Title:    issue.Title,
+				Priority: beads.PriorityFromBeads(issue.Priority),
+				Status:   issue.Status,
+				Due:      issue.DueAt,
+				ThreadID: t.ThreadID,
+				Account:  t.Account,
+			})
+		}
+	}
+	return out, nil
+}
+
+// isVIP reports whether address is a VIP, and why.
+func isVIP(address string) (bool, string) {
+	if notify.MatchSender(cfg.Triage.VIPs, address) {
+		return true, "triage.vips"
+	}
+	for _, r := range cfg.Triage.Rules {
+		if r.Priority == "high" && len(r.Senders) > 0 && len(r.SubjectContains) == 0 && r.Predicted == "" && !r.Bulk &&
+			notify.MatchSender(r.Senders, address) {
+			if r.Name != "" {
+				return true, fmt.Sprintf("triage rule %q", r.Name)
+			}
+			return true, "a high-priority triage rule"
+		}
+	}
+	return false, ""
+}
+
+func init() {
+	whoisCmd.Flags().IntVarP(&whoisLimit, "limit", "n", 10, "Max threads to list (0 = all)")
+	rootCmd.AddCommand(whoisCmd)
+}
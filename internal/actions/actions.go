@@ -0,0 +1,223 @@
+// Package actions finds action items in plain-text email bodies: explicit
+// requests ("Could you send...", "Please review..."), direct questions, and
+// deadlines ("by Friday", "due June 5"). It is a heuristic pass meant to be
+// run on bodies with quoted replies already stripped.
+package actions
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of action item.
+const (
+	KindAsk      = "ask"
+	KindQuestion = "question"
+	KindDeadline = "deadline"
+)
+
+// Item is one action item found in a message.
+type Item struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+	// Due is the resolved deadline (YYYY-MM-DD), if the item names one.
+	Due string `json:"due,omitempty"`
+}
+
+var (
+	askPattern = regexp.MustCompile(`(?i)^(please|pls|kindly)\b|\b(could|can|would|will) you\b|\b(please|kindly) \w+|` +
+		`\blet (me|us) know\b|\b(i|we) need you to\b|\bneed (your|you to)\b|\bmake sure\b|\b(action item|todo|to-do)\b|` +
+		`\bwaiting (on|for) (you|your)\b|\bdon'?t forget\b|\bremember to\b`)
+
+	// Deadline phrases; the captured group is resolved by resolveDue.
+	deadlinePattern = regexp.MustCompile(`(?i)\b(?:by|before|due|until|no later than|deadline(?: is)?:?)\s+` +
+		`(?:the\s+)?(end of (?:the )?(?:day|week|month)|eod|eow|cob|today|tonight|tomorrow|` +
+		`(?:next\s+)?(?:mon|tues|wednes|thurs|fri|satur|sun)day|` +
+		`\d{4}-\d{2}-\d{2}|\d{1,2}/\d{1,2}(?:/\d{2,4})?|` +
+		`(?:jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+\d{1,2}(?:st|nd|rd|th)?|` +
+		`\d{1,2}(?:st|nd|rd|th)?\s+(?:jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*)\b`)
+
+	sentenceEnd = regexp.MustCompile(`[.!?]+["')\]]*\s+`)
+	greeting    = regexp.MustCompile(`(?i)^(hi|hey|hello|dear|thanks|thank you|cheers|best|regards)\b[^.?!]{0,30}[,!.]?$`)
+)
+
+// Extract returns the action items in body, in order. sent is when the
+// message was sent; relative deadlines ("by Friday") are resolved against it.
+func Extract(body string, sent time.Time) []Item {
+	var items []Item
+	seen := make(map[string]bool)
+	for _, s := range sentences(body) {
+		item, ok := classify(s, sent)
+		if !ok {
+			continue
+		}
+		key := strings.ToLower(item.Text)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		items = append(items, item)
+	}
+	return items
+}
+
+func classify(s string, sent time.Time) (Item, bool) {
+	if len(s) < 8 || greeting.MatchString(s) {
+		return Item{}, false
+	}
+	if m := deadlinePattern.FindStringSubmatch(s); m != nil {
+		return Item{Kind: KindDeadline, Text: s, Due: resolveDue(m[1], sent)}, true
+	}
+	if strings.HasSuffix(s, "?") {
+		return Item{Kind: KindQuestion, Text: s}, true
+	}
+	if askPattern.MatchString(s) {
+		return Item{Kind: KindAsk, Text: s}, true
+	}
+	return Item{}, false
+}
+
+// sentences splits body into trimmed sentences, skipping quoted lines and
+// joining hard-wrapped lines within a paragraph.
+func sentences(body string) []string {
+	var out []string
+	for _, para := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n\n") {
+		var lines []string
+		for _, line := range strings.Split(para, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, ">") {
+				continue
+			}
+			// Bullets and numbered list items stand on their own.
+			if item, ok := listItem(line); ok {
+				out = append(out, splitSentences(strings.Join(lines, " "))...)
+				lines = nil
+				out = append(out, splitSentences(item)...)
+				continue
+			}
+			lines = append(lines, line)
+		}
+		out = append(out, splitSentences(strings.Join(lines, " "))...)
+	}
+	return out
+}
+
+var listMarker = regexp.MustCompile(`^(?:[-*•]|\d{1,2}[.)])\s+`)
+
+func listItem(line string) (string, bool) {
+	if loc := listMarker.FindStringIndex(line); loc != nil {
+		return line[loc[1]:], true
+	}
+	return "", false
+}
+
+func splitSentences(text string) []string {
+	var out []string
+	start := 0
+	for _, loc := range sentenceEnd.FindAllStringIndex(text, -1) {
+		if s := strings.TrimSpace(text[start:loc[1]]); s != "" {
+			out = append(out, s)
+		}
+		start = loc[1]
+	}
+	if s := strings.TrimSpace(text[start:]); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday,
+	"wednesday": time.Wednesday, "thursday": time.Thursday, "friday": time.Friday,
+	"saturday": time.Saturday,
+}
+
+var (
+	monthDay = regexp.MustCompile(`^([a-z]+)\.?\s+(\d{1,2})|^(\d{1,2})\s+([a-z]+)`)
+	ordinal  = regexp.MustCompile(`(\d)(st|nd|rd|th)\b`)
+)
+
+// resolveDue turns a deadline phrase into a date relative to sent, or ""
+// if it can't be resolved.
+func resolveDue(phrase string, sent time.Time) string {
+	p := strings.ToLower(strings.Join(strings.Fields(phrase), " "))
+	p = ordinal.ReplaceAllString(p, "$1")
+	day := func(t time.Time) string { return t.Format("2006-01-02") }
+
+	switch p {
+	case "today", "tonight", "eod", "cob", "end of day", "end of the day":
+		return day(sent)
+	case "tomorrow":
+		return day(sent.AddDate(0, 0, 1))
+	case "eow", "end of week", "end of the week":
+		return day(nextWeekday(sent, time.Friday, true))
+	case "end of month", "end of the month":
+		return day(time.Date(sent.Year(), sent.Month()+1, 0, 0, 0, 0, 0, sent.Location()))
+	}
+
+	if name, ok := strings.CutPrefix(p, "next "); ok {
+		if wd, ok := weekdays[name]; ok {
+			return day(nextWeekday(sent, wd, false).AddDate(0, 0, 7))
+		}
+	}
+	if wd, ok := weekdays[p]; ok {
+		return day(nextWeekday(sent, wd, false))
+	}
+
+	if t, err := time.Parse("2006-01-02", p); err == nil {
+		return day(t)
+	}
+	if parts := strings.Split(p, "/"); len(parts) >= 2 {
+		// Month/day, as written in US English mail.
+		m, err1 := strconv.Atoi(parts[0])
+		d, err2 := strconv.Atoi(parts[1])
+		if err1 == nil && err2 == nil && m >= 1 && m <= 12 && d >= 1 && d <= 31 {
+			y := sent.Year()
+			if len(parts) == 3 {
+				if n, err := strconv.Atoi(parts[2]); err == nil {
+					y = n
+					if y < 100 {
+						y += 2000
+					}
+				}
+			}
+			return day(rollForward(time.Date(y, time.Month(m), d, 0, 0, 0, 0, sent.Location()), sent, len(parts) < 3))
+		}
+	}
+	if m := monthDay.FindStringSubmatch(p); m != nil {
+		name, num := m[1], m[2]
+		if name == "" {
+			name, num = m[4], m[3]
+		}
+		d, _ := strconv.Atoi(num)
+		for i, mon := range []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"} {
+			if strings.HasPrefix(name, mon) {
+				t := time.Date(sent.Year(), time.Month(i+1), d, 0, 0, 0, 0, sent.Location())
+				return day(rollForward(t, sent, true))
+			}
+		}
+	}
+	return ""
+}
+
+// nextWeekday returns the next wd on or after t (strictly after t unless
+// sameDay is set).
+func nextWeekday(t time.Time, wd time.Weekday, sameDay bool) time.Time {
+	n := (int(wd) - int(t.Weekday()) + 7) % 7
+	if n == 0 && !sameDay {
+		n = 7
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// rollForward moves a date without an explicit year into next year when it
+// would otherwise fall well before the message was sent ("by Jan 5" written
+// in December).
+func rollForward(t, sent time.Time, noYear bool) time.Time {
+	if noYear && t.Before(sent.AddDate(0, -1, 0)) {
+		return t.AddDate(1, 0, 0)
+	}
+	return t
+}
@@ -0,0 +1,230 @@
+// Package attachtext extracts searchable plain text from email attachments.
+//
+// Plain-text attachments are used as-is, DOCX documents are read from their
+// word/document.xml part, and PDFs get a best-effort scan of their text
+// operators (after inflating FlateDecode streams). Anything else — scanned
+// PDFs, encrypted documents, images — yields no text. Extraction never fails
+// loudly: unreadable input simply returns "".
+package attachtext
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxInput is the largest attachment (in bytes) worth downloading for
+// extraction. MaxText caps the text kept per attachment.
+const (
+	MaxInput = 5 << 20
+	MaxText  = 100 << 10
+)
+
+// Supported reports whether text can be extracted from an attachment with
+// the given filename and MIME type.
+func Supported(filename, mimeType string) bool {
+	return kind(filename, mimeType) != ""
+}
+
+// Extract returns the text content of an attachment, truncated to MaxText.
+func Extract(filename, mimeType string, data []byte) string {
+	var text string
+	switch kind(filename, mimeType) {
+	case "text":
+		if utf8.Valid(data) {
+			text = string(data)
+		}
+	case "docx":
+		text = docx(data)
+	case "pdf":
+		text = pdf(data)
+	}
+	return truncate(strings.TrimSpace(text), MaxText)
+}
+
+func kind(filename, mimeType string) string {
+	mimeType = strings.ToLower(mimeType)
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".txt", ".csv", ".md", ".log":
+		return "text"
+	case ".docx":
+		return "docx"
+	case ".pdf":
+		return "pdf"
+	}
+	switch {
+	case mimeType == "text/plain", mimeType == "text/csv", mimeType == "text/markdown":
+		return "text"
+	case mimeType == "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		return "docx"
+	case mimeType == "application/pdf":
+		return "pdf"
+	}
+	return ""
+}
+
+// docx reads the main document part of a Word file, one line per paragraph.
+func docx(data []byte) string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ""
+	}
+	for _, f := range zr.File {
+		if f.Name != "word/document.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return ""
+		}
+		defer rc.Close()
+
+		var b strings.Builder
+		dec := xml.NewDecoder(io.LimitReader(rc, MaxInput*4))
+		inText := false
+		for b.Len() < MaxText {
+			tok, err := dec.Token()
+			if err != nil {
+				break
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				switch t.Name.Local {
+				case "t":
+					inText = true
+				case "tab":
+					b.WriteByte('\t')
+				case "br":
+					b.WriteByte('\n')
+				}
+			case xml.EndElement:
+				switch t.Name.Local {
+				case "t":
+					inText = false
+				case "p":
+					b.WriteByte('\n')
+				}
+			case xml.CharData:
+				if inText {
+					b.Write(t)
+				}
+			}
+		}
+		return b.String()
+	}
+	return ""
+}
+
+var (
+	pdfStream  = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+	pdfTextOp  = regexp.MustCompile(`(?s)(\((?:\\.|[^\\)])*\)|\[(?:\\.|[^\]])*\])\s*(Tj|TJ|'|")`)
+	pdfString  = regexp.MustCompile(`(?s)\((?:\\.|[^\\)])*\)`)
+	pdfEndText = regexp.MustCompile(`(?:^|\s)ET(?:\s|$)`)
+)
+
+// pdf pulls literal strings shown by text operators (Tj, TJ, ', ") out of
+// a PDF's content streams. It handles uncompressed and FlateDecode
+// streams; fonts with custom encodings come out garbled or not at all.
+func pdf(data []byte) string {
+	var b strings.Builder
+	for _, loc := range pdfStream.FindAllSubmatchIndex(data, -1) {
+		if b.Len() >= MaxText {
+			break
+		}
+		dict := data[loc[2]:loc[3]]
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		content := data[start : start+end]
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			zr, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+			inflated, _ := io.ReadAll(io.LimitReader(zr, MaxInput*4))
+			zr.Close()
+			content = inflated
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			continue
+		}
+		pdfContentText(&b, content)
+	}
+	return b.String()
+}
+
+// pdfContentText appends the text shown in one content stream, starting a
+// new line at each text object (BT ... ET).
+func pdfContentText(b *strings.Builder, content []byte) {
+	for _, block := range pdfEndText.Split(string(content), -1) {
+		var line strings.Builder
+		for _, m := range pdfTextOp.FindAllStringSubmatch(block, -1) {
+			for _, s := range pdfString.FindAllString(m[1], -1) {
+				line.WriteString(pdfUnescape(s[1 : len(s)-1]))
+			}
+			// TJ arrays use large negative kerning for word gaps; a space
+			// between show operators keeps words apart in the common case.
+			line.WriteByte(' ')
+		}
+		if text := strings.TrimSpace(line.String()); text != "" {
+			b.WriteString(text)
+			b.WriteByte('\n')
+		}
+	}
+}
+
+// pdfUnescape decodes the backslash escapes of a PDF literal string. Bytes
+// are read as Latin-1, which matches the standard encodings for ASCII text.
+func pdfUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			b.WriteRune(rune(c))
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'b', 'f':
+		case '\r', '\n':
+			// Line continuation.
+		default:
+			if s[i] >= '0' && s[i] <= '7' {
+				n := 0
+				j := i
+				for ; j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7'; j++ {
+					n = n*8 + int(s[j]-'0')
+				}
+				b.WriteRune(rune(n & 0xff))
+				i = j - 1
+			} else {
+				b.WriteRune(rune(s[i]))
+			}
+		}
+	}
+	return b.String()
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -7,23 +7,44 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 
+	mbgmail "github.com/daviddao/mailbeads/internal/gmail"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	gmail "google.golang.org/api/gmail/v1"
 	"google.golang.org/api/option"
+	tasks "google.golang.org/api/tasks/v1"
 )
 
-// Default scopes matching the Python scripts.
+// Default scopes matching the Python scripts, plus gmail.settings.basic
+// for mb rules push and tasks for mirroring beads into Google Tasks.
+// Tokens granted before those scopes were added keep working for
+// everything else.
 var DefaultScopes = []string{
 	"https://www.googleapis.com/auth/gmail.readonly",
 	"https://www.googleapis.com/auth/gmail.compose",
 	"https://www.googleapis.com/auth/gmail.modify",
+	"https://www.googleapis.com/auth/gmail.settings.basic",
+	tasks.TasksScope,
+}
+
+// ErrOffline is returned by LoadGmailService in offline mode.
+var ErrOffline = errors.New("Gmail is unavailable in offline mode")
+
+// offline blocks every Gmail connection, including token refreshes.
+var offline bool
+
+// SetOffline turns offline mode on or off. While it is on, no Gmail
+// service can be created, so nothing in the process reaches Google.
+func SetOffline(on bool) {
+	offline = on
 }
 
 // pythonToken represents the token.json format written by Python's google-auth library.
@@ -40,13 +61,30 @@ type pythonToken struct {
 // LoadGmailService returns an authenticated Gmail API service for the given account.
 // credentialsPath should point to the credentials.json file (e.g., "account@example.com/credentials.json").
 func LoadGmailService(ctx context.Context, credentialsPath string) (*gmail.Service, error) {
+	if offline {
+		return nil, ErrOffline
+	}
 	client, err := getClient(ctx, credentialsPath)
 	if err != nil {
 		return nil, fmt.Errorf("get oauth client: %w", err)
 	}
+	client.Transport = mbgmail.NewRetryTransport(client.Transport)
 	return gmail.NewService(ctx, option.WithHTTPClient(client))
 }
 
+// LoadTasksService returns an authenticated Google Tasks API service for
+// the given account, using the same credentials as LoadGmailService.
+func LoadTasksService(ctx context.Context, credentialsPath string) (*tasks.Service, error) {
+	if offline {
+		return nil, ErrOffline
+	}
+	client, err := getClient(ctx, credentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("get oauth client: %w", err)
+	}
+	return tasks.NewService(ctx, option.WithHTTPClient(client))
+}
+
 // getClient returns an authenticated HTTP client by loading the OAuth config
 // from credentials.json and the token from token.json.
 func getClient(ctx context.Context, credentialsPath string) (*http.Client, error) {
@@ -72,7 +110,7 @@ func getClient(ctx context.Context, credentialsPath string) (*http.Client, error
 	if newToken.AccessToken != token.AccessToken {
 		if saveErr := savePythonToken(tokenPath, newToken, config); saveErr != nil {
 			// Non-fatal: log but don't fail.
-			fmt.Fprintf(os.Stderr, "warning: could not save refreshed token: %v\n", saveErr)
+			slog.Warn("could not save refreshed token", "path", tokenPath, "err", saveErr)
 		}
 	}
 
@@ -16,17 +16,20 @@ import (
 
 // Issue is the subset of beads issue fields that mailbeads cares about.
 type Issue struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description,omitempty"`
-	Notes       string `json:"notes,omitempty"`
-	Status      string `json:"status"`
-	Priority    int    `json:"priority"`
-	IssueType   string `json:"issue_type"`
-	ExternalRef string `json:"external_ref,omitempty"`
-	CreatedAt   string `json:"created_at,omitempty"`
-	UpdatedAt   string `json:"updated_at,omitempty"`
-	CloseReason string `json:"close_reason,omitempty"`
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Notes       string   `json:"notes,omitempty"`
+	Status      string   `json:"status"`
+	Priority    int      `json:"priority"`
+	IssueType   string   `json:"issue_type"`
+	ExternalRef string   `json:"external_ref,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty"`
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+	CloseReason string   `json:"close_reason,omitempty"`
+	DueAt       string   `json:"due_at,omitempty"`
+	DeferUntil  string   `json:"defer_until,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
 }
 
 // Available checks if the bd binary is on PATH.
@@ -74,8 +77,75 @@ func ExternalRef(threadID string) string {
 	return "mb:" + threadID
 }
 
+// ThreadIDFromRef extracts the thread ID from an mb: external_ref, or returns
+// empty string if the ref wasn't created by mailbeads.
+func ThreadIDFromRef(ref string) string {
+	if !strings.HasPrefix(ref, "mb:") {
+		return ""
+	}
+	return strings.TrimPrefix(ref, "mb:")
+}
+
+// Category returns the triage category label of an issue (the first label
+// other than email/triage), or "" if it has none.
+func Category(issue Issue) string {
+	for _, l := range issue.Labels {
+		if l != "email" && l != "triage" {
+			return l
+		}
+	}
+	return ""
+}
+
+// NoteField returns the value of a key=value field in triage notes, e.g.
+// NoteField(notes, "account"), or "" if absent.
+func NoteField(notes, key string) string {
+	for _, f := range strings.Fields(notes) {
+		if v, ok := strings.CutPrefix(f, key+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// SetNoteField sets a key=value field in triage notes, replacing the
+// field if present and appending it as a new line otherwise.
+func SetNoteField(notes, key, value string) string {
+	fields := strings.Fields(notes)
+	for _, f := range fields {
+		if strings.HasPrefix(f, key+"=") {
+			return strings.Replace(notes, f, key+"="+value, 1)
+		}
+	}
+	return strings.TrimRight(notes, "\n") + "\n" + key + "=" + value
+}
+
+// RemoveNoteField drops a key=value field from triage notes, along with
+// its line if nothing else is on it.
+func RemoveNoteField(notes, key string) string {
+	lines := strings.Split(notes, "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		kept := fields[:0]
+		for _, f := range fields {
+			if !strings.HasPrefix(f, key+"=") {
+				kept = append(kept, f)
+			}
+		}
+		switch {
+		case len(kept) == len(fields):
+			out = append(out, line)
+		case len(kept) > 0:
+			out = append(out, strings.Join(kept, " "))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
 // Create creates a new beads issue and returns the created issue.
-func Create(title, description, notes, priority, category, parent string, labels []string, threadID string) (*Issue, error) {
+// due is an optional due date (YYYY-MM-DD or RFC 3339).
+func Create(title, description, notes, priority, category, parent, due string, labels []string, threadID string) (*Issue, error) {
 	args := []string{"create", title,
 		"-p", priority,
 		"-t", "task",
@@ -101,6 +171,9 @@ func Create(title, description, notes, priority, category, parent string, labels
 	if parent != "" {
 		args = append(args, "--parent", parent)
 	}
+	if due != "" {
+		args = append(args, "--due", due)
+	}
 
 	out, err := run(args...)
 	if err != nil {
@@ -114,6 +187,71 @@ func Create(title, description, notes, priority, category, parent string, labels
 	return &issue, nil
 }
 
+// CreateEpic creates a beads epic with the given labels.
+func CreateEpic(title, description string, labels []string) (*Issue, error) {
+	args := []string{"create", title,
+		"-p", "2",
+		"-t", "epic",
+		"--json", "--silent",
+	}
+	if description != "" {
+		args = append(args, "-d", description)
+	}
+	if len(labels) > 0 {
+		args = append(args, "-l", strings.Join(labels, ","))
+	}
+
+	out, err := run(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var issue Issue
+	if err := json.Unmarshal(out, &issue); err != nil {
+		return nil, fmt.Errorf("parse bd create output: %w", err)
+	}
+	return &issue, nil
+}
+
+// Epics returns the beads epics with status (all but closed ones if
+// empty).
+func Epics(status string) ([]Issue, error) {
+	args := []string{"list", "--type", "epic", "--json"}
+	if status != "" {
+		args = append(args, "-s", status)
+	}
+
+	out, err := run(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	if err := json.Unmarshal(out, &issues); err != nil {
+		return nil, fmt.Errorf("parse bd list output: %w", err)
+	}
+	return issues, nil
+}
+
+// Children returns the child issues of parentID that have all of labels.
+func Children(parentID string, labels []string) ([]Issue, error) {
+	args := []string{"list", "--parent", parentID, "--json"}
+	if len(labels) > 0 {
+		args = append(args, "-l", strings.Join(labels, ","))
+	}
+
+	out, err := run(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	if err := json.Unmarshal(out, &issues); err != nil {
+		return nil, fmt.Errorf("parse bd list output: %w", err)
+	}
+	return issues, nil
+}
+
 // Close closes a beads issue with a reason.
 func Close(beadID, reason string) error {
 	args := []string{"close", beadID, "-q"}
@@ -124,6 +262,16 @@ func Close(beadID, reason string) error {
 	return err
 }
 
+// Reopen reopens a closed beads issue.
+func Reopen(beadID, reason string) error {
+	args := []string{"reopen", beadID, "-q"}
+	if reason != "" {
+		args = append(args, "-r", reason)
+	}
+	_, err := run(args...)
+	return err
+}
+
 // Show returns a beads issue by ID.
 func Show(beadID string) (*Issue, error) {
 	out, err := run("show", beadID, "--json")
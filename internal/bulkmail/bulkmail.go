@@ -0,0 +1,25 @@
+// Package bulkmail recognizes newsletters, mailing lists, and other bulk
+// mail by the headers their senders add, so sync can tag such threads and
+// triage can hide or dismiss them.
+package bulkmail
+
+import "strings"
+
+// Headers are the headers Detect looks at, for fetching them selectively.
+var Headers = []string{"List-Id", "Precedence", "List-Unsubscribe-Post"}
+
+// Detect reports whether a message is bulk mail: it has a List-Id (RFC
+// 2919, set by mailing lists and most newsletter services), a Precedence
+// of bulk, list, or junk, or a one-click List-Unsubscribe-Post (RFC 8058,
+// which Gmail requires of bulk senders). header returns a header's value,
+// or "" if the message has none.
+func Detect(header func(name string) string) bool {
+	if strings.TrimSpace(header("List-Id")) != "" {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(header("Precedence"))) {
+	case "bulk", "list", "junk":
+		return true
+	}
+	return strings.Contains(strings.ToLower(header("List-Unsubscribe-Post")), "one-click")
+}
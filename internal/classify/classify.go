@@ -0,0 +1,194 @@
+// Package classify predicts a thread's triage priority from its first
+// message with a multinomial naive Bayes model trained on the user's own
+// triage history. It is small and fast enough to run on every listing, so
+// predictions need no LLM call.
+package classify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// MinExamples is the number of training examples below which a model
+// refuses to predict: with less history its guesses are noise.
+const MinExamples = 10
+
+// maxBodyWords bounds how much of a body contributes tokens, so long
+// newsletters don't drown out the sender and subject.
+const maxBodyWords = 40
+
+// Model is a trained classifier. Its fields are exported for JSON only.
+type Model struct {
+	TrainedAt string `json:"trained_at"`
+	// Docs counts training examples per priority.
+	Docs map[string]int `json:"docs"`
+	// Tokens counts token occurrences per priority.
+	Tokens map[string]map[string]int `json:"tokens"`
+	// Totals is the sum of Tokens per priority.
+	Totals map[string]int `json:"totals"`
+	// Vocab is the number of distinct tokens across all priorities.
+	Vocab int `json:"vocab"`
+}
+
+// Example is one training example: a message and the priority it was
+// triaged with.
+type Example struct {
+	Email    *types.Email
+	Priority string
+}
+
+// Train builds a model from examples.
+func Train(examples []Example) *Model {
+	m := &Model{
+		TrainedAt: db.Now(),
+		Docs:      map[string]int{},
+		Tokens:    map[string]map[string]int{},
+		Totals:    map[string]int{},
+	}
+	vocab := map[string]bool{}
+	for _, ex := range examples {
+		if ex.Email == nil || !types.IsValidPriority(ex.Priority) {
+			continue
+		}
+		m.Docs[ex.Priority]++
+		counts := m.Tokens[ex.Priority]
+		if counts == nil {
+			counts = map[string]int{}
+			m.Tokens[ex.Priority] = counts
+		}
+		for _, tok := range Tokens(ex.Email) {
+			counts[tok]++
+			m.Totals[ex.Priority]++
+			vocab[tok] = true
+		}
+	}
+	m.Vocab = len(vocab)
+	return m
+}
+
+// Examples returns the number of training examples.
+func (m *Model) Examples() int {
+	n := 0
+	for _, c := range m.Docs {
+		n += c
+	}
+	return n
+}
+
+// Ready reports whether the model has enough history to predict: at least
+// MinExamples examples across at least two priorities.
+func (m *Model) Ready() bool {
+	return m != nil && len(m.Docs) >= 2 && m.Examples() >= MinExamples
+}
+
+// Predict returns the most likely priority for e and its posterior
+// probability, or "" and 0 if the model isn't Ready.
+func (m *Model) Predict(e *types.Email) (string, float64) {
+	if !m.Ready() {
+		return "", 0
+	}
+	tokens := Tokens(e)
+	total := float64(m.Examples())
+	classes := make([]string, 0, len(m.Docs))
+	for c := range m.Docs {
+		classes = append(classes, c)
+	}
+	sort.Strings(classes) // deterministic ties
+
+	scores := make([]float64, len(classes))
+	for i, c := range classes {
+		score := math.Log(float64(m.Docs[c]) / total)
+		denom := float64(m.Totals[c] + m.Vocab + 1)
+		for _, tok := range tokens {
+			score += math.Log(float64(m.Tokens[c][tok]+1) / denom)
+		}
+		scores[i] = score
+	}
+
+	best := 0
+	for i := range scores {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+	var sum float64
+	for _, s := range scores {
+		sum += math.Exp(s - scores[best])
+	}
+	return classes[best], 1 / sum
+}
+
+// Tokens returns the features of e: its sender address and domain, and the
+// words of its subject and snippet (or the start of its body). The snippet
+// comes first because every sync mode stores it, so training and
+// prediction see the same text.
+func Tokens(e *types.Email) []string {
+	var tokens []string
+	if address, _ := db.ParseSender(e.From); address != "" {
+		tokens = append(tokens, "from:"+address)
+		if _, domain, ok := strings.Cut(address, "@"); ok {
+			tokens = append(tokens, "domain:"+domain)
+		}
+	}
+	for _, w := range words(e.Subject, -1) {
+		tokens = append(tokens, "subject:"+w)
+	}
+	body := e.Snippet
+	if body == "" {
+		body = e.Body
+	}
+	tokens = append(tokens, words(body, maxBodyWords)...)
+	return tokens
+}
+
+// words splits s into lowercase words of 3 to 30 letters or digits,
+// keeping at most limit of them (all if limit < 0).
+func words(s string, limit int) []string {
+	var out []string
+	for _, f := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if limit >= 0 && len(out) >= limit {
+			break
+		}
+		if n := len([]rune(f)); n >= 3 && n <= 30 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Save writes the model to path as JSON.
+func (m *Model) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Load reads a model saved by Save. It returns nil and no error if there
+// is no model at path.
+func Load(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &m, nil
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// queryTimeout bounds an ad-hoc query, so a runaway join can't hang the
+// caller.
+const queryTimeout = 30 * time.Second
+
+// ReadOnlyQuery runs one ad-hoc SELECT (or WITH, EXPLAIN, or VALUES)
+// statement and returns at most limit rows (limit <= 0 means all). The
+// statement runs on a connection switched to the engine's read-only mode,
+// so a data-modifying statement fails even if it gets past the keyword
+// check.
+func (d *DB) ReadOnlyQuery(query string, limit int) (*types.QueryResult, error) {
+	query, err := checkReadOnly(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	conn, err := d.conn.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, d.dl.readOnly); err != nil {
+		return nil, fmt.Errorf("enter read-only mode: %w", err)
+	}
+	// The connection goes back to the pool, so it must leave read-only
+	// mode even when the query fails.
+	defer conn.ExecContext(context.Background(), d.dl.readWrite)
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.QueryResult{Columns: columns, Rows: []map[string]any{}}
+	for rows.Next() {
+		if limit > 0 && len(result.Rows) == limit {
+			result.Truncated = true
+			break
+		}
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(columns))
+		for i, c := range columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[c] = values[i]
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
+
+// checkReadOnly returns query without a trailing semicolon if it is a
+// single statement starting with SELECT, WITH, EXPLAIN, or VALUES.
+func checkReadOnly(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	for strings.HasSuffix(query, ";") {
+		query = strings.TrimSpace(strings.TrimSuffix(query, ";"))
+	}
+	if hasSemicolon(query) {
+		return "", fmt.Errorf("only one statement may be run")
+	}
+	first := strings.TrimLeft(stripLeadingComments(query), "( \t\r\n")
+	if end := strings.IndexFunc(first, func(r rune) bool { return !unicode.IsLetter(r) }); end >= 0 {
+		first = first[:end]
+	}
+	switch strings.ToUpper(first) {
+	case "SELECT", "WITH", "EXPLAIN", "VALUES":
+		return query, nil
+	}
+	return "", fmt.Errorf("only SELECT, WITH, EXPLAIN, and VALUES statements are allowed")
+}
+
+// hasSemicolon reports whether query has a semicolon outside string
+// literals, quoted identifiers, and comments.
+func hasSemicolon(query string) bool {
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return false
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return false
+			}
+			i += end + 3
+		case c == ';':
+			return true
+		}
+	}
+	return false
+}
+
+// stripLeadingComments drops the whitespace and comments before the first
+// keyword of query.
+func stripLeadingComments(query string) string {
+	for {
+		query = strings.TrimSpace(query)
+		switch {
+		case strings.HasPrefix(query, "--"):
+			_, rest, ok := strings.Cut(query, "\n")
+			if !ok {
+				return ""
+			}
+			query = rest
+		case strings.HasPrefix(query, "/*"):
+			_, rest, ok := strings.Cut(query, "*/")
+			if !ok {
+				return ""
+			}
+			query = rest
+		default:
+			return query
+		}
+	}
+}
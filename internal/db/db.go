@@ -1,53 +1,166 @@
-// Package db provides SQLite storage for mailbeads.
+// Package db provides storage for mailbeads: a SQLite file by default, or a
+// shared PostgreSQL or remote libsql database selected by a DSN.
 package db
 
 import (
+	"bytes"
+	"compress/gzip"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/mail"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/daviddao/mailbeads/internal/types"
-	_ "modernc.org/sqlite"
+	_ "github.com/jackc/pgx/v5/stdlib"                   // registers the "pgx" driver
+	_ "github.com/tursodatabase/libsql-client-go/libsql" // registers the "libsql" driver
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
-// DB wraps a SQLite connection for mailbeads operations.
+// DB implements Store on a SQLite file, a PostgreSQL database, or a remote
+// libsql database.
+//
+// Several mb processes may share a SQLite database (mb watch alongside
+// interactive commands), so every connection waits up to busyTimeout for
+// a lock, and all writes in this process go through a single writer
+// connection whose transactions take the write lock up front. Servers
+// handle concurrent writers themselves; conn and w are the same pool there.
 type DB struct {
-	conn *sql.DB
+	conn *pool // readers
+	w    *pool // the single writer
 	path string
+	dl   dialect
+	// reads and writes cache prepared statements on conn and w.
+	reads, writes *stmtCache
 }
 
-// Open opens (or creates) a mailbeads database at the given path.
+const (
+	// busyTimeout is how long SQLite itself waits for a lock.
+	busyTimeout = 5 * time.Second
+	// busyRetries is how many more times a write is retried after
+	// busyTimeout runs out, with exponential backoff from busyBackoff.
+	busyRetries = 5
+	busyBackoff = 100 * time.Millisecond
+)
+
+// Open opens (or creates) a mailbeads database: the SQLite file at
+// target, the server database if target is a DSN (see IsDSN), or a fresh
+// in-memory database if target is MemoryPath.
 // Automatically migrates from old schema if needed.
-func Open(dbPath string) (*DB, error) {
+func Open(target string) (*DB, error) {
+	if dl, ok := serverDialect(target); ok {
+		return openServer(dl, target)
+	}
+	if target == MemoryPath {
+		return openMemory()
+	}
+	dbPath := target
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return nil, fmt.Errorf("create directory %s: %w", dir, err)
 	}
 
-	conn, err := sql.Open("sqlite", dbPath+"?_pragma=journal_mode(WAL)&_pragma=foreign_keys(ON)")
+	dsn := fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(ON)",
+		dbPath, busyTimeout.Milliseconds())
+	conn, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	// BEGIN IMMEDIATE: a deferred transaction that later needs to write
+	// fails with SQLITE_BUSY at once instead of waiting.
+	w, err := sql.Open("sqlite", dsn+"&_txlock=immediate")
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	w.SetMaxOpenConns(1)
+
+	return setup(sqliteDialect, conn, w, dbPath)
+}
+
+// openMemory opens an empty in-memory database. An in-memory SQLite
+// database belongs to the connection that created it, so readers and the
+// writer share one connection, kept open until Close.
+func openMemory() (*DB, error) {
+	conn, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(ON)")
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	conn.SetMaxOpenConns(1)
+	conn.SetMaxIdleConns(1)
+	return setup(sqliteDialect, conn, conn, MemoryPath)
+}
+
+// openServer opens a database on a server addressed by dsn.
+func openServer(dl dialect, dsn string) (*DB, error) {
+	conn, err := sql.Open(dl.driver, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connect to %s database: %w", dl.name, err)
+	}
+	return setup(dl, conn, conn, dsn)
+}
 
-	d := &DB{conn: conn, path: dbPath}
+// setup wraps the reader and writer pools and brings the schema up to
+// date.
+func setup(dl dialect, conn, w *sql.DB, path string) (*DB, error) {
+	d := &DB{conn: &pool{conn, dl}, path: path, dl: dl}
+	d.w = d.conn
+	if w != conn {
+		d.w = &pool{w, dl}
+	}
+	d.reads, d.writes = newStmtCache(d.conn), newStmtCache(d.w)
 
 	// Check if we need to migrate from old schema.
-	if d.needsMigration() {
+	if dl.legacy && d.needsMigration() {
 		if err := d.migrate(); err != nil {
-			conn.Close()
+			d.Close()
 			return nil, fmt.Errorf("migrate schema: %w", err)
 		}
 	}
 
+	// Add columns introduced after a table was first created.
+	if err := d.addMissingColumns(); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("migrate columns: %w", err)
+	}
+
 	// Apply current schema (creates tables if they don't exist).
-	if _, err := conn.Exec(Schema); err != nil {
-		conn.Close()
+	if _, err := d.exec(dl.schema); err != nil {
+		d.Close()
 		return nil, fmt.Errorf("initialize schema: %w", err)
 	}
 
+	// Both dialects: a shared database may predate the addresses table.
+	if err := d.backfillAddresses(); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("parse email addresses: %w", err)
+	}
+
+	if !dl.legacy {
+		return d, nil
+	}
+
+	if err := d.backfillSentAt(); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("normalize email dates: %w", err)
+	}
+
+	if err := d.backfillTriageLog(); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("backfill triage log: %w", err)
+	}
+
 	return d, nil
 }
 
@@ -60,21 +173,208 @@ func (d *DB) needsMigration() bool {
 	return err == nil && name == "action"
 }
 
+// addMissingColumns adds columns that newer schemas expect to tables
+// created by older versions. The indexes in Schema depend on them.
+func (d *DB) addMissingColumns() error {
+	columns := []struct{ table, column, decl string }{
+		{"emails", "sent_at", "TEXT"},
+		{"emails", "attachment_text", "TEXT"},
+		{"emails", "auth_results", "TEXT"},
+		{"emails", "is_bulk", "INTEGER DEFAULT 0"},
+		{"triage_log", "priority", "TEXT"},
+		{"suggestions", "confidence", "REAL"},
+	}
+	for _, c := range columns {
+		if !d.dl.legacy {
+			_, err := d.exec(fmt.Sprintf("ALTER TABLE IF EXISTS %s ADD COLUMN IF NOT EXISTS %s %s", c.table, c.column, c.decl))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		var exists, has int
+		d.conn.QueryRow("SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?", c.table).Scan(&exists)
+		if exists == 0 {
+			continue
+		}
+		d.conn.QueryRow("SELECT 1 FROM pragma_table_info(?) WHERE name = ?", c.table, c.column).Scan(&has)
+		if has == 1 {
+			continue
+		}
+		if _, err := d.exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.decl)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillSentAt fills sent_at for rows stored before it existed.
+func (d *DB) backfillSentAt() error {
+	rows, err := d.conn.Query("SELECT id, date, fetched_at FROM emails WHERE sent_at IS NULL OR sent_at = ''")
+	if err != nil {
+		return err
+	}
+	type row struct{ id, date, fetchedAt string }
+	var pending []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.date, &r.fetchedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(pending) == 0 {
+		return err
+	}
+
+	return d.tx(func(tx *txn) error {
+		for _, r := range pending {
+			if _, err := tx.Exec("UPDATE emails SET sent_at = ? WHERE id = ?", sentAt(r.date, r.fetchedAt), r.id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// backfillAddresses parses the address headers of emails stored before
+// the addresses table existed.
+func (d *DB) backfillAddresses() error {
+	rows, err := d.conn.Query(`
+		SELECT e.id, e.account, e.thread_id, e.from_addr, COALESCE(e.to_addr, ''), COALESCE(e.cc, '')
+		FROM emails e
+		WHERE NOT EXISTS (SELECT 1 FROM addresses a WHERE a.email_id = e.id)`)
+	if err != nil {
+		return err
+	}
+	type row struct{ id, account, threadID, from, to, cc string }
+	var pending []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.account, &r.threadID, &r.from, &r.to, &r.cc); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(pending) == 0 {
+		return err
+	}
+
+	return d.tx(func(tx *txn) error {
+		for _, r := range pending {
+			for _, a := range emailAddresses(r.from, r.to, r.cc) {
+				if _, err := tx.Exec(`
+					INSERT INTO addresses (email_id, account, thread_id, role, address, name)
+					VALUES (?, ?, ?, ?, ?, ?)
+					ON CONFLICT DO NOTHING`,
+					r.id, r.account, r.threadID, a.role, a.address, a.name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// backfillTriageLog logs triage refs created before triage_log existed.
+func (d *DB) backfillTriageLog() error {
+	_, err := d.exec(`
+		INSERT INTO triage_log (thread_id, account, bead_id, arrived_at, triaged_at)
+		SELECT t.thread_id, t.account, t.bead_id,
+		       COALESCE((SELECT MIN(e.sent_at) FROM emails e
+		                 WHERE e.thread_id = t.thread_id AND e.account = t.account), t.created_at),
+		       t.created_at
+		FROM triage t
+		WHERE t.bead_id NOT LIKE 'legacy-%'
+		  AND NOT EXISTS (SELECT 1 FROM triage_log l WHERE l.bead_id = t.bead_id)
+		GROUP BY t.bead_id`)
+	return err
+}
+
 // migrate runs the V2 schema migration.
 func (d *DB) migrate() error {
-	_, err := d.conn.Exec(MigrationV2)
+	_, err := d.exec(MigrationV2)
 	return err
 }
 
-// Close closes the database connection.
+// Close closes the database connections.
 func (d *DB) Close() error {
+	var errs []error
+	if d.w != nil && d.dl.optimize != "" {
+		// Refresh the planner's statistics for the indexes used so far.
+		d.w.Exec(d.dl.optimize)
+	}
+	if d.reads != nil {
+		errs = append(errs, d.reads.close(), d.writes.close())
+	}
+	if d.w != nil && d.w != d.conn {
+		errs = append(errs, d.w.Close())
+	}
 	if d.conn != nil {
-		return d.conn.Close()
+		errs = append(errs, d.conn.Close())
 	}
-	return nil
+	return errors.Join(errs...)
+}
+
+// exec runs a write statement on the writer connection, retrying while
+// another process holds the database lock.
+func (d *DB) exec(query string, args ...any) (sql.Result, error) {
+	var res sql.Result
+	err := retryBusy(func() error {
+		var err error
+		res, err = d.w.Exec(query, args...)
+		return err
+	})
+	return res, err
+}
+
+// tx runs fn in a write transaction on the writer connection, committing
+// if it returns nil. A transaction that can't get the lock is retried from
+// the start, so fn must not have side effects outside tx.
+func (d *DB) tx(fn func(*txn) error) error {
+	return retryBusy(func() error {
+		tx, err := d.w.Begin()
+		if err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
 }
 
-// Path returns the database file path.
+// retryBusy calls fn until it succeeds, fails with an error other than
+// SQLITE_BUSY, or busyRetries retries have been spent.
+func retryBusy(fn func() error) error {
+	delay := busyBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !IsBusy(err) || attempt == busyRetries {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// IsBusy reports whether err is SQLite's "database is locked" (SQLITE_BUSY
+// or SQLITE_LOCKED, including their extended codes).
+func IsBusy(err error) bool {
+	var serr *sqlite.Error
+	if !errors.As(err, &serr) {
+		return false
+	}
+	code := serr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
+
+// Path returns the database file path (or DSN, or MemoryPath).
 func (d *DB) Path() string {
 	return d.path
 }
@@ -87,14 +387,31 @@ func Now() string {
 // DiscoverDB finds the mailbeads database by walking up from cwd.
 // Returns the path to .mailbeads/mail.db or empty string if not found.
 func DiscoverDB() string {
+	dir := DiscoverDir()
+	if dir == "" {
+		return ""
+	}
+	candidate := filepath.Join(dir, "mail.db")
+	if _, err := os.Stat(candidate); err != nil {
+		return ""
+	}
+	return candidate
+}
+
+// DiscoverDir finds the .mailbeads directory by walking up from cwd: the
+// first one holding mail.db or a config file (which may point at a shared
+// database instead). Returns empty string if not found.
+func DiscoverDir() string {
 	dir, err := os.Getwd()
 	if err != nil {
 		return ""
 	}
 	for {
-		candidate := filepath.Join(dir, ".mailbeads", "mail.db")
-		if _, err := os.Stat(candidate); err == nil {
-			return candidate
+		candidate := filepath.Join(dir, ".mailbeads")
+		for _, name := range []string{"mail.db", "config.json"} {
+			if _, err := os.Stat(filepath.Join(candidate, name)); err == nil {
+				return candidate
+			}
 		}
 		parent := filepath.Dir(dir)
 		if parent == dir {
@@ -126,35 +443,207 @@ func FindProjectRoot() string {
 
 // --- Email operations ---
 
-// InsertEmail inserts an email, ignoring duplicates.
+// InsertEmail inserts an email, ignoring duplicates. SentAt is derived from
+// Date (falling back to FetchedAt) when not set. The body is stored as NULL
+// when BodyMissing is set.
 func (d *DB) InsertEmail(e *types.Email) error {
-	_, err := d.conn.Exec(`
-		INSERT OR IGNORE INTO emails
-			(id, account, thread_id, message_id, from_addr, to_addr, cc, subject, snippet, body, date, labels, is_read, fetched_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	if e.SentAt == "" {
+		e.SentAt = sentAt(e.Date, e.FetchedAt)
+	}
+	var body any = e.Body
+	if e.BodyMissing {
+		body = nil
+	}
+	bulk := 0
+	if e.IsBulk {
+		bulk = 1
+	}
+	res, err := d.execCached(`
+		INSERT INTO emails
+			(id, account, thread_id, message_id, from_addr, to_addr, cc, subject, snippet, body, attachment_text, date, sent_at, labels, is_read, fetched_at, auth_results, is_bulk)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
 		e.ID, e.Account, e.ThreadID, e.MessageID, e.From, e.To, e.CC,
-		e.Subject, e.Snippet, e.Body, e.Date, e.Labels, e.IsRead, e.FetchedAt,
+		e.Subject, e.Snippet, body, e.AttachmentText, e.Date, e.SentAt, e.Labels, e.IsRead, e.FetchedAt, e.AuthResults, bulk,
 	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+	for _, a := range emailAddresses(e.From, e.To, e.CC) {
+		if _, err := d.execCached(`
+			INSERT INTO addresses (email_id, account, thread_id, role, address, name)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT DO NOTHING`,
+			e.ID, e.Account, e.ThreadID, a.role, a.address, a.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// roleAddress is one person on a message's From, To, or Cc line.
+type roleAddress struct {
+	role, address, name string
+}
+
+// emailAddresses parses a message's From, To, and Cc headers into one
+// entry per person and header.
+func emailAddresses(from, to, cc string) []roleAddress {
+	var out []roleAddress
+	for _, h := range []struct{ role, header string }{{"from", from}, {"to", to}, {"cc", cc}} {
+		seen := make(map[string]bool)
+		for _, a := range ParseAddressList(h.header) {
+			if !seen[a.Address] {
+				seen[a.Address] = true
+				out = append(out, roleAddress{h.role, a.Address, a.Name})
+			}
+		}
+	}
+	return out
+}
+
+// SetEmailBody stores a body (and attachment text) fetched after a
+// headers-only sync.
+func (d *DB) SetEmailBody(id, body, attachmentText string) error {
+	_, err := d.execCached("UPDATE emails SET body = ?, attachment_text = ? WHERE id = ?",
+		body, attachmentText, id)
 	return err
 }
 
-// EmailExists checks if an email ID already exists.
-func (d *DB) EmailExists(id string) bool {
-	var n int
-	d.conn.QueryRow("SELECT 1 FROM emails WHERE id = ?", id).Scan(&n)
-	return n == 1
+// SetEmailLabels updates a stored message's labels and read state, and
+// reports whether they changed. Unknown IDs are ignored.
+func (d *DB) SetEmailLabels(id, labels string, isRead int) (bool, error) {
+	res, err := d.execCached(`
+		UPDATE emails SET labels = ?, is_read = ?
+		WHERE id = ? AND (COALESCE(labels, '') != ? OR is_read != ?)`,
+		labels, isRead, id, labels, isRead)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// StoreRawSource saves a message's RFC 822 source, gzip-compressed,
+// replacing any earlier copy.
+func (d *DB) StoreRawSource(emailID string, raw []byte) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	_, err := d.execCached(`
+		INSERT INTO raw_messages (email_id, raw) VALUES (?, ?)
+		ON CONFLICT(email_id) DO UPDATE SET raw = excluded.raw`, emailID, buf.Bytes())
+	return err
+}
+
+// RawSource returns a message's stored RFC 822 source, or nil if it was
+// synced without --raw.
+func (d *DB) RawSource(emailID string) ([]byte, error) {
+	var compressed []byte
+	err := d.queryRow("SELECT raw FROM raw_messages WHERE email_id = ?", emailID).Scan(&compressed)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("decompress %s: %w", emailID, err)
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// GetEmail returns a single email by Gmail message ID, or nil if not found.
+func (d *DB) GetEmail(id string) (*types.Email, error) {
+	rows, err := d.query(`
+		SELECT id, account, thread_id, message_id, from_addr, to_addr, cc,
+		       subject, snippet, body, attachment_text, date, COALESCE(sent_at, ''), labels, is_read, fetched_at, auth_results, is_bulk
+		FROM emails
+		WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	emails, err := scanEmails(rows)
+	if err != nil || len(emails) == 0 {
+		return nil, err
+	}
+	return emails[0], nil
+}
+
+// existingIDsBatch bounds the IDs per ExistingEmailIDs query, below
+// SQLite's limit on bound parameters.
+const existingIDsBatch = 500
+
+// ExistingEmailIDs returns which of ids are already stored, in one query
+// per batch instead of one per ID.
+func (d *DB) ExistingEmailIDs(ids []string) (map[string]bool, error) {
+	existing := make(map[string]bool, len(ids))
+	for start := 0; start < len(ids); start += existingIDsBatch {
+		batch := ids[start:min(start+existingIDsBatch, len(ids))]
+		args := make([]any, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		rows, err := d.conn.Query("SELECT id FROM emails WHERE id IN (?"+strings.Repeat(", ?", len(batch)-1)+")", args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			existing[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
 }
 
-// LatestEmailDate returns the most recent email date for an account.
+// LatestEmailDate returns the most recent email date (RFC 3339) for an account.
 func (d *DB) LatestEmailDate(account string) string {
 	var date sql.NullString
-	d.conn.QueryRow("SELECT MAX(date) FROM emails WHERE account = ?", account).Scan(&date)
+	d.queryRow("SELECT MAX(sent_at) FROM emails WHERE account = ?", account).Scan(&date)
 	if date.Valid {
 		return date.String
 	}
 	return ""
 }
 
+// SyncCursor returns the provider cursor saved by the account's last
+// default sync, or "" if there is none.
+func (d *DB) SyncCursor(account string) string {
+	var cursor string
+	d.queryRow("SELECT cursor FROM sync_state WHERE account = ?", account).Scan(&cursor)
+	return cursor
+}
+
+// SetSyncCursor saves the provider cursor of the account's latest default
+// sync.
+func (d *DB) SetSyncCursor(account, cursor string) error {
+	_, err := d.execCached(`
+		INSERT INTO sync_state (account, cursor, synced_at) VALUES (?, ?, ?)
+		ON CONFLICT(account) DO UPDATE SET
+			cursor = excluded.cursor, synced_at = excluded.synced_at`,
+		account, cursor, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
 // EmailCount returns the total number of emails.
 func (d *DB) EmailCount() int {
 	var n int
@@ -181,12 +670,12 @@ func (d *DB) LatestFetchedAt(account string) string {
 
 // ThreadEmails returns all emails in a thread, ordered by date.
 func (d *DB) ThreadEmails(threadID, account string) ([]*types.Email, error) {
-	rows, err := d.conn.Query(`
+	rows, err := d.query(`
 		SELECT id, account, thread_id, message_id, from_addr, to_addr, cc,
-		       subject, snippet, body, date, labels, is_read, fetched_at
+		       subject, snippet, body, attachment_text, date, COALESCE(sent_at, ''), labels, is_read, fetched_at, auth_results, is_bulk
 		FROM emails
 		WHERE thread_id = ? AND account = ?
-		ORDER BY date ASC`, threadID, account)
+		ORDER BY sent_at ASC`, threadID, account)
 	if err != nil {
 		return nil, err
 	}
@@ -196,7 +685,7 @@ func (d *DB) ThreadEmails(threadID, account string) ([]*types.Email, error) {
 
 // ThreadAccounts returns which accounts a thread_id appears in.
 func (d *DB) ThreadAccounts(threadID string) ([]string, error) {
-	rows, err := d.conn.Query(
+	rows, err := d.query(
 		"SELECT DISTINCT account FROM emails WHERE thread_id = ?", threadID)
 	if err != nil {
 		return nil, err
@@ -213,39 +702,103 @@ func (d *DB) ThreadAccounts(threadID string) ([]string, error) {
 	return accounts, rows.Err()
 }
 
+// EmailsWithInlineCalendar returns emails whose stored body contains a
+// pasted VCALENDAR block.
+func (d *DB) EmailsWithInlineCalendar() ([]*types.Email, error) {
+	rows, err := d.conn.Query(`
+		SELECT id, account, thread_id, message_id, from_addr, to_addr, cc,
+		       subject, snippet, body, attachment_text, date, COALESCE(sent_at, ''), labels, is_read, fetched_at, auth_results, is_bulk
+		FROM emails
+		WHERE body LIKE '%BEGIN:VCALENDAR%'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanEmails(rows)
+}
+
+// EmailsAfterRow returns emails inserted after the given row cursor, in
+// insertion order, along with the new cursor.
+func (d *DB) EmailsAfterRow(after int64) ([]*types.Email, int64, error) {
+	upto := d.MaxEmailRow()
+	if upto <= after {
+		return nil, after, nil
+	}
+	rows, err := d.conn.Query(`
+		SELECT id, account, thread_id, message_id, from_addr, to_addr, cc,
+		       subject, snippet, body, attachment_text, date, COALESCE(sent_at, ''), labels, is_read, fetched_at, auth_results, is_bulk
+		FROM emails
+		WHERE `+d.dl.rowSeq+` > ? AND `+d.dl.rowSeq+` <= ?
+		ORDER BY `+d.dl.rowSeq+` ASC`, after, upto)
+	if err != nil {
+		return nil, after, err
+	}
+	defer rows.Close()
+	emails, err := scanEmails(rows)
+	if err != nil {
+		return nil, after, err
+	}
+	return emails, upto, nil
+}
+
+// MaxEmailRow returns the row cursor of the most recently inserted email.
+func (d *DB) MaxEmailRow() int64 {
+	var n sql.NullInt64
+	d.conn.QueryRow("SELECT MAX(" + d.dl.rowSeq + ") FROM emails").Scan(&n)
+	return n.Int64
+}
+
 func scanEmails(rows *sql.Rows) ([]*types.Email, error) {
 	var result []*types.Email
 	for rows.Next() {
-		e := &types.Email{}
-		var msgID, to, cc, snippet, body, labels sql.NullString
-		if err := rows.Scan(
-			&e.ID, &e.Account, &e.ThreadID, &msgID, &e.From, &to, &cc,
-			&e.Subject, &snippet, &body, &e.Date, &labels, &e.IsRead, &e.FetchedAt,
-		); err != nil {
+		e, err := scanEmail(rows)
+		if err != nil {
 			return nil, err
 		}
-		e.MessageID = msgID.String
-		e.To = to.String
-		e.CC = cc.String
-		e.Snippet = snippet.String
-		e.Body = body.String
-		e.Labels = labels.String
 		result = append(result, e)
 	}
 	return result, rows.Err()
 }
 
+// scanEmail scans the current row of an email query (the columns selected
+// by SearchEmails).
+func scanEmail(rows *sql.Rows) (*types.Email, error) {
+	e := &types.Email{}
+	var msgID, to, cc, snippet, body, attachText, labels, authResults sql.NullString
+	if err := rows.Scan(
+		&e.ID, &e.Account, &e.ThreadID, &msgID, &e.From, &to, &cc,
+		&e.Subject, &snippet, &body, &attachText, &e.Date, &e.SentAt, &labels, &e.IsRead, &e.FetchedAt, &authResults, &e.IsBulk,
+	); err != nil {
+		return nil, err
+	}
+	e.MessageID = msgID.String
+	e.To = to.String
+	e.CC = cc.String
+	e.Snippet = snippet.String
+	e.Body = body.String
+	e.BodyMissing = !body.Valid
+	e.AttachmentText = attachText.String
+	e.Labels = labels.String
+	e.AuthResults = authResults.String
+	return e, nil
+}
+
 // --- Triage cross-reference operations ---
 
 // GetTriageRef returns the triage cross-reference for a thread, or nil if untriaged.
 func (d *DB) GetTriageRef(threadID, account string) (*types.TriageRef, error) {
+	return scanTriageRef(d.queryRow(triageRefQuery, threadID, account))
+}
+
+const triageRefQuery = `
+	SELECT thread_id, account, bead_id, created_at
+	FROM triage
+	WHERE thread_id = ? AND account = ?`
+
+// scanTriageRef scans a triage row, returning nil if there is none.
+func scanTriageRef(row *sql.Row) (*types.TriageRef, error) {
 	t := &types.TriageRef{}
-	err := d.conn.QueryRow(`
-		SELECT thread_id, account, bead_id, created_at
-		FROM triage
-		WHERE thread_id = ? AND account = ?`, threadID, account).Scan(
-		&t.ThreadID, &t.Account, &t.BeadID, &t.CreatedAt,
-	)
+	err := row.Scan(&t.ThreadID, &t.Account, &t.BeadID, &t.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -275,64 +828,148 @@ func (d *DB) GetTriageRefByBead(beadID string) (*types.TriageRef, error) {
 
 // UpsertTriageRef creates or updates a triage cross-reference.
 func (d *DB) UpsertTriageRef(threadID, account, beadID string) (created bool, err error) {
-	existing, err := d.GetTriageRef(threadID, account)
+	err = d.tx(func(tx *txn) error {
+		created, err = upsertTriageRef(tx, threadID, account, beadID)
+		return err
+	})
+	return created, err
+}
+
+func upsertTriageRef(q querier, threadID, account, beadID string) (created bool, err error) {
+	existing, err := scanTriageRef(q.QueryRow(triageRefQuery, threadID, account))
 	if err != nil {
 		return false, err
 	}
 
 	now := Now()
 	if existing != nil {
-		_, err = d.conn.Exec(`
+		_, err = q.Exec(`
 			UPDATE triage SET bead_id = ? WHERE thread_id = ? AND account = ?`,
 			beadID, threadID, account,
 		)
 		return false, err
 	}
 
-	_, err = d.conn.Exec(`
+	_, err = q.Exec(`
 		INSERT INTO triage (thread_id, account, bead_id, created_at)
 		VALUES (?, ?, ?, ?)`,
 		threadID, account, beadID, now,
 	)
-	return true, err
+	if err != nil {
+		return true, err
+	}
+	return true, logTriage(q, threadID, account, beadID, now)
+}
+
+// logTriage opens a triage_log row for beadID unless one is already open
+// (duplicates linked to the same bead share its row). The arrival time is
+// the earliest message since the thread was last closed.
+func logTriage(q querier, threadID, account, beadID, now string) error {
+	_, err := q.Exec(`
+		INSERT INTO triage_log (thread_id, account, bead_id, arrived_at, triaged_at)
+		SELECT ?, ?, ?, COALESCE((
+		           SELECT MIN(sent_at) FROM emails
+		           WHERE thread_id = ? AND account = ? AND sent_at > COALESCE((
+		               SELECT MAX(closed_at) FROM triage_log
+		               WHERE thread_id = ? AND account = ?), '')), ?), ?
+		WHERE NOT EXISTS (SELECT 1 FROM triage_log WHERE bead_id = ? AND closed_at IS NULL)`,
+		threadID, account, beadID, threadID, account, threadID, account, now, now, beadID,
+	)
+	return err
 }
 
 // DeleteTriageRef removes a triage cross-reference by bead ID.
 func (d *DB) DeleteTriageRef(beadID string) error {
-	_, err := d.conn.Exec("DELETE FROM triage WHERE bead_id = ?", beadID)
+	_, err := d.exec("DELETE FROM triage WHERE bead_id = ?", beadID)
 	return err
 }
 
-// AllTriageRefs returns all triage cross-references.
-func (d *DB) AllTriageRefs() ([]*types.TriageRef, error) {
+// DeleteThreadTriageRef removes the triage cross-reference for one thread.
+func (d *DB) DeleteThreadTriageRef(threadID, account string) error {
+	_, err := d.exec("DELETE FROM triage WHERE thread_id = ? AND account = ?", threadID, account)
+	return err
+}
+
+// CloseTriageRef records that beadID was closed with outcome ("done" or
+// "dismissed") in triage_log and removes its triage cross-reference.
+func (d *DB) CloseTriageRef(beadID, outcome string) error {
+	_, err := d.exec(`
+		UPDATE triage_log SET closed_at = ?, outcome = ?
+		WHERE bead_id = ? AND closed_at IS NULL`, Now(), outcome, beadID)
+	if err != nil {
+		return err
+	}
+	return d.DeleteTriageRef(beadID)
+}
+
+// SetTriagePriority records a bead's triage priority in triage_log.
+func (d *DB) SetTriagePriority(beadID, priority string) error {
+	_, err := d.exec("UPDATE triage_log SET priority = ? WHERE bead_id = ?", priority, beadID)
+	return err
+}
+
+// TriageLog returns triage_log entries triaged or closed at or after since,
+// oldest triage first.
+func (d *DB) TriageLog(since string) ([]*types.TriageLogEntry, error) {
 	rows, err := d.conn.Query(`
-		SELECT thread_id, account, bead_id, created_at
-		FROM triage
-		ORDER BY created_at DESC`)
+		SELECT thread_id, account, bead_id, arrived_at, triaged_at,
+		       COALESCE(closed_at, ''), COALESCE(outcome, ''), COALESCE(priority, '')
+		FROM triage_log
+		WHERE triaged_at >= ? OR closed_at >= ?
+		ORDER BY triaged_at`, since, since)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var result []*types.TriageRef
+	var result []*types.TriageLogEntry
 	for rows.Next() {
-		t := &types.TriageRef{}
-		if err := rows.Scan(&t.ThreadID, &t.Account, &t.BeadID, &t.CreatedAt); err != nil {
+		e := &types.TriageLogEntry{}
+		if err := rows.Scan(&e.ThreadID, &e.Account, &e.BeadID, &e.ArrivedAt, &e.TriagedAt, &e.ClosedAt, &e.Outcome, &e.Priority); err != nil {
 			return nil, err
 		}
-		result = append(result, t)
+		result = append(result, e)
 	}
 	return result, rows.Err()
 }
 
-// LegacyTriageRefs returns triage refs with "legacy-" prefixed bead IDs
-// (created during schema migration, not yet migrated to real beads issues).
-func (d *DB) LegacyTriageRefs() ([]*types.TriageRef, error) {
-	rows, err := d.conn.Query(`
+// TriageLatencies returns, in seconds, the time from arrival to triage for
+// beads triaged at or after since, and from triage to close for beads
+// closed at or after since. Arrival times in the future (clock skew) count
+// as zero.
+func (d *DB) TriageLatencies(since string) (toTriage, toClose []float64, err error) {
+	entries, err := d.TriageLog(since)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, e := range entries {
+		a, err1 := time.Parse(time.RFC3339, e.ArrivedAt)
+		t, err2 := time.Parse(time.RFC3339, e.TriagedAt)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if e.TriagedAt >= since {
+			toTriage = append(toTriage, max(t.Sub(a).Seconds(), 0))
+		}
+		if c, err := time.Parse(time.RFC3339, e.ClosedAt); err == nil && e.ClosedAt >= since {
+			toClose = append(toClose, max(c.Sub(t).Seconds(), 0))
+		}
+	}
+	return toTriage, toClose, nil
+}
+
+// TriageRefsByBead returns every triage ref pointing at beadID (a thread
+// and any duplicates linked to it).
+func (d *DB) TriageRefsByBead(beadID string) ([]*types.TriageRef, error) {
+	return triageRefsByBead(d.conn, beadID)
+}
+
+func triageRefsByBead(q querier, beadID string) ([]*types.TriageRef, error) {
+	rows, err := q.Query(`
 		SELECT thread_id, account, bead_id, created_at
 		FROM triage
-		WHERE bead_id LIKE 'legacy-%'
-		ORDER BY created_at DESC`)
+		WHERE bead_id = ?
+		ORDER BY created_at`, beadID)
 	if err != nil {
 		return nil, err
 	}
@@ -349,71 +986,802 @@ func (d *DB) LegacyTriageRefs() ([]*types.TriageRef, error) {
 	return result, rows.Err()
 }
 
-// --- Thread queries ---
-
-// UntriagedThreads returns threads without a triage entry.
-func (d *DB) UntriagedThreads(account string, limit int) ([]*types.Thread, error) {
-	query := `
-		SELECT e.thread_id, e.account,
-		       MAX(e.subject) as subject,
-		       MAX(e.from_addr) as from_addr,
-		       COUNT(e.id) as email_count,
-		       MAX(e.date) as latest_date
-		FROM emails e
-		LEFT JOIN triage t ON e.thread_id = t.thread_id AND e.account = t.account`
+// RestoreTriageRefs puts back triage refs removed by CloseTriageRef and
+// reopens the bead's latest triage_log row.
+func (d *DB) RestoreTriageRefs(beadID string, refs []types.TriageRef) error {
+	return d.tx(func(tx *txn) error {
+		for _, r := range refs {
+			if _, err := tx.Exec(`
+				INSERT INTO triage (thread_id, account, bead_id, created_at)
+				VALUES (?, ?, ?, ?)
+				ON CONFLICT(thread_id, account) DO UPDATE SET
+					bead_id = excluded.bead_id, created_at = excluded.created_at`, r.ThreadID, r.Account, r.BeadID, r.CreatedAt); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(`
+			UPDATE triage_log SET closed_at = NULL, outcome = NULL
+			WHERE `+d.dl.rowSeq+` = (SELECT MAX(`+d.dl.rowSeq+`) FROM triage_log WHERE bead_id = ?)`, beadID)
+		return err
+	})
+}
 
-	var conditions []string
-	args := []any{}
+// --- Auto-dismissals ---
 
-	if account != "" {
-		conditions = append(conditions, `e.account = ?`)
-		args = append(args, account)
-	}
+// DismissThreads records threads as auto-dismissed. Threads already
+// dismissed keep their original record.
+func (d *DB) DismissThreads(dismissals []*types.Dismissal) error {
+	return d.tx(func(tx *txn) error {
+		for _, x := range dismissals {
+			if x.DismissedAt == "" {
+				x.DismissedAt = Now()
+			}
+			if _, err := tx.Exec(`
+				INSERT INTO dismissals (thread_id, account, policy, dismissed_at)
+				VALUES (?, ?, ?, ?)
+				ON CONFLICT DO NOTHING`, x.ThreadID, x.Account, x.Policy, x.DismissedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
+// Dismissals returns auto-dismissed threads, most recently dismissed
+// first, with their latest subject and sender.
+func (d *DB) Dismissals() ([]*types.Dismissal, error) {
+	rows, err := d.conn.Query(`
+		SELECT dm.thread_id, dm.account, dm.policy, dm.dismissed_at,
+		       COALESCE(` + latestSQL("subject", "dm.thread_id", "dm.account") + `, ''),
+		       COALESCE(` + latestSQL("from_addr", "dm.thread_id", "dm.account") + `, '')
+		FROM dismissals dm
+		ORDER BY dm.dismissed_at DESC, dm.thread_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*types.Dismissal
+	for rows.Next() {
+		x := &types.Dismissal{}
+		if err := rows.Scan(&x.ThreadID, &x.Account, &x.Policy, &x.DismissedAt, &x.Subject, &x.From); err != nil {
+			return nil, err
+		}
+		result = append(result, x)
+	}
+	return result, rows.Err()
+}
+
+// RestoreDismissed removes a thread's auto-dismissal, returning it to
+// mb untriaged. It reports whether the thread was dismissed.
+func (d *DB) RestoreDismissed(threadID, account string) (bool, error) {
+	res, err := d.exec("DELETE FROM dismissals WHERE thread_id = ? AND account = ?", threadID, account)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// --- Muted threads ---
+
+// MuteThread mutes a thread, reporting whether it wasn't muted already.
+func (d *DB) MuteThread(threadID, account string) (bool, error) {
+	res, err := d.exec(`
+		INSERT INTO muted (thread_id, account, muted_at) VALUES (?, ?, ?)
+		ON CONFLICT DO NOTHING`, threadID, account, Now())
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// UnmuteThread unmutes a thread, reporting whether it was muted.
+func (d *DB) UnmuteThread(threadID, account string) (bool, error) {
+	res, err := d.exec("DELETE FROM muted WHERE thread_id = ? AND account = ?", threadID, account)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// IsMuted reports whether a thread is muted.
+func (d *DB) IsMuted(threadID, account string) bool {
+	var n int
+	d.queryRow("SELECT 1 FROM muted WHERE thread_id = ? AND account = ?", threadID, account).Scan(&n)
+	return n == 1
+}
+
+// MutedThreads returns the muted threads with their latest subject and
+// sender, most recently muted first.
+func (d *DB) MutedThreads() ([]*types.Thread, error) {
+	rows, err := d.conn.Query(`
+		SELECT mu.thread_id, mu.account,
+		       COALESCE(` + latestSQL("subject", "mu.thread_id", "mu.account") + `, ''),
+		       COALESCE(` + latestSQL("from_addr", "mu.thread_id", "mu.account") + `, ''),
+		       (SELECT COUNT(*) FROM emails c WHERE c.thread_id = mu.thread_id AND c.account = mu.account),
+		       COALESCE((SELECT MAX(c.sent_at) FROM emails c WHERE c.thread_id = mu.thread_id AND c.account = mu.account), '')
+		FROM muted mu
+		ORDER BY mu.muted_at DESC, mu.thread_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var threads []*types.Thread
+	for rows.Next() {
+		t := &types.Thread{Muted: true}
+		if err := rows.Scan(&t.ThreadID, &t.Account, &t.Subject, &t.From, &t.EmailCount, &t.LatestDate); err != nil {
+			return nil, err
+		}
+		threads = append(threads, t)
+	}
+	return threads, rows.Err()
+}
+
+// --- Triage suggestions ---
+
+// QueueSuggestions queues suggestions for review, replacing any earlier
+// suggestion for the same thread, rejected or not.
+func (d *DB) QueueSuggestions(suggestions []*types.Suggestion) error {
+	return d.tx(func(tx *txn) error {
+		for _, s := range suggestions {
+			if err := queueSuggestion(tx, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func queueSuggestion(q querier, s *types.Suggestion) error {
+	if s.CreatedAt == "" {
+		s.CreatedAt = Now()
+	}
+	_, err := q.Exec(`
+		INSERT INTO suggestions (thread_id, account, priority, action, suggestion, agent_notes,
+		                         category, due, confidence, source, created_at, rejected_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULL)
+		ON CONFLICT(thread_id, account) DO UPDATE SET
+			priority = excluded.priority, action = excluded.action,
+			suggestion = excluded.suggestion, agent_notes = excluded.agent_notes,
+			category = excluded.category, due = excluded.due,
+			confidence = excluded.confidence, source = excluded.source,
+			created_at = excluded.created_at, rejected_at = NULL`,
+		s.ThreadID, s.Account, s.Priority, s.Action, s.Suggestion, s.AgentNotes,
+		s.Category, s.Due, s.Confidence, s.Source, s.CreatedAt)
+	return err
+}
+
+// Suggestions returns queued triage suggestions, oldest first, with their
+// thread's latest subject and sender. With pending, rejected ones are
+// left out.
+func (d *DB) Suggestions(pending bool) ([]*types.Suggestion, error) {
+	where := ""
+	if pending {
+		where = "WHERE sg.rejected_at IS NULL"
+	}
+	rows, err := d.conn.Query(`
+		SELECT sg.thread_id, sg.account, sg.priority, sg.action, COALESCE(sg.suggestion, ''),
+		       COALESCE(sg.agent_notes, ''), COALESCE(sg.category, ''), COALESCE(sg.due, ''),
+		       sg.confidence, sg.source, sg.created_at, COALESCE(sg.rejected_at, ''),
+		       COALESCE(` + latestSQL("subject", "sg.thread_id", "sg.account") + `, ''),
+		       COALESCE(` + latestSQL("from_addr", "sg.thread_id", "sg.account") + `, '')
+		FROM suggestions sg
+		` + where + `
+		ORDER BY sg.created_at, sg.thread_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*types.Suggestion
+	for rows.Next() {
+		s := &types.Suggestion{}
+		if err := rows.Scan(&s.ThreadID, &s.Account, &s.Priority, &s.Action, &s.Suggestion,
+			&s.AgentNotes, &s.Category, &s.Due, &s.Confidence, &s.Source, &s.CreatedAt, &s.RejectedAt,
+			&s.Subject, &s.From); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+// RejectSuggestion marks a thread's pending suggestion rejected,
+// reporting whether there was one.
+func (d *DB) RejectSuggestion(threadID, account string) (bool, error) {
+	res, err := d.exec(`
+		UPDATE suggestions SET rejected_at = ?
+		WHERE thread_id = ? AND account = ? AND rejected_at IS NULL`, Now(), threadID, account)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// DeleteSuggestion removes a thread's suggestion, reporting whether there
+// was one.
+func (d *DB) DeleteSuggestion(threadID, account string) (bool, error) {
+	res, err := d.exec("DELETE FROM suggestions WHERE thread_id = ? AND account = ?", threadID, account)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RemoveTriage deletes a bead's triage refs and its open triage_log row,
+// as if it had never been triaged.
+func (d *DB) RemoveTriage(beadID string) error {
+	if _, err := d.exec("DELETE FROM triage_log WHERE bead_id = ? AND closed_at IS NULL", beadID); err != nil {
+		return err
+	}
+	return d.DeleteTriageRef(beadID)
+}
+
+// RecordTriages writes the local records of triage decisions in one
+// transaction: each thread's triage ref, pointed at its bead along with
+// the thread's untriaged duplicates, its triage_log priority, and its undo
+// journal entry, whose Refs are set to the refs it added — or its queued
+// suggestion — and its audit log entry. If any write fails, none is made.
+func (d *DB) RecordTriages(recs []*types.TriageRecord) error {
+	return d.tx(func(tx *txn) error {
+		for _, r := range recs {
+			if err := recordTriage(tx, r); err != nil {
+				return fmt.Errorf("%s: %w", r.ThreadID, err)
+			}
+		}
+		return nil
+	})
+}
+
+func recordTriage(q querier, r *types.TriageRecord) error {
+	if r.Suggestion != nil {
+		if err := queueSuggestion(q, r.Suggestion); err != nil {
+			return err
+		}
+		return appendAudit(q, r.Audit)
+	}
+
+	before, err := triageRefsByBead(q, r.BeadID)
+	if err != nil {
+		return err
+	}
+	if _, err := upsertTriageRef(q, r.ThreadID, r.Account, r.BeadID); err != nil {
+		return err
+	}
+	if _, err := q.Exec("UPDATE triage_log SET priority = ? WHERE bead_id = ?", r.Priority, r.BeadID); err != nil {
+		return err
+	}
+	if r.Linked, err = linkDuplicates(q, r.ThreadID, r.Account, r.BeadID); err != nil {
+		return err
+	}
+
+	after, err := triageRefsByBead(q, r.BeadID)
+	if err != nil {
+		return err
+	}
+	had := make(map[string]bool)
+	for _, t := range before {
+		had[t.Account+"\x00"+t.ThreadID] = true
+	}
+	r.Op.Refs = nil
+	for _, t := range after {
+		if !had[t.Account+"\x00"+t.ThreadID] {
+			r.Op.Refs = append(r.Op.Refs, *t)
+		}
+	}
+	if err := recordOp(q, r.Op); err != nil {
+		return err
+	}
+	return appendAudit(q, r.Audit)
+}
+
+// AllTriageRefs returns all triage cross-references.
+func (d *DB) AllTriageRefs() ([]*types.TriageRef, error) {
+	rows, err := d.conn.Query(`
+		SELECT thread_id, account, bead_id, created_at
+		FROM triage
+		ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*types.TriageRef
+	for rows.Next() {
+		t := &types.TriageRef{}
+		if err := rows.Scan(&t.ThreadID, &t.Account, &t.BeadID, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
+
+// LegacyTriageRefs returns triage refs with "legacy-" prefixed bead IDs
+// (created during schema migration, not yet migrated to real beads issues).
+func (d *DB) LegacyTriageRefs() ([]*types.TriageRef, error) {
+	rows, err := d.conn.Query(`
+		SELECT thread_id, account, bead_id, created_at
+		FROM triage
+		WHERE bead_id LIKE 'legacy-%'
+		ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*types.TriageRef
+	for rows.Next() {
+		t := &types.TriageRef{}
+		if err := rows.Scan(&t.ThreadID, &t.Account, &t.BeadID, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
+
+// --- Thread queries ---
+
+// notShadowedSQL is a HAVING condition (over rows grouped by e.thread_id,
+// e.account) that hides threads duplicated in another account when the
+// duplicate is already triaged or belongs to an alphabetically earlier
+// account, so each cross-account message is triaged once.
+const notShadowedSQL = `NOT EXISTS (
+			SELECT 1 FROM emails x
+			JOIN emails o ON o.message_id = x.message_id AND o.account != x.account
+			LEFT JOIN triage ot ON ot.thread_id = o.thread_id AND ot.account = o.account
+			WHERE x.thread_id = e.thread_id AND x.account = e.account
+			  AND x.message_id != ''
+			  AND (ot.bead_id IS NOT NULL OR o.account < x.account))`
+
+// notDismissedSQL excludes threads (as e) that a policy auto-dismissed.
+const notDismissedSQL = `NOT EXISTS (SELECT 1 FROM dismissals dm
+			                  WHERE dm.thread_id = e.thread_id AND dm.account = e.account)`
+
+// notMutedSQL excludes threads (as e) muted with mb mute.
+const notMutedSQL = `NOT EXISTS (SELECT 1 FROM muted mu
+			                  WHERE mu.thread_id = e.thread_id AND mu.account = e.account)`
+
+// latestSQL is a subquery for column of the most recent message in the
+// thread identified by the thread and account expressions, so listings
+// show the current subject and sender rather than an arbitrary one.
+func latestSQL(column, thread, account string) string {
+	return `(SELECT l.` + column + ` FROM emails l
+		WHERE l.thread_id = ` + thread + ` AND l.account = ` + account + `
+		ORDER BY l.sent_at DESC, l.id DESC LIMIT 1)`
+}
+
+// DuplicateThreads returns threads in other accounts that share at least one
+// RFC Message-ID with the given thread, i.e. the same mail delivered to
+// several accounts.
+func (d *DB) DuplicateThreads(threadID, account string) ([]*types.Thread, error) {
+	return duplicateThreads(d.conn, threadID, account)
+}
+
+func duplicateThreads(q querier, threadID, account string) ([]*types.Thread, error) {
+	rows, err := q.Query(`
+		SELECT DISTINCT o.thread_id, o.account
+		FROM emails e
+		JOIN emails o ON o.message_id = e.message_id AND o.account != e.account
+		WHERE e.thread_id = ? AND e.account = ? AND e.message_id != ''
+		ORDER BY o.account, o.thread_id`, threadID, account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var threads []*types.Thread
+	for rows.Next() {
+		t := &types.Thread{}
+		if err := rows.Scan(&t.ThreadID, &t.Account); err != nil {
+			return nil, err
+		}
+		threads = append(threads, t)
+	}
+	return threads, rows.Err()
+}
+
+// DuplicateTriageRef returns the triage ref of a duplicate of the given
+// thread in another account, or nil if no duplicate is triaged.
+func (d *DB) DuplicateTriageRef(threadID, account string) (*types.TriageRef, error) {
+	dups, err := d.DuplicateThreads(threadID, account)
+	if err != nil {
+		return nil, err
+	}
+	for _, dup := range dups {
+		ref, err := d.GetTriageRef(dup.ThreadID, dup.Account)
+		if err != nil {
+			return nil, err
+		}
+		if ref != nil {
+			return ref, nil
+		}
+	}
+	return nil, nil
+}
+
+// LinkDuplicates points every untriaged duplicate of a thread at beadID so
+// the message is triaged once across accounts. Returns the number linked.
+func (d *DB) LinkDuplicates(threadID, account, beadID string) (linked int, err error) {
+	err = d.tx(func(tx *txn) error {
+		linked, err = linkDuplicates(tx, threadID, account, beadID)
+		return err
+	})
+	return linked, err
+}
+
+func linkDuplicates(q querier, threadID, account, beadID string) (int, error) {
+	dups, err := duplicateThreads(q, threadID, account)
+	if err != nil {
+		return 0, err
+	}
+	linked := 0
+	for _, dup := range dups {
+		ref, err := scanTriageRef(q.QueryRow(triageRefQuery, dup.ThreadID, dup.Account))
+		if err != nil {
+			return linked, err
+		}
+		if ref != nil {
+			continue
+		}
+		if _, err := upsertTriageRef(q, dup.ThreadID, dup.Account, beadID); err != nil {
+			return linked, err
+		}
+		linked++
+	}
+	return linked, nil
+}
+
+// DuplicateGroups returns sets of threads (across accounts) linked by shared
+// Message-IDs. Each group has at least two threads, ordered by account.
+func (d *DB) DuplicateGroups() ([][]*types.Thread, error) {
+	rows, err := d.conn.Query(`
+		SELECT DISTINCT e.message_id, e.thread_id, e.account
+		FROM emails e
+		WHERE e.message_id IN (
+			SELECT message_id FROM emails
+			WHERE message_id != ''
+			GROUP BY message_id
+			HAVING COUNT(DISTINCT account) > 1)
+		ORDER BY e.message_id, e.account`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Union threads that share any message ID.
+	parent := make(map[string]string)
+	var find func(k string) string
+	find = func(k string) string {
+		if parent[k] == k {
+			return k
+		}
+		parent[k] = find(parent[k])
+		return parent[k]
+	}
+	threads := make(map[string]*types.Thread)
+	var order []string
+	byMessage := make(map[string]string)
+	for rows.Next() {
+		var msgID string
+		t := &types.Thread{}
+		if err := rows.Scan(&msgID, &t.ThreadID, &t.Account); err != nil {
+			return nil, err
+		}
+		key := t.Account + "\x00" + t.ThreadID
+		if _, ok := threads[key]; !ok {
+			threads[key] = t
+			parent[key] = key
+			order = append(order, key)
+		}
+		if first, ok := byMessage[msgID]; ok {
+			parent[find(key)] = find(first)
+		} else {
+			byMessage[msgID] = key
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	members := make(map[string][]*types.Thread)
+	var roots []string
+	for _, key := range order {
+		root := find(key)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], threads[key])
+	}
+	var groups [][]*types.Thread
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			groups = append(groups, members[root])
+		}
+	}
+	return groups, nil
+}
+
+// UntriagedThreads returns threads without a triage entry.
+func (d *DB) UntriagedThreads(account string, limit int) ([]*types.Thread, error) {
+	// Group and page over the (thread_id, account, sent_at) index alone,
+	// then read the latest subject and sender for the page's threads only.
+	accountFilter := ""
+	args := []any{}
+	if account != "" {
+		accountFilter = `AND e.account = ?`
+		args = append(args, account)
+	}
+	limitClause := ""
+	if limit > 0 {
+		limitClause = "LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := d.query(`
+		WITH page AS (
+			SELECT e.thread_id, e.account,
+			       COUNT(*) AS email_count,
+			       MAX(e.sent_at) AS latest_date
+			FROM emails e
+			WHERE NOT EXISTS (SELECT 1 FROM triage t
+			                  WHERE t.thread_id = e.thread_id AND t.account = e.account)
+			  AND `+notDismissedSQL+`
+			  AND `+notMutedSQL+`
+			  `+accountFilter+`
+			GROUP BY e.thread_id, e.account
+			HAVING `+notShadowedSQL+`
+			ORDER BY latest_date DESC
+			`+limitClause+`
+		)
+		SELECT p.thread_id, p.account,
+		       `+latestSQL("subject", "p.thread_id", "p.account")+`,
+		       `+latestSQL("from_addr", "p.thread_id", "p.account")+`,
+		       p.email_count, p.latest_date,
+		       EXISTS (SELECT 1 FROM emails b
+		               WHERE b.thread_id = p.thread_id AND b.account = p.account AND b.is_bulk = 1)
+		FROM page p
+		ORDER BY p.latest_date DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var threads []*types.Thread
+	for rows.Next() {
+		t := &types.Thread{}
+		if err := rows.Scan(&t.ThreadID, &t.Account, &t.Subject, &t.From, &t.EmailCount, &t.LatestDate, &t.IsBulk); err != nil {
+			return nil, err
+		}
+		threads = append(threads, t)
+	}
+	return threads, rows.Err()
+}
+
+// ThreadsWithNewEmails returns threads that have a triage ref but received
+// new emails since triage (by checking if the thread's latest email date is
+// newer than the triage created_at). Returns the thread info and the triage ref.
+func (d *DB) ThreadsWithNewEmails() ([]*types.Thread, error) {
+	rows, err := d.conn.Query(`
+		SELECT e.thread_id, e.account,
+		       ` + latestSQL("subject", "e.thread_id", "e.account") + ` as subject,
+		       ` + latestSQL("from_addr", "e.thread_id", "e.account") + ` as from_addr,
+		       COUNT(e.id) as email_count,
+		       MAX(e.sent_at) as latest_date,
+		       t.bead_id, t.created_at
+		FROM emails e
+		JOIN triage t ON e.thread_id = t.thread_id AND e.account = t.account
+		WHERE ` + notMutedSQL + `
+		GROUP BY e.thread_id, e.account, t.bead_id, t.created_at
+		HAVING MAX(e.fetched_at) > t.created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var threads []*types.Thread
+	for rows.Next() {
+		t := &types.Thread{}
+		ref := &types.TriageRef{}
+		if err := rows.Scan(&t.ThreadID, &t.Account, &t.Subject, &t.From,
+			&t.EmailCount, &t.LatestDate, &ref.BeadID, &ref.CreatedAt); err != nil {
+			return nil, err
+		}
+		ref.ThreadID = t.ThreadID
+		ref.Account = t.Account
+		t.TriageRef = ref
+		threads = append(threads, t)
+	}
+	return threads, rows.Err()
+}
+
+// RecentThreads returns threads that received emails fetched at or after
+// since (RFC 3339), with their triage ref if triaged. EmailCount counts only
+// the new emails.
+func (d *DB) RecentThreads(since string) ([]*types.Thread, error) {
+	rows, err := d.conn.Query(`
+		SELECT e.thread_id, e.account,
+		       `+latestSQL("subject", "e.thread_id", "e.account")+` as subject,
+		       `+latestSQL("from_addr", "e.thread_id", "e.account")+` as from_addr,
+		       COUNT(e.id) as email_count,
+		       MAX(e.sent_at) as latest_date,
+		       t.bead_id, t.created_at
+		FROM emails e
+		LEFT JOIN triage t ON e.thread_id = t.thread_id AND e.account = t.account
+		WHERE e.fetched_at >= ?
+		GROUP BY e.thread_id, e.account, t.bead_id, t.created_at
+		ORDER BY MAX(e.fetched_at) DESC`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var threads []*types.Thread
+	for rows.Next() {
+		t := &types.Thread{}
+		var beadID, createdAt sql.NullString
+		if err := rows.Scan(&t.ThreadID, &t.Account, &t.Subject, &t.From,
+			&t.EmailCount, &t.LatestDate, &beadID, &createdAt); err != nil {
+			return nil, err
+		}
+		if beadID.Valid {
+			t.TriageRef = &types.TriageRef{
+				ThreadID:  t.ThreadID,
+				Account:   t.Account,
+				BeadID:    beadID.String,
+				CreatedAt: createdAt.String,
+			}
+		}
+		threads = append(threads, t)
+	}
+	return threads, rows.Err()
+}
+
+// Threads returns aggregated info for every thread, optionally limited to one
+// account, most recent first.
+func (d *DB) Threads(account string) ([]*types.Thread, error) {
+	query := `
+		SELECT e.thread_id, e.account,
+		       ` + latestSQL("subject", "e.thread_id", "e.account") + `,
+		       ` + latestSQL("from_addr", "e.thread_id", "e.account") + `,
+		       COUNT(e.id), MAX(e.sent_at), MAX(e.is_bulk)
+		FROM emails e`
+	args := []any{}
+	if account != "" {
+		query += ` WHERE e.account = ?`
+		args = append(args, account)
+	}
+	query += ` GROUP BY e.thread_id, e.account ORDER BY MAX(e.sent_at) DESC`
+
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var threads []*types.Thread
+	for rows.Next() {
+		t := &types.Thread{}
+		if err := rows.Scan(&t.ThreadID, &t.Account, &t.Subject, &t.From, &t.EmailCount, &t.LatestDate, &t.IsBulk); err != nil {
+			return nil, err
+		}
+		threads = append(threads, t)
+	}
+	return threads, rows.Err()
+}
+
+// SearchEmails returns emails, newest first, in which every word of query
+// appears (case-insensitively) in the subject, sender, snippet, body, or
+// attachment text. Words of the form from:X, to:X, cc:X, or with:X (any
+// of the three) instead match the addresses table: X with an @ is an exact
+// address, anything else a substring of an address or display name. Empty
+// account searches all accounts; limit <= 0 means no limit.
+func (d *DB) SearchEmails(query, account string, limit int) ([]*types.Email, error) {
+	var result []*types.Email
+	err := d.SearchEmailsFunc(query, account, limit, func(e *types.Email) error {
+		result = append(result, e)
+		return nil
+	})
+	return result, err
+}
+
+// AddressTerm splits a search word of the form from:X, to:X, cc:X, or
+// with:X into its role and value.
+func AddressTerm(word string) (role, value string, ok bool) {
+	role, value, ok = strings.Cut(word, ":")
+	if !ok || value == "" {
+		return "", "", false
+	}
+	switch role = strings.ToLower(role); role {
+	case "from", "to", "cc", "with":
+		return role, value, true
+	}
+	return "", "", false
+}
+
+// SearchEmailsFunc is SearchEmails calling fn for each email as it is read,
+// so large result sets aren't held in memory. An error from fn stops the
+// search and is returned. fn must not use the store: an in-memory store
+// has a single connection, held until the search ends.
+func (d *DB) SearchEmailsFunc(query, account string, limit int, fn func(*types.Email) error) error {
+	q := `
+		SELECT id, account, thread_id, message_id, from_addr, to_addr, cc,
+		       subject, snippet, body, attachment_text, date, COALESCE(sent_at, ''), labels, is_read, fetched_at, auth_results, is_bulk
+		FROM emails`
+	var conditions []string
+	args := []any{}
+	for _, word := range strings.Fields(query) {
+		if role, value, ok := AddressTerm(word); ok {
+			cond := `EXISTS (SELECT 1 FROM addresses a WHERE a.email_id = emails.id`
+			if role != "with" {
+				cond += ` AND a.role = ?`
+				args = append(args, role)
+			}
+			if strings.Contains(value, "@") {
+				cond += ` AND a.address = ?)`
+				args = append(args, strings.ToLower(value))
+			} else {
+				cond += ` AND (a.address ` + d.dl.ilike + ` ? OR a.name ` + d.dl.ilike + ` ?))`
+				args = append(args, "%"+value+"%", "%"+value+"%")
+			}
+			conditions = append(conditions, cond)
+			continue
+		}
+		conditions = append(conditions, strings.ReplaceAll(`(subject LIKE ? OR from_addr LIKE ? OR snippet LIKE ?
+			OR body LIKE ? OR attachment_text LIKE ?)`, "LIKE", d.dl.ilike))
+		pattern := "%" + word + "%"
+		args = append(args, pattern, pattern, pattern, pattern, pattern)
+	}
+	if account != "" {
+		conditions = append(conditions, `account = ?`)
+		args = append(args, account)
+	}
 	if len(conditions) > 0 {
-		query += ` WHERE ` + strings.Join(conditions, " AND ")
+		q += ` WHERE ` + strings.Join(conditions, " AND ")
 	}
-
-	query += ` GROUP BY e.thread_id, e.account
-		HAVING t.bead_id IS NULL
-		ORDER BY latest_date DESC`
-
+	q += ` ORDER BY sent_at DESC`
 	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", limit)
+		q += fmt.Sprintf(" LIMIT %d", limit)
 	}
 
-	rows, err := d.conn.Query(query, args...)
+	rows, err := d.conn.Query(q, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
-
-	var threads []*types.Thread
 	for rows.Next() {
-		t := &types.Thread{}
-		if err := rows.Scan(&t.ThreadID, &t.Account, &t.Subject, &t.From, &t.EmailCount, &t.LatestDate); err != nil {
-			return nil, err
+		e, err := scanEmail(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
 		}
-		threads = append(threads, t)
 	}
-	return threads, rows.Err()
+	return rows.Err()
 }
 
-// ThreadsWithNewEmails returns threads that have a triage ref but received
-// new emails since triage (by checking if the thread's latest email date is
-// newer than the triage created_at). Returns the thread info and the triage ref.
-func (d *DB) ThreadsWithNewEmails() ([]*types.Thread, error) {
+// AddressThreads returns the threads with a message from, to, or copied
+// to address (lowercased, as in the addresses table), most recent first,
+// with their triage ref if triaged.
+func (d *DB) AddressThreads(address string) ([]*types.Thread, error) {
 	rows, err := d.conn.Query(`
 		SELECT e.thread_id, e.account,
-		       MAX(e.subject) as subject,
-		       MAX(e.from_addr) as from_addr,
-		       COUNT(e.id) as email_count,
-		       MAX(e.date) as latest_date,
-		       t.bead_id, t.created_at
+		       `+latestSQL("subject", "e.thread_id", "e.account")+`,
+		       `+latestSQL("from_addr", "e.thread_id", "e.account")+`,
+		       COUNT(e.id), MAX(e.sent_at), t.bead_id, t.created_at
 		FROM emails e
-		JOIN triage t ON e.thread_id = t.thread_id AND e.account = t.account
-		GROUP BY e.thread_id, e.account
-		HAVING MAX(e.fetched_at) > t.created_at`)
+		LEFT JOIN triage t ON e.thread_id = t.thread_id AND e.account = t.account
+		WHERE (e.thread_id, e.account) IN (SELECT a.thread_id, a.account FROM addresses a WHERE a.address = ?)
+		GROUP BY e.thread_id, e.account, t.bead_id, t.created_at
+		ORDER BY MAX(e.sent_at) DESC`, strings.ToLower(address))
 	if err != nil {
 		return nil, err
 	}
@@ -422,14 +1790,19 @@ func (d *DB) ThreadsWithNewEmails() ([]*types.Thread, error) {
 	var threads []*types.Thread
 	for rows.Next() {
 		t := &types.Thread{}
-		ref := &types.TriageRef{}
+		var beadID, createdAt sql.NullString
 		if err := rows.Scan(&t.ThreadID, &t.Account, &t.Subject, &t.From,
-			&t.EmailCount, &t.LatestDate, &ref.BeadID, &ref.CreatedAt); err != nil {
+			&t.EmailCount, &t.LatestDate, &beadID, &createdAt); err != nil {
 			return nil, err
 		}
-		ref.ThreadID = t.ThreadID
-		ref.Account = t.Account
-		t.TriageRef = ref
+		if beadID.Valid {
+			t.TriageRef = &types.TriageRef{
+				ThreadID:  t.ThreadID,
+				Account:   t.Account,
+				BeadID:    beadID.String,
+				CreatedAt: createdAt.String,
+			}
+		}
 		threads = append(threads, t)
 	}
 	return threads, rows.Err()
@@ -438,12 +1811,16 @@ func (d *DB) ThreadsWithNewEmails() ([]*types.Thread, error) {
 // ThreadInfo returns aggregated info about a thread from the emails table.
 func (d *DB) ThreadInfo(threadID, account string) (*types.Thread, error) {
 	t := &types.Thread{}
-	err := d.conn.QueryRow(`
-		SELECT thread_id, account, MAX(subject), MAX(from_addr), COUNT(id), MAX(date)
-		FROM emails
-		WHERE thread_id = ? AND account = ?
-		GROUP BY thread_id, account`, threadID, account).Scan(
-		&t.ThreadID, &t.Account, &t.Subject, &t.From, &t.EmailCount, &t.LatestDate,
+	err := d.queryRow(`
+		SELECT e.thread_id, e.account,
+		       `+latestSQL("subject", "e.thread_id", "e.account")+`,
+		       `+latestSQL("from_addr", "e.thread_id", "e.account")+`,
+		       COUNT(e.id), MAX(e.sent_at), MAX(e.is_bulk),
+		       EXISTS (SELECT 1 FROM muted mu WHERE mu.thread_id = e.thread_id AND mu.account = e.account)
+		FROM emails e
+		WHERE e.thread_id = ? AND e.account = ?
+		GROUP BY e.thread_id, e.account`, threadID, account).Scan(
+		&t.ThreadID, &t.Account, &t.Subject, &t.From, &t.EmailCount, &t.LatestDate, &t.IsBulk, &t.Muted,
 	)
 	if err != nil {
 		return nil, err
@@ -451,16 +1828,163 @@ func (d *DB) ThreadInfo(threadID, account string) (*types.Thread, error) {
 	return t, nil
 }
 
+// --- Operation journal ---
+
+// RecordOp appends an operation to the undo journal.
+func (d *DB) RecordOp(op *types.Operation) error {
+	return retryBusy(func() error { return recordOp(d.w, op) })
+}
+
+func recordOp(q querier, op *types.Operation) error {
+	refs, err := json.Marshal(op.Refs)
+	if err != nil {
+		return err
+	}
+	previous, err := json.Marshal(op.Previous)
+	if err != nil {
+		return err
+	}
+	if op.At == "" {
+		op.At = Now()
+	}
+	return q.QueryRow(`
+		INSERT INTO journal (at, op, bead_id, refs, previous)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id`, op.At, op.Op, op.BeadID, string(refs), string(previous)).Scan(&op.ID)
+}
+
+// Operations returns journal entries newest first. With pending set, only
+// entries not yet undone are returned.
+func (d *DB) Operations(pending bool, limit int) ([]*types.Operation, error) {
+	query := `SELECT id, at, op, bead_id, COALESCE(refs, ''), COALESCE(previous, ''), COALESCE(undone_at, '') FROM journal`
+	if pending {
+		query += ` WHERE undone_at IS NULL`
+	}
+	query += ` ORDER BY id DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := d.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*types.Operation
+	for rows.Next() {
+		op := &types.Operation{}
+		var refs, previous string
+		if err := rows.Scan(&op.ID, &op.At, &op.Op, &op.BeadID, &refs, &previous, &op.UndoneAt); err != nil {
+			return nil, err
+		}
+		if refs != "" {
+			json.Unmarshal([]byte(refs), &op.Refs)
+		}
+		if previous != "" {
+			json.Unmarshal([]byte(previous), &op.Previous)
+		}
+		result = append(result, op)
+	}
+	return result, rows.Err()
+}
+
+// MarkUndone records that a journal entry has been reverted.
+func (d *DB) MarkUndone(id int64) error {
+	_, err := d.exec("UPDATE journal SET undone_at = ? WHERE id = ?", Now(), id)
+	return err
+}
+
+// --- Audit log ---
+
+// AppendAudit adds an entry to the audit log.
+func (d *DB) AppendAudit(e *types.AuditEntry) error {
+	return retryBusy(func() error { return appendAudit(d.w, e) })
+}
+
+func appendAudit(q querier, e *types.AuditEntry) error {
+	if e.At == "" {
+		e.At = Now()
+	}
+	return q.QueryRow(`
+		INSERT INTO audit (at, actor, command, action, thread_id, account, bead_id, detail)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		e.At, e.Actor, e.Command, e.Action, e.ThreadID, e.Account, e.BeadID, e.Detail,
+	).Scan(&e.ID)
+}
+
+// AuditLog returns audit entries matching f, newest first.
+func (d *DB) AuditLog(f types.AuditFilter) ([]*types.AuditEntry, error) {
+	query := `
+		SELECT id, at, actor, command, action,
+		       COALESCE(thread_id, ''), COALESCE(account, ''), COALESCE(bead_id, ''), COALESCE(detail, '')
+		FROM audit`
+
+	var conditions []string
+	args := []any{}
+	if f.Since != "" {
+		conditions = append(conditions, "at >= ?")
+		args = append(args, f.Since)
+	}
+	if f.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, f.Action)
+	}
+	if f.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, f.Actor)
+	}
+	if f.ThreadID != "" {
+		conditions = append(conditions, "thread_id = ?")
+		args = append(args, f.ThreadID)
+	}
+	if f.BeadID != "" {
+		conditions = append(conditions, "bead_id = ?")
+		args = append(args, f.BeadID)
+	}
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY id DESC`
+	if f.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*types.AuditEntry
+	for rows.Next() {
+		e := &types.AuditEntry{}
+		if err := rows.Scan(&e.ID, &e.At, &e.Actor, &e.Command, &e.Action,
+			&e.ThreadID, &e.Account, &e.BeadID, &e.Detail); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
 // --- Aggregate queries ---
 
 // UntriagedCount returns the number of untriaged threads.
 func (d *DB) UntriagedCount() int {
 	var n int
 	d.conn.QueryRow(`
-		SELECT COUNT(DISTINCT e.thread_id || '|' || e.account)
-		FROM emails e
-		LEFT JOIN triage t ON e.thread_id = t.thread_id AND e.account = t.account
-		WHERE t.bead_id IS NULL`).Scan(&n)
+		SELECT COUNT(*) FROM (
+			SELECT e.thread_id
+			FROM emails e
+			WHERE NOT EXISTS (SELECT 1 FROM triage t
+			                  WHERE t.thread_id = e.thread_id AND t.account = e.account)
+			  AND ` + notDismissedSQL + `
+			  AND ` + notMutedSQL + `
+			GROUP BY e.thread_id, e.account
+			HAVING ` + notShadowedSQL + `
+		) AS u`).Scan(&n)
 	return n
 }
 
@@ -478,6 +2002,44 @@ func (d *DB) ThreadCount() int {
 	return n
 }
 
+// SnapshotStats records the current inbox counts in stats_history.
+func (d *DB) SnapshotStats() error {
+	var last string
+	d.conn.QueryRow("SELECT COALESCE(MAX(taken_at), '') FROM stats_history").Scan(&last)
+	var newTriaged int
+	d.conn.QueryRow("SELECT COUNT(*) FROM triage WHERE created_at > ?", last).Scan(&newTriaged)
+
+	_, err := d.exec(`
+		INSERT INTO stats_history (taken_at, emails, threads, untriaged, triaged, new_triaged)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		Now(), d.EmailCount(), d.ThreadCount(), d.UntriagedCount(), d.TriagedCount(), newTriaged,
+	)
+	return err
+}
+
+// StatsHistory returns snapshots taken at or after since, oldest first.
+func (d *DB) StatsHistory(since string) ([]*types.StatsSnapshot, error) {
+	rows, err := d.conn.Query(`
+		SELECT taken_at, emails, threads, untriaged, triaged, new_triaged
+		FROM stats_history
+		WHERE taken_at >= ?
+		ORDER BY taken_at`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*types.StatsSnapshot
+	for rows.Next() {
+		s := &types.StatsSnapshot{}
+		if err := rows.Scan(&s.TakenAt, &s.Emails, &s.Threads, &s.Untriaged, &s.Triaged, &s.NewTriaged); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
 // Accounts returns distinct email accounts.
 func (d *DB) Accounts() []string {
 	rows, err := d.conn.Query("SELECT DISTINCT account FROM emails ORDER BY account")
@@ -494,7 +2056,336 @@ func (d *DB) Accounts() []string {
 	return accs
 }
 
+// --- Sender operations ---
+
+// ParseSender splits a From header into a lowercased address and display name.
+// Headers that don't parse are returned trimmed as the address.
+func ParseSender(from string) (address, name string) {
+	if a, err := mail.ParseAddress(from); err == nil {
+		return strings.ToLower(a.Address), a.Name
+	}
+	return strings.ToLower(strings.TrimSpace(from)), ""
+}
+
+// ParseAddressList parses an address header (From, To, Cc) into its
+// addresses, lowercased, with their display names. Parts that don't parse
+// as a list are read one by one with ParseSender; anything left without an
+// @ is dropped.
+func ParseAddressList(header string) []*mail.Address {
+	if strings.TrimSpace(header) == "" {
+		return nil
+	}
+	list, err := mail.ParseAddressList(header)
+	if err != nil {
+		list = nil
+		for _, part := range strings.Split(header, ",") {
+			address, name := ParseSender(part)
+			list = append(list, &mail.Address{Name: name, Address: address})
+		}
+	}
+	var out []*mail.Address
+	for _, a := range list {
+		address := strings.ToLower(strings.TrimSpace(a.Address))
+		if strings.Contains(address, "@") {
+			out = append(out, &mail.Address{Name: strings.TrimSpace(a.Name), Address: address})
+		}
+	}
+	return out
+}
+
+// normalizeDate converts an RFC 2822 email date to RFC 3339 UTC so it sorts
+// correctly as a string. Unparseable dates fall back to the current time.
+func normalizeDate(date string) string {
+	return sentAt(date, Now())
+}
+
+// sentAt normalizes an email Date header to RFC 3339 UTC, returning fallback
+// if it can't be parsed.
+func sentAt(date, fallback string) string {
+	if t, err := mail.ParseDate(date); err == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return fallback
+}
+
+// TopSenders returns the senders with the most messages sent at or after
+// since, busiest first. Only Address, Name, MessageCount, and ThreadCount
+// are set.
+func (d *DB) TopSenders(since string, limit int) ([]*types.Contact, error) {
+	rows, err := d.conn.Query(`
+		SELECT a.address, MAX(COALESCE(a.name, '')), a.thread_id || '|' || a.account, COUNT(*)
+		FROM addresses a
+		JOIN emails e ON e.id = a.email_id
+		WHERE a.role = 'from' AND e.sent_at >= ?
+		GROUP BY a.address, a.thread_id, a.account`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byAddress := make(map[string]*types.Contact)
+	threads := make(map[string]map[string]bool)
+	for rows.Next() {
+		var addr, name, thread string
+		var messages int
+		if err := rows.Scan(&addr, &name, &thread, &messages); err != nil {
+			return nil, err
+		}
+		c, ok := byAddress[addr]
+		if !ok {
+			c = &types.Contact{Address: addr}
+			byAddress[addr] = c
+			threads[addr] = make(map[string]bool)
+		}
+		if c.Name == "" {
+			c.Name = name
+		}
+		c.MessageCount += messages
+		threads[addr][thread] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.Contact, 0, len(byAddress))
+	for addr, c := range byAddress {
+		c.ThreadCount = len(threads[addr])
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].MessageCount != result[j].MessageCount {
+			return result[i].MessageCount > result[j].MessageCount
+		}
+		return result[i].Address < result[j].Address
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// RecordSender bumps the message count for the sender of an email and
+// updates its display name and last-seen time.
+func (d *DB) RecordSender(from, date string) error {
+	address, name := ParseSender(from)
+	if address == "" {
+		return nil
+	}
+	seen := normalizeDate(date)
+	_, err := d.execCached(`
+		INSERT INTO senders (address, name, message_count, first_seen, last_seen)
+		VALUES (?, ?, 1, ?, ?)
+		ON CONFLICT(address) DO UPDATE SET
+			name = CASE WHEN excluded.name != '' THEN excluded.name ELSE senders.name END,
+			message_count = senders.message_count + 1,
+			first_seen = CASE WHEN excluded.first_seen < senders.first_seen
+				THEN excluded.first_seen ELSE senders.first_seen END,
+			last_seen = CASE WHEN excluded.last_seen > senders.last_seen
+				THEN excluded.last_seen ELSE senders.last_seen END`,
+		address, name, seen, seen,
+	)
+	return err
+}
+
+// RebuildSenders recomputes the senders table from all stored emails.
+func (d *DB) RebuildSenders() (int, error) {
+	rows, err := d.conn.Query("SELECT from_addr, date FROM emails")
+	if err != nil {
+		return 0, err
+	}
+	type row struct{ from, date string }
+	var all []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.from, &r.date); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		all = append(all, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if _, err := d.exec("DELETE FROM senders"); err != nil {
+		return 0, err
+	}
+	for _, r := range all {
+		if err := d.RecordSender(r.from, r.date); err != nil {
+			return 0, err
+		}
+	}
+	var n int
+	d.conn.QueryRow("SELECT COUNT(*) FROM senders").Scan(&n)
+	return n, nil
+}
+
+// Senders returns all known senders, optionally filtered by a substring of
+// the address or name, ordered by message count.
+func (d *DB) Senders(search string) ([]*types.Contact, error) {
+	query := `
+		SELECT address, COALESCE(name, ''), message_count, first_seen, last_seen
+		FROM senders`
+	args := []any{}
+	if search != "" {
+		query += ` WHERE address ` + d.dl.ilike + ` ? OR name ` + d.dl.ilike + ` ?`
+		pattern := "%" + search + "%"
+		args = append(args, pattern, pattern)
+	}
+	query += ` ORDER BY message_count DESC, last_seen DESC`
+
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*types.Contact
+	for rows.Next() {
+		c := &types.Contact{}
+		if err := rows.Scan(&c.Address, &c.Name, &c.MessageCount, &c.FirstSeen, &c.LastSeen); err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
+
+// SenderThreads maps each sender address to the distinct thread IDs they
+// have sent email on.
+func (d *DB) SenderThreads() (map[string][]string, error) {
+	rows, err := d.conn.Query("SELECT DISTINCT address, thread_id FROM addresses WHERE role = 'from'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]string)
+	for rows.Next() {
+		var address, threadID string
+		if err := rows.Scan(&address, &threadID); err != nil {
+			return nil, err
+		}
+		result[address] = append(result[address], threadID)
+	}
+	return result, rows.Err()
+}
+
+// --- Calendar event operations ---
+
+// InsertEvent stores a parsed meeting invite, replacing any previous version
+// of the same event from the same email.
+func (d *DB) InsertEvent(ev *types.CalendarEvent) error {
+	allDay := 0
+	if ev.AllDay {
+		allDay = 1
+	}
+	_, err := d.exec(`
+		INSERT INTO events
+			(email_id, account, thread_id, uid, method, summary, location, organizer, attendees, start_at, end_at, all_day, status, ics)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(email_id, uid) DO UPDATE SET
+			account = excluded.account, thread_id = excluded.thread_id, method = excluded.method,
+			summary = excluded.summary, location = excluded.location, organizer = excluded.organizer,
+			attendees = excluded.attendees, start_at = excluded.start_at, end_at = excluded.end_at,
+			all_day = excluded.all_day, status = excluded.status, ics = excluded.ics`,
+		ev.EmailID, ev.Account, ev.ThreadID, ev.UID, ev.Method, ev.Summary, ev.Location,
+		ev.Organizer, ev.Attendees, ev.Start, ev.End, allDay, ev.Status, ev.ICS,
+	)
+	return err
+}
+
+const eventColumns = `email_id, account, thread_id, uid, method, summary, location,
+		       organizer, attendees, start_at, end_at, all_day, status, ics`
+
+// ThreadEvents returns the meeting invites found in a thread.
+func (d *DB) ThreadEvents(threadID, account string) ([]*types.CalendarEvent, error) {
+	rows, err := d.conn.Query(`
+		SELECT `+eventColumns+`
+		FROM events
+		WHERE thread_id = ? AND account = ?
+		ORDER BY start_at ASC`, threadID, account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanEvents(rows)
+}
+
+// EmailEvents returns the meeting invites attached to a single email.
+func (d *DB) EmailEvents(emailID string) ([]*types.CalendarEvent, error) {
+	rows, err := d.conn.Query(`
+		SELECT `+eventColumns+`
+		FROM events
+		WHERE email_id = ?
+		ORDER BY start_at ASC`, emailID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanEvents(rows)
+}
+
+// UpcomingEvents returns invites starting between from and until (RFC 3339,
+// until may be empty for no upper bound), optionally for one account.
+// Cancellations and superseded copies of the same UID are not filtered.
+func (d *DB) UpcomingEvents(account, from, until string) ([]*types.CalendarEvent, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE start_at >= ?`
+	args := []any{from}
+	if until != "" {
+		query += ` AND start_at < ?`
+		args = append(args, until)
+	}
+	if account != "" {
+		query += ` AND account = ?`
+		args = append(args, account)
+	}
+	query += ` ORDER BY start_at ASC`
+
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanEvents(rows)
+}
+
+func scanEvents(rows *sql.Rows) ([]*types.CalendarEvent, error) {
+	var result []*types.CalendarEvent
+	for rows.Next() {
+		ev := &types.CalendarEvent{}
+		var method, summary, location, organizer, attendees, end, status, ics sql.NullString
+		var allDay int
+		if err := rows.Scan(
+			&ev.EmailID, &ev.Account, &ev.ThreadID, &ev.UID, &method, &summary, &location,
+			&organizer, &attendees, &ev.Start, &end, &allDay, &status, &ics,
+		); err != nil {
+			return nil, err
+		}
+		ev.Method = method.String
+		ev.Summary = summary.String
+		ev.Location = location.String
+		ev.Organizer = organizer.String
+		ev.Attendees = attendees.String
+		ev.End = end.String
+		ev.AllDay = allDay == 1
+		ev.Status = status.String
+		ev.ICS = ics.String
+		result = append(result, ev)
+	}
+	return result, rows.Err()
+}
+
 // Underlying returns the raw sql.DB connection (for frontend readonly access).
 func (d *DB) Underlying() *sql.DB {
-	return d.conn
+	return d.conn.DB
 }
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+)
+
+// dialect captures the SQL differences between the engines mailbeads can
+// store its data in. Queries are written once, with ? placeholders and
+// syntax both engines accept; dialect covers the rest.
+type dialect struct {
+	name string
+	// driver is the database/sql driver name.
+	driver string
+	// schema is the DDL that creates the tables and indexes.
+	schema string
+	// numbered placeholders ($1, $2, ...) instead of ?.
+	numbered bool
+	// rowSeq is the column that numbers emails and triage_log rows in
+	// insertion order.
+	rowSeq string
+	// ilike is the case-insensitive LIKE operator.
+	ilike string
+	// legacy databases may have been created by older mailbeads versions,
+	// so Open migrates and backfills them.
+	legacy bool
+	// optimize, if set, is run when the database is closed.
+	optimize string
+	// readOnly and readWrite switch a connection into and out of a mode
+	// that rejects writes, for ad-hoc queries.
+	readOnly, readWrite string
+}
+
+var (
+	sqliteDialect = dialect{
+		name:      "sqlite",
+		driver:    "sqlite",
+		schema:    Schema,
+		rowSeq:    "rowid",
+		ilike:     "LIKE",
+		legacy:    true,
+		optimize:  "PRAGMA optimize",
+		readOnly:  "PRAGMA query_only = ON",
+		readWrite: "PRAGMA query_only = OFF",
+	}
+	postgresDialect = dialect{
+		name:      "postgres",
+		driver:    "pgx",
+		schema:    PostgresSchema,
+		numbered:  true,
+		rowSeq:    "seq",
+		ilike:     "ILIKE",
+		readOnly:  "SET default_transaction_read_only = on",
+		readWrite: "SET default_transaction_read_only = off",
+	}
+	// libsqlDialect is a remote SQLite database served by libsql (Turso or
+	// a self-hosted sqld). It may hold a mail.db uploaded from an older
+	// version, so it is upgraded like a local file.
+	libsqlDialect = dialect{
+		name:      "libsql",
+		driver:    "libsql",
+		schema:    Schema,
+		rowSeq:    "rowid",
+		ilike:     "LIKE",
+		legacy:    true,
+		readOnly:  "PRAGMA query_only = ON",
+		readWrite: "PRAGMA query_only = OFF",
+	}
+)
+
+// serverDialects maps DSN schemes to the dialect of the server they
+// address.
+var serverDialects = map[string]dialect{
+	"postgres":   postgresDialect,
+	"postgresql": postgresDialect,
+	"libsql":     libsqlDialect,
+	"https":      libsqlDialect,
+	"http":       libsqlDialect,
+	"wss":        libsqlDialect,
+	"ws":         libsqlDialect,
+}
+
+// serverDialect returns the dialect of the server target addresses, if it
+// is a DSN rather than a file path.
+func serverDialect(target string) (dialect, bool) {
+	scheme, _, ok := strings.Cut(target, "://")
+	if !ok {
+		return dialect{}, false
+	}
+	dl, ok := serverDialects[strings.ToLower(scheme)]
+	return dl, ok
+}
+
+// IsDSN reports whether target names a database server (PostgreSQL or
+// libsql) rather than a SQLite file.
+func IsDSN(target string) bool {
+	_, ok := serverDialect(target)
+	return ok
+}
+
+// rebind rewrites the ? placeholders in query for dialects that number
+// them. Question marks inside quoted strings are left alone.
+func (dl dialect) rebind(query string) string {
+	if !dl.numbered || !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
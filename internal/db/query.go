@@ -0,0 +1,142 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// pool is a connection pool whose queries are written with ? placeholders
+// and rebound for its dialect.
+type pool struct {
+	*sql.DB
+	dl dialect
+}
+
+func (p *pool) Query(query string, args ...any) (*sql.Rows, error) {
+	return p.DB.Query(p.dl.rebind(query), args...)
+}
+
+func (p *pool) QueryRow(query string, args ...any) *sql.Row {
+	return p.DB.QueryRow(p.dl.rebind(query), args...)
+}
+
+func (p *pool) Exec(query string, args ...any) (sql.Result, error) {
+	return p.DB.Exec(p.dl.rebind(query), args...)
+}
+
+func (p *pool) Prepare(query string) (*sql.Stmt, error) {
+	return p.DB.Prepare(p.dl.rebind(query))
+}
+
+// Begin starts a transaction whose statements are rebound like the pool's.
+func (p *pool) Begin() (*txn, error) {
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &txn{tx, p.dl}, nil
+}
+
+// txn is a transaction on a pool.
+type txn struct {
+	*sql.Tx
+	dl dialect
+}
+
+func (t *txn) Exec(query string, args ...any) (sql.Result, error) {
+	return t.Tx.Exec(t.dl.rebind(query), args...)
+}
+
+func (t *txn) Query(query string, args ...any) (*sql.Rows, error) {
+	return t.Tx.Query(t.dl.rebind(query), args...)
+}
+
+func (t *txn) QueryRow(query string, args ...any) *sql.Row {
+	return t.Tx.QueryRow(t.dl.rebind(query), args...)
+}
+
+// querier runs statements on a pool or in a transaction, for writes that
+// happen both alone and as part of a larger transaction.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// stmtCache prepares each distinct query once per connection pool and
+// reuses it, so the queries run per thread or per message (listings, sync
+// inserts) don't pay for parsing and planning on every call. Only queries
+// built from a fixed set of strings belong here: the cache never evicts.
+type stmtCache struct {
+	db    *pool
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+func newStmtCache(db *pool) *stmtCache {
+	return &stmtCache{db: db, stmts: map[string]*sql.Stmt{}}
+}
+
+// prepare returns the cached statement for query, preparing it on first
+// use.
+func (c *stmtCache) prepare(query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if st, ok := c.stmts[query]; ok {
+		return st, nil
+	}
+	st, err := c.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[query] = st
+	return st, nil
+}
+
+// close closes every cached statement.
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for q, st := range c.stmts {
+		errs = append(errs, st.Close())
+		delete(c.stmts, q)
+	}
+	return errors.Join(errs...)
+}
+
+// query runs a cached read statement.
+func (d *DB) query(query string, args ...any) (*sql.Rows, error) {
+	st, err := d.reads.prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return st.Query(args...)
+}
+
+// queryRow runs a cached single-row read statement. A preparation error
+// surfaces from Scan, as with sql.DB.QueryRow.
+func (d *DB) queryRow(query string, args ...any) *sql.Row {
+	st, err := d.reads.prepare(query)
+	if err != nil {
+		return d.conn.QueryRow(query, args...)
+	}
+	return st.QueryRow(args...)
+}
+
+// execCached runs a cached write statement on the writer connection,
+// retrying on lock contention like exec.
+func (d *DB) execCached(query string, args ...any) (sql.Result, error) {
+	st, err := d.writes.prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	var res sql.Result
+	err = retryBusy(func() error {
+		var err error
+		res, err = st.Exec(args...)
+		return err
+	})
+	return res, err
+}
@@ -7,6 +7,65 @@ package db
 // the beads (.beads/) database — mailbeads only stores the mapping so that
 // mb untriaged / mb show can efficiently look up whether a thread has been
 // triaged without querying beads.
+//
+// The senders table aggregates per-address message counts. It is maintained
+// during sync so mb contacts can rank correspondents without a full scan.
+//
+// emails.date keeps the raw Date header; emails.sent_at holds it normalized
+// to RFC 3339 UTC so it sorts and compares correctly as a string.
+// emails.attachment_text holds text extracted from PDF, DOCX, and plain-text
+// attachments at sync time (size-capped) so mb search can match it.
+// emails.auth_results keeps Gmail's Authentication-Results header, from
+// which mb show and mb untriaged report SPF/DKIM/DMARC and spoofing signs.
+// emails.is_bulk is 1 for newsletters and list mail (List-Id, Precedence:
+// bulk, or one-click unsubscribe headers), tagged as they are synced.
+//
+// addresses holds each message's From, To, and Cc lines parsed into one row
+// per person (role from, to, or cc; address lowercased), so per-person
+// queries match "Bob <bob@x>" and "bob@x" alike. The raw headers stay in
+// emails.
+//
+// The same message delivered to several accounts is stored once per account;
+// such threads are linked by their shared RFC Message-ID (message_id).
+//
+// raw_messages holds the original RFC 822 source of messages synced with
+// mb sync --raw, gzip-compressed, for re-parsing and lossless .eml export.
+//
+// The events table holds meeting invites parsed from iCalendar parts during
+// sync, keyed by (email_id, uid), with the raw ICS kept for RSVP replies.
+//
+// stats_history gets one row of inbox counts per sync, for mb stats --trend.
+// new_triaged counts triage refs created since the previous snapshot.
+//
+// triage_log keeps one row per triaged bead even after mb done/dismiss
+// removes the triage ref: when its mail arrived, when it was triaged, and
+// when and how it was closed. mb stats derives triage latency from it, and
+// mb train learns priorities from it (priority is the triage priority:
+// high, medium, low, or spam).
+//
+// journal records each undoable operation (triage, update, done, dismiss)
+// with the triage refs it touched and, for updates, the bead's prior fields
+// as JSON. mb undo reverts the latest entry without undone_at.
+//
+// audit is an append-only record of every mutating mb action: who ran it
+// (actor), the command line, and the thread/bead it touched. mb log reads it.
+//
+// sync_state keeps each account's provider cursor (Gmail's history ID) as of
+// its last default sync, so the next one can skip listing an unchanged
+// mailbox.
+//
+// dismissals records untriaged threads auto-dismissed by a config policy
+// (the policy's name) during sync. They stay out of mb untriaged until
+// restored with mb dismissed restore or mb undo.
+//
+// muted holds threads muted with mb mute: they stay out of mb untriaged,
+// and new mail on them neither comments on their bead nor notifies.
+//
+// suggestions queues the triage decisions mb auto-triage --review proposes,
+// one per thread, until mb review accepts (and deletes) or rejects them.
+// A rejected suggestion keeps its row, with rejected_at set, so later
+// auto-triage runs don't propose the same thread again. confidence is
+// the decision's own 0–1 estimate, NULL if it gave none.
 const Schema = `
 CREATE TABLE IF NOT EXISTS emails (
     id          TEXT PRIMARY KEY,
@@ -19,10 +78,19 @@ CREATE TABLE IF NOT EXISTS emails (
     subject     TEXT NOT NULL,
     snippet     TEXT,
     body        TEXT,
+    attachment_text TEXT,
     date        TEXT NOT NULL,
+    sent_at     TEXT,
     labels      TEXT,
     is_read     INTEGER DEFAULT 0,
-    fetched_at  TEXT NOT NULL
+    fetched_at  TEXT NOT NULL,
+    auth_results TEXT,
+    is_bulk     INTEGER DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS raw_messages (
+    email_id    TEXT PRIMARY KEY,
+    raw         BLOB NOT NULL
 );
 
 CREATE TABLE IF NOT EXISTS triage (
@@ -33,11 +101,310 @@ CREATE TABLE IF NOT EXISTS triage (
     UNIQUE(thread_id, account)
 );
 
-CREATE INDEX IF NOT EXISTS idx_emails_account ON emails(account);
-CREATE INDEX IF NOT EXISTS idx_emails_thread ON emails(thread_id);
+CREATE TABLE IF NOT EXISTS senders (
+    address       TEXT PRIMARY KEY,
+    name          TEXT,
+    message_count INTEGER NOT NULL DEFAULT 0,
+    first_seen    TEXT NOT NULL,
+    last_seen     TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS events (
+    email_id    TEXT NOT NULL,
+    account     TEXT NOT NULL,
+    thread_id   TEXT NOT NULL,
+    uid         TEXT NOT NULL,
+    method      TEXT,
+    summary     TEXT,
+    location    TEXT,
+    organizer   TEXT,
+    attendees   TEXT,
+    start_at    TEXT NOT NULL,
+    end_at      TEXT,
+    all_day     INTEGER DEFAULT 0,
+    status      TEXT,
+    ics         TEXT,
+    UNIQUE(email_id, uid)
+);
+
+CREATE TABLE IF NOT EXISTS stats_history (
+    taken_at     TEXT NOT NULL,
+    emails       INTEGER NOT NULL,
+    threads      INTEGER NOT NULL,
+    untriaged    INTEGER NOT NULL,
+    triaged      INTEGER NOT NULL,
+    new_triaged  INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS triage_log (
+    thread_id   TEXT NOT NULL,
+    account     TEXT NOT NULL,
+    bead_id     TEXT NOT NULL,
+    arrived_at  TEXT NOT NULL,
+    triaged_at  TEXT NOT NULL,
+    closed_at   TEXT,
+    outcome     TEXT,
+    priority    TEXT
+);
+
+CREATE TABLE IF NOT EXISTS journal (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    at          TEXT NOT NULL,
+    op          TEXT NOT NULL,
+    bead_id     TEXT NOT NULL,
+    refs        TEXT,
+    previous    TEXT,
+    undone_at   TEXT
+);
+
+CREATE TABLE IF NOT EXISTS audit (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    at          TEXT NOT NULL,
+    actor       TEXT NOT NULL,
+    command     TEXT NOT NULL,
+    action      TEXT NOT NULL,
+    thread_id   TEXT,
+    account     TEXT,
+    bead_id     TEXT,
+    detail      TEXT
+);
+
+CREATE TABLE IF NOT EXISTS addresses (
+    email_id    TEXT NOT NULL,
+    account     TEXT NOT NULL,
+    thread_id   TEXT NOT NULL,
+    role        TEXT NOT NULL,
+    address     TEXT NOT NULL,
+    name        TEXT,
+    UNIQUE(email_id, role, address)
+);
+
+CREATE TABLE IF NOT EXISTS sync_state (
+    account     TEXT PRIMARY KEY,
+    cursor      TEXT NOT NULL,
+    synced_at   TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS dismissals (
+    thread_id    TEXT NOT NULL,
+    account      TEXT NOT NULL,
+    policy       TEXT NOT NULL,
+    dismissed_at TEXT NOT NULL,
+    PRIMARY KEY (thread_id, account)
+);
+
+CREATE TABLE IF NOT EXISTS muted (
+    thread_id   TEXT NOT NULL,
+    account     TEXT NOT NULL,
+    muted_at    TEXT NOT NULL,
+    PRIMARY KEY (thread_id, account)
+);
+
+CREATE TABLE IF NOT EXISTS suggestions (
+    thread_id   TEXT NOT NULL,
+    account     TEXT NOT NULL,
+    priority    TEXT NOT NULL,
+    action      TEXT NOT NULL,
+    suggestion  TEXT,
+    agent_notes TEXT,
+    category    TEXT,
+    due         TEXT,
+    confidence  REAL,
+    source      TEXT NOT NULL,
+    created_at  TEXT NOT NULL,
+    rejected_at TEXT,
+    PRIMARY KEY (thread_id, account)
+);
+
+-- Thread listings group by (thread_id, account), aggregate sent_at, and
+-- look for the same message_id in other accounts; these indexes cover all
+-- of it without reading table rows. They replace the single-column indexes
+-- older versions created.
+DROP INDEX IF EXISTS idx_emails_account;
+DROP INDEX IF EXISTS idx_emails_thread;
+DROP INDEX IF EXISTS idx_emails_message_id;
+CREATE INDEX IF NOT EXISTS idx_emails_thread_account ON emails(thread_id, account, sent_at, message_id);
+CREATE INDEX IF NOT EXISTS idx_emails_account_sent ON emails(account, sent_at);
+CREATE INDEX IF NOT EXISTS idx_emails_message_account ON emails(message_id, account, thread_id);
 CREATE INDEX IF NOT EXISTS idx_emails_date ON emails(date DESC);
+CREATE INDEX IF NOT EXISTS idx_emails_sent_at ON emails(sent_at DESC);
 CREATE INDEX IF NOT EXISTS idx_triage_thread ON triage(thread_id, account);
 CREATE INDEX IF NOT EXISTS idx_triage_bead ON triage(bead_id);
+CREATE INDEX IF NOT EXISTS idx_events_start ON events(start_at);
+CREATE INDEX IF NOT EXISTS idx_events_thread ON events(thread_id, account);
+CREATE INDEX IF NOT EXISTS idx_triage_log_bead ON triage_log(bead_id);
+CREATE INDEX IF NOT EXISTS idx_audit_at ON audit(at);
+CREATE INDEX IF NOT EXISTS idx_addresses_address ON addresses(address, role);
+CREATE INDEX IF NOT EXISTS idx_stats_history_taken ON stats_history(taken_at);
+`
+
+// PostgresSchema is Schema for a shared PostgreSQL database. The tables
+// and columns match; emails and triage_log gain a seq column in place of
+// SQLite's implicit rowid, and raw sources are stored as bytea.
+const PostgresSchema = `
+CREATE TABLE IF NOT EXISTS emails (
+    id          TEXT PRIMARY KEY,
+    account     TEXT NOT NULL,
+    thread_id   TEXT NOT NULL,
+    message_id  TEXT,
+    from_addr   TEXT NOT NULL,
+    to_addr     TEXT,
+    cc          TEXT,
+    subject     TEXT NOT NULL,
+    snippet     TEXT,
+    body        TEXT,
+    attachment_text TEXT,
+    date        TEXT NOT NULL,
+    sent_at     TEXT,
+    labels      TEXT,
+    is_read     INTEGER DEFAULT 0,
+    fetched_at  TEXT NOT NULL,
+    auth_results TEXT,
+    is_bulk     INTEGER DEFAULT 0,
+    seq         BIGSERIAL
+);
+
+CREATE TABLE IF NOT EXISTS raw_messages (
+    email_id    TEXT PRIMARY KEY,
+    raw         BYTEA NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS triage (
+    thread_id   TEXT NOT NULL,
+    account     TEXT NOT NULL,
+    bead_id     TEXT NOT NULL,
+    created_at  TEXT NOT NULL,
+    UNIQUE(thread_id, account)
+);
+
+CREATE TABLE IF NOT EXISTS senders (
+    address       TEXT PRIMARY KEY,
+    name          TEXT,
+    message_count INTEGER NOT NULL DEFAULT 0,
+    first_seen    TEXT NOT NULL,
+    last_seen     TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS events (
+    email_id    TEXT NOT NULL,
+    account     TEXT NOT NULL,
+    thread_id   TEXT NOT NULL,
+    uid         TEXT NOT NULL,
+    method      TEXT,
+    summary     TEXT,
+    location    TEXT,
+    organizer   TEXT,
+    attendees   TEXT,
+    start_at    TEXT NOT NULL,
+    end_at      TEXT,
+    all_day     INTEGER DEFAULT 0,
+    status      TEXT,
+    ics         TEXT,
+    UNIQUE(email_id, uid)
+);
+
+CREATE TABLE IF NOT EXISTS stats_history (
+    taken_at     TEXT NOT NULL,
+    emails       INTEGER NOT NULL,
+    threads      INTEGER NOT NULL,
+    untriaged    INTEGER NOT NULL,
+    triaged      INTEGER NOT NULL,
+    new_triaged  INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS triage_log (
+    thread_id   TEXT NOT NULL,
+    account     TEXT NOT NULL,
+    bead_id     TEXT NOT NULL,
+    arrived_at  TEXT NOT NULL,
+    triaged_at  TEXT NOT NULL,
+    closed_at   TEXT,
+    outcome     TEXT,
+    priority    TEXT,
+    seq         BIGSERIAL
+);
+
+CREATE TABLE IF NOT EXISTS journal (
+    id          BIGSERIAL PRIMARY KEY,
+    at          TEXT NOT NULL,
+    op          TEXT NOT NULL,
+    bead_id     TEXT NOT NULL,
+    refs        TEXT,
+    previous    TEXT,
+    undone_at   TEXT
+);
+
+CREATE TABLE IF NOT EXISTS audit (
+    id          BIGSERIAL PRIMARY KEY,
+    at          TEXT NOT NULL,
+    actor       TEXT NOT NULL,
+    command     TEXT NOT NULL,
+    action      TEXT NOT NULL,
+    thread_id   TEXT,
+    account     TEXT,
+    bead_id     TEXT,
+    detail      TEXT
+);
+
+CREATE TABLE IF NOT EXISTS addresses (
+    email_id    TEXT NOT NULL,
+    account     TEXT NOT NULL,
+    thread_id   TEXT NOT NULL,
+    role        TEXT NOT NULL,
+    address     TEXT NOT NULL,
+    name        TEXT,
+    UNIQUE(email_id, role, address)
+);
+
+CREATE TABLE IF NOT EXISTS sync_state (
+    account     TEXT PRIMARY KEY,
+    cursor      TEXT NOT NULL,
+    synced_at   TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS dismissals (
+    thread_id    TEXT NOT NULL,
+    account      TEXT NOT NULL,
+    policy       TEXT NOT NULL,
+    dismissed_at TEXT NOT NULL,
+    PRIMARY KEY (thread_id, account)
+);
+
+CREATE TABLE IF NOT EXISTS muted (
+    thread_id   TEXT NOT NULL,
+    account     TEXT NOT NULL,
+    muted_at    TEXT NOT NULL,
+    PRIMARY KEY (thread_id, account)
+);
+
+CREATE TABLE IF NOT EXISTS suggestions (
+    thread_id   TEXT NOT NULL,
+    account     TEXT NOT NULL,
+    priority    TEXT NOT NULL,
+    action      TEXT NOT NULL,
+    suggestion  TEXT,
+    agent_notes TEXT,
+    category    TEXT,
+    due         TEXT,
+    confidence  REAL,
+    source      TEXT NOT NULL,
+    created_at  TEXT NOT NULL,
+    rejected_at TEXT,
+    PRIMARY KEY (thread_id, account)
+);
+
+CREATE INDEX IF NOT EXISTS idx_emails_thread_account ON emails(thread_id, account, sent_at, message_id);
+CREATE INDEX IF NOT EXISTS idx_emails_account_sent ON emails(account, sent_at);
+CREATE INDEX IF NOT EXISTS idx_emails_message_account ON emails(message_id, account, thread_id);
+CREATE INDEX IF NOT EXISTS idx_emails_sent_at ON emails(sent_at DESC);
+CREATE INDEX IF NOT EXISTS idx_emails_seq ON emails(seq);
+CREATE INDEX IF NOT EXISTS idx_triage_bead ON triage(bead_id);
+CREATE INDEX IF NOT EXISTS idx_events_start ON events(start_at);
+CREATE INDEX IF NOT EXISTS idx_events_thread ON events(thread_id, account);
+CREATE INDEX IF NOT EXISTS idx_triage_log_bead ON triage_log(bead_id);
+CREATE INDEX IF NOT EXISTS idx_audit_at ON audit(at);
+CREATE INDEX IF NOT EXISTS idx_addresses_address ON addresses(address, role);
+CREATE INDEX IF NOT EXISTS idx_stats_history_taken ON stats_history(taken_at);
 `
 
 // MigrationV2 migrates from the old fat triage table to the new slim one.
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// MemoryPath, passed to Open, opens an empty database that lives in memory
+// until it is closed: for tests, and for scratch work that shouldn't touch
+// disk. Seed fills it.
+const MemoryPath = ":memory:"
+
+// Seed is data to preload into a database, such as a test fixture or a
+// mailbox imported for scratch triage. mb --seed reads it as JSON.
+type Seed struct {
+	Emails []*types.Email     `json:"emails"`
+	Triage []*types.TriageRef `json:"triage,omitempty"`
+}
+
+// Seed stores s: its emails (skipping ones already stored) with their
+// senders, then its triage refs. It returns the number of emails added.
+func (d *DB) Seed(s *Seed) (int, error) {
+	ids := make([]string, len(s.Emails))
+	for i, e := range s.Emails {
+		ids[i] = e.ID
+	}
+	existing, err := d.ExistingEmailIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, e := range s.Emails {
+		if e.ID == "" || e.ThreadID == "" || e.Account == "" {
+			return added, fmt.Errorf("seed email %q: id, thread_id, and account are required", e.ID)
+		}
+		if existing[e.ID] {
+			continue
+		}
+		if e.FetchedAt == "" {
+			e.FetchedAt = Now()
+		}
+		if err := d.InsertEmail(e); err != nil {
+			return added, fmt.Errorf("seed email %s: %w", e.ID, err)
+		}
+		if err := d.RecordSender(e.From, e.Date); err != nil {
+			return added, fmt.Errorf("seed email %s: %w", e.ID, err)
+		}
+		existing[e.ID] = true
+		added++
+	}
+
+	for _, r := range s.Triage {
+		if _, err := d.UpsertTriageRef(r.ThreadID, r.Account, r.BeadID); err != nil {
+			return added, fmt.Errorf("seed triage ref for %s: %w", r.ThreadID, err)
+		}
+	}
+	return added, nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import "github.com/daviddao/mailbeads/internal/types"
+
+// Store is the mailbeads database as the rest of mailbeads uses it. *DB
+// implements it on SQLite and PostgreSQL; see Open.
+type Store interface {
+	Close() error
+
+	// Emails
+	InsertEmail(e *types.Email) error
+	SetEmailBody(id, body, attachmentText string) error
+	SetEmailLabels(id, labels string, isRead int) (bool, error)
+	StoreRawSource(emailID string, raw []byte) error
+	RawSource(emailID string) ([]byte, error)
+	GetEmail(id string) (*types.Email, error)
+	ExistingEmailIDs(ids []string) (map[string]bool, error)
+	LatestEmailDate(account string) string
+	EmailCount() int
+	EmailCountByAccount(account string) int
+	LatestFetchedAt(account string) string
+	SyncCursor(account string) string
+	SetSyncCursor(account, cursor string) error
+	ThreadEmails(threadID, account string) ([]*types.Email, error)
+	ThreadAccounts(threadID string) ([]string, error)
+	EmailsWithInlineCalendar() ([]*types.Email, error)
+	EmailsAfterRow(after int64) ([]*types.Email, int64, error)
+	MaxEmailRow() int64
+	SearchEmails(query, account string, limit int) ([]*types.Email, error)
+	SearchEmailsFunc(query, account string, limit int, fn func(*types.Email) error) error
+
+	// Triage cross-references and history
+	GetTriageRef(threadID, account string) (*types.TriageRef, error)
+	GetTriageRefByBead(beadID string) (*types.TriageRef, error)
+	UpsertTriageRef(threadID, account, beadID string) (created bool, err error)
+	DeleteTriageRef(beadID string) error
+	DeleteThreadTriageRef(threadID, account string) error
+	CloseTriageRef(beadID, outcome string) error
+	SetTriagePriority(beadID, priority string) error
+	TriageLog(since string) ([]*types.TriageLogEntry, error)
+	TriageLatencies(since string) (toTriage, toClose []float64, err error)
+	TriageRefsByBead(beadID string) ([]*types.TriageRef, error)
+	RestoreTriageRefs(beadID string, refs []types.TriageRef) error
+	RemoveTriage(beadID string) error
+	RecordTriages(recs []*types.TriageRecord) error
+	AllTriageRefs() ([]*types.TriageRef, error)
+	LegacyTriageRefs() ([]*types.TriageRef, error)
+	DismissThreads(dismissals []*types.Dismissal) error
+	Dismissals() ([]*types.Dismissal, error)
+	RestoreDismissed(threadID, account string) (bool, error)
+	MuteThread(threadID, account string) (bool, error)
+	UnmuteThread(threadID, account string) (bool, error)
+	IsMuted(threadID, account string) bool
+	MutedThreads() ([]*types.Thread, error)
+	QueueSuggestions(suggestions []*types.Suggestion) error
+	Suggestions(pending bool) ([]*types.Suggestion, error)
+	RejectSuggestion(threadID, account string) (bool, error)
+	DeleteSuggestion(threadID, account string) (bool, error)
+
+	// Threads
+	DuplicateThreads(threadID, account string) ([]*types.Thread, error)
+	DuplicateTriageRef(threadID, account string) (*types.TriageRef, error)
+	LinkDuplicates(threadID, account, beadID string) (int, error)
+	DuplicateGroups() ([][]*types.Thread, error)
+	UntriagedThreads(account string, limit int) ([]*types.Thread, error)
+	ThreadsWithNewEmails() ([]*types.Thread, error)
+	RecentThreads(since string) ([]*types.Thread, error)
+	Threads(account string) ([]*types.Thread, error)
+	ThreadInfo(threadID, account string) (*types.Thread, error)
+	AddressThreads(address string) ([]*types.Thread, error)
+
+	// Undo journal and audit log
+	RecordOp(op *types.Operation) error
+	Operations(pending bool, limit int) ([]*types.Operation, error)
+	MarkUndone(id int64) error
+	AppendAudit(e *types.AuditEntry) error
+	AuditLog(f types.AuditFilter) ([]*types.AuditEntry, error)
+
+	// Counts and statistics
+	UntriagedCount() int
+	TriagedCount() int
+	ThreadCount() int
+	SnapshotStats() error
+	StatsHistory(since string) ([]*types.StatsSnapshot, error)
+	Accounts() []string
+
+	// Senders
+	TopSenders(since string, limit int) ([]*types.Contact, error)
+	RecordSender(from, date string) error
+	RebuildSenders() (int, error)
+	Senders(search string) ([]*types.Contact, error)
+	SenderThreads() (map[string][]string, error)
+
+	// Calendar events
+	InsertEvent(ev *types.CalendarEvent) error
+	ThreadEvents(threadID, account string) ([]*types.CalendarEvent, error)
+	EmailEvents(emailID string) ([]*types.CalendarEvent, error)
+	UpcomingEvents(account, from, until string) ([]*types.CalendarEvent, error)
+
+	// ReadOnlyQuery runs an ad-hoc SELECT in read-only mode (mb query).
+	ReadOnlyQuery(query string, limit int) (*types.QueryResult, error)
+
+	// Seed preloads a fixture or imported mailbox.
+	Seed(s *Seed) (int, error)
+}
+
+var _ Store = (*DB)(nil)
@@ -0,0 +1,91 @@
+package display
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// AccountStyle customizes how one account is labelled. Empty fields keep
+// the defaults: the domain-derived name and an automatically assigned color.
+type AccountStyle struct {
+	Label string
+	Color string // "#rrggbb" or ANSI 0-255
+	Icon  string // e.g. an emoji, shown before the label
+}
+
+// accountPalette colors accounts without a configured color, so listings
+// that mix accounts stay scannable out of the box.
+var accountPalette = []string{"#2563eb", "#0d9488", "#9333ea", "#c2410c", "#be185d", "#4d7c0f"}
+
+var accountStyles = map[string]AccountStyle{}
+
+// SetAccountStyles sets per-account labels, colors, and icons. Keys are
+// account addresses or their default labels (e.g. "work" for
+// "me@work.com").
+func SetAccountStyles(styles map[string]AccountStyle) error {
+	for key, st := range styles {
+		if st.Color != "" && !colorPattern.MatchString(st.Color) {
+			return fmt.Errorf("invalid color %q for account %q (use #rrggbb or 0-255)", st.Color, key)
+		}
+	}
+	accountStyles = styles
+	return nil
+}
+
+// AccountName returns the plain short name for an account: its configured
+// label, or the domain without TLD ("user@example.com" -> "example").
+func AccountName(account string) string {
+	if st, ok := accountStyle(account); ok && st.Label != "" {
+		return st.Label
+	}
+	return defaultAccountName(account)
+}
+
+// AccountLabel returns the account's name in its color, prefixed with its
+// icon if one is configured. Use AccountName where styling doesn't belong
+// (templates, Markdown, error messages).
+func AccountLabel(account string) string {
+	st, _ := accountStyle(account)
+	color := st.Color
+	if color == "" {
+		h := fnv.New32a()
+		h.Write([]byte(strings.ToLower(account)))
+		color = accountPalette[h.Sum32()%uint32(len(accountPalette))]
+	}
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(AccountName(account))
+	if st.Icon != "" {
+		label = st.Icon + " " + label
+	}
+	return label
+}
+
+// PadRight pads s with spaces to width terminal cells, ignoring ANSI
+// styling, so styled labels line up in columns.
+func PadRight(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+func accountStyle(account string) (AccountStyle, bool) {
+	if st, ok := accountStyles[account]; ok {
+		return st, true
+	}
+	st, ok := accountStyles[defaultAccountName(account)]
+	return st, ok
+}
+
+func defaultAccountName(account string) string {
+	if idx := strings.Index(account, "@"); idx > 0 {
+		domain := account[idx+1:]
+		if dotIdx := strings.Index(domain, "."); dotIdx > 0 {
+			return domain[:dotIdx]
+		}
+		return domain
+	}
+	return account
+}
@@ -0,0 +1,73 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+// ColorEnabled reports whether output should be styled: not disabled with
+// --no-color or NO_COLOR, and stdout is a terminal (not a pipe or file).
+func ColorEnabled(noColorFlag bool) bool {
+	if noColorFlag || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(os.Stdout.Fd())
+}
+
+// SetColor enables or disables all ANSI styling.
+func SetColor(enabled bool) {
+	if enabled {
+		lipgloss.SetColorProfile(termenv.NewOutput(os.Stdout).EnvColorProfile())
+		return
+	}
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// Theme maps style roles to colors ("#rrggbb" or an ANSI 0-255 number).
+// Roles: high, medium, low, spam, muted, dim, success, error, link, heading.
+type Theme map[string]string
+
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[0-9]{1,3})$`)
+
+// ApplyTheme overrides style colors. Unknown roles or malformed colors are
+// an error; roles not in the theme keep their defaults.
+func ApplyTheme(t Theme) error {
+	styles := map[string]*lipgloss.Style{
+		"high":    &HighStyle,
+		"medium":  &MediumStyle,
+		"low":     &LowStyle,
+		"spam":    &SpamStyle,
+		"muted":   &Muted,
+		"dim":     &Dim,
+		"success": &Success,
+		"error":   &ErrStyle,
+		"link":    &LinkStyle,
+		"heading": &HeadingStyle,
+	}
+	for role, color := range t {
+		style, ok := styles[role]
+		if !ok {
+			roles := make([]string, 0, len(styles))
+			for r := range styles {
+				roles = append(roles, r)
+			}
+			sort.Strings(roles)
+			return fmt.Errorf("unknown theme role %q (must be one of: %s)", role, strings.Join(roles, ", "))
+		}
+		if !colorPattern.MatchString(color) {
+			return fmt.Errorf("invalid color %q for theme role %q (use #rrggbb or 0-255)", color, role)
+		}
+		*style = style.Foreground(lipgloss.Color(color))
+	}
+	return nil
+}
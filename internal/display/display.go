@@ -10,11 +10,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Styles. Colors can be overridden with ApplyTheme and disabled with SetColor.
 var (
-	// Detect if we're in a TTY for color support.
-	isTTY = lipgloss.HasDarkBackground()
-
-	// Styles
 	Muted    = lipgloss.NewStyle().Foreground(lipgloss.Color("#6b7280"))
 	Dim      = lipgloss.NewStyle().Foreground(lipgloss.Color("#9ca3af"))
 	Bold     = lipgloss.NewStyle().Bold(true)
@@ -60,18 +57,50 @@ func PriorityLabel(priority string) string {
 	}
 }
 
-// AccountLabel returns a short label for an account.
-// Derives the label from the domain (e.g., "user@example.com" -> "example").
-func AccountLabel(account string) string {
-	if idx := strings.Index(account, "@"); idx > 0 {
-		domain := account[idx+1:]
-		// Use the domain name without TLD (e.g., "example.com" -> "workorg")
-		if dotIdx := strings.Index(domain, "."); dotIdx > 0 {
-			return domain[:dotIdx]
-		}
-		return domain
+// DateOptions controls how timestamps are displayed.
+type DateOptions struct {
+	Absolute bool // show "Jan 2 15:04" instead of "3h ago"
+	Clock12  bool // 12-hour clock ("3:04 PM") for absolute times
+}
+
+var dateOpts DateOptions
+
+// SetDateOptions sets how FormatTime renders timestamps. The display time
+// zone is time.Local, which callers may override from config.
+func SetDateOptions(o DateOptions) {
+	dateOpts = o
+}
+
+// FormatTime formats an ISO date string for display, relative ("3h ago") or
+// absolute depending on the configured DateOptions.
+func FormatTime(isoDate string) string {
+	if !dateOpts.Absolute {
+		return TimeAgo(isoDate)
+	}
+	t, ok := parseISO(isoDate)
+	if !ok {
+		return isoDate[:min(10, len(isoDate))]
+	}
+	return AbsoluteTime(t)
+}
+
+// AbsoluteTime formats t in the display time zone, with the year only when
+// it differs from the current one.
+func AbsoluteTime(t time.Time) string {
+	t = t.Local()
+	layout := "Jan 2 " + ClockLayout()
+	if t.Year() != time.Now().Year() {
+		layout = "Jan 2 2006 " + ClockLayout()
 	}
-	return account
+	return t.Format(layout)
+}
+
+// ClockLayout returns the time-of-day layout for the configured clock.
+func ClockLayout() string {
+	if dateOpts.Clock12 {
+		return "3:04 PM"
+	}
+	return "15:04"
 }
 
 // TimeAgo formats an ISO date string as a relative time.
@@ -79,17 +108,8 @@ func TimeAgo(isoDate string) string {
 	if isoDate == "" {
 		return ""
 	}
-
-	// Try multiple formats
-	var t time.Time
-	var err error
-	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05Z", "2006-01-02 15:04:05", time.RFC3339Nano} {
-		t, err = time.Parse(layout, isoDate)
-		if err == nil {
-			break
-		}
-	}
-	if err != nil {
+	t, ok := parseISO(isoDate)
+	if !ok {
 		return isoDate[:min(10, len(isoDate))]
 	}
 
@@ -107,8 +127,18 @@ func TimeAgo(isoDate string) string {
 		days := int(d.Hours() / 24)
 		return fmt.Sprintf("%dd ago", days)
 	default:
-		return t.Format("Jan 2")
+		return t.Local().Format("Jan 2")
+	}
+}
+
+// parseISO parses the ISO 8601 variants stored by mailbeads and beads.
+func parseISO(isoDate string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05Z", "2006-01-02 15:04:05", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, isoDate); err == nil {
+			return t, true
+		}
 	}
+	return time.Time{}, false
 }
 
 // Truncate shortens a string to maxLen, adding ellipsis if needed.
@@ -148,7 +178,7 @@ func SubHeader(title string) {
 // connector is one of "┌─", "├─", "└─"
 func EmailTree(connector, from, date, body string) {
 	fromStr := Bold.Render(from)
-	dateStr := Dim.Render(TimeAgo(date))
+	dateStr := Dim.Render(FormatTime(date))
 	fmt.Printf("  %s %s  ·  %s\n", Muted.Render(connector), fromStr, dateStr)
 	if body != "" {
 		// Indent body lines under the connector
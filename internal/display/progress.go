@@ -0,0 +1,128 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// Progress shows the progress of a multi-phase job, such as one account's
+// sync. On a terminal it redraws a single bar line with a count and ETA;
+// elsewhere (a pipe, a log file) it prints a plain line as each phase
+// finishes, and at most every progressLogEvery while a long phase runs.
+type Progress struct {
+	w    io.Writer
+	tty  bool
+	last time.Time // last redraw or log line
+
+	phase      string
+	phaseStart time.Time
+	done       int
+	total      int
+	drawn      bool
+}
+
+// progressRedraw throttles bar redraws; progressLogEvery throttles plain
+// lines.
+const (
+	progressRedraw   = 100 * time.Millisecond
+	progressLogEvery = 10 * time.Second
+	progressBarWidth = 24
+)
+
+// NewProgress returns a Progress writing to stdout.
+func NewProgress() *Progress {
+	return &Progress{
+		w:   os.Stdout,
+		tty: os.Getenv("TERM") != "dumb" && term.IsTerminal(os.Stdout.Fd()),
+	}
+}
+
+// Update reports done of total items in phase (e.g. "search", "fetch",
+// "store"). A total of 0 means unknown. Moving to a new phase finishes the
+// previous one.
+func (p *Progress) Update(phase string, done, total int) {
+	now := time.Now()
+	if phase != p.phase {
+		p.finish()
+		p.phase, p.phaseStart, p.last = phase, now, time.Time{}
+	}
+	p.done, p.total = done, total
+
+	complete := total > 0 && done >= total
+	if p.tty {
+		if complete || now.Sub(p.last) >= progressRedraw {
+			p.draw()
+			p.last = now
+		}
+		return
+	}
+	if !p.last.IsZero() && now.Sub(p.last) >= progressLogEvery && !complete {
+		fmt.Fprintf(p.w, "  %s %s\n", p.phase, p.count())
+		p.last = now
+	} else if p.last.IsZero() {
+		p.last = now
+	}
+}
+
+// Done finishes the last phase and clears the bar.
+func (p *Progress) Done() {
+	p.finish()
+	p.phase = ""
+}
+
+// finish ends the current phase: it clears the bar on a terminal, or logs
+// the phase's total elsewhere.
+func (p *Progress) finish() {
+	if p.phase == "" {
+		return
+	}
+	if p.tty {
+		if p.drawn {
+			fmt.Fprint(p.w, "\r\033[K")
+			p.drawn = false
+		}
+		return
+	}
+	if p.done > 0 {
+		fmt.Fprintf(p.w, "  %s %d in %s\n", p.phase, p.done, time.Since(p.phaseStart).Round(100*time.Millisecond))
+	}
+}
+
+// draw redraws the bar line in place.
+func (p *Progress) draw() {
+	bar := ""
+	if p.total > 0 {
+		filled := progressBarWidth * min(p.done, p.total) / p.total
+		bar = Success.Render(strings.Repeat("█", filled)) + Dim.Render(strings.Repeat("░", progressBarWidth-filled)) + "  "
+	}
+	line := fmt.Sprintf("  %-6s %s%s", p.phase, bar, p.count())
+	if eta := p.eta(); eta > 0 {
+		line += Dim.Render("  ETA " + eta.String())
+	}
+	fmt.Fprint(p.w, "\r\033[K"+line)
+	p.drawn = true
+}
+
+// count renders done/total, or done alone when the total is unknown.
+func (p *Progress) count() string {
+	if p.total > 0 {
+		return fmt.Sprintf("%d/%d", p.done, p.total)
+	}
+	return fmt.Sprint(p.done)
+}
+
+// eta extrapolates the phase's remaining time from its rate so far, or
+// returns 0 while there is too little to go on.
+func (p *Progress) eta() time.Duration {
+	elapsed := time.Since(p.phaseStart)
+	if p.total <= 0 || p.done <= 0 || p.done >= p.total || elapsed < time.Second {
+		return 0
+	}
+	remaining := time.Duration(float64(elapsed) / float64(p.done) * float64(p.total-p.done))
+	return remaining.Round(time.Second)
+}
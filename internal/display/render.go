@@ -0,0 +1,103 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+)
+
+var (
+	LinkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#2563eb")).Underline(true)
+	HeadingStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7c3aed"))
+	QuoteStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#6b7280")).Italic(true)
+
+	urlPattern  = regexp.MustCompile(`(https?://|mailto:)[^\s<>()"]+[^\s<>()".,;:!?]`)
+	boldPattern = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	listPattern = regexp.MustCompile(`^(\s*)([-*•]|\d+[.)])\s+`)
+)
+
+// TermWidth returns the terminal width for rendering, clamped to a readable
+// range. Falls back to 80 columns when stdout is not a terminal.
+func TermWidth() int {
+	w, _, err := term.GetSize(os.Stdout.Fd())
+	if err != nil || w <= 0 {
+		return 80
+	}
+	return max(40, min(w, 100))
+}
+
+// MessageSeparator returns a full-width rule introducing message i of n.
+func MessageSeparator(i, n int, from, date string, width int) string {
+	label := fmt.Sprintf("─── %d/%d ", i, n)
+	head := Muted.Render(label) + Bold.Render(from) + Dim.Render("  ·  "+FormatTime(date)) + " "
+	fill := width - ansi.StringWidth(head)
+	if fill < 3 {
+		fill = 3
+	}
+	return head + Muted.Render(strings.Repeat("─", fill))
+}
+
+// RenderBody formats a plain-text or markdown-ish email body for the
+// terminal: long lines are wrapped to width (continuing list indentation),
+// links are styled, "# headings" and **bold** are emphasized, and quoted
+// lines are dimmed.
+func RenderBody(body string, width int) string {
+	var out []string
+	blank := 0
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			// Collapse runs of blank lines to one.
+			if blank++; blank == 1 {
+				out = append(out, "")
+			}
+			continue
+		}
+		blank = 0
+		out = append(out, renderLine(line, width)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+func renderLine(line string, width int) []string {
+	trimmed := strings.TrimLeft(line, " ")
+
+	if strings.HasPrefix(trimmed, ">") {
+		text := strings.TrimSpace(strings.TrimLeft(trimmed, "> "))
+		bar := Muted.Render("│ ")
+		var lines []string
+		for _, l := range strings.Split(ansi.Wrap(text, width-2, ""), "\n") {
+			lines = append(lines, bar+QuoteStyle.Render(l))
+		}
+		return lines
+	}
+
+	if strings.HasPrefix(trimmed, "#") {
+		text := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		return []string{HeadingStyle.Render(ansi.Wrap(text, width, ""))}
+	}
+
+	// Continuation lines of a list item align under the item text.
+	indent := ""
+	if m := listPattern.FindString(line); m != "" {
+		indent = strings.Repeat(" ", ansi.StringWidth(m))
+	}
+
+	styled := boldPattern.ReplaceAllStringFunc(line, func(s string) string {
+		return Bold.Render(strings.Trim(s, "*"))
+	})
+	styled = urlPattern.ReplaceAllStringFunc(styled, func(s string) string {
+		return LinkStyle.Render(s)
+	})
+
+	wrapped := strings.Split(ansi.Wrap(styled, width-len(indent), " -"), "\n")
+	for i := 1; i < len(wrapped); i++ {
+		wrapped[i] = indent + strings.TrimLeft(wrapped[i], " ")
+	}
+	return wrapped
+}
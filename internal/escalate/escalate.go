@@ -0,0 +1,149 @@
+// Package escalate files email threads as issues in external trackers, for
+// work that is tracked outside beads.
+package escalate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/config"
+)
+
+// Thread is the context of an escalated thread, rendered into the issue
+// body by each tracker.
+type Thread struct {
+	ThreadID string
+	Account  string
+	Subject  string
+	From     string
+	// URL links back to the thread in the mail client.
+	URL      string
+	BeadID   string
+	Priority string
+	// Title is the issue title: the bead's action, else the subject.
+	Title string
+	// Messages are oldest first, with quoted replies stripped.
+	Messages []Message
+}
+
+// Message is one message of an escalated thread.
+type Message struct {
+	From string
+	Date string
+	Body string
+}
+
+// Issue is an issue created in a tracker.
+type Issue struct {
+	Tracker string `json:"tracker"`
+	// Key is the tracker's identifier, e.g. "OPS-123".
+	Key string `json:"key"`
+	URL string `json:"url"`
+}
+
+// NoteValue is what is recorded on the bead for the issue: the key for
+// Jira, the issue URL for GitHub and Linear.
+func (is *Issue) NoteValue() string {
+	if is.Tracker == "jira" {
+		return is.Key
+	}
+	return is.URL
+}
+
+// Recorded rebuilds an issue from the value recorded on a bead.
+func Recorded(cfg config.EscalateConfig, tracker, value string) *Issue {
+	is := &Issue{Tracker: tracker, Key: value, URL: value}
+	switch tracker {
+	case "jira":
+		is.URL = JiraURL(cfg.Jira, value)
+	case "github":
+		// https://github.com/owner/repo/issues/12 -> owner/repo#12
+		if path, ok := strings.CutPrefix(value, "https://github.com/"); ok {
+			if repo, n, ok := strings.Cut(path, "/issues/"); ok {
+				is.Key = repo + "#" + n
+			}
+		}
+	case "linear":
+		// https://linear.app/acme/issue/ENG-123/slug -> ENG-123
+		if _, rest, ok := strings.Cut(value, "/issue/"); ok {
+			is.Key, _, _ = strings.Cut(rest, "/")
+		}
+	}
+	return is
+}
+
+// bodyLimit caps each message's text in an issue body, keeping long
+// threads readable and under tracker size limits.
+const bodyLimit = 4000
+
+// markdownBody renders t in Markdown, each message as a block quote.
+func markdownBody(t *Thread) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Reported by email: **%s**\n\n", t.Subject)
+	fmt.Fprintf(&b, "- From: %s\n", t.From)
+	if t.URL != "" {
+		fmt.Fprintf(&b, "- Thread: [%s](%s)\n", t.ThreadID, t.URL)
+	}
+	if t.BeadID != "" {
+		fmt.Fprintf(&b, "- Bead: `%s` (%s priority)\n", t.BeadID, t.Priority)
+	}
+	for _, m := range t.Messages {
+		fmt.Fprintf(&b, "\n#### %s — %s\n\n", m.From, m.Date)
+		for _, line := range strings.Split(strings.TrimSpace(truncate(m.Body, bodyLimit)), "\n") {
+			fmt.Fprintf(&b, "> %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// postJSON posts payload to url with the given headers and decodes the
+// JSON response into out.
+func postJSON(url string, headers map[string]string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		if msg := bytes.TrimSpace(data); len(msg) > 0 {
+			return fmt.Errorf("post %s: %s: %s", url, resp.Status, msg)
+		}
+		return fmt.Errorf("post %s: %s", url, resp.Status)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+	return nil
+}
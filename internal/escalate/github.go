@@ -0,0 +1,50 @@
+package escalate
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/config"
+)
+
+// GitHubTokenEnv holds the GitHub token; $GH_TOKEN, as used by the gh
+// CLI, is the fallback.
+const GitHubTokenEnv = "GITHUB_TOKEN"
+
+// GitHub opens an issue for t in repo ("owner/name").
+func GitHub(cfg config.GitHubConfig, repo string, t *Thread) (*Issue, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid repository %q (want owner/repo)", repo)
+	}
+	token := firstNonEmpty(os.Getenv(GitHubTokenEnv), os.Getenv("GH_TOKEN"))
+	if token == "" {
+		return nil, fmt.Errorf("no GitHub token: set $%s", GitHubTokenEnv)
+	}
+	api := strings.TrimRight(cfg.APIURL, "/")
+	if api == "" {
+		api = "https://api.github.com"
+	}
+
+	payload := map[string]any{
+		"title": truncate(t.Title, 250),
+		"body":  markdownBody(t),
+	}
+	if len(cfg.Labels) > 0 {
+		payload["labels"] = cfg.Labels
+	}
+	headers := map[string]string{
+		"Authorization":        "Bearer " + token,
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+	var created struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := postJSON(fmt.Sprintf("%s/repos/%s/%s/issues", api, owner, name), headers, payload, &created); err != nil {
+		return nil, fmt.Errorf("create GitHub issue: %w", err)
+	}
+	return &Issue{Tracker: "github", Key: fmt.Sprintf("%s#%d", repo, created.Number), URL: created.HTMLURL}, nil
+}
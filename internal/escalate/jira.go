@@ -0,0 +1,96 @@
+package escalate
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/config"
+)
+
+// JiraTokenEnv holds the Jira API token (Cloud) or personal access token
+// (Data Center).
+const JiraTokenEnv = "JIRA_API_TOKEN"
+
+// Jira creates an issue for t in a Jira project through the REST API (v2,
+// which Cloud and Data Center share). With an email configured it
+// authenticates with Cloud basic auth, else with the token as a bearer
+// personal access token.
+func Jira(cfg config.JiraConfig, project string, t *Thread) (*Issue, error) {
+	base := jiraBase(cfg)
+	if base == "" {
+		return nil, fmt.Errorf(`no Jira URL: set "escalate": {"jira": {"url": ...}} in .mailbeads/config.json or $JIRA_URL`)
+	}
+	token := os.Getenv(JiraTokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("no Jira token: set $%s", JiraTokenEnv)
+	}
+	headers := map[string]string{"Authorization": "Bearer " + token}
+	if email := firstNonEmpty(cfg.Email, os.Getenv("JIRA_EMAIL")); email != "" {
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(email+":"+token))
+	}
+
+	issueType := cfg.IssueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+	fields := map[string]any{
+		"project":     map[string]string{"key": project},
+		"issuetype":   map[string]string{"name": issueType},
+		"summary":     truncate(t.Title, 250),
+		"description": jiraDescription(t),
+	}
+	if len(cfg.Labels) > 0 {
+		fields["labels"] = cfg.Labels
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := postJSON(base+"/rest/api/2/issue", headers, map[string]any{"fields": fields}, &created); err != nil {
+		return nil, fmt.Errorf("create Jira issue: %w", err)
+	}
+	return &Issue{Tracker: "jira", Key: created.Key, URL: JiraURL(cfg, created.Key)}, nil
+}
+
+// JiraURL returns the web URL of a Jira issue, or "" if no site is
+// configured.
+func JiraURL(cfg config.JiraConfig, key string) string {
+	base := jiraBase(cfg)
+	if base == "" {
+		return ""
+	}
+	return base + "/browse/" + key
+}
+
+func jiraBase(cfg config.JiraConfig) string {
+	return strings.TrimRight(firstNonEmpty(cfg.URL, os.Getenv("JIRA_URL")), "/")
+}
+
+// jiraDescription renders t in Jira wiki markup.
+func jiraDescription(t *Thread) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Escalated from email thread *%s*\n\n", t.Subject)
+	fmt.Fprintf(&b, "* From: %s\n", t.From)
+	fmt.Fprintf(&b, "* Account: %s\n", t.Account)
+	if t.URL != "" {
+		fmt.Fprintf(&b, "* Thread: [%s|%s]\n", t.ThreadID, t.URL)
+	}
+	if t.BeadID != "" {
+		fmt.Fprintf(&b, "* Bead: %s (%s priority)\n", t.BeadID, t.Priority)
+	}
+	for _, m := range t.Messages {
+		fmt.Fprintf(&b, "\nh4. %s — %s\n{noformat}\n%s\n{noformat}\n", m.From, m.Date, truncate(m.Body, bodyLimit))
+	}
+	return b.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
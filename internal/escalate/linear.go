@@ -0,0 +1,131 @@
+package escalate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/daviddao/mailbeads/internal/config"
+)
+
+// LinearKeyEnv holds the Linear personal API key.
+const LinearKeyEnv = "LINEAR_API_KEY"
+
+const linearAPI = "https://api.linear.app/graphql"
+
+// Linear creates an issue for t in the Linear team with key team (e.g.
+// "ENG"), in project (a project name, if set) and, with cfg.Cycle
+// "active", in the team's active cycle.
+func Linear(cfg config.LinearConfig, team, project string, t *Thread) (*Issue, error) {
+	key := os.Getenv(LinearKeyEnv)
+	if key == "" {
+		return nil, fmt.Errorf("no Linear API key: set $%s", LinearKeyEnv)
+	}
+
+	var teams struct {
+		Teams struct {
+			Nodes []struct {
+				ID          string `json:"id"`
+				ActiveCycle *struct {
+					ID string `json:"id"`
+				} `json:"activeCycle"`
+			} `json:"nodes"`
+		} `json:"teams"`
+	}
+	err := linearQuery(key, `query($key: String!) {
+  teams(filter: {key: {eq: $key}}) { nodes { id activeCycle { id } } }
+}`, map[string]any{"key": team}, &teams)
+	if err != nil {
+		return nil, err
+	}
+	if len(teams.Teams.Nodes) == 0 {
+		return nil, fmt.Errorf("no Linear team with key %q", team)
+	}
+	found := teams.Teams.Nodes[0]
+
+	input := map[string]any{
+		"teamId":      found.ID,
+		"title":       truncate(t.Title, 250),
+		"description": markdownBody(t),
+		"priority":    linearPriority(t.Priority),
+	}
+	if project != "" {
+		var projects struct {
+			Projects struct {
+				Nodes []struct {
+					ID string `json:"id"`
+				} `json:"nodes"`
+			} `json:"projects"`
+		}
+		err := linearQuery(key, `query($name: String!) {
+  projects(filter: {name: {eqIgnoreCase: $name}}) { nodes { id } }
+}`, map[string]any{"name": project}, &projects)
+		if err != nil {
+			return nil, err
+		}
+		if len(projects.Projects.Nodes) == 0 {
+			return nil, fmt.Errorf("no Linear project named %q", project)
+		}
+		input["projectId"] = projects.Projects.Nodes[0].ID
+	}
+	switch cfg.Cycle {
+	case "":
+	case "active":
+		if found.ActiveCycle != nil {
+			input["cycleId"] = found.ActiveCycle.ID
+		}
+	default:
+		return nil, fmt.Errorf(`config escalate.linear.cycle: %q (must be: "active" or empty)`, cfg.Cycle)
+	}
+
+	var created struct {
+		IssueCreate struct {
+			Success bool `json:"success"`
+			Issue   struct {
+				Identifier string `json:"identifier"`
+				URL        string `json:"url"`
+			} `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	err = linearQuery(key, `mutation($input: IssueCreateInput!) {
+  issueCreate(input: $input) { success issue { identifier url } }
+}`, map[string]any{"input": input}, &created)
+	if err != nil {
+		return nil, err
+	}
+	if !created.IssueCreate.Success {
+		return nil, fmt.Errorf("create Linear issue: not created")
+	}
+	return &Issue{Tracker: "linear", Key: created.IssueCreate.Issue.Identifier, URL: created.IssueCreate.Issue.URL}, nil
+}
+
+// linearQuery runs a GraphQL query against the Linear API and decodes its
+// data into out.
+func linearQuery(key, query string, vars map[string]any, out any) error {
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	payload := map[string]any{"query": query, "variables": vars}
+	if err := postJSON(linearAPI, map[string]string{"Authorization": key}, payload, &resp); err != nil {
+		return fmt.Errorf("Linear API: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("Linear API: %s", resp.Errors[0].Message)
+	}
+	return json.Unmarshal(resp.Data, out)
+}
+
+// linearPriority maps an mb priority to Linear's scale (1 urgent .. 4 low).
+func linearPriority(priority string) int {
+	switch priority {
+	case "high":
+		return 2
+	case "medium":
+		return 3
+	default:
+		return 4
+	}
+}
@@ -0,0 +1,81 @@
+package gmail
+
+import (
+	"fmt"
+	"slices"
+
+	gm "google.golang.org/api/gmail/v1"
+)
+
+// Filter is a server-side Gmail filter: incoming mail matching every
+// non-empty criterion gets AddLabels added and RemoveLabels removed.
+type Filter struct {
+	ID           string   `json:"id,omitempty"`
+	From         string   `json:"from,omitempty"`
+	To           string   `json:"to,omitempty"`
+	Subject      string   `json:"subject,omitempty"`
+	Query        string   `json:"query,omitempty"`
+	AddLabels    []string `json:"add_labels,omitempty"`
+	RemoveLabels []string `json:"remove_labels,omitempty"`
+}
+
+// Same reports whether f and o match the same mail and apply the same
+// labels, ignoring their IDs.
+func (f Filter) Same(o Filter) bool {
+	return f.From == o.From && f.To == o.To && f.Subject == o.Subject && f.Query == o.Query &&
+		sameSet(f.AddLabels, o.AddLabels) && sameSet(f.RemoveLabels, o.RemoveLabels)
+}
+
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// ListFilters returns the account's Gmail filters. Filters using criteria
+// Filter doesn't model (size, attachments, chats) are left out.
+func ListFilters(svc *gm.Service) ([]Filter, error) {
+	resp, err := svc.Users.Settings.Filters.List("me").Do()
+	if err != nil {
+		return nil, fmt.Errorf("list filters: %w", err)
+	}
+	var filters []Filter
+	for _, f := range resp.Filter {
+		c, a := f.Criteria, f.Action
+		if c == nil || a == nil || c.Size != 0 || c.HasAttachment || c.ExcludeChats || c.NegatedQuery != "" || a.Forward != "" {
+			continue
+		}
+		filters = append(filters, Filter{
+			ID:           f.Id,
+			From:         c.From,
+			To:           c.To,
+			Subject:      c.Subject,
+			Query:        c.Query,
+			AddLabels:    a.AddLabelIds,
+			RemoveLabels: a.RemoveLabelIds,
+		})
+	}
+	return filters, nil
+}
+
+// CreateFilter creates f and returns its ID. It needs the
+// gmail.settings.basic scope.
+func CreateFilter(svc *gm.Service, f Filter) (string, error) {
+	created, err := svc.Users.Settings.Filters.Create("me", &gm.Filter{
+		Criteria: &gm.FilterCriteria{
+			From:    f.From,
+			To:      f.To,
+			Subject: f.Subject,
+			Query:   f.Query,
+		},
+		Action: &gm.FilterAction{
+			AddLabelIds:    f.AddLabels,
+			RemoveLabelIds: f.RemoveLabels,
+		},
+	}).Do()
+	if err != nil {
+		return "", fmt.Errorf("create filter: %w", err)
+	}
+	return created.Id, nil
+}
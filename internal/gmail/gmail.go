@@ -5,22 +5,38 @@
 package gmail
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 
+	"github.com/daviddao/mailbeads/internal/attachtext"
+	"github.com/daviddao/mailbeads/internal/bulkmail"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	"github.com/daviddao/mailbeads/internal/ical"
 	gm "google.golang.org/api/gmail/v1"
 )
 
-// MessageSummary matches the JSON output of search_emails.py.
+// MessageSummary matches the JSON output of search_emails.py, plus the
+// headers and labels a headers-only sync needs.
 type MessageSummary struct {
-	ID       string `json:"id"`
-	ThreadID string `json:"thread_id"`
-	From     string `json:"from"`
-	To       string `json:"to"`
-	Subject  string `json:"subject"`
-	Date     string `json:"date"`
-	Snippet  string `json:"snippet"`
+	ID        string   `json:"id"`
+	ThreadID  string   `json:"thread_id"`
+	MessageID string   `json:"message_id,omitempty"`
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	CC        string   `json:"cc,omitempty"`
+	Subject   string   `json:"subject"`
+	Date      string   `json:"date"`
+	Snippet   string   `json:"snippet"`
+	Labels    []string `json:"labels,omitempty"`
+	// AuthResults is Gmail's Authentication-Results header (SPF, DKIM,
+	// DMARC verdicts).
+	AuthResults string `json:"authentication_results,omitempty"`
+	// Bulk marks newsletters and list mail (see bulkmail.Detect).
+	Bulk bool `json:"bulk,omitempty"`
 }
 
 // FullMessage matches the JSON output of read_email.py with --format full.
@@ -36,6 +52,16 @@ type FullMessage struct {
 	Body      string   `json:"body"`
 	Labels    []string `json:"labels,omitempty"`
 	Snippet   string   `json:"snippet,omitempty"`
+	ICS       string   `json:"ics,omitempty"`
+	// AttachmentText is the text extracted from PDF, DOCX, and plain-text
+	// attachments, for local search. Only FetchAttachmentText sets it.
+	AttachmentText string `json:"attachment_text,omitempty"`
+	// AuthResults is Gmail's Authentication-Results header.
+	AuthResults string `json:"authentication_results,omitempty"`
+	// Bulk marks newsletters and list mail (see bulkmail.Detect).
+	Bulk bool `json:"bulk,omitempty"`
+
+	payload *gm.MessagePart
 }
 
 // AttachmentInfo holds metadata about a message attachment.
@@ -56,42 +82,132 @@ type FullMessageWithAttachments struct {
 // Search finds messages matching a Gmail query and returns summaries.
 // This replaces search_emails.py.
 func Search(svc *gm.Service, query string, maxResults int64) ([]MessageSummary, error) {
-	resp, err := svc.Users.Messages.List("me").
-		Q(query).
-		MaxResults(maxResults).
-		Do()
-	if err != nil {
-		return nil, fmt.Errorf("list messages: %w", err)
-	}
+	summaries, _, err := SearchPage(svc, query, maxResults, "")
+	return summaries, err
+}
 
-	if len(resp.Messages) == 0 {
-		return nil, nil
+// SearchPage returns one page of up to pageSize messages matching query,
+// starting at pageToken ("" for the first page), and the token for the
+// next page, which is "" on the last one.
+func SearchPage(svc *gm.Service, query string, pageSize int64, pageToken string) ([]MessageSummary, string, error) {
+	refs, next, err := ListPage(svc, query, pageSize, pageToken)
+	if err != nil {
+		return nil, "", err
 	}
-
-	summaries := make([]MessageSummary, 0, len(resp.Messages))
-	for _, msg := range resp.Messages {
-		detail, err := svc.Users.Messages.Get("me", msg.Id).
-			Format("metadata").
-			MetadataHeaders("From", "To", "Subject", "Date").
-			Do()
+	summaries := make([]MessageSummary, 0, len(refs))
+	for _, ref := range refs {
+		s, err := ReadSummary(svc, ref.ID)
 		if err != nil {
 			// Skip individual message failures.
 			continue
 		}
+		summaries = append(summaries, *s)
+	}
+	return summaries, next, nil
+}
+
+// MessageRef identifies a listed message.
+type MessageRef struct {
+	ID       string
+	ThreadID string
+}
+
+// ListPage is SearchPage without reading the messages: one API call for
+// the IDs of the page, newest first.
+func ListPage(svc *gm.Service, query string, pageSize int64, pageToken string) ([]MessageRef, string, error) {
+	call := svc.Users.Messages.List("me").
+		Q(query).
+		MaxResults(pageSize)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	resp, err := call.Do()
+	if err != nil {
+		return nil, "", fmt.Errorf("list messages: %w", err)
+	}
+	refs := make([]MessageRef, len(resp.Messages))
+	for i, msg := range resp.Messages {
+		refs[i] = MessageRef{ID: msg.Id, ThreadID: msg.ThreadId}
+	}
+	return refs, resp.NextPageToken, nil
+}
+
+// summaryHeaders are the headers ReadSummary fetches.
+var summaryHeaders = append([]string{"From", "To", "Cc", "Subject", "Date", "Message-ID", "Authentication-Results"},
+	bulkmail.Headers...)
+
+// ReadSummary reads a message's headers, snippet, and labels.
+func ReadSummary(svc *gm.Service, messageID string) (*MessageSummary, error) {
+	detail, err := svc.Users.Messages.Get("me", messageID).
+		Format("metadata").
+		MetadataHeaders(summaryHeaders...).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	headers := headerMap(detail.Payload.Headers)
+	return &MessageSummary{
+		ID:        detail.Id,
+		ThreadID:  detail.ThreadId,
+		MessageID: headers["Message-ID"],
+		From:      headers["From"],
+		To:        headers["To"],
+		CC:        headers["Cc"],
+		Subject:   defaultStr(headers["Subject"], "(no subject)"),
+		Date:      headers["Date"],
+		Snippet:   detail.Snippet,
+		Labels:    detail.LabelIds,
 
-		headers := headerMap(detail.Payload.Headers)
-		summaries = append(summaries, MessageSummary{
-			ID:       detail.Id,
-			ThreadID: detail.ThreadId,
-			From:     headers["From"],
-			To:       headers["To"],
-			Subject:  defaultStr(headers["Subject"], "(no subject)"),
-			Date:     headers["Date"],
-			Snippet:  detail.Snippet,
-		})
+		AuthResults: authResults(detail.Payload.Headers),
+		Bulk:        isBulk(detail.Payload.Headers),
+	}, nil
+}
+
+// HistoryID returns the mailbox's current history ID. Gmail advances it
+// on every change to the mailbox — new mail, label and read-state
+// changes, deletions — so an unchanged ID means nothing changed.
+func HistoryID(svc *gm.Service) (string, error) {
+	profile, err := svc.Users.GetProfile("me").Do()
+	if err != nil {
+		return "", fmt.Errorf("get profile: %w", err)
 	}
+	return strconv.FormatUint(profile.HistoryId, 10), nil
+}
 
-	return summaries, nil
+// LabelChanges returns the current labels of each message whose labels
+// (including UNREAD) changed since the history ID since, keyed by message
+// ID. Gmail keeps history for about a week; older IDs fail with a 404.
+func LabelChanges(svc *gm.Service, since string) (map[string][]string, error) {
+	start, err := strconv.ParseUint(since, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("bad history ID %q", since)
+	}
+	changes := make(map[string][]string)
+	call := svc.Users.History.List("me").
+		StartHistoryId(start).
+		HistoryTypes("labelAdded", "labelRemoved").
+		MaxResults(500)
+	// Records are oldest first, so the last one seen for a message holds
+	// its current labels.
+	err = call.Pages(context.Background(), func(resp *gm.ListHistoryResponse) error {
+		for _, h := range resp.History {
+			for _, c := range h.LabelsAdded {
+				if c.Message != nil {
+					changes[c.Message.Id] = c.Message.LabelIds
+				}
+			}
+			for _, c := range h.LabelsRemoved {
+				if c.Message != nil {
+					changes[c.Message.Id] = c.Message.LabelIds
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list history: %w", err)
+	}
+	return changes, nil
 }
 
 // ReadFull fetches a complete message by ID, decoding the body.
@@ -103,22 +219,49 @@ func ReadFull(svc *gm.Service, messageID string) (*FullMessage, error) {
 	if err != nil {
 		return nil, fmt.Errorf("get message %s: %w", messageID, err)
 	}
+	return fullMessage(svc, msg), nil
+}
 
-	headers := headerMap(msg.Payload.Headers)
+// RawMessage is a message's original RFC 822 source.
+type RawMessage struct {
+	ID       string `json:"id"`
+	ThreadID string `json:"thread_id"`
+	Raw      string `json:"raw"`
+}
 
-	return &FullMessage{
-		ID:        msg.Id,
-		ThreadID:  msg.ThreadId,
-		MessageID: headers["Message-ID"],
-		From:      headers["From"],
-		To:        headers["To"],
-		CC:        headers["Cc"],
-		Subject:   defaultStr(headers["Subject"], "(no subject)"),
-		Date:      headers["Date"],
-		Body:      extractBody(msg.Payload),
-		Labels:    msg.LabelIds,
-		Snippet:   msg.Snippet,
-	}, nil
+// ReadRaw fetches a message's original MIME source, exactly as Gmail
+// received it, for header forensics or re-ingesting into other tools.
+func ReadRaw(svc *gm.Service, messageID string) (*RawMessage, error) {
+	msg, err := svc.Users.Messages.Get("me", messageID).
+		Format("raw").
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("get message %s: %w", messageID, err)
+	}
+	raw, err := decodeBase64URL(msg.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode message %s: %w", messageID, err)
+	}
+	return &RawMessage{ID: msg.Id, ThreadID: msg.ThreadId, Raw: raw}, nil
+}
+
+// ReadThread fetches every message in a thread, oldest first, in a single
+// Users.Threads.Get call rather than one call per message.
+func ReadThread(svc *gm.Service, threadID string) ([]*FullMessage, error) {
+	thread, err := svc.Users.Threads.Get("me", threadID).
+		Format("full").
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("get thread %s: %w", threadID, err)
+	}
+	messages := make([]*FullMessage, 0, len(thread.Messages))
+	for _, msg := range thread.Messages {
+		if msg.Payload == nil {
+			continue
+		}
+		messages = append(messages, fullMessage(svc, msg))
+	}
+	return messages, nil
 }
 
 // ReadFullWithAttachments fetches a complete message including attachment info.
@@ -130,62 +273,79 @@ func ReadFullWithAttachments(svc *gm.Service, messageID string) (*FullMessageWit
 		return nil, fmt.Errorf("get message %s: %w", messageID, err)
 	}
 
-	headers := headerMap(msg.Payload.Headers)
-
 	return &FullMessageWithAttachments{
-		FullMessage: FullMessage{
-			ID:        msg.Id,
-			ThreadID:  msg.ThreadId,
-			MessageID: headers["Message-ID"],
-			From:      headers["From"],
-			To:        headers["To"],
-			CC:        headers["Cc"],
-			Subject:   defaultStr(headers["Subject"], "(no subject)"),
-			Date:      headers["Date"],
-			Body:      extractBody(msg.Payload),
-			Labels:    msg.LabelIds,
-			Snippet:   msg.Snippet,
-		},
+		FullMessage:  *fullMessage(svc, msg),
 		Attachments:  extractAttachments(msg.Payload),
 		SizeEstimate: msg.SizeEstimate,
 	}, nil
 }
 
+// fullMessage decodes a message fetched in "full" format.
+func fullMessage(svc *gm.Service, msg *gm.Message) *FullMessage {
+	headers := headerMap(msg.Payload.Headers)
+	body := extractBody(msg.Payload)
+
+	return &FullMessage{
+		ID:        msg.Id,
+		ThreadID:  msg.ThreadId,
+		MessageID: headers["Message-ID"],
+		From:      headers["From"],
+		To:        headers["To"],
+		CC:        headers["Cc"],
+		Subject:   defaultStr(headers["Subject"], "(no subject)"),
+		Date:      headers["Date"],
+		Body:      body,
+		Labels:    msg.LabelIds,
+		Snippet:   msg.Snippet,
+		ICS:       extractCalendar(svc, msg.Id, msg.Payload, body),
+
+		AuthResults: authResults(msg.Payload.Headers),
+		Bulk:        isBulk(msg.Payload.Headers),
+		payload:     msg.Payload,
+	}
+}
+
+// FetchAttachmentText sets m.AttachmentText. It downloads the attachments
+// it can read, so reads leave it to sync, which stores the text.
+func FetchAttachmentText(svc *gm.Service, m *FullMessage) {
+	if m.payload != nil {
+		m.AttachmentText = extractAttachmentText(svc, m.ID, m.payload)
+	}
+}
+
 // extractBody gets the plain text body from a message payload.
-// Handles multipart messages recursively, preferring text/plain over text/html.
+// Handles multipart messages recursively, preferring text/plain over
+// text/html. HTML-only messages are converted to plain text.
 func extractBody(payload *gm.MessagePart) string {
-	// Direct body on the payload itself.
+	if text, ok := findPart(payload, "text/plain"); ok {
+		return text
+	}
+	if markup, ok := findPart(payload, "text/html"); ok {
+		return htmltext.Convert(markup)
+	}
+	// Non-multipart message without a declared text type.
 	if payload.Body != nil && payload.Body.Data != "" {
 		if decoded, err := decodeBase64URL(payload.Body.Data); err == nil {
 			return decoded
 		}
 	}
+	return "(No readable body found)"
+}
 
-	// Recurse into parts — prefer text/plain first pass.
-	for _, part := range payload.Parts {
-		if part.MimeType == "text/plain" && part.Body != nil && part.Body.Data != "" {
-			if decoded, err := decodeBase64URL(part.Body.Data); err == nil {
-				return decoded
-			}
-		}
-		// Recurse into nested multipart.
-		if len(part.Parts) > 0 {
-			if body := extractBody(part); body != "" {
-				return body
-			}
+// findPart returns the decoded body of the first inline part with the given
+// MIME type, searching nested multiparts depth-first.
+func findPart(part *gm.MessagePart, mimeType string) (string, bool) {
+	if part.MimeType == mimeType && part.Filename == "" && part.Body != nil && part.Body.Data != "" {
+		if decoded, err := decodeBase64URL(part.Body.Data); err == nil {
+			return decoded, true
 		}
 	}
-
-	// Second pass: fall back to HTML.
-	for _, part := range payload.Parts {
-		if part.MimeType == "text/html" && part.Body != nil && part.Body.Data != "" {
-			if decoded, err := decodeBase64URL(part.Body.Data); err == nil {
-				return "(HTML content)\n" + decoded
-			}
+	for _, child := range part.Parts {
+		if body, ok := findPart(child, mimeType); ok {
+			return body, true
 		}
 	}
-
-	return "(No readable body found)"
+	return "", false
 }
 
 // extractAttachments gets attachment metadata from a message payload.
@@ -218,6 +378,97 @@ func extractAttachments(payload *gm.MessagePart) []AttachmentInfo {
 	return attachments
 }
 
+// extractAttachmentText downloads the attachments attachtext can read (up to
+// attachtext.MaxInput each) and returns their text, one block per file
+// headed by its filename. Attachments that fail to download are skipped.
+func extractAttachmentText(svc *gm.Service, messageID string, payload *gm.MessagePart) string {
+	var blocks []string
+	for _, att := range extractAttachments(payload) {
+		if !attachtext.Supported(att.Filename, att.MimeType) || att.Size > attachtext.MaxInput {
+			continue
+		}
+		var data string
+		if att.AttachmentID != "" {
+			body, err := svc.Users.Messages.Attachments.Get("me", messageID, att.AttachmentID).Do()
+			if err != nil {
+				continue
+			}
+			data = body.Data
+		} else if data = inlineData(payload, att.Filename); data == "" {
+			continue
+		}
+		decoded, err := decodeBase64URL(data)
+		if err != nil {
+			continue
+		}
+		if text := attachtext.Extract(att.Filename, att.MimeType, []byte(decoded)); text != "" {
+			blocks = append(blocks, att.Filename+":\n"+text)
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// inlineData returns the base64url body of the attachment part with the
+// given filename when Gmail sent it inline rather than by attachment ID.
+func inlineData(part *gm.MessagePart, filename string) string {
+	if part.Filename == filename && part.Body != nil && part.Body.Data != "" {
+		return part.Body.Data
+	}
+	for _, child := range part.Parts {
+		if data := inlineData(child, filename); data != "" {
+			return data
+		}
+	}
+	return ""
+}
+
+// ThreadURL returns the Gmail web URL for a thread in the given account.
+// The authuser parameter makes Gmail switch to the right signed-in account.
+func ThreadURL(account, threadID string) string {
+	return fmt.Sprintf("https://mail.google.com/mail/u/?authuser=%s#all/%s",
+		url.QueryEscape(account), threadID)
+}
+
+// extractCalendar returns the iCalendar data of a meeting invite, looking
+// first for text/calendar parts (inline or as .ics attachments) and then for
+// a VCALENDAR block pasted into the body. Returns empty string if none.
+func extractCalendar(svc *gm.Service, messageID string, payload *gm.MessagePart, body string) string {
+	var found string
+
+	var scan func(part *gm.MessagePart)
+	scan = func(part *gm.MessagePart) {
+		if found != "" {
+			return
+		}
+		isCal := part.MimeType == "text/calendar" || part.MimeType == "application/ics" ||
+			strings.HasSuffix(strings.ToLower(part.Filename), ".ics")
+		if isCal && part.Body != nil {
+			switch {
+			case part.Body.Data != "":
+				if decoded, err := decodeBase64URL(part.Body.Data); err == nil {
+					found = decoded
+				}
+			case part.Body.AttachmentId != "":
+				att, err := svc.Users.Messages.Attachments.Get("me", messageID, part.Body.AttachmentId).Do()
+				if err == nil {
+					if decoded, err := decodeBase64URL(att.Data); err == nil {
+						found = decoded
+					}
+				}
+			}
+		}
+		for _, child := range part.Parts {
+			scan(child)
+		}
+	}
+	scan(payload)
+
+	if found == "" {
+		found = ical.Extract(body)
+	}
+	return found
+}
+
 // headerMap converts Gmail API headers into a simple key-value map.
 func headerMap(headers []*gm.MessagePartHeader) map[string]string {
 	m := make(map[string]string, len(headers))
@@ -227,6 +478,38 @@ func headerMap(headers []*gm.MessagePartHeader) map[string]string {
 	return m
 }
 
+// isBulk reports whether a message's headers mark it as bulk mail. Header
+// names are matched case-insensitively, as senders vary their case.
+func isBulk(headers []*gm.MessagePartHeader) bool {
+	return bulkmail.Detect(func(name string) string {
+		for _, h := range headers {
+			if strings.EqualFold(h.Name, name) {
+				return h.Value
+			}
+		}
+		return ""
+	})
+}
+
+// authResults returns the Authentication-Results header Gmail added on
+// receipt. Relays along the way may add their own (and a sender can forge
+// them), so Gmail's, the topmost, is preferred.
+func authResults(headers []*gm.MessagePartHeader) string {
+	first := ""
+	for _, h := range headers {
+		if !strings.EqualFold(h.Name, "Authentication-Results") {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(h.Value), "mx.google.com") {
+			return h.Value
+		}
+		if first == "" {
+			first = h.Value
+		}
+	}
+	return first
+}
+
 // decodeBase64URL decodes Gmail's base64url-encoded content.
 func decodeBase64URL(data string) (string, error) {
 	// Gmail uses URL-safe base64 without padding.
@@ -0,0 +1,54 @@
+package gmail
+
+import (
+	"fmt"
+	"sort"
+
+	gm "google.golang.org/api/gmail/v1"
+)
+
+// Label is a Gmail label with its message and thread counts.
+type Label struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"` // "system" or "user"
+	MessagesTotal  int64  `json:"messages_total"`
+	MessagesUnread int64  `json:"messages_unread"`
+	ThreadsTotal   int64  `json:"threads_total"`
+	ThreadsUnread  int64  `json:"threads_unread"`
+}
+
+// ListLabels returns the account's labels, system labels first, then user
+// labels by name. Users.Labels.List omits counts, so each label is fetched
+// with Users.Labels.Get (1 quota unit each).
+func ListLabels(svc *gm.Service) ([]Label, error) {
+	resp, err := svc.Users.Labels.List("me").Do()
+	if err != nil {
+		return nil, fmt.Errorf("list labels: %w", err)
+	}
+
+	labels := make([]Label, 0, len(resp.Labels))
+	for _, l := range resp.Labels {
+		full, err := svc.Users.Labels.Get("me", l.Id).Do()
+		if err != nil {
+			return nil, fmt.Errorf("get label %s: %w", l.Name, err)
+		}
+		labels = append(labels, Label{
+			ID:             full.Id,
+			Name:           full.Name,
+			Type:           full.Type,
+			MessagesTotal:  full.MessagesTotal,
+			MessagesUnread: full.MessagesUnread,
+			ThreadsTotal:   full.ThreadsTotal,
+			ThreadsUnread:  full.ThreadsUnread,
+		})
+	}
+
+	sort.SliceStable(labels, func(i, j int) bool {
+		if labels[i].Type != labels[j].Type {
+			return labels[i].Type == "system"
+		}
+		return labels[i].Name < labels[j].Name
+	})
+	return labels, nil
+}
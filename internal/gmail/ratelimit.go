@@ -0,0 +1,206 @@
+package gmail
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Request pacing and retry policy for Gmail API calls. Gmail allows 250
+// quota units per user per second and messages.get costs 5, so 40 requests
+// a second stays under the limit with headroom for other clients.
+const (
+	DefaultRequestsPerSecond = 40
+	maxRetries               = 6
+	baseBackoff              = 500 * time.Millisecond
+	maxBackoff               = 32 * time.Second
+)
+
+// limiter is shared by every Gmail client in the process, so parallel work
+// across accounts and commands can't exceed the rate together.
+var limiter = newRateLimiter(DefaultRequestsPerSecond)
+
+// SetRequestsPerSecond changes the shared request rate. n <= 0 disables
+// pacing; retries on rate-limit errors still apply.
+func SetRequestsPerSecond(n float64) {
+	limiter.setRate(n)
+}
+
+// rateLimiter spaces requests evenly at a fixed rate. When Gmail reports a
+// rate limit, pause holds back every caller, not just the one that hit it.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	l := &rateLimiter{}
+	l.setRate(perSecond)
+	return l
+}
+
+func (l *rateLimiter) setRate(perSecond float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.interval = 0
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+}
+
+// reserve returns how long the caller must wait before its request.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	return wait
+}
+
+// pause delays all requests until at least d from now.
+func (l *rateLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.next) {
+		l.next = until
+	}
+}
+
+// RetryTransport wraps an HTTP transport for the Gmail API with the shared
+// rate limiter and retries with exponential backoff and jitter. Rate-limit
+// responses (429, and 403 with a rateLimitExceeded reason) are retried for
+// every request; 5xx responses only for requests that are safe to repeat,
+// so a send that may have gone through is never sent twice.
+type RetryTransport struct {
+	Base http.RoundTripper
+}
+
+// NewRetryTransport returns base wrapped in a RetryTransport. A nil base
+// uses http.DefaultTransport.
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if err := sleep(ctx, limiter.reserve()); err != nil {
+			return nil, err
+		}
+
+		r := req
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+		resp, err := t.Base.RoundTrip(r)
+
+		retry, rateLimited := shouldRetry(req, resp, err)
+		if !retry || attempt == maxRetries || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		delay := backoff(attempt)
+		if resp != nil {
+			if ra := retryAfter(resp.Header.Get("Retry-After")); ra > delay {
+				delay = ra
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if rateLimited {
+			limiter.pause(delay)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry reports whether a request should be retried and whether the
+// failure was a rate limit. A 403 body is read to tell rate limits from
+// permission errors, and restored for the caller.
+func shouldRetry(req *http.Request, resp *http.Response, err error) (retry, rateLimited bool) {
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+	if err != nil {
+		// Connection failures: the request may have reached Gmail.
+		return idempotent && req.Context().Err() == nil, false
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true, true
+	case resp.StatusCode == http.StatusForbidden:
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr == nil && (bytes.Contains(body, []byte("rateLimitExceeded")) ||
+			bytes.Contains(body, []byte("userRateLimitExceeded")) ||
+			bytes.Contains(body, []byte("quotaExceeded"))) {
+			return true, true
+		}
+		return false, false
+	case resp.StatusCode >= 500:
+		return idempotent, false
+	}
+	return false, false
+}
+
+// backoff returns the delay before retry attempt+1: between half and all of
+// baseBackoff·2^attempt, capped at maxBackoff. The jitter keeps clients that
+// were throttled together from retrying in lockstep.
+func backoff(attempt int) time.Duration {
+	ceiling := baseBackoff << attempt
+	if ceiling > maxBackoff || ceiling <= 0 {
+		ceiling = maxBackoff
+	}
+	return ceiling/2 + rand.N(ceiling/2)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(v string) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// sleep waits for d, returning ctx's error early if it is canceled.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
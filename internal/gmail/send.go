@@ -0,0 +1,124 @@
+package gmail
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+
+	gm "google.golang.org/api/gmail/v1"
+)
+
+// Send sends a plain-text email from the authenticated account. Extra headers
+// (e.g. In-Reply-To) are added verbatim. Returns the sent message ID.
+func Send(svc *gm.Service, from, to, subject, body string, extra map[string]string) (string, error) {
+	raw, err := buildRaw(from, to, subject, `text/plain; charset="UTF-8"`, body, extra)
+	if err != nil {
+		return "", err
+	}
+	sent, err := svc.Users.Messages.Send("me", &gm.Message{Raw: raw}).Do()
+	if err != nil {
+		return "", fmt.Errorf("send message: %w", err)
+	}
+	return sent.Id, nil
+}
+
+// SendReply sends a plain-text reply into threadID. Pass In-Reply-To and
+// References in extra so recipients' clients thread it too. Returns the sent
+// message ID.
+func SendReply(svc *gm.Service, threadID, from, to, subject, body string, extra map[string]string) (string, error) {
+	raw, err := buildRaw(from, to, subject, `text/plain; charset="UTF-8"`, body, extra)
+	if err != nil {
+		return "", err
+	}
+	sent, err := svc.Users.Messages.Send("me", &gm.Message{Raw: raw, ThreadId: threadID}).Do()
+	if err != nil {
+		return "", fmt.Errorf("send reply: %w", err)
+	}
+	return sent.Id, nil
+}
+
+// CreateReplyDraft saves a plain-text reply into threadID as a Gmail draft
+// without sending it. Returns the draft ID.
+func CreateReplyDraft(svc *gm.Service, threadID, from, to, subject, body string, extra map[string]string) (string, error) {
+	raw, err := buildRaw(from, to, subject, `text/plain; charset="UTF-8"`, body, extra)
+	if err != nil {
+		return "", err
+	}
+	draft, err := svc.Users.Drafts.Create("me", &gm.Draft{
+		Message: &gm.Message{Raw: raw, ThreadId: threadID},
+	}).Do()
+	if err != nil {
+		return "", fmt.Errorf("create draft: %w", err)
+	}
+	return draft.Id, nil
+}
+
+// SendCalendarReply sends an iTIP reply (multipart/alternative with a
+// text/plain summary and a text/calendar; method=REPLY part) into threadID.
+// Returns the sent message ID.
+func SendCalendarReply(svc *gm.Service, threadID, from, to, subject, body, ics string, extra map[string]string) (string, error) {
+	const boundary = "mailbeads-itip-reply"
+	var mp strings.Builder
+	fmt.Fprintf(&mp, "--%s\r\n", boundary)
+	fmt.Fprintf(&mp, "Content-Type: text/plain; charset=\"UTF-8\"\r\n\r\n%s\r\n", body)
+	fmt.Fprintf(&mp, "--%s\r\n", boundary)
+	fmt.Fprintf(&mp, "Content-Type: text/calendar; charset=\"UTF-8\"; method=REPLY\r\n\r\n%s\r\n", ics)
+	fmt.Fprintf(&mp, "--%s--\r\n", boundary)
+
+	contentType := fmt.Sprintf(`multipart/alternative; boundary="%s"`, boundary)
+	raw, err := buildRaw(from, to, subject, contentType, mp.String(), extra)
+	if err != nil {
+		return "", err
+	}
+	sent, err := svc.Users.Messages.Send("me", &gm.Message{Raw: raw, ThreadId: threadID}).Do()
+	if err != nil {
+		return "", fmt.Errorf("send calendar reply: %w", err)
+	}
+	return sent.Id, nil
+}
+
+// buildRaw assembles an RFC 822 message and encodes it as base64url, the
+// format expected by the Gmail API's raw field. Header values containing
+// a line break are refused, since they could add headers of their own (a
+// Bcc, say); a non-ASCII subject is RFC 2047-encoded.
+func buildRaw(from, to, subject, contentType, body string, extra map[string]string) (string, error) {
+	// Sort extra headers so output is deterministic.
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	headers := [][2]string{}
+	if from != "" {
+		headers = append(headers, [2]string{"From", from})
+	}
+	headers = append(headers,
+		[2]string{"To", to},
+		[2]string{"Subject", subject},
+		[2]string{"MIME-Version", "1.0"},
+		[2]string{"Content-Type", contentType},
+	)
+	for _, k := range keys {
+		headers = append(headers, [2]string{k, extra[k]})
+	}
+
+	var b strings.Builder
+	for _, h := range headers {
+		name, value := h[0], h[1]
+		if strings.ContainsAny(name+value, "\r\n") {
+			return "", fmt.Errorf("%s header contains a line break", name)
+		}
+		if name == "Subject" {
+			value = mime.QEncoding.Encode("utf-8", value)
+		}
+		fmt.Fprintf(&b, "%s: %s\r\n", name, value)
+	}
+
+	b.WriteString("\r\n")
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return base64.URLEncoding.EncodeToString([]byte(b.String())), nil
+}
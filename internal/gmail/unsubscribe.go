@@ -0,0 +1,118 @@
+package gmail
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	gm "google.golang.org/api/gmail/v1"
+)
+
+// UnsubscribeInfo holds the parsed List-Unsubscribe options of a message.
+// HTTPS is the web link, which is plain http only when the header offers
+// no https one; OneClick is never set for such a link.
+type UnsubscribeInfo struct {
+	MessageID string `json:"message_id"`
+	HTTPS     string `json:"https,omitempty"`
+	Mailto    string `json:"mailto,omitempty"`
+	OneClick  bool   `json:"one_click"`
+}
+
+// Available reports whether the message offers any unsubscribe method.
+func (u *UnsubscribeInfo) Available() bool {
+	return u.HTTPS != "" || u.Mailto != ""
+}
+
+// GetUnsubscribe fetches a message's List-Unsubscribe headers and parses them.
+func GetUnsubscribe(svc *gm.Service, messageID string) (*UnsubscribeInfo, error) {
+	msg, err := svc.Users.Messages.Get("me", messageID).
+		Format("metadata").
+		MetadataHeaders("List-Unsubscribe", "List-Unsubscribe-Post").
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("get message %s: %w", messageID, err)
+	}
+	headers := headerMap(msg.Payload.Headers)
+	info := ParseListUnsubscribe(headers["List-Unsubscribe"], headers["List-Unsubscribe-Post"])
+	info.MessageID = messageID
+	return info, nil
+}
+
+// ParseListUnsubscribe parses a List-Unsubscribe header (RFC 2369) and the
+// optional List-Unsubscribe-Post header (RFC 8058). The header is a comma
+// separated list of <URI> entries; the first https and mailto URIs win. An
+// http URI is kept only if there is no https one, and only as a link to
+// open in a browser: mb never POSTs a one-click unsubscribe in the clear.
+func ParseListUnsubscribe(header, post string) *UnsubscribeInfo {
+	info := &UnsubscribeInfo{}
+	for _, part := range strings.Split(header, ",") {
+		uri := strings.TrimSpace(part)
+		uri = strings.TrimPrefix(uri, "<")
+		uri = strings.TrimSuffix(uri, ">")
+		lower := strings.ToLower(uri)
+		switch {
+		case strings.HasPrefix(lower, "https:") && !isHTTPS(info.HTTPS):
+			info.HTTPS = uri
+		case strings.HasPrefix(lower, "http:") && info.HTTPS == "":
+			info.HTTPS = uri
+		case strings.HasPrefix(lower, "mailto:") && info.Mailto == "":
+			info.Mailto = uri
+		}
+	}
+	info.OneClick = isHTTPS(info.HTTPS) &&
+		strings.Contains(strings.ToLower(post), "list-unsubscribe=one-click")
+	return info
+}
+
+func isHTTPS(uri string) bool {
+	return strings.HasPrefix(strings.ToLower(uri), "https:")
+}
+
+// OneClickUnsubscribe performs an RFC 8058 one-click unsubscribe POST.
+func OneClickUnsubscribe(target string) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.PostForm(target, url.Values{"List-Unsubscribe": {"One-Click"}})
+	if err != nil {
+		return fmt.Errorf("post %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("post %s: %s", target, resp.Status)
+	}
+	return nil
+}
+
+// SendMailtoUnsubscribe sends the unsubscribe email described by a mailto:
+// URI (address plus optional subject and body query parameters) from the
+// authenticated account. The URI comes from the sender, so it must name
+// exactly one recipient.
+func SendMailtoUnsubscribe(svc *gm.Service, from, mailto string) error {
+	u, err := url.Parse(mailto)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", mailto, err)
+	}
+	to := u.Opaque
+	if to == "" {
+		to = u.Path
+	}
+	if to, err = url.PathUnescape(to); err != nil {
+		return fmt.Errorf("parse %s: %w", mailto, err)
+	}
+	addrs, err := mail.ParseAddressList(to)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", mailto, err)
+	}
+	if len(addrs) != 1 {
+		return fmt.Errorf("%s names %d recipients; unsubscribing sends to one only", mailto, len(addrs))
+	}
+	to = addrs[0].Address
+	q := u.Query()
+	subject := defaultStr(q.Get("subject"), "unsubscribe")
+	body := defaultStr(q.Get("body"), "unsubscribe")
+
+	_, err = Send(svc, from, to, subject, body, nil)
+	return err
+}
@@ -0,0 +1,88 @@
+// Package hooks runs the user's lifecycle hook scripts from
+// .mailbeads/hooks/, so automations (notify a channel, log to a
+// spreadsheet) can react to mb without forking it.
+//
+// A hook is an executable named after its event. It gets the event's JSON
+// payload on stdin and MAILBEADS_HOOK set to the event name; its output
+// goes to mb's stderr, keeping --json output clean. A missing hook is
+// skipped.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// DirName is the hooks directory inside .mailbeads.
+const DirName = "hooks"
+
+// Events with a hook.
+const (
+	// PostSync runs after mb sync (and each mb watch poll) with the sync
+	// summary.
+	PostSync = "post-sync"
+	// PreTriage runs before a thread is triaged, with the decision. A
+	// non-zero exit rejects it.
+	PreTriage = "pre-triage"
+	// PostDone runs after a bead is closed by mb done, mb dismiss, or
+	// mb unsubscribe --dismiss.
+	PostDone = "post-done"
+)
+
+// Timeout bounds a hook's run time, so a hung script can't wedge mb watch.
+const Timeout = time.Minute
+
+// Path returns the path of an event's hook in a .mailbeads directory.
+func Path(dir, event string) string {
+	return filepath.Join(dir, DirName, event)
+}
+
+// Run runs an event's hook, if the .mailbeads directory dir has one, with
+// payload as JSON on stdin, in the project root. It fails if the hook
+// exits non-zero.
+func Run(dir, event string, payload any) error {
+	if dir == "" {
+		return nil
+	}
+	path := Path(dir, event)
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s hook: %w", event, err)
+	}
+	if info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+		return fmt.Errorf("%s hook: %s is not executable", event, path)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s hook: encode payload: %w", event, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = filepath.Dir(dir)
+	cmd.Env = append(os.Environ(), "MAILBEADS_HOOK="+event, "MAILBEADS_DIR="+dir)
+	cmd.Stdin = bytes.NewReader(append(data, '\n'))
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s hook: timed out after %s", event, Timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s hook: %w", event, err)
+	}
+	return nil
+}
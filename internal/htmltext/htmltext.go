@@ -0,0 +1,264 @@
+// Package htmltext renders HTML email bodies as readable plain text.
+//
+// Block elements become line breaks, links keep their target as
+// "text (url)", list items get bullets, and tables are flattened to one
+// line per row with cells separated by " | ". Scripts, styles, and other
+// non-visible content are dropped.
+package htmltext
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// LegacyPrefix marks bodies stored by older versions of mb, which kept raw
+// HTML when a message had no text/plain part.
+const LegacyPrefix = "(HTML content)\n"
+
+// Convert renders an HTML document or fragment as plain text. If the input
+// cannot be parsed it is returned unchanged.
+func Convert(src string) string {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return src
+	}
+	r := &renderer{}
+	r.walk(doc)
+	return tidy(string(r.b))
+}
+
+// Readable returns body as plain text, converting legacy raw-HTML bodies.
+func Readable(body string) string {
+	if rest, ok := strings.CutPrefix(body, LegacyPrefix); ok {
+		return Convert(rest)
+	}
+	return body
+}
+
+type renderer struct {
+	b     []byte // output so far, trimmed by reslicing
+	pre   int    // depth inside <pre>
+	lists []int  // per open list: -1 for <ul>, else next <ol> number
+	cell  bool   // a cell was already written in the current table row
+}
+
+// skipped elements never contribute visible text.
+var skipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Title: true,
+	atom.Noscript: true, atom.Template: true, atom.Svg: true, atom.Iframe: true,
+}
+
+// blocks start and end on their own line.
+var blocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Header: true, atom.Footer: true, atom.Blockquote: true, atom.Pre: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Table: true, atom.Center: true,
+	atom.Address: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Form: true, atom.Fieldset: true, atom.Main: true, atom.Nav: true,
+}
+
+func (r *renderer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+		return
+	case html.ElementNode:
+		if skipped[n.DataAtom] {
+			return
+		}
+	case html.CommentNode, html.DoctypeNode:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		r.write("\n")
+		return
+	case atom.Hr:
+		r.block()
+		r.write("---\n")
+		return
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			r.text("[" + alt + "]")
+		}
+		return
+	case atom.A:
+		r.link(n)
+		return
+	case atom.Li:
+		r.item(n)
+		return
+	case atom.Tr:
+		r.block()
+		r.cell = false
+		r.children(n)
+		r.write("\n")
+		return
+	case atom.Td, atom.Th:
+		if r.cell {
+			r.write(" | ")
+		}
+		r.cell = true
+		r.write(strings.Join(strings.Fields(r.inline(n)), " "))
+		return
+	case atom.Ul:
+		r.lists = append(r.lists, -1)
+		defer func() { r.lists = r.lists[:len(r.lists)-1] }()
+	case atom.Ol:
+		r.lists = append(r.lists, 1)
+		defer func() { r.lists = r.lists[:len(r.lists)-1] }()
+	case atom.Pre:
+		r.pre++
+		defer func() { r.pre-- }()
+	}
+
+	if n.Type == html.ElementNode && blocks[n.DataAtom] {
+		r.block()
+		if n.DataAtom == atom.Blockquote {
+			start := len(r.b)
+			r.children(n)
+			quoted := quote(string(r.b[start:]))
+			r.b = append(r.b[:start], quoted...)
+		} else {
+			r.children(n)
+		}
+		r.block()
+		if n.DataAtom >= atom.H1 && n.DataAtom <= atom.H6 || n.DataAtom == atom.P {
+			r.write("\n")
+		}
+		return
+	}
+	r.children(n)
+}
+
+func (r *renderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c)
+	}
+}
+
+// text writes a text node, collapsing whitespace outside <pre>.
+func (r *renderer) text(s string) {
+	if r.pre > 0 {
+		r.write(s)
+		return
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" && !r.atSpace() {
+			r.write(" ")
+		}
+		return
+	}
+	if startsWithSpace(s) && !r.atSpace() {
+		r.write(" ")
+	}
+	r.write(strings.Join(fields, " "))
+	if endsWithSpace(s) {
+		r.write(" ")
+	}
+}
+
+// link writes an anchor as "text (url)", or just the text when the URL adds
+// nothing (same as the text, in-page anchors, javascript:).
+func (r *renderer) link(n *html.Node) {
+	text := strings.Join(strings.Fields(r.inline(n)), " ")
+	href := strings.TrimSpace(attr(n, "href"))
+	target := strings.TrimPrefix(href, "mailto:")
+	switch {
+	case href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:"):
+		r.text(text)
+	case text == "" || text == href || text == target:
+		r.text(target)
+	default:
+		r.text(text + " (" + target + ")")
+	}
+}
+
+// item writes a list item with a bullet or number.
+func (r *renderer) item(n *html.Node) {
+	r.block()
+	depth := len(r.lists)
+	if depth > 1 {
+		r.write(strings.Repeat("  ", depth-1))
+	}
+	if depth > 0 && r.lists[depth-1] > 0 {
+		r.write(strconv.Itoa(r.lists[depth-1]) + ". ")
+		r.lists[depth-1]++
+	} else {
+		r.write("- ")
+	}
+	r.children(n)
+	r.block()
+}
+
+// inline renders n's children into a separate buffer and returns the text.
+func (r *renderer) inline(n *html.Node) string {
+	sub := &renderer{pre: r.pre, lists: r.lists}
+	sub.children(n)
+	return string(sub.b)
+}
+
+// block ensures the output ends at the start of a line.
+func (r *renderer) block() {
+	if len(r.b) == 0 || r.b[len(r.b)-1] == '\n' {
+		return
+	}
+	r.b = bytes.TrimRight(r.b, " ")
+	r.write("\n")
+}
+
+func (r *renderer) write(s string) {
+	r.b = append(r.b, s...)
+}
+
+func (r *renderer) atSpace() bool {
+	return len(r.b) == 0 || strings.IndexByte(" \t\n\r\f", r.b[len(r.b)-1]) >= 0
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// quote prefixes each line of s with "> ".
+func quote(s string) string {
+	lines := strings.Split(strings.Trim(s, "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = "> " + l
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func startsWithSpace(s string) bool {
+	return s != "" && strings.ContainsRune(" \t\n\r\f", rune(s[0]))
+}
+
+func endsWithSpace(s string) bool {
+	return s != "" && strings.ContainsRune(" \t\n\r\f", rune(s[len(s)-1]))
+}
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// tidy trims trailing spaces on each line and collapses runs of blank lines.
+func tidy(s string) string {
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	s = strings.Join(lines, "\n")
+	s = blankLines.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
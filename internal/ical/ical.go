@@ -0,0 +1,367 @@
+// Package ical parses the subset of iCalendar (RFC 5545) found in email
+// meeting invites: VEVENT components with their times, organizer, and
+// attendees. It also encodes events into a calendar feed.
+package ical
+
+import (
+	"strings"
+	"time"
+)
+
+// Calendar is a parsed VCALENDAR object.
+type Calendar struct {
+	Method string  `json:"method,omitempty"`
+	Events []Event `json:"events"`
+}
+
+// Person is an organizer or attendee.
+type Person struct {
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email"`
+	PartStat string `json:"partstat,omitempty"`
+}
+
+// Event is a parsed VEVENT.
+type Event struct {
+	UID         string    `json:"uid"`
+	Sequence    string    `json:"sequence,omitempty"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end,omitempty"`
+	AllDay      bool      `json:"all_day,omitempty"`
+	Organizer   Person    `json:"organizer"`
+	Attendees   []Person  `json:"attendees,omitempty"`
+}
+
+// property is one content line: NAME;PARAM=VALUE:value
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Extract returns the first BEGIN:VCALENDAR..END:VCALENDAR block embedded in
+// text, or empty string if there is none.
+func Extract(text string) string {
+	start := strings.Index(text, "BEGIN:VCALENDAR")
+	if start < 0 {
+		return ""
+	}
+	end := strings.Index(text[start:], "END:VCALENDAR")
+	if end < 0 {
+		return ""
+	}
+	return text[start : start+end+len("END:VCALENDAR")]
+}
+
+// Parse parses iCalendar text. Unknown properties and components are ignored.
+func Parse(data string) *Calendar {
+	cal := &Calendar{}
+	var cur *Event
+	depth := 0 // nesting inside VEVENT (e.g. VALARM)
+
+	for _, line := range unfold(data) {
+		p, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT"):
+			cur = &Event{}
+			depth = 0
+			continue
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT"):
+			if cur != nil {
+				cal.Events = append(cal.Events, *cur)
+			}
+			cur = nil
+			continue
+		case p.name == "BEGIN" && cur != nil:
+			depth++
+			continue
+		case p.name == "END" && cur != nil:
+			depth--
+			continue
+		}
+
+		if cur == nil {
+			if p.name == "METHOD" {
+				cal.Method = strings.ToUpper(p.value)
+			}
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+
+		switch p.name {
+		case "UID":
+			cur.UID = p.value
+		case "SEQUENCE":
+			cur.Sequence = p.value
+		case "SUMMARY":
+			cur.Summary = unescape(p.value)
+		case "DESCRIPTION":
+			cur.Description = unescape(p.value)
+		case "LOCATION":
+			cur.Location = unescape(p.value)
+		case "STATUS":
+			cur.Status = strings.ToUpper(p.value)
+		case "DTSTART":
+			cur.Start, cur.AllDay = parseTime(p)
+		case "DTEND":
+			cur.End, _ = parseTime(p)
+		case "ORGANIZER":
+			cur.Organizer = parsePerson(p)
+		case "ATTENDEE":
+			cur.Attendees = append(cur.Attendees, parsePerson(p))
+		}
+	}
+	return cal
+}
+
+// unfold joins continuation lines (lines starting with a space or tab).
+func unfold(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var lines []string
+	for _, raw := range strings.Split(data, "\n") {
+		if len(raw) > 0 && (raw[0] == ' ' || raw[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += raw[1:]
+			continue
+		}
+		lines = append(lines, raw)
+	}
+	return lines
+}
+
+// parseLine splits a content line into name, parameters, and value.
+func parseLine(line string) (property, bool) {
+	// The value starts at the first colon outside a quoted parameter value.
+	inQuote := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuote = !inQuote
+		} else if r == ':' && !inQuote {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return property{}, false
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := strings.Split(head, ";")
+	p := property{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string),
+		value:  value,
+	}
+	for _, param := range parts[1:] {
+		if k, v, ok := strings.Cut(param, "="); ok {
+			p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return p, true
+}
+
+// parseTime parses DTSTART/DTEND in UTC, floating, TZID, or DATE form.
+func parseTime(p property) (time.Time, bool) {
+	if p.params["VALUE"] == "DATE" || len(p.value) == 8 {
+		t, err := time.ParseInLocation("20060102", p.value, time.Local)
+		if err != nil {
+			return time.Time{}, true
+		}
+		return t, true
+	}
+
+	loc := time.Local
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	if strings.HasSuffix(p.value, "Z") {
+		if t, err := time.Parse("20060102T150405Z", p.value); err == nil {
+			return t, false
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", p.value, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, false
+}
+
+// parsePerson parses an ORGANIZER or ATTENDEE property.
+func parsePerson(p property) Person {
+	email := p.value
+	if len(email) >= 7 && strings.EqualFold(email[:7], "mailto:") {
+		email = email[7:]
+	}
+	return Person{
+		Name:     p.params["CN"],
+		Email:    strings.ToLower(email),
+		PartStat: strings.ToUpper(p.params["PARTSTAT"]),
+	}
+}
+
+// unescape reverses TEXT value escaping (\n, \, \; \\).
+func unescape(s string) string {
+	r := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return r.Replace(s)
+}
+
+// replyProps are the VEVENT properties copied verbatim into an iTIP REPLY
+// (RFC 5546 §3.2.3) so the organizer's client can match it to the event.
+var replyProps = map[string]bool{
+	"UID": true, "SEQUENCE": true, "RECURRENCE-ID": true, "DTSTART": true,
+	"DTEND": true, "SUMMARY": true, "ORGANIZER": true,
+}
+
+// Reply builds an iTIP METHOD:REPLY calendar answering the event with the
+// given UID in original on behalf of attendee. partstat is ACCEPTED,
+// DECLINED, or TENTATIVE. Returns empty string if the UID is not found.
+func Reply(original, uid, attendee, name, partstat string) string {
+	var event []string
+	inEvent, depth, matched := false, 0, false
+
+scan:
+	for _, line := range unfold(original) {
+		p, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT"):
+			inEvent, depth, event = true, 0, nil
+			continue
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT"):
+			if matched {
+				break scan
+			}
+			inEvent = false
+			continue
+		case p.name == "BEGIN" && inEvent:
+			depth++
+			continue
+		case p.name == "END" && inEvent:
+			depth--
+			continue
+		}
+		if !inEvent || depth > 0 {
+			continue
+		}
+		if p.name == "UID" && p.value == uid {
+			matched = true
+		}
+		if replyProps[p.name] {
+			event = append(event, line)
+		}
+	}
+	if !matched {
+		return ""
+	}
+
+	cn := ""
+	if name != "" {
+		cn = `;CN="` + name + `"`
+	}
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"PRODID:-//mailbeads//mb//EN",
+		"VERSION:2.0",
+		"METHOD:REPLY",
+		"BEGIN:VEVENT",
+	}
+	lines = append(lines, event...)
+	lines = append(lines,
+		"DTSTAMP:"+time.Now().UTC().Format("20060102T150405Z"),
+		"ATTENDEE;PARTSTAT="+partstat+cn+":mailto:"+attendee,
+		"END:VEVENT",
+		"END:VCALENDAR",
+	)
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// Encode renders events as a VCALENDAR object titled name, for calendar
+// apps to import or subscribe to. stamp is every event's DTSTAMP; passing
+// the time the underlying data last changed keeps the output identical
+// until it does.
+func Encode(name string, stamp time.Time, events []Event) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"PRODID:-//mailbeads//mb//EN",
+		"VERSION:2.0",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escape(name),
+	}
+	for _, e := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+e.UID,
+			"DTSTAMP:"+stamp.UTC().Format("20060102T150405Z"),
+		)
+		if e.AllDay {
+			end := e.End
+			if end.IsZero() {
+				end = e.Start.AddDate(0, 0, 1)
+			}
+			lines = append(lines,
+				"DTSTART;VALUE=DATE:"+e.Start.Format("20060102"),
+				"DTEND;VALUE=DATE:"+end.Format("20060102"))
+		} else {
+			lines = append(lines, "DTSTART:"+e.Start.UTC().Format("20060102T150405Z"))
+			if !e.End.IsZero() {
+				lines = append(lines, "DTEND:"+e.End.UTC().Format("20060102T150405Z"))
+			}
+		}
+		lines = append(lines, "SUMMARY:"+escape(e.Summary))
+		if e.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escape(e.Description))
+		}
+		if e.Location != "" {
+			lines = append(lines, "LOCATION:"+escape(e.Location))
+		}
+		if e.Status != "" {
+			lines = append(lines, "STATUS:"+e.Status)
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(fold(line))
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+// escape escapes a TEXT value, the reverse of unescape.
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// fold splits a content line into lines of at most 75 octets, without
+// breaking a UTF-8 sequence; continuation lines start with a space.
+func fold(line string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range line {
+		size := len(string(r))
+		if n+size > 75 {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
@@ -0,0 +1,183 @@
+// Package links finds URLs in plain-text email bodies and classifies them
+// by what they point at (documents, pull requests, calendars, unsubscribe
+// pages), so agents can pick out "the doc linked in that thread" directly.
+package links
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// Kinds returned by Classify.
+const (
+	KindDoc         = "doc"
+	KindPR          = "pr"
+	KindIssue       = "issue"
+	KindCalendar    = "calendar"
+	KindMeeting     = "meeting"
+	KindUnsubscribe = "unsubscribe"
+	KindOther       = "other"
+)
+
+var (
+	urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"'\x60{}|\\^]+`)
+
+	prPath    = regexp.MustCompile(`/(pull|pulls|merge_requests|pull-requests)/\d+`)
+	issuePath = regexp.MustCompile(`/(issues/\d+|browse/[A-Z][A-Z0-9]+-\d+)`)
+
+	githubCode = regexp.MustCompile(`^/([^/]+/[^/]+)/(pull|issues)/(\d+)(?:/|$)`)
+	gitlabCode = regexp.MustCompile(`^/(.+?)(?:/-)?/(merge_requests|issues)/(\d+)(?:/|$)`)
+)
+
+// Extract returns the URLs in text in order of appearance, with trailing
+// punctuation and unbalanced closing brackets trimmed. Duplicates are kept.
+func Extract(text string) []string {
+	var urls []string
+	for _, raw := range urlPattern.FindAllString(text, -1) {
+		u := trimURL(raw)
+		if parsed, err := url.Parse(u); err != nil || parsed.Host == "" {
+			continue
+		}
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// trimURL drops sentence punctuation and closing brackets that belong to the
+// surrounding text rather than the URL, e.g. "see (https://x.io/a)." .
+func trimURL(u string) string {
+	for len(u) > 0 {
+		last := u[len(u)-1]
+		switch {
+		case strings.IndexByte(".,;:!?*_~", last) >= 0:
+			u = u[:len(u)-1]
+		case last == ')' && strings.Count(u, "(") < strings.Count(u, ")"):
+			u = u[:len(u)-1]
+		case last == ']' && strings.Count(u, "[") < strings.Count(u, "]"):
+			u = u[:len(u)-1]
+		default:
+			return u
+		}
+	}
+	return u
+}
+
+// Normalize returns the canonical form used to dedupe links: utm_* tracking
+// parameters removed, host lowercased, and a bare trailing slash dropped.
+func Normalize(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.Host = strings.ToLower(u.Host)
+	if u.RawQuery != "" {
+		q := u.Query()
+		changed := false
+		for k := range q {
+			if strings.HasPrefix(strings.ToLower(k), "utm_") {
+				q.Del(k)
+				changed = true
+			}
+		}
+		if changed {
+			u.RawQuery = q.Encode()
+		}
+	}
+	if u.Path == "/" && u.RawQuery == "" && u.Fragment == "" {
+		u.Path = ""
+	}
+	return u.String()
+}
+
+// Classify returns the kind of resource a URL points at.
+func Classify(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return KindOther
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	p := u.Path
+	lower := strings.ToLower(raw)
+
+	switch {
+	case strings.Contains(lower, "unsubscribe") || strings.Contains(lower, "optout") ||
+		strings.Contains(lower, "opt-out") || strings.Contains(lower, "email-preferences") ||
+		strings.Contains(lower, "manage-preferences"):
+		return KindUnsubscribe
+
+	case host == "calendar.google.com" || (strings.HasPrefix(host, "outlook.") && strings.HasPrefix(p, "/calendar/")) ||
+		host == "cal.com" || host == "calendly.com" || strings.HasSuffix(strings.ToLower(p), ".ics"):
+		return KindCalendar
+
+	case host == "meet.google.com" || strings.HasSuffix(host, "zoom.us") ||
+		host == "teams.microsoft.com" || host == "teams.live.com" || strings.HasSuffix(host, "webex.com"):
+		return KindMeeting
+
+	case (host == "github.com" || strings.Contains(host, "gitlab") || host == "bitbucket.org") && prPath.MatchString(p):
+		return KindPR
+
+	case (host == "github.com" || strings.Contains(host, "gitlab")) && issuePath.MatchString(p),
+		host == "linear.app" && strings.Contains(p, "/issue/"),
+		strings.HasSuffix(host, "atlassian.net") && issuePath.MatchString(p):
+		return KindIssue
+
+	case host == "docs.google.com" || host == "drive.google.com" || host == "notion.so" ||
+		strings.HasSuffix(host, ".notion.site") || host == "dropbox.com" || host == "paper.dropbox.com" ||
+		strings.HasSuffix(host, ".sharepoint.com") || host == "onedrive.live.com" || host == "1drv.ms" ||
+		host == "figma.com" || host == "quip.com" || host == "coda.io" || host == "airtable.com":
+		return KindDoc
+	}
+
+	switch strings.ToLower(path.Ext(p)) {
+	case ".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx", ".odt", ".csv":
+		return KindDoc
+	}
+	return KindOther
+}
+
+// Code parses a GitHub or GitLab pull request, merge request, or issue URL
+// (including its /files, /diffs, ... subpages), or returns nil. The link's
+// URL is the canonical page of the PR or issue.
+func Code(raw string) *types.CodeLink {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	var forge string
+	var m []string
+	switch {
+	case host == "github.com":
+		forge, m = "github", githubCode.FindStringSubmatch(u.Path)
+	case strings.Contains(host, "gitlab"):
+		forge, m = "gitlab", gitlabCode.FindStringSubmatch(u.Path)
+	}
+	if m == nil {
+		return nil
+	}
+	number, err := strconv.Atoi(m[3])
+	if err != nil || number == 0 {
+		return nil
+	}
+	link := &types.CodeLink{Kind: KindIssue, Forge: forge, Repo: m[1], Number: number}
+	sep := "#"
+	if m[2] != "issues" {
+		link.Kind = KindPR
+		if forge == "gitlab" {
+			sep = "!"
+		}
+	}
+	link.Ref = link.Repo + sep + m[3]
+	if forge == "gitlab" {
+		link.URL = fmt.Sprintf("%s://%s/%s/-/%s/%d", u.Scheme, host, link.Repo, m[2], number)
+	} else {
+		link.URL = fmt.Sprintf("%s://%s/%s/%s/%d", u.Scheme, host, link.Repo, m[2], number)
+	}
+	return link
+}
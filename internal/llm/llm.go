@@ -0,0 +1,161 @@
+// Package llm sends prompts to the language model configured in
+// .mailbeads/config.json. Mailbeads works without one; features that use it
+// are opt-in.
+package llm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/config"
+)
+
+// ErrNotConfigured is returned when no LLM backend is configured.
+var ErrNotConfigured = errors.New(`no LLM configured — set "llm": {"command": [...]} or "llm": {"providers": {...}} in .mailbeads/config.json`)
+
+// TypeCommand is the Backend type of an llm.command.
+const TypeCommand = "command"
+
+// Backend is the resolved LLM backend prompts go to: a command, or a
+// provider API.
+type Backend struct {
+	// Name is the provider's name in llm.providers; empty for a command.
+	Name string
+	// Type is TypeCommand or a provider type.
+	Type    string
+	Model   string
+	URL     string
+	Command []string
+
+	keyEnv        string
+	maxTokens     int
+	contextTokens int
+	apiVersion    string
+}
+
+// Configured reports whether an LLM backend is set up.
+func Configured(cfg config.LLMConfig) bool {
+	return len(cfg.Command) > 0 || len(cfg.Providers) > 0
+}
+
+// Select resolves the backend cfg names: llm.provider, else the command,
+// else the only provider. With llm.local_only, a backend that isn't Local
+// is an error.
+func Select(cfg config.LLMConfig) (*Backend, error) {
+	name := cfg.Provider
+	switch {
+	case name != "":
+	case len(cfg.Command) > 0:
+		if cfg.LocalOnly {
+			return nil, errors.New("llm.local_only is set, but llm.command can't be checked to stay on this machine — use an ollama provider instead")
+		}
+		return &Backend{Type: TypeCommand, Command: cfg.Command}, nil
+	case len(cfg.Providers) == 1:
+		name = slices.Collect(maps.Keys(cfg.Providers))[0]
+	case len(cfg.Providers) > 1:
+		return nil, fmt.Errorf("several LLM providers configured (%s) — choose one with llm.provider",
+			strings.Join(slices.Sorted(maps.Keys(cfg.Providers)), ", "))
+	default:
+		return nil, ErrNotConfigured
+	}
+	p, ok := cfg.Providers[name]
+	if !ok {
+		return nil, fmt.Errorf("LLM provider %q is not in llm.providers", name)
+	}
+	b, err := newProviderBackend(name, p)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.LocalOnly && !b.Local() {
+		return nil, fmt.Errorf("llm.local_only is set, but LLM provider %s is at %s, off this machine", name, b.URL)
+	}
+	return b, nil
+}
+
+// Complete sends prompt to the configured LLM and returns its reply.
+func Complete(cfg config.LLMConfig, prompt string) (string, error) {
+	b, err := Select(cfg)
+	if err != nil {
+		return "", err
+	}
+	return b.Complete(prompt)
+}
+
+// Complete sends prompt to the backend and returns its reply.
+func (b *Backend) Complete(prompt string) (string, error) {
+	if b.Type == TypeCommand {
+		return runCommand(b.Command, prompt)
+	}
+	reply, err := b.completeAPI(prompt)
+	if err != nil {
+		return "", fmt.Errorf("LLM provider %s: %w", b.Name, err)
+	}
+	return strings.TrimSpace(reply), nil
+}
+
+// Local reports whether prompts stay on this machine: the backend is a
+// provider whose url is a loopback address. What a command does with a
+// prompt can't be known, so commands aren't local.
+func (b *Backend) Local() bool {
+	if b.Type == TypeCommand {
+		return false
+	}
+	u, err := url.Parse(b.URL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// String describes the backend for messages.
+func (b *Backend) String() string {
+	if b.Type == TypeCommand {
+		return strings.Join(b.Command, " ")
+	}
+	return fmt.Sprintf("%s (%s, %s)", b.Name, b.Type, b.Model)
+}
+
+func runCommand(command []string, prompt string) (string, error) {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(prompt)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", command[0], msg)
+		}
+		return "", fmt.Errorf("%s: %w", command[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// JSONBlock returns the first JSON array or object in an LLM reply,
+// dropping Markdown code fences and any prose around it.
+func JSONBlock(reply string) string {
+	start := strings.IndexAny(reply, "[{")
+	if start < 0 {
+		return reply
+	}
+	closer := byte(']')
+	if reply[start] == '{' {
+		closer = '}'
+	}
+	end := strings.LastIndexByte(reply, closer)
+	if end < start {
+		return reply[start:]
+	}
+	return reply[start : end+1]
+}
@@ -0,0 +1,259 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/config"
+)
+
+// Provider types.
+const (
+	TypeAnthropic = "anthropic"
+	TypeOpenAI    = "openai"
+	TypeAzure     = "azure"
+	TypeOllama    = "ollama"
+)
+
+const (
+	defaultMaxTokens     = 2048
+	defaultContextTokens = 8192
+	defaultAPIVersion    = "2024-10-21"
+	anthropicVersion     = "2023-06-01"
+)
+
+// providerDefaults are each provider type's API base URL and API key
+// variable.
+var providerDefaults = map[string]struct{ url, keyEnv string }{
+	TypeAnthropic: {"https://api.anthropic.com", "ANTHROPIC_API_KEY"},
+	TypeOpenAI:    {"https://api.openai.com/v1", "OPENAI_API_KEY"},
+	TypeAzure:     {"", "AZURE_OPENAI_API_KEY"},
+	TypeOllama:    {"http://localhost:11434", ""},
+}
+
+// Local models can take minutes on a long thread.
+var client = &http.Client{Timeout: 5 * time.Minute}
+
+func newProviderBackend(name string, p config.LLMProvider) (*Backend, error) {
+	typ := p.Type
+	if typ == "" {
+		typ = name
+	}
+	defaults, ok := providerDefaults[typ]
+	if !ok {
+		return nil, fmt.Errorf("LLM provider %s: unknown type %q (want anthropic, openai, azure, or ollama)", name, typ)
+	}
+	if p.Model == "" {
+		return nil, fmt.Errorf("LLM provider %s: no model", name)
+	}
+	b := &Backend{
+		Name:          name,
+		Type:          typ,
+		Model:         p.Model,
+		URL:           p.URL,
+		keyEnv:        p.APIKeyEnv,
+		maxTokens:     p.MaxTokens,
+		contextTokens: p.ContextTokens,
+		apiVersion:    p.APIVersion,
+	}
+	if b.URL == "" && typ == TypeOllama {
+		b.URL = ollamaHost()
+	}
+	if b.URL == "" {
+		b.URL = defaults.url
+	}
+	if b.URL == "" {
+		return nil, fmt.Errorf("LLM provider %s: no url (https://RESOURCE.openai.azure.com)", name)
+	}
+	b.URL = strings.TrimRight(b.URL, "/")
+	if b.keyEnv == "" {
+		b.keyEnv = defaults.keyEnv
+	}
+	if b.maxTokens <= 0 {
+		b.maxTokens = defaultMaxTokens
+	}
+	if b.contextTokens <= 0 {
+		b.contextTokens = defaultContextTokens
+	}
+	if b.apiVersion == "" {
+		b.apiVersion = defaultAPIVersion
+	}
+	return b, nil
+}
+
+// ollamaHost is $OLLAMA_HOST, as the ollama CLI reads it, as a URL.
+func ollamaHost() string {
+	host := os.Getenv("OLLAMA_HOST")
+	if host != "" && !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return host
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// completeAPI sends prompt to the provider API as a single user message.
+func (b *Backend) completeAPI(prompt string) (string, error) {
+	key := ""
+	if b.keyEnv != "" {
+		key = os.Getenv(b.keyEnv)
+		// A key is optional for ollama, which may sit behind a proxy.
+		if key == "" && b.Type != TypeOllama {
+			return "", fmt.Errorf("no API key: set $%s", b.keyEnv)
+		}
+	}
+	messages := []chatMessage{{Role: "user", Content: prompt}}
+
+	switch b.Type {
+	case TypeAnthropic:
+		var resp struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+		}
+		err := postJSON(b.URL+"/v1/messages", map[string]string{
+			"x-api-key":         key,
+			"anthropic-version": anthropicVersion,
+		}, map[string]any{
+			"model":      b.Model,
+			"max_tokens": b.maxTokens,
+			"messages":   messages,
+		}, &resp)
+		if err != nil {
+			return "", err
+		}
+		var text strings.Builder
+		for _, c := range resp.Content {
+			if c.Type == "text" {
+				text.WriteString(c.Text)
+			}
+		}
+		return nonEmpty(text.String())
+
+	case TypeOpenAI, TypeAzure:
+		endpoint := b.URL + "/chat/completions"
+		headers := map[string]string{"Authorization": "Bearer " + key}
+		if b.Type == TypeAzure {
+			endpoint = b.URL + "/openai/deployments/" + url.PathEscape(b.Model) +
+				"/chat/completions?api-version=" + url.QueryEscape(b.apiVersion)
+			headers = map[string]string{"api-key": key}
+		}
+		var resp struct {
+			Choices []struct {
+				Message chatMessage `json:"message"`
+			} `json:"choices"`
+		}
+		err := postJSON(endpoint, headers, map[string]any{
+			"model":                 b.Model,
+			"max_completion_tokens": b.maxTokens,
+			"messages":              messages,
+		}, &resp)
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			return "", errors.New("empty reply")
+		}
+		return nonEmpty(resp.Choices[0].Message.Content)
+
+	case TypeOllama:
+		headers := map[string]string{}
+		if key != "" {
+			headers["Authorization"] = "Bearer " + key
+		}
+		var resp struct {
+			Message chatMessage `json:"message"`
+		}
+		err := postJSON(b.URL+"/api/chat", headers, map[string]any{
+			"model":    b.Model,
+			"messages": messages,
+			"stream":   false,
+			"options": map[string]any{
+				"num_predict": b.maxTokens,
+				"num_ctx":     b.contextTokens,
+			},
+		}, &resp)
+		if err != nil {
+			return "", err
+		}
+		return nonEmpty(resp.Message.Content)
+	}
+	return "", fmt.Errorf("unknown type %q", b.Type)
+}
+
+func nonEmpty(reply string) (string, error) {
+	if strings.TrimSpace(reply) == "" {
+		return "", errors.New("empty reply")
+	}
+	return reply, nil
+}
+
+// postJSON posts payload to url with the given headers and decodes the
+// JSON response into out.
+func postJSON(url string, headers map[string]string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		if msg := apiError(data); msg != "" {
+			return fmt.Errorf("%s: %s", resp.Status, msg)
+		}
+		return errors.New(resp.Status)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+	return nil
+}
+
+// apiError extracts the message from an API error response: {"error":
+// {"message": ...}} (Anthropic, OpenAI, Azure) or {"error": "..."}
+// (ollama), else the body itself.
+func apiError(data []byte) string {
+	var e struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &e) == nil && len(e.Error) > 0 {
+		var msg string
+		if json.Unmarshal(e.Error, &msg) == nil && msg != "" {
+			return msg
+		}
+		var obj struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(e.Error, &obj) == nil && obj.Message != "" {
+			return obj.Message
+		}
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,154 @@
+// Package logging sets up mb's leveled diagnostics (log/slog): warnings,
+// errors, and daemon progress, as opposed to command output. Interactive
+// use gets terse console lines on stderr; unattended use can switch to
+// slog's text or JSON records and a log file.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/daviddao/mailbeads/internal/display"
+)
+
+// Formats are the accepted --log-format values.
+var Formats = []string{"console", "text", "json"}
+
+// Options configures the default logger.
+type Options struct {
+	// Level is debug, info, warn, or error. Default: info.
+	Level string
+	// Format is console (default on stderr), text (default in a file), or
+	// json.
+	Format string
+	// File, if set, is appended to instead of writing to stderr.
+	File string
+}
+
+// Setup installs the default slog logger described by opts. The returned
+// closer closes the log file, if any.
+func Setup(opts Options) (io.Closer, error) {
+	var level slog.Level
+	switch strings.ToLower(opts.Level) {
+	case "", "info":
+		level = slog.LevelInfo
+	case "debug":
+		level = slog.LevelDebug
+	case "warn", "warning":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	default:
+		return nil, fmt.Errorf("invalid log level %q (must be: debug, info, warn, error)", opts.Level)
+	}
+
+	var w io.Writer = os.Stderr
+	var closer io.Closer = io.NopCloser(nil)
+	format := opts.Format
+	if opts.File != "" {
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open log file: %w", err)
+		}
+		w, closer = f, f
+		if format == "" {
+			format = "text"
+		}
+	}
+
+	hopts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	switch format {
+	case "", "console":
+		h = &consoleHandler{w: w, level: level}
+	case "text":
+		h = slog.NewTextHandler(w, hopts)
+	case "json":
+		h = slog.NewJSONHandler(w, hopts)
+	default:
+		closer.Close()
+		return nil, fmt.Errorf("invalid log format %q (must be: %s)", format, strings.Join(Formats, ", "))
+	}
+	slog.SetDefault(slog.New(h))
+	return closer, nil
+}
+
+// consoleHandler writes one terse line per record, marked by level the
+// way mb's interactive output always has: "✗" for errors, "!" for
+// warnings. Attributes follow as key=value.
+type consoleHandler struct {
+	w     io.Writer
+	level slog.Level
+	attrs []slog.Attr
+	group string
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString(display.ErrStyle.Render("✗") + " ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString(display.MediumStyle.Render("!") + " ")
+	}
+	msg := r.Message
+	if r.Level < slog.LevelInfo {
+		msg = display.Dim.Render(msg)
+	}
+	b.WriteString(msg)
+	write := func(a slog.Attr) {
+		key := a.Key
+		if h.group != "" {
+			key = h.group + "." + key
+		}
+		fmt.Fprintf(&b, " %s=%s", display.Dim.Render(key), quote(a.Value.Resolve().String()))
+	}
+	for _, a := range h.attrs {
+		write(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		write(a)
+		return true
+	})
+	b.WriteString("\n")
+
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+// consoleMu serializes the writes of console handlers.
+var consoleMu sync.Mutex
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &h2
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	if h2.group != "" {
+		name = h2.group + "." + name
+	}
+	h2.group = name
+	return &h2
+}
+
+// quote quotes values that would be ambiguous unquoted.
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
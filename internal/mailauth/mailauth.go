@@ -0,0 +1,101 @@
+// Package mailauth evaluates sender authentication for triage: the SPF,
+// DKIM, and DMARC verdicts Gmail records in the Authentication-Results
+// header (RFC 8601), and a From display name that shows a different
+// address or domain than the one the mail really came from.
+package mailauth
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// Check reports the authentication verdict for a message with the given
+// From header and Authentication-Results header. It returns nil when there
+// is nothing to report: no results and no display-name mismatch.
+func Check(from, authResults string) *types.AuthCheck {
+	c := Parse(authResults)
+	if c.DMARC == "fail" {
+		c.Warnings = append(c.Warnings, "DMARC failed: the message isn't authenticated for its From domain")
+	}
+	if c.DKIM != "pass" && (c.SPF == "fail" || c.SPF == "softfail") {
+		c.Warnings = append(c.Warnings, fmt.Sprintf("SPF %s without a valid DKIM signature", c.SPF))
+	} else if c.DKIM == "fail" && c.SPF != "pass" {
+		c.Warnings = append(c.Warnings, "DKIM signature failed to verify")
+	}
+	if w := DisplayNameMismatch(from); w != "" {
+		c.Warnings = append(c.Warnings, w)
+	}
+	if c.SPF == "" && c.DKIM == "" && c.DMARC == "" && len(c.Warnings) == 0 {
+		return nil
+	}
+	return c
+}
+
+// comments matches RFC 5322 comments, which carry only explanations.
+var comments = regexp.MustCompile(`\([^)]*\)`)
+
+// Parse extracts the spf, dkim, and dmarc results from an
+// Authentication-Results header. With several DKIM signatures, any pass
+// counts: one valid signature is enough.
+func Parse(header string) *types.AuthCheck {
+	c := &types.AuthCheck{}
+	header = comments.ReplaceAllString(header, "")
+	for i, part := range strings.Split(header, ";") {
+		if i == 0 {
+			continue // authserv-id, e.g. mx.google.com
+		}
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		method, result, ok := strings.Cut(fields[0], "=")
+		if !ok {
+			continue
+		}
+		result = strings.ToLower(result)
+		switch strings.ToLower(method) {
+		case "spf":
+			c.SPF = result
+		case "dkim":
+			if c.DKIM != "pass" {
+				c.DKIM = result
+			}
+		case "dmarc":
+			c.DMARC = result
+		}
+	}
+	return c
+}
+
+var (
+	addressInName = regexp.MustCompile(`[A-Za-z0-9._%+-]+@([A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+)`)
+	domainInName  = regexp.MustCompile(`\b((?:[A-Za-z0-9-]+\.)+(?:com|org|net|edu|gov|io|co|de|uk|fr|app|dev))\b`)
+)
+
+// DisplayNameMismatch returns a warning when the display name of a From
+// header shows an address or domain other than the sender's, a common
+// phishing trick ("PayPal <support@paypal.com>" <x@attacker.example>).
+func DisplayNameMismatch(from string) string {
+	address, name := db.ParseSender(from)
+	if name == "" || address == "" {
+		return ""
+	}
+	_, domain, _ := strings.Cut(address, "@")
+	if m := addressInName.FindString(name); m != "" {
+		if !strings.EqualFold(m, address) {
+			return fmt.Sprintf("display name shows %s but the sender is %s", m, address)
+		}
+		return ""
+	}
+	if m := domainInName.FindStringSubmatch(name); m != nil {
+		shown := strings.ToLower(m[1])
+		if shown != domain && !strings.HasSuffix(domain, "."+shown) && !strings.HasSuffix(shown, "."+domain) {
+			return fmt.Sprintf("display name shows %s but the sender is %s", shown, address)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,214 @@
+// Package mailquery evaluates Gmail search queries against cached emails,
+// so Gmail lookups can fall back to the local database when the API is
+// unreachable. It covers the common operators (from:, to:, subject:,
+// is:, in:, label:, after:, before:, newer_than:, older_than:), quoted
+// phrases, and negation; other operators are reported as unsupported and
+// ignored.
+package mailquery
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// Query is a parsed Gmail search query. Every term must match.
+type Query struct {
+	terms []term
+	// Unsupported lists the terms that were ignored, e.g. "has:attachment".
+	Unsupported []string
+}
+
+type term struct {
+	op     string // "" for free text
+	value  string
+	negate bool
+	// since/until bound sent_at for date operators.
+	since, until time.Time
+}
+
+var tokenPattern = regexp.MustCompile(`-?(?:[a-zA-Z_]+:)?(?:"[^"]*"|\([^)]*\)|\S+)`)
+
+// Parse parses q relative to now (for newer_than: and older_than:).
+func Parse(q string, now time.Time) Query {
+	var query Query
+	for _, tok := range tokenPattern.FindAllString(q, -1) {
+		t := term{}
+		raw := tok
+		if strings.HasPrefix(tok, "-") && len(tok) > 1 {
+			t.negate = true
+			tok = tok[1:]
+		}
+		if op, value, ok := strings.Cut(tok, ":"); ok && !strings.HasPrefix(tok, `"`) {
+			t.op, tok = strings.ToLower(op), value
+		}
+		t.value = strings.ToLower(strings.Trim(tok, `"()`))
+		if t.value == "" || raw == "AND" {
+			continue
+		}
+		if raw == "OR" {
+			// Terms are always ANDed; say so rather than guess.
+			query.Unsupported = append(query.Unsupported, raw)
+			continue
+		}
+
+		switch t.op {
+		case "", "from", "to", "cc", "subject", "label":
+		case "is", "in":
+			if _, ok := stateLabels[t.value]; !ok && t.value != "read" && t.value != "anywhere" {
+				query.Unsupported = append(query.Unsupported, raw)
+				continue
+			}
+		case "after", "before":
+			d, ok := parseDate(t.value)
+			if !ok {
+				query.Unsupported = append(query.Unsupported, raw)
+				continue
+			}
+			if t.op == "after" {
+				t.since = d
+			} else {
+				t.until = d
+			}
+		case "newer_than", "older_than":
+			d, ok := relative(t.value, now)
+			if !ok {
+				query.Unsupported = append(query.Unsupported, raw)
+				continue
+			}
+			if t.op == "newer_than" {
+				t.since = d
+			} else {
+				t.until = d
+			}
+		default:
+			query.Unsupported = append(query.Unsupported, raw)
+			continue
+		}
+		query.terms = append(query.terms, t)
+	}
+	return query
+}
+
+// Words returns the free-text words of the query, for a coarse database
+// prefilter before Match.
+func (q Query) Words() []string {
+	var words []string
+	for _, t := range q.terms {
+		if t.op == "" && !t.negate {
+			words = append(words, strings.Fields(t.value)...)
+		}
+	}
+	return words
+}
+
+// Match reports whether e satisfies every term.
+func (q Query) Match(e *types.Email) bool {
+	for _, t := range q.terms {
+		if t.match(e) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// stateLabels maps is:/in: values to the Gmail label they test.
+var stateLabels = map[string]string{
+	"unread":    "UNREAD",
+	"starred":   "STARRED",
+	"important": "IMPORTANT",
+	"inbox":     "INBOX",
+	"sent":      "SENT",
+	"spam":      "SPAM",
+	"trash":     "TRASH",
+	"draft":     "DRAFT",
+	"drafts":    "DRAFT",
+}
+
+func (t term) match(e *types.Email) bool {
+	contains := func(s string) bool { return strings.Contains(strings.ToLower(s), t.value) }
+	switch t.op {
+	case "":
+		return contains(e.Subject) || contains(e.From) || contains(e.To) || contains(e.Snippet) ||
+			contains(e.Body) || contains(e.AttachmentText)
+	case "from":
+		return contains(e.From)
+	case "to":
+		return contains(e.To) || contains(e.CC)
+	case "cc":
+		return contains(e.CC)
+	case "subject":
+		return contains(e.Subject)
+	case "label":
+		return hasLabel(e.Labels, t.value)
+	case "is", "in":
+		switch t.value {
+		case "read":
+			return !hasLabel(e.Labels, "unread")
+		case "anywhere":
+			return true
+		}
+		return hasLabel(e.Labels, stateLabels[t.value])
+	}
+	sent, err := time.Parse(time.RFC3339, e.SentAt)
+	if err != nil {
+		return false
+	}
+	if !t.since.IsZero() {
+		return !sent.Before(t.since)
+	}
+	return sent.Before(t.until)
+}
+
+// hasLabel reports whether the comma-separated labels include name. User
+// labels match with spaces or dashes interchangeably, as in Gmail.
+func hasLabel(labels, name string) bool {
+	name = strings.ReplaceAll(strings.ToLower(name), "-", " ")
+	for _, l := range strings.Split(labels, ",") {
+		if strings.ReplaceAll(strings.ToLower(strings.TrimSpace(l)), "-", " ") == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDate parses Gmail's after:/before: dates (2024/01/31 or 2024-01-31)
+// and Unix timestamps, in local time.
+func parseDate(v string) (time.Time, bool) {
+	for _, layout := range []string{"2006/01/02", "2006-01-02", "2006/1/2"} {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, true
+		}
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(n, 0), true
+	}
+	return time.Time{}, false
+}
+
+// relative resolves newer_than:/older_than: values such as 3d, 2w, 6m, 1y.
+func relative(v string, now time.Time) (time.Time, bool) {
+	if len(v) < 2 {
+		return time.Time{}, false
+	}
+	n, err := strconv.Atoi(v[:len(v)-1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	switch v[len(v)-1] {
+	case 'h':
+		return now.Add(-time.Duration(n) * time.Hour), true
+	case 'd':
+		return now.AddDate(0, 0, -n), true
+	case 'w':
+		return now.AddDate(0, 0, -7*n), true
+	case 'm':
+		return now.AddDate(0, -n, 0), true
+	case 'y':
+		return now.AddDate(-n, 0, 0), true
+	}
+	return time.Time{}, false
+}
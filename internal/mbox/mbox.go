@@ -0,0 +1,294 @@
+// Package mbox reads mailboxes in mbox format, as exported by Google
+// Takeout, Thunderbird, or mutt, into emails mailbeads can store. Gmail's
+// X-GM-THRID and X-Gmail-Labels headers are used when present, so a
+// Takeout export keeps Gmail's threads and read state.
+package mbox
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/bulkmail"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/htmltext"
+	"github.com/daviddao/mailbeads/internal/types"
+	"golang.org/x/net/html/charset"
+)
+
+// snippetLen is the length of the snippet derived from each body, about
+// what Gmail shows.
+const snippetLen = 200
+
+// Read parses every message in an mbox stream. Messages are filed under
+// their Delivered-To address, or account if they have none.
+func Read(r io.Reader, account string) ([]*types.Email, error) {
+	var emails []*types.Email
+	var msg bytes.Buffer
+	flush := func() error {
+		if msg.Len() == 0 {
+			return nil
+		}
+		e, err := Parse(msg.Bytes(), account)
+		if err != nil {
+			return fmt.Errorf("message %d: %w", len(emails)+1, err)
+		}
+		emails = append(emails, e)
+		msg.Reset()
+		return nil
+	}
+
+	br := bufio.NewReader(r)
+	blank := true // the start of the file counts as following a blank line
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			switch {
+			case blank && bytes.HasPrefix(line, []byte("From ")):
+				if ferr := flush(); ferr != nil {
+					return nil, ferr
+				}
+			case isQuotedFrom(line):
+				msg.Write(line[1:]) // mboxrd: ">From " was escaped
+			default:
+				msg.Write(line)
+			}
+			blank = len(bytes.TrimRight(line, "\r\n")) == 0
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// isQuotedFrom reports whether line is a body line starting with "From "
+// behind one or more '>' escapes.
+func isQuotedFrom(line []byte) bool {
+	rest := bytes.TrimLeft(line, ">")
+	return len(rest) < len(line) && bytes.HasPrefix(rest, []byte("From "))
+}
+
+// Parse converts one RFC 822 message to an email filed under account
+// (unless it has a Delivered-To header). IDs are derived from the
+// Message-ID, so importing the same mailbox twice yields the same emails.
+func Parse(raw []byte, account string) (*types.Email, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	h := msg.Header
+	dec := &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+	header := func(name string) string {
+		v := strings.TrimSpace(h.Get(name))
+		if decoded, err := dec.DecodeHeader(v); err == nil {
+			return decoded
+		}
+		return v
+	}
+
+	messageID := strings.TrimSpace(h.Get("Message-ID"))
+	id := messageID
+	if id == "" {
+		id = string(raw)
+	}
+
+	body := textBody(h.Get("Content-Type"), h.Get("Content-Transfer-Encoding"), msg.Body)
+	labels := gmailLabels(h.Get("X-Gmail-Labels"))
+
+	e := &types.Email{
+		ID:          shortHash(id),
+		Account:     account,
+		ThreadID:    threadID(h, messageID, id),
+		MessageID:   messageID,
+		From:        header("From"),
+		To:          header("To"),
+		CC:          header("Cc"),
+		Subject:     header("Subject"),
+		Snippet:     snippet(body),
+		Body:        body,
+		Date:        h.Get("Date"),
+		Labels:      strings.Join(labels, ","),
+		IsRead:      isRead(labels, h.Get("Status")),
+		FetchedAt:   db.Now(),
+		AuthResults: h.Get("Authentication-Results"),
+		IsBulk:      bulkmail.Detect(h.Get),
+	}
+	if e.Subject == "" {
+		e.Subject = "(no subject)"
+	}
+	if to := strings.TrimSpace(h.Get("Delivered-To")); to != "" {
+		if addr, err := mail.ParseAddress(to); err == nil {
+			e.Account = addr.Address
+		}
+	}
+	return e, nil
+}
+
+// threadID returns Gmail's thread ID (X-GM-THRID, in hex as the Gmail API
+// gives it) or else groups the message with the first message it
+// references.
+func threadID(h mail.Header, messageID, fallback string) string {
+	if thrid, err := strconv.ParseUint(strings.TrimSpace(h.Get("X-GM-THRID")), 10, 64); err == nil {
+		return strconv.FormatUint(thrid, 16)
+	}
+	root := messageID
+	if refs := strings.Fields(h.Get("References")); len(refs) > 0 {
+		root = refs[0]
+	} else if reply := strings.TrimSpace(h.Get("In-Reply-To")); reply != "" {
+		root = strings.Fields(reply)[0]
+	}
+	if root == "" {
+		root = fallback
+	}
+	return shortHash(root)
+}
+
+func shortHash(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+// textBody returns the text of a message or part: its first text/plain
+// part, or its first text/html part rendered as text. Attachments are
+// skipped.
+func textBody(contentType, encoding string, r io.Reader) string {
+	plain, markup := findText(contentType, encoding, r)
+	if plain != "" {
+		return plain
+	}
+	if markup != "" {
+		return htmltext.Convert(markup)
+	}
+	return ""
+}
+
+func findText(contentType, encoding string, r io.Reader) (plain, markup string) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			if isAttachment(p.Header) {
+				continue
+			}
+			pp, pm := findText(p.Header.Get("Content-Type"), p.Header.Get("Content-Transfer-Encoding"), p)
+			if plain == "" {
+				plain = pp
+			}
+			if markup == "" {
+				markup = pm
+			}
+			if plain != "" {
+				break
+			}
+		}
+		return plain, markup
+	}
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", ""
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		r = quotedprintable.NewReader(r)
+	}
+	if cs := params["charset"]; cs != "" {
+		if cr, err := charset.NewReaderLabel(cs, r); err == nil {
+			r = cr
+		}
+	}
+	data, _ := io.ReadAll(r)
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if mediaType == "text/html" {
+		return "", text
+	}
+	return text, ""
+}
+
+func isAttachment(h textproto.MIMEHeader) bool {
+	disposition, _, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	return disposition == "attachment"
+}
+
+func snippet(body string) string {
+	s := strings.Join(strings.Fields(body), " ")
+	if r := []rune(s); len(r) > snippetLen {
+		s = string(r[:snippetLen])
+	}
+	return s
+}
+
+// gmailSystemLabels maps the label names in Takeout's X-Gmail-Labels to
+// the IDs the Gmail API (and so mb sync) uses.
+var gmailSystemLabels = map[string]string{
+	"inbox":     "INBOX",
+	"unread":    "UNREAD",
+	"important": "IMPORTANT",
+	"starred":   "STARRED",
+	"sent":      "SENT",
+	"spam":      "SPAM",
+	"trash":     "TRASH",
+	"draft":     "DRAFT",
+	"opened":    "",
+}
+
+func gmailLabels(header string) []string {
+	var labels []string
+	for _, l := range strings.Split(header, ",") {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		if id, ok := gmailSystemLabels[strings.ToLower(l)]; ok {
+			if id != "" {
+				labels = append(labels, id)
+			}
+			continue
+		}
+		labels = append(labels, l)
+	}
+	return labels
+}
+
+// isRead uses Gmail's UNREAD label when the message came from Gmail, and
+// the mbox Status header ("R" once read) otherwise.
+func isRead(labels []string, status string) int {
+	if labels != nil {
+		for _, l := range labels {
+			if l == "UNREAD" {
+				return 0
+			}
+		}
+		return 1
+	}
+	if strings.Contains(status, "R") {
+		return 1
+	}
+	return 0
+}
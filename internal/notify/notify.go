@@ -0,0 +1,205 @@
+// Package notify posts webhook notifications (Slack or generic JSON) when
+// threads with new mail match the notification rules in config, and
+// delivers reminders for due and snoozed beads.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/config"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// Event is the JSON payload posted to generic webhooks.
+type Event struct {
+	Event      string `json:"event"`
+	Rule       string `json:"rule"`
+	ThreadID   string `json:"thread_id"`
+	Account    string `json:"account"`
+	Subject    string `json:"subject"`
+	From       string `json:"from"`
+	EmailCount int    `json:"email_count"`
+	Priority   string `json:"priority,omitempty"`
+	BeadID     string `json:"bead_id,omitempty"`
+	URL        string `json:"url"`
+	// Title, Reason ("due" or "snooze_expired"), and Due describe the bead
+	// of a reminder event.
+	Title  string `json:"title,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Due    string `json:"due,omitempty"`
+}
+
+// Enabled reports whether any webhooks and rules are configured.
+func Enabled(cfg *config.Config) bool {
+	return len(cfg.Notify.Webhooks) > 0 && len(cfg.Notify.Rules) > 0
+}
+
+// NewThreads evaluates the rules against threads in account that just
+// received mail and posts one notification per matching thread. Returns the
+// events sent.
+func NewThreads(store db.Store, cfg *config.Config, account string, threadIDs []string) ([]Event, error) {
+	if !Enabled(cfg) {
+		return nil, nil
+	}
+
+	var sent []Event
+	var errs []string
+	for _, threadID := range threadIDs {
+		info, err := store.ThreadInfo(threadID, account)
+		if err != nil || info.Muted {
+			continue
+		}
+
+		// Triaged threads carry a beads priority that rules can match on.
+		priority, beadID := "", ""
+		if ref, err := store.GetTriageRef(threadID, account); err == nil && ref != nil {
+			beadID = ref.BeadID
+			if beads.Available() {
+				if issue, err := beads.Show(ref.BeadID); err == nil {
+					priority = beads.PriorityFromBeads(issue.Priority)
+				}
+			}
+		}
+
+		rule := Match(cfg.Notify.Rules, info, priority)
+		if rule == nil {
+			continue
+		}
+
+		ev := Event{
+			Event:      "new_mail",
+			Rule:       rule.Name,
+			ThreadID:   info.ThreadID,
+			Account:    info.Account,
+			Subject:    info.Subject,
+			From:       info.From,
+			EmailCount: info.EmailCount,
+			Priority:   priority,
+			BeadID:     beadID,
+			URL:        gmail.ThreadURL(info.Account, info.ThreadID),
+		}
+		for _, hook := range cfg.Notify.Webhooks {
+			if err := Post(hook, ev); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		sent = append(sent, ev)
+	}
+
+	if len(errs) > 0 {
+		return sent, fmt.Errorf("webhook errors: %s", strings.Join(errs, "; "))
+	}
+	return sent, nil
+}
+
+// Match returns the first rule matching a thread, or nil.
+func Match(rules []config.NotifyRule, t *types.Thread, priority string) *config.NotifyRule {
+	for i := range rules {
+		if matches(&rules[i], t, priority) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func matches(r *config.NotifyRule, t *types.Thread, priority string) bool {
+	if len(r.Senders) > 0 && !MatchSender(r.Senders, t.From) {
+		return false
+	}
+	if len(r.SubjectContains) > 0 && !containsAny(t.Subject, r.SubjectContains) {
+		return false
+	}
+	if len(r.Accounts) > 0 && !containsAny(t.Account, r.Accounts) {
+		return false
+	}
+	if r.Priority != "" {
+		if priority == "" || rank(priority) > rank(r.Priority) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchSender matches a From header against addresses, "@domain" entries,
+// or plain substrings.
+func MatchSender(patterns []string, from string) bool {
+	address, _ := db.ParseSender(from)
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case p == "":
+			continue
+		case strings.HasPrefix(p, "@"):
+			if strings.HasSuffix(address, p) {
+				return true
+			}
+		case address == p:
+			return true
+		case strings.Contains(strings.ToLower(from), p):
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(s string, subs []string) bool {
+	s = strings.ToLower(s)
+	for _, sub := range subs {
+		if sub != "" && strings.Contains(s, strings.ToLower(sub)) {
+			return true
+		}
+	}
+	return false
+}
+
+// rank orders mb priorities from most (1) to least (4) urgent.
+func rank(priority string) int {
+	switch priority {
+	case types.PriorityHigh:
+		return 1
+	case types.PriorityMedium:
+		return 2
+	case types.PriorityLow:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// Post sends an event to a webhook.
+func Post(hook config.Webhook, ev Event) error {
+	var payload any = ev
+	if hook.Format == "slack" && ev.Event == "reminder" {
+		payload = map[string]string{"text": fmt.Sprintf("%s\n<%s|Open in Gmail> · %s", ReminderText(ev), ev.URL, ev.BeadID)}
+	} else if hook.Format == "slack" {
+		text := fmt.Sprintf("*%s* from %s", ev.Subject, ev.From)
+		if ev.Priority != "" {
+			text += fmt.Sprintf(" [%s]", ev.Priority)
+		}
+		text += fmt.Sprintf("\n<%s|Open in Gmail> · rule: %s", ev.URL, ev.Rule)
+		payload = map[string]string{"text": text}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(hook.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("post %s: %w", hook.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("post %s: %s", hook.URL, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/config"
+)
+
+// Remind delivers a reminder event to the targets enabled in
+// cfg.Notify.Reminders: a desktop notification and the webhooks.
+func Remind(cfg *config.Config, ev Event) error {
+	var errs []string
+	if cfg.Notify.Reminders.Desktop {
+		if err := Desktop("mailbeads", ReminderText(ev)); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if cfg.Notify.Reminders.Webhooks {
+		for _, hook := range cfg.Notify.Webhooks {
+			if err := Post(hook, ev); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("reminder errors: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ReminderText is the one-line message of a reminder event.
+func ReminderText(ev Event) string {
+	text := fmt.Sprintf("Due: %s", ev.Title)
+	if ev.Reason == "snooze_expired" {
+		text = fmt.Sprintf("Back from snooze: %s", ev.Title)
+	}
+	if ev.Priority != "" {
+		text += fmt.Sprintf(" [%s]", ev.Priority)
+	}
+	return text
+}
+
+// Desktop shows a desktop notification with notify-send (Linux and BSD) or
+// osascript (macOS).
+func Desktop(title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=mailbeads", title, body)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("desktop notification: %w: %s", err, msg)
+		}
+		return fmt.Errorf("desktop notification: %w", err)
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,381 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/auth"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/types"
+	gm "google.golang.org/api/gmail/v1"
+)
+
+func init() {
+	Register(Default, &gmailProvider{services: make(map[string]*gm.Service)})
+}
+
+// gmailProvider syncs through the Gmail API. An account's OAuth client is
+// ACCOUNT/credentials.json in the project root, or the "credentials"
+// setting.
+type gmailProvider struct {
+	mu       gosync.Mutex
+	services map[string]*gm.Service // by credentials path
+}
+
+// Discover finds accounts by scanning for */credentials.json directories
+// in the project root. Returns email addresses (directory names).
+func (*gmailProvider) Discover(projectRoot string) []string {
+	entries, err := os.ReadDir(projectRoot)
+	if err != nil {
+		return nil
+	}
+
+	var accounts []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		// Look for directories that contain credentials.json and look like email addresses.
+		if !strings.Contains(name, "@") {
+			continue
+		}
+		credPath := filepath.Join(projectRoot, name, "credentials.json")
+		if _, err := os.Stat(credPath); err == nil {
+			accounts = append(accounts, name)
+		}
+	}
+
+	sort.Strings(accounts)
+	return accounts
+}
+
+// service returns an authenticated Gmail service for acct, reusing one
+// already made for its credentials.
+func (p *gmailProvider) service(acct Account) (*gm.Service, error) {
+	credPath := acct.Settings["credentials"]
+	if credPath == "" {
+		credPath = filepath.Join(acct.ProjectRoot, acct.Address, "credentials.json")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if svc, ok := p.services[credPath]; ok {
+		return svc, nil
+	}
+	if _, err := os.Stat(credPath); err != nil {
+		return nil, fmt.Errorf("credentials not found")
+	}
+	svc, err := auth.LoadGmailService(context.Background(), credPath)
+	if err != nil {
+		return nil, fmt.Errorf("auth failed: %w", err)
+	}
+	p.services[credPath] = svc
+	return svc, nil
+}
+
+func (p *gmailProvider) Sync(acct Account, req *SyncRequest) (*Batch, error) {
+	svc, err := p.service(acct)
+	if err != nil {
+		return nil, err
+	}
+
+	// One cheap call tells whether anything changed since the last sync.
+	cursor, err := gmail.HistoryID(svc)
+	if err != nil {
+		req.warnf("%v; listing the window instead", err)
+	} else if req.Cursor != "" && cursor == req.Cursor {
+		return &Batch{Cursor: cursor, Unchanged: true}, nil
+	}
+	// Relabeling, archiving, and reading don't change what the window
+	// lists, so stored messages pick them up from the history instead.
+	var updates []LabelUpdate
+	if req.Cursor != "" && cursor != "" {
+		changes, err := gmail.LabelChanges(svc, req.Cursor)
+		if err != nil {
+			req.warnf("%v; label changes since the last sync are skipped", err)
+		}
+		for id, labels := range changes {
+			updates = append(updates, LabelUpdate{ID: id, Labels: strings.Join(labels, ","), IsRead: isRead(labels)})
+		}
+		sort.Slice(updates, func(i, j int) bool { return updates[i].ID < updates[j].ID })
+	}
+
+	var refs []gmail.MessageRef
+	if req.Query != "" {
+		// A query backfill takes every page, not just the newest 100.
+		for token := ""; ; {
+			page, next, err := gmail.ListPage(svc, req.Query, 100, token)
+			if err != nil {
+				return nil, fmt.Errorf("search failed: %w", err)
+			}
+			refs = append(refs, page...)
+			req.progress(PhaseSearch, len(refs), 0)
+			if next == "" {
+				break
+			}
+			token = next
+		}
+	} else {
+		query := "newer_than:3d"
+		if !req.Since.IsZero() {
+			query = "after:" + req.Since.Format("2006/01/02")
+		}
+		// Only sync inbox by default (excludes drafts, sent-only, spam, trash).
+		if !req.IncludeSpam {
+			query += " in:inbox"
+		}
+		refs, _, err = gmail.ListPage(svc, query, 100, "")
+		if err != nil {
+			return nil, fmt.Errorf("search failed: %w", err)
+		}
+	}
+
+	// Filter already-synced before reading anything, so known messages
+	// cost nothing.
+	ids := make([]string, len(refs))
+	for i, r := range refs {
+		ids[i] = r.ID
+	}
+	existing, err := req.Known(ids)
+	if err != nil {
+		return nil, fmt.Errorf("check synced emails: %w", err)
+	}
+	var newRefs []gmail.MessageRef
+	for _, r := range refs {
+		if !existing[r.ID] {
+			newRefs = append(newRefs, r)
+		}
+	}
+	// The list is newest first, so a cap keeps the most recent mail.
+	capped := 0
+	if req.Max > 0 && len(newRefs) > req.Max {
+		capped = len(newRefs) - req.Max
+		newRefs = newRefs[:req.Max]
+	}
+
+	summaries := make([]*gmail.MessageSummary, len(newRefs))
+	var readMu gosync.Mutex
+	read := 0
+	parallel(len(newRefs), req.workers(), func(i int) {
+		s, err := gmail.ReadSummary(svc, newRefs[i].ID)
+		if err != nil {
+			req.warnf("failed to read %s, retrying next sync: %v", newRefs[i].ID, err)
+		}
+		summaries[i] = s
+		readMu.Lock()
+		read++
+		done := read
+		readMu.Unlock()
+		req.progress(PhaseSearch, done, len(newRefs))
+	})
+	var newEmails []gmail.MessageSummary
+	failed := 0
+	for _, s := range summaries {
+		if s != nil {
+			newEmails = append(newEmails, *s)
+		} else {
+			failed++
+		}
+	}
+
+	batch := &Batch{Listed: len(refs), Capped: capped, Failed: failed, Cursor: cursor, Updates: updates}
+	if len(newEmails) == 0 {
+		return batch, nil
+	}
+
+	// Fetch full content for new emails (headers-only syncs keep the
+	// metadata already read).
+	now := time.Now().UTC().Format(time.RFC3339)
+	var prefetched map[string]*gmail.FullMessage
+	req.progress(PhaseFetch, 0, len(newEmails))
+	if !req.HeadersOnly {
+		prefetched = fetchThreads(svc, newEmails, req)
+	}
+
+	batch.Messages = make([]*Message, len(newEmails))
+	var mu gosync.Mutex
+	fetched := 0
+	parallel(len(newEmails), req.workers(), func(i int) {
+		summary := newEmails[i]
+		m := &Message{}
+		if req.HeadersOnly {
+			m.Email = headersOnlyEmail(summary, acct.Address, now)
+		} else {
+			full := prefetched[summary.ID]
+			var err error
+			if full == nil {
+				full, err = gmail.ReadFull(svc, summary.ID)
+			}
+			if err != nil {
+				// Keep the message from its search metadata rather than
+				// dropping it: incremental syncs won't look this far back
+				// again, and mb show fetches the body on demand.
+				req.warnf("failed to read %s, storing headers only: %v", summary.ID, err)
+				m.Email = headersOnlyEmail(summary, acct.Address, now)
+			} else {
+				gmail.FetchAttachmentText(svc, full)
+				m.Email = fullEmail(full, summary, acct.Address, now)
+				m.ICS = full.ICS
+				if req.Raw {
+					if raw, err := gmail.ReadRaw(svc, summary.ID); err != nil {
+						req.warnf("failed to fetch raw source of %s: %v", summary.ID, err)
+					} else {
+						m.Raw = []byte(raw.Raw)
+					}
+				}
+			}
+		}
+		batch.Messages[i] = m
+		mu.Lock()
+		fetched++
+		done := fetched
+		mu.Unlock()
+		req.progress(PhaseFetch, done, len(newEmails))
+	})
+	return batch, nil
+}
+
+func (p *gmailProvider) Read(acct Account, messageID string) (*Message, error) {
+	svc, err := p.service(acct)
+	if err != nil {
+		return nil, err
+	}
+	full, err := gmail.ReadFull(svc, messageID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	return &Message{
+		Email: fullEmail(full, gmail.MessageSummary{}, acct.Address, now),
+		ICS:   full.ICS,
+	}, nil
+}
+
+func (p *gmailProvider) Send(acct Account, m *Outgoing) (string, error) {
+	svc, err := p.service(acct)
+	if err != nil {
+		return "", err
+	}
+	if m.ThreadID != "" {
+		return gmail.SendReply(svc, m.ThreadID, m.From, m.To, m.Subject, m.Body, m.Headers)
+	}
+	return gmail.Send(svc, m.From, m.To, m.Subject, m.Body, m.Headers)
+}
+
+// fetchThreads fetches the threads holding more than one new message with
+// a single Users.Threads.Get call each, and returns the new messages by ID.
+// Threads with one new message are left to Users.Messages.Get, which costs
+// half the quota and doesn't download the rest of the conversation. Threads
+// that fail to fetch are skipped; their messages are read one by one.
+func fetchThreads(svc *gm.Service, newEmails []gmail.MessageSummary, req *SyncRequest) map[string]*gmail.FullMessage {
+	wanted := make(map[string]bool, len(newEmails))
+	perThread := make(map[string]int)
+	for _, email := range newEmails {
+		wanted[email.ID] = true
+		perThread[email.ThreadID]++
+	}
+
+	var threadIDs []string
+	for threadID, n := range perThread {
+		if n >= 2 {
+			threadIDs = append(threadIDs, threadID)
+		}
+	}
+
+	var mu gosync.Mutex
+	fetched := make(map[string]*gmail.FullMessage)
+	parallel(len(threadIDs), req.workers(), func(i int) {
+		messages, err := gmail.ReadThread(svc, threadIDs[i])
+		if err != nil {
+			req.warnf("failed to read thread %s, reading its messages one by one: %v", threadIDs[i], err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, m := range messages {
+			if wanted[m.ID] {
+				fetched[m.ID] = m
+			}
+		}
+	})
+	return fetched
+}
+
+// fullEmail builds an email from a fully fetched message, falling back to
+// the search summary for missing headers.
+func fullEmail(full *gmail.FullMessage, summary gmail.MessageSummary, account, now string) *types.Email {
+	fromAddr := full.From
+	if fromAddr == "" {
+		fromAddr = summary.From
+	}
+	subject := full.Subject
+	if subject == "" {
+		subject = summary.Subject
+	}
+	date := full.Date
+	if date == "" {
+		date = summary.Date
+	}
+	authResults := full.AuthResults
+	if authResults == "" {
+		authResults = summary.AuthResults
+	}
+
+	return &types.Email{
+		ID:             full.ID,
+		Account:        account,
+		ThreadID:       full.ThreadID,
+		MessageID:      full.MessageID,
+		From:           fromAddr,
+		To:             full.To,
+		CC:             full.CC,
+		Subject:        subject,
+		Snippet:        summary.Snippet,
+		Body:           full.Body,
+		AttachmentText: full.AttachmentText,
+		Date:           date,
+		Labels:         strings.Join(full.Labels, ","),
+		IsRead:         isRead(full.Labels),
+		FetchedAt:      now,
+		AuthResults:    authResults,
+		IsBulk:         full.Bulk || summary.Bulk,
+	}
+}
+
+// headersOnlyEmail builds an email from search metadata alone.
+func headersOnlyEmail(summary gmail.MessageSummary, account, now string) *types.Email {
+	return &types.Email{
+		ID:          summary.ID,
+		Account:     account,
+		ThreadID:    summary.ThreadID,
+		MessageID:   summary.MessageID,
+		From:        summary.From,
+		To:          summary.To,
+		CC:          summary.CC,
+		Subject:     summary.Subject,
+		Snippet:     summary.Snippet,
+		BodyMissing: true,
+		Date:        summary.Date,
+		Labels:      strings.Join(summary.Labels, ","),
+		IsRead:      isRead(summary.Labels),
+		FetchedAt:   now,
+		AuthResults: summary.AuthResults,
+		IsBulk:      summary.Bulk,
+	}
+}
+
+// isRead reports 0 if the UNREAD label is present, 1 otherwise.
+func isRead(labels []string) int {
+	for _, l := range labels {
+		if l == "UNREAD" {
+			return 0
+		}
+	}
+	return 1
+}
@@ -0,0 +1,275 @@
+// Package provider defines the mail providers mailbeads syncs from and
+// sends through, and the registry that selects one per account.
+//
+// Gmail is built in. Other providers (JMAP, Proton Bridge, Exchange)
+// implement MailProvider and call Register; an account uses one when its
+// "accounts" entry in .mailbeads/config.json names it. The sync core only
+// sees MailProvider, so adding a provider doesn't touch it.
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/config"
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// Default is the provider of accounts the config doesn't assign one.
+const Default = "gmail"
+
+// MailProvider fetches and sends mail for the accounts it serves.
+type MailProvider interface {
+	// Discover lists the accounts under projectRoot the provider can
+	// serve without configuration, e.g. directories holding credentials.
+	Discover(projectRoot string) []string
+	// Sync lists the account's messages in the request's window and
+	// returns those req.Known doesn't report as stored, fully fetched.
+	Sync(acct Account, req *SyncRequest) (*Batch, error)
+	// Read fetches one message in full.
+	Read(acct Account, messageID string) (*Message, error)
+	// Send sends a message and returns its provider ID.
+	Send(acct Account, m *Outgoing) (string, error)
+}
+
+// Account is an account as handed to its provider.
+type Account struct {
+	// Address is the account's email address.
+	Address     string
+	ProjectRoot string
+	// Settings are the account's "settings" from the config, passed
+	// through as is (e.g. a server URL).
+	Settings map[string]string
+}
+
+// SyncRequest describes one sync of an account.
+type SyncRequest struct {
+	// Since is the date of the latest stored message; the sync resumes
+	// from that day. Zero means a full sync of the last 72 hours.
+	Since time.Time
+	// IncludeSpam syncs all mail, not just the inbox.
+	IncludeSpam bool
+	// Query, in the provider's search syntax, replaces the window: the
+	// sync lists every message matching it, in any folder, and ignores
+	// Since and IncludeSpam.
+	Query string
+	// HeadersOnly returns messages without bodies (Email.BodyMissing).
+	HeadersOnly bool
+	// Raw also returns each message's RFC 822 source.
+	Raw bool
+	// Workers is how many messages to fetch concurrently; 0 means
+	// DefaultWorkers.
+	Workers int
+	// Max, if positive, caps the new messages fetched: only the newest
+	// Max are returned, and the rest counted in Batch.Capped.
+	Max int
+	// Cursor is the Batch.Cursor of the previous sync of the same window.
+	// A provider may return Batch.Unchanged instead of listing when the
+	// mailbox hasn't changed since.
+	Cursor string
+	// Known reports which of the given message IDs are already stored.
+	Known func(ids []string) (map[string]bool, error)
+	// Warnf reports a problem the sync recovered from; Progress reports
+	// the messages handled so far in a phase (PhaseSearch or PhaseFetch),
+	// with a total of 0 if it isn't known yet. Either may be nil; Warnf
+	// may be called from several goroutines at once.
+	Warnf    func(format string, args ...any)
+	Progress func(phase string, done, total int)
+
+	// progressMu serializes Progress calls from concurrent fetches.
+	progressMu gosync.Mutex
+}
+
+// DefaultWorkers is the number of concurrent fetches of a sync that
+// doesn't set SyncRequest.Workers.
+const DefaultWorkers = 4
+
+// Sync phases reported to SyncRequest.Progress.
+const (
+	// PhaseSearch lists the messages in the window.
+	PhaseSearch = "search"
+	// PhaseFetch fetches the new ones.
+	PhaseFetch = "fetch"
+)
+
+// Batch is the result of a sync.
+type Batch struct {
+	// Listed counts the messages in the window, stored or not.
+	Listed int
+	// Messages are the new ones, oldest first if the provider can tell.
+	Messages []*Message
+	// Capped counts the older new messages left out by SyncRequest.Max.
+	Capped int
+	// Failed counts new messages that couldn't be read and are missing
+	// from Messages. The sync then keeps its old cursor, so the next one
+	// lists them again instead of returning early as unchanged.
+	Failed int
+	// Cursor is opaque provider state marking the mailbox as of this sync
+	// (for Gmail, its history ID), or "" if the provider has none.
+	Cursor string
+	// Unchanged reports that nothing changed since SyncRequest.Cursor, so
+	// nothing was listed.
+	Unchanged bool
+	// Updates are the messages whose labels or read state changed since
+	// SyncRequest.Cursor, stored or not, if the provider can tell.
+	Updates []LabelUpdate
+}
+
+// LabelUpdate is the current labels and read state of a message, in the
+// form of types.Email's Labels and IsRead.
+type LabelUpdate struct {
+	ID     string
+	Labels string
+	IsRead int
+}
+
+// Message is a fetched message.
+type Message struct {
+	Email *types.Email
+	// ICS is the message's iCalendar invitation, if any.
+	ICS string
+	// Raw is the RFC 822 source when it was requested.
+	Raw []byte
+}
+
+// Outgoing is a message to send. With ThreadID set it is a reply in that
+// thread, and Headers (In-Reply-To, References) thread it for recipients.
+type Outgoing struct {
+	From     string
+	To       string
+	Subject  string
+	Body     string
+	ThreadID string
+	Headers  map[string]string
+}
+
+func (r *SyncRequest) warnf(format string, args ...any) {
+	if r.Warnf != nil {
+		r.Warnf(format, args...)
+	}
+}
+
+func (r *SyncRequest) progress(phase string, done, total int) {
+	if r.Progress != nil {
+		r.progressMu.Lock()
+		defer r.progressMu.Unlock()
+		r.Progress(phase, done, total)
+	}
+}
+
+func (r *SyncRequest) workers() int {
+	if r.Workers > 0 {
+		return r.Workers
+	}
+	return DefaultWorkers
+}
+
+// parallel calls fn for each i in [0, n) on up to workers goroutines and
+// waits for them all.
+func parallel(n, workers int, fn func(i int)) {
+	next := make(chan int)
+	var wg gosync.WaitGroup
+	for range min(n, workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+var (
+	mu        gosync.RWMutex
+	providers = make(map[string]MailProvider)
+)
+
+// Register makes a provider available under name. It panics if name is
+// already taken, as database/sql.Register does.
+func Register(name string, p MailProvider) {
+	mu.Lock()
+	defer mu.Unlock()
+	if p == nil {
+		panic("provider: Register provider is nil")
+	}
+	if _, dup := providers[name]; dup {
+		panic("provider: Register called twice for provider " + name)
+	}
+	providers[name] = p
+}
+
+// Names returns the registered providers' names, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the provider registered under name.
+func Get(name string) (MailProvider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := providers[name]
+	return p, ok
+}
+
+// NameFor returns the name of the provider the config assigns account.
+func NameFor(cfg *config.Config, account string) string {
+	if ac, ok := cfg.Accounts[account]; ok && ac.Provider != "" {
+		return ac.Provider
+	}
+	return Default
+}
+
+// For returns account's provider and the Account to pass it.
+func For(cfg *config.Config, projectRoot, account string) (MailProvider, Account, error) {
+	name := NameFor(cfg, account)
+	p, ok := Get(name)
+	if !ok {
+		return nil, Account{}, fmt.Errorf("unknown mail provider %q (registered: %s)",
+			name, strings.Join(Names(), ", "))
+	}
+	return p, Account{
+		Address:     account,
+		ProjectRoot: projectRoot,
+		Settings:    cfg.Accounts[account].Settings,
+	}, nil
+}
+
+// Discover returns every account to sync, sorted: those each registered
+// provider discovers under projectRoot, plus those listed in the config.
+func Discover(cfg *config.Config, projectRoot string) []string {
+	seen := make(map[string]bool)
+	var accounts []string
+	add := func(account string) {
+		if !seen[account] {
+			seen[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+	for _, name := range Names() {
+		p, _ := Get(name)
+		for _, account := range p.Discover(projectRoot) {
+			add(account)
+		}
+	}
+	for account := range cfg.Accounts {
+		add(account)
+	}
+	sort.Strings(accounts)
+	return accounts
+}
@@ -0,0 +1,95 @@
+// Package quotes strips quoted reply chains and signatures from plain-text
+// email bodies, leaving only what the sender actually wrote in that message.
+package quotes
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Options controls what Strip removes.
+type Options struct {
+	// KeepSignatures leaves signature blocks ("-- " and mobile footers) in place.
+	KeepSignatures bool
+	// Markers are extra line prefixes that start a quoted reply or signature
+	// (e.g. a company disclaimer header). Matching is case-insensitive.
+	Markers []string
+}
+
+var (
+	// "On Mon, Jan 2, 2006 at 3:04 PM Bob <bob@example.com> wrote:" — may be
+	// wrapped over two lines by the sending client.
+	onWrote = regexp.MustCompile(`(?i)^\s*(on|am|le|el|il|op)\s.+(wrote|schrieb|a écrit|escribió|ha scritto|schreef)\s*:\s*$`)
+	onStart = regexp.MustCompile(`(?i)^\s*(on|am|le|el|il|op)\s.+`)
+
+	// Outlook-style reply headers.
+	originalMessage = regexp.MustCompile(`(?i)^\s*-{2,}\s*(original message|forwarded message|ursprüngliche nachricht)\s*-{2,}\s*$`)
+	outlookRule     = regexp.MustCompile(`^\s*_{20,}\s*$`)
+	headerFrom      = regexp.MustCompile(`(?i)^\s*\*?(from|von|de):\*?\s`)
+	headerNext      = regexp.MustCompile(`(?i)^\s*\*?(sent|date|to|subject|gesendet|an|envoyé):\*?\s`)
+
+	// Mobile client footers.
+	sentFrom = regexp.MustCompile(`(?i)^\s*(sent from my|sent from (outlook|mail) for|get outlook for|sent via)\b`)
+)
+
+// Strip removes quoted replies and, unless opts.KeepSignatures is set,
+// signatures from body. If stripping would leave nothing, the original body
+// is returned so a message is never blanked out.
+func Strip(body string, opts Options) string {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	lines := strings.Split(body, "\n")
+
+	cut := len(lines)
+	for i, line := range lines {
+		if startsReply(lines, i, opts.Markers) {
+			cut = i
+			break
+		}
+		if !opts.KeepSignatures && startsSignature(line) {
+			cut = i
+			break
+		}
+	}
+
+	var kept []string
+	for _, line := range lines[:cut] {
+		// Interleaved quoting: drop quoted lines, keep the inline answers.
+		if strings.HasPrefix(strings.TrimLeft(line, " "), ">") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	out := strings.TrimSpace(strings.Join(kept, "\n"))
+	if out == "" {
+		return strings.TrimSpace(body)
+	}
+	return out
+}
+
+// startsReply reports whether lines[i] begins a quoted reply chain.
+func startsReply(lines []string, i int, markers []string) bool {
+	line := lines[i]
+	switch {
+	case onWrote.MatchString(line):
+		return true
+	case onStart.MatchString(line) && i+1 < len(lines) && onWrote.MatchString(line+" "+lines[i+1]):
+		return true
+	case originalMessage.MatchString(line), outlookRule.MatchString(line):
+		return true
+	case headerFrom.MatchString(line) && i+1 < len(lines) && headerNext.MatchString(lines[i+1]):
+		return true
+	}
+	lower := strings.ToLower(strings.TrimSpace(line))
+	for _, m := range markers {
+		if m = strings.ToLower(strings.TrimSpace(m)); m != "" && strings.HasPrefix(lower, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// startsSignature reports whether line is a signature delimiter or footer.
+func startsSignature(line string) bool {
+	return line == "-- " || line == "--" || sentFrom.MatchString(line)
+}
@@ -0,0 +1,108 @@
+// Package schedule computes the run times of the recurring jobs mb serve
+// runs: every interval, or daily at a time of day on chosen weekdays.
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/config"
+)
+
+// Spec is a parsed job schedule.
+type Spec struct {
+	every  time.Duration
+	hour   int
+	minute int
+	days   map[time.Weekday]bool // nil = every day
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Parse validates a job's schedule: exactly one of Every (at least a
+// minute) and At, with Days only alongside At.
+func Parse(job config.ScheduledJob) (*Spec, error) {
+	if len(job.Args) == 0 {
+		return nil, fmt.Errorf("no args")
+	}
+	switch {
+	case job.Every != "" && job.At != "":
+		return nil, fmt.Errorf("set either every or at, not both")
+	case job.Every != "":
+		if len(job.Days) > 0 {
+			return nil, fmt.Errorf("days applies to at only")
+		}
+		d, err := time.ParseDuration(job.Every)
+		if err != nil {
+			return nil, fmt.Errorf("every: %w", err)
+		}
+		if d < time.Minute {
+			return nil, fmt.Errorf("every: %s is under a minute", d)
+		}
+		return &Spec{every: d}, nil
+	case job.At != "":
+		t, err := time.Parse("15:04", job.At)
+		if err != nil {
+			return nil, fmt.Errorf(`at: %q (want "HH:MM")`, job.At)
+		}
+		s := &Spec{hour: t.Hour(), minute: t.Minute()}
+		for _, d := range job.Days {
+			wd, ok := weekdays[strings.ToLower(d)]
+			if !ok {
+				return nil, fmt.Errorf(`days: %q (want "mon" ... "sun")`, d)
+			}
+			if s.days == nil {
+				s.days = make(map[time.Weekday]bool)
+			}
+			s.days[wd] = true
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("set every or at")
+	}
+}
+
+// Next returns the first run time after after. Interval jobs run every
+// interval from after.
+func (s *Spec) Next(after time.Time) time.Time {
+	if s.every > 0 {
+		return after.Add(s.every)
+	}
+	y, m, d := after.Date()
+	for i := 0; i <= 7; i++ {
+		t := time.Date(y, m, d+i, s.hour, s.minute, 0, 0, after.Location())
+		if t.After(after) && (s.days == nil || s.days[t.Weekday()]) {
+			return t
+		}
+	}
+	// Unreachable: some day of the next week always matches.
+	return after.Add(24 * time.Hour)
+}
+
+// Interval reports whether the job runs every interval, rather than at a
+// time of day.
+func (s *Spec) Interval() bool {
+	return s.every > 0
+}
+
+// String describes the schedule, e.g. "every 10m0s" or "at 08:00 mon,fri".
+func (s *Spec) String() string {
+	if s.every > 0 {
+		return "every " + s.every.String()
+	}
+	out := fmt.Sprintf("at %02d:%02d", s.hour, s.minute)
+	if s.days != nil {
+		var names []string
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			if s.days[wd] {
+				names = append(names, strings.ToLower(wd.String()[:3]))
+			}
+		}
+		out += " " + strings.Join(names, ",")
+	}
+	return out
+}
@@ -0,0 +1,268 @@
+// Package service installs mb as a background service that starts at
+// login and restarts on failure: a systemd user unit on Linux, a launchd
+// agent on macOS.
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Spec describes the service to install.
+type Spec struct {
+	// Name is the unit name or launchd label suffix, e.g. "mailbeads-inbox".
+	Name string
+	// Exe and Args are the command line; Exe is an absolute path.
+	Exe  string
+	Args []string
+	// Dir is the working directory: the project root.
+	Dir string
+	// Env is set in the service's environment.
+	Env map[string]string
+	// LogPath receives the service's output under launchd; systemd logs to
+	// the journal.
+	LogPath string
+}
+
+// Status is the state of an installed service.
+type Status struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Installed bool   `json:"installed"`
+	Running   bool   `json:"running"`
+	// Detail is the service manager's own status report.
+	Detail string `json:"detail,omitempty"`
+}
+
+// Supported reports whether services can be installed on this system.
+func Supported() error {
+	switch runtime.GOOS {
+	case "linux", "darwin":
+		return nil
+	default:
+		return fmt.Errorf("services are supported on Linux (systemd) and macOS (launchd), not %s", runtime.GOOS)
+	}
+}
+
+// label is the launchd label of a service.
+func label(name string) string {
+	return "com.mailbeads." + name
+}
+
+// Path returns where the unit file or plist of the named service lives.
+func Path(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(home, "Library", "LaunchAgents", label(name)+".plist"), nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "systemd", "user", name+".service"), nil
+}
+
+// Render returns the unit file or plist for spec.
+func Render(spec Spec) string {
+	if runtime.GOOS == "darwin" {
+		return renderPlist(spec)
+	}
+	return renderUnit(spec)
+}
+
+// Install writes the service file and starts the service, now and at
+// every login. Returns the file's path.
+func Install(spec Spec) (string, error) {
+	if err := Supported(); err != nil {
+		return "", err
+	}
+	path, err := Path(spec.Name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	// The environment may hold tokens.
+	if err := os.WriteFile(path, []byte(Render(spec)), 0o600); err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "darwin" {
+		domain := fmt.Sprintf("gui/%d", os.Getuid())
+		// Replace a loaded older version; bootout fails if none is loaded.
+		run("launchctl", "bootout", domain+"/"+label(spec.Name))
+		_, err = run("launchctl", "bootstrap", domain, path)
+		return path, err
+	}
+	if _, err := run("systemctl", "--user", "daemon-reload"); err != nil {
+		return path, err
+	}
+	if _, err := run("systemctl", "--user", "enable", spec.Name+".service"); err != nil {
+		return path, err
+	}
+	_, err = run("systemctl", "--user", "restart", spec.Name+".service")
+	return path, err
+}
+
+// Uninstall stops the named service and removes its file.
+func Uninstall(name string) (string, error) {
+	if err := Supported(); err != nil {
+		return "", err
+	}
+	path, err := Path(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return path, fmt.Errorf("service %s is not installed (no %s)", name, path)
+	}
+	if runtime.GOOS == "darwin" {
+		run("launchctl", "bootout", fmt.Sprintf("gui/%d/%s", os.Getuid(), label(name)))
+		return path, os.Remove(path)
+	}
+	if _, err := run("systemctl", "--user", "disable", "--now", name+".service"); err != nil {
+		return path, err
+	}
+	if err := os.Remove(path); err != nil {
+		return path, err
+	}
+	_, err = run("systemctl", "--user", "daemon-reload")
+	return path, err
+}
+
+// Query reports whether the named service is installed and running.
+func Query(name string) (*Status, error) {
+	if err := Supported(); err != nil {
+		return nil, err
+	}
+	path, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+	st := &Status{Name: name, Path: path}
+	if _, err := os.Stat(path); err != nil {
+		return st, nil
+	}
+	st.Installed = true
+	if runtime.GOOS == "darwin" {
+		out, err := run("launchctl", "print", fmt.Sprintf("gui/%d/%s", os.Getuid(), label(name)))
+		st.Running = err == nil && strings.Contains(out, "state = running")
+		st.Detail = out
+		return st, nil
+	}
+	out, _ := run("systemctl", "--user", "is-active", name+".service")
+	st.Running = strings.TrimSpace(out) == "active"
+	// status exits non-zero for stopped units; its report is still useful.
+	st.Detail, _ = run("systemctl", "--user", "status", "--no-pager", "--lines=10", name+".service")
+	return st, nil
+}
+
+// run runs a service manager command, returning its combined output.
+func run(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return string(out), fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, msg)
+		}
+		return string(out), fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+func renderUnit(spec Spec) string {
+	var b strings.Builder
+	b.WriteString("# Installed by mb service install.\n")
+	b.WriteString("[Unit]\n")
+	desc := fmt.Sprintf("mailbeads (mb %s) in %s", strings.Join(spec.Args, " "), spec.Dir)
+	fmt.Fprintf(&b, "Description=%s\n", strings.ReplaceAll(desc, "%", "%%"))
+	b.WriteString("After=network-online.target\n")
+	b.WriteString("Wants=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(spec.Dir))
+	for _, k := range sortedKeys(spec.Env) {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(k+"="+spec.Env[k]))
+	}
+	// Only command lines expand $VARIABLES.
+	var words []string
+	for _, a := range append([]string{spec.Exe}, spec.Args...) {
+		words = append(words, systemdQuote(strings.ReplaceAll(a, "$", "$$")))
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(words, " "))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=10\n")
+	// mb watch and mb serve stop cleanly on SIGINT.
+	b.WriteString("KillSignal=SIGINT\n\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdQuote quotes a word for a unit file, escaping specifiers.
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if s != "" && !strings.ContainsAny(s, " \t\"';\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func renderPlist(spec Spec) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<!-- Installed by mb service install. -->
+<plist version="1.0">
+<dict>
+`)
+	key := func(k, v string) {
+		fmt.Fprintf(&b, "\t<key>%s</key>\n\t<string>%s</string>\n", k, xmlEscape(v))
+	}
+	key("Label", label(spec.Name))
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, a := range append([]string{spec.Exe}, spec.Args...) {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", xmlEscape(a))
+	}
+	b.WriteString("\t</array>\n")
+	key("WorkingDirectory", spec.Dir)
+	if len(spec.Env) > 0 {
+		b.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, k := range sortedKeys(spec.Env) {
+			fmt.Fprintf(&b, "\t\t<key>%s</key>\n\t\t<string>%s</string>\n", xmlEscape(k), xmlEscape(spec.Env[k]))
+		}
+		b.WriteString("\t</dict>\n")
+	}
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	// Restart after a crash, not after a clean exit.
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("\t<key>ThrottleInterval</key>\n\t<integer>10</integer>\n")
+	if spec.LogPath != "" {
+		key("StandardOutPath", spec.LogPath)
+		key("StandardErrorPath", spec.LogPath)
+	}
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
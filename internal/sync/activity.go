@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/config"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/notify"
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// applyActivityRules applies the first of rules that matches a triaged
+// thread with new mail to the thread's bead. It returns a description of
+// the change for the bead comment, or "" if nothing changed. The previous
+// fields are journaled, so mb undo reverts the change.
+func applyActivityRules(store db.Store, rules []config.ActivityRule, t *types.Thread) (string, error) {
+	if len(rules) == 0 {
+		return "", nil
+	}
+	beadID := t.TriageRef.BeadID
+	issue, err := beads.Show(beadID)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range rules {
+		if len(r.Statuses) > 0 && !slices.Contains(r.Statuses, issue.Status) {
+			continue
+		}
+		if len(r.Senders) > 0 && !notify.MatchSender(r.Senders, t.From) {
+			continue
+		}
+		if len(r.Accounts) > 0 && !slices.ContainsFunc(r.Accounts, func(a string) bool {
+			return a != "" && strings.Contains(strings.ToLower(t.Account), strings.ToLower(a))
+		}) {
+			continue
+		}
+		return applyActivityRule(store, r, issue)
+	}
+	return "", nil
+}
+
+// applyActivityRule updates issue as r says.
+func applyActivityRule(store db.Store, r config.ActivityRule, issue *beads.Issue) (string, error) {
+	if r.Priority != "" && r.Priority != "bump" && !types.IsValidPriority(r.Priority) {
+		return "", fmt.Errorf("on_new_mail rule %q: invalid priority %q (must be: high, medium, low, spam, bump)", r.Name, r.Priority)
+	}
+
+	fields := make(map[string]string)
+	previous := make(map[string]string)
+	var changes []string
+
+	current := beads.PriorityFromBeads(issue.Priority)
+	priority := r.Priority
+	if priority == "bump" {
+		priority = bumpPriority(current)
+	}
+	if priority != "" && priority != current {
+		fields["priority"] = beads.PriorityToBeads(priority)
+		previous["priority"] = strconv.Itoa(issue.Priority)
+		changes = append(changes, "priority "+current+" → "+priority)
+	}
+
+	if r.Status != "" && r.Status != issue.Status {
+		if issue.Status == "closed" {
+			if err := beads.Reopen(issue.ID, "new email activity"); err != nil {
+				return "", err
+			}
+			previous["status"] = issue.Status
+		}
+		if r.Status != "open" || issue.Status != "closed" {
+			fields["status"] = r.Status
+			previous["status"] = issue.Status
+		}
+		if r.Status == "open" && issue.DeferUntil != "" {
+			fields["defer"] = ""
+			previous["defer"] = issue.DeferUntil
+		}
+		changes = append(changes, "status "+issue.Status+" → "+r.Status)
+	}
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	if len(fields) > 0 {
+		if err := beads.Update(issue.ID, fields); err != nil {
+			return "", err
+		}
+	}
+	if _, ok := fields["priority"]; ok {
+		if err := store.SetTriagePriority(issue.ID, priority); err != nil {
+			return "", err
+		}
+	}
+	if err := store.RecordOp(&types.Operation{Op: types.OpUpdate, BeadID: issue.ID, Previous: previous}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (rule %q)", strings.Join(changes, ", "), r.Name), nil
+}
+
+// bumpPriority returns the priority one level more urgent than p.
+func bumpPriority(p string) string {
+	switch p {
+	case types.PrioritySpam:
+		return types.PriorityLow
+	case types.PriorityLow:
+		return types.PriorityMedium
+	default:
+		return types.PriorityHigh
+	}
+}
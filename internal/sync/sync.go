@@ -1,216 +1,298 @@
-// Package sync fetches emails from Gmail using native Go API calls.
+// Package sync fetches emails from each account's mail provider into the
+// database.
 package sync
 
 import (
-	"context"
 	"fmt"
-	"os"
-	"path/filepath"
-	"sort"
+	"log/slog"
 	"strings"
 	"time"
 
-	"github.com/daviddao/mailbeads/internal/auth"
 	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/config"
 	"github.com/daviddao/mailbeads/internal/db"
-	"github.com/daviddao/mailbeads/internal/gmail"
+	"github.com/daviddao/mailbeads/internal/display"
+	"github.com/daviddao/mailbeads/internal/ical"
+	"github.com/daviddao/mailbeads/internal/provider"
 	"github.com/daviddao/mailbeads/internal/types"
 )
 
-// DiscoverAccounts finds accounts by scanning for */credentials.json
-// directories in the project root. Returns email addresses (directory names).
-func DiscoverAccounts(projectRoot string) []string {
-	entries, err := os.ReadDir(projectRoot)
-	if err != nil {
-		return nil
-	}
-
-	var accounts []string
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-		name := entry.Name()
-		// Look for directories that contain credentials.json and look like email addresses.
-		if !strings.Contains(name, "@") {
-			continue
-		}
-		credPath := filepath.Join(projectRoot, name, "credentials.json")
-		if _, err := os.Stat(credPath); err == nil {
-			accounts = append(accounts, name)
-		}
-	}
-
-	sort.Strings(accounts)
-	return accounts
+// DiscoverAccounts returns the accounts to sync: those the registered
+// providers find in the project root (for Gmail, */credentials.json
+// directories), plus those configured in cfg.Accounts.
+func DiscoverAccounts(cfg *config.Config, projectRoot string) []string {
+	return provider.Discover(cfg, projectRoot)
 }
 
-// toGmailDate converts an ISO date to Gmail after: format.
-func toGmailDate(isoDate string) string {
+// parseDate parses a stored ISO date.
+func parseDate(isoDate string) time.Time {
 	for _, layout := range []string{time.RFC3339, time.RFC3339Nano, "2006-01-02T15:04:05Z"} {
 		if t, err := time.Parse(layout, isoDate); err == nil {
-			return t.Format("2006/01/02")
+			return t
 		}
 	}
-	return ""
+	return time.Time{}
 }
 
-// SyncAccount fetches emails for a single account using native Go Gmail API.
-func SyncAccount(store *db.DB, projectRoot, account string, forceFull bool, includeSpam bool, quiet bool) (*types.SyncResult, error) {
-	result := &types.SyncResult{Account: account}
-	ctx := context.Background()
+// Options adjusts what SyncAccount fetches and prints.
+type Options struct {
+	// Full rescans the last 72 hours instead of resuming after the latest
+	// stored message.
+	Full bool
+	// IncludeSpam syncs all mail, not just the inbox.
+	IncludeSpam bool
+	// Query, in the provider's search syntax, replaces the automatic
+	// window: exactly the matching messages are synced. Full and
+	// IncludeSpam don't apply.
+	Query string
+	// Max, if positive, caps the new messages fetched per account to the
+	// newest Max; older ones are left unsynced.
+	Max int
+	// HeadersOnly stores messages from their metadata without fetching
+	// bodies; mb show fetches those on demand.
+	HeadersOnly bool
+	// StoreRaw also fetches and stores each new message's RFC 822 source.
+	// HeadersOnly takes precedence.
+	StoreRaw bool
+	// DryRun lists the new messages without fetching their bodies or
+	// storing anything; they are reported in the result's WouldFetch.
+	DryRun bool
+	// Quiet suppresses progress output.
+	Quiet bool
+}
 
-	credPath := filepath.Join(projectRoot, account, "credentials.json")
-	if _, err := os.Stat(credPath); err != nil {
-		result.Error = "credentials not found"
+// SyncAccount fetches new emails for a single account from its provider.
+// Provider failures are reported in the result's Error.
+func SyncAccount(store db.Store, cfg *config.Config, projectRoot, account string, opts Options) (*types.SyncResult, error) {
+	quiet := opts.Quiet
+	result := &types.SyncResult{Account: account}
+	fail := func(err error) (*types.SyncResult, error) {
+		result.Error = err.Error()
+		// Callers report result.Error; log it only where nobody else will.
 		if !quiet {
-			fmt.Fprintf(os.Stderr, "  ! %s — credentials not found, skipping\n", account)
+			slog.Warn("sync failed, skipping account", "account", account, "err", err)
 		}
 		return result, nil
 	}
 
-	// Authenticate and get Gmail service.
-	svc, err := auth.LoadGmailService(ctx, credPath)
+	p, acct, err := provider.For(cfg, projectRoot, account)
 	if err != nil {
-		result.Error = fmt.Sprintf("auth failed: %v", err)
-		if !quiet {
-			fmt.Fprintf(os.Stderr, "  ! %s — auth failed: %v\n", account, err)
-		}
-		return result, nil
+		return fail(err)
 	}
 
-	// Determine search window.
-	var query string
-	latestDate := store.LatestEmailDate(account)
-
-	if !forceFull && latestDate != "" {
-		gmailDate := toGmailDate(latestDate)
-		if gmailDate != "" {
-			query = "after:" + gmailDate
-			if !quiet {
-				fmt.Printf("\n  %s — incremental (after %s)\n", account, gmailDate)
-			}
-		}
+	req := &provider.SyncRequest{
+		IncludeSpam: opts.IncludeSpam,
+		Query:       opts.Query,
+		Max:         opts.Max,
+		Workers:     cfg.Sync.Workers,
+		HeadersOnly: opts.HeadersOnly || opts.DryRun,
+		Raw:         opts.StoreRaw && !opts.HeadersOnly && !opts.DryRun,
+		Known:       store.ExistingEmailIDs,
 	}
-	if query == "" {
-		query = "newer_than:3d"
-		if !quiet {
+	if !opts.Full && opts.Query == "" {
+		req.Since = parseDate(store.LatestEmailDate(account))
+	}
+	// Only the default window is compared against the last sync's cursor:
+	// a --full, --include-spam, or --query run covers other mail.
+	defaultWindow := !opts.Full && !opts.IncludeSpam && opts.Query == "" && !opts.DryRun
+	if defaultWindow {
+		req.Cursor = store.SyncCursor(account)
+	}
+	var progress *display.Progress
+	if !quiet {
+		if opts.Query != "" {
+			fmt.Printf("\n  %s — query %q\n", account, opts.Query)
+		} else if req.Since.IsZero() {
 			fmt.Printf("\n  %s — full sync (last 72h)\n", account)
+		} else {
+			fmt.Printf("\n  %s — incremental (after %s)\n", account, req.Since.Format("2006/01/02"))
 		}
+		progress = display.NewProgress()
+		req.Progress = progress.Update
 	}
 
-	// Only sync inbox by default (excludes drafts, sent-only, spam, trash).
-	if !includeSpam {
-		query += " in:inbox"
+	req.Warnf = func(format string, args ...any) {
+		slog.Warn(fmt.Sprintf(format, args...), "account", account)
 	}
 
-	// Search Gmail natively.
-	results, err := gmail.Search(svc, query, 100)
+	batch, err := p.Sync(acct, req)
+	if progress != nil {
+		progress.Done()
+	}
 	if err != nil {
-		result.Error = fmt.Sprintf("search failed: %v", err)
+		return fail(err)
+	}
+	if batch.Unchanged {
+		result.Unchanged = true
 		if !quiet {
-			fmt.Fprintf(os.Stderr, "  ! search failed: %v\n", err)
+			fmt.Println("  ✓ unchanged since last sync")
 		}
 		return result, nil
 	}
-
-	// Filter already-synced.
-	var newEmails []gmail.MessageSummary
-	for _, r := range results {
-		if !store.EmailExists(r.ID) {
-			newEmails = append(newEmails, r)
+	// Save the cursor once the batch is stored; mail left out by --max or
+	// that failed to read must still be listed next time.
+	saveCursor := func() {
+		if defaultWindow && batch.Cursor != "" && batch.Capped == 0 && batch.Failed == 0 {
+			if err := store.SetSyncCursor(account, batch.Cursor); err != nil {
+				slog.Warn("failed to save sync cursor", "account", account, "err", err)
+			}
 		}
 	}
 
 	if !quiet {
-		fmt.Printf("  Found %d results, %d new\n", len(results), len(newEmails))
+		if batch.Capped > 0 {
+			fmt.Printf("  Found %d results, %d new, fetching the newest %d\n", batch.Listed, len(batch.Messages)+batch.Capped, len(batch.Messages))
+		} else {
+			fmt.Printf("  Found %d results, %d new\n", batch.Listed, len(batch.Messages))
+		}
+	}
+	// Apply label changes before storing new messages, whose labels are
+	// already current.
+	if !opts.DryRun {
+		for _, u := range batch.Updates {
+			changed, err := store.SetEmailLabels(u.ID, u.Labels, u.IsRead)
+			if err != nil {
+				slog.Warn("failed to update labels", "email", u.ID, "err", err)
+			} else if changed {
+				result.Updated++
+			}
+		}
+		if result.Updated > 0 && !quiet {
+			fmt.Printf("  ✓ %d updated (labels, read state)\n", result.Updated)
+		}
 	}
 
-	if len(newEmails) == 0 {
-		result.Skipped = len(results)
+	result.Capped = batch.Capped
+	result.Skipped = batch.Listed - len(batch.Messages) - batch.Capped - batch.Failed
+	if len(batch.Messages) == 0 {
 		if !quiet {
-			fmt.Printf("  ✓ 0 new, %d already synced\n", len(results))
+			fmt.Printf("  ✓ 0 new, %d already synced\n", batch.Listed)
 		}
+		saveCursor()
 		return result, nil
 	}
 
-	// Fetch full content for new emails.
-	now := time.Now().UTC().Format(time.RFC3339)
-
-	for i, email := range newEmails {
-		full, err := gmail.ReadFull(svc, email.ID)
-		if err != nil {
+	if opts.DryRun {
+		for _, m := range batch.Messages {
+			e := m.Email
+			result.WouldFetch = append(result.WouldFetch, types.SyncPreview{
+				ID: e.ID, ThreadID: e.ThreadID, From: e.From, Subject: e.Subject, Date: e.Date,
+			})
 			if !quiet {
-				fmt.Fprintf(os.Stderr, "  ! failed to read %s: %v\n", email.ID, err)
-			}
-			continue
-		}
-
-		labels := strings.Join(full.Labels, ",")
-		isRead := 1
-		for _, l := range full.Labels {
-			if l == "UNREAD" {
-				isRead = 0
-				break
+				fmt.Printf("  [dry-run] Would fetch %s  %s  %s\n", e.ID, e.From, e.Subject)
 			}
 		}
+		return result, nil
+	}
 
-		fromAddr := full.From
-		if fromAddr == "" {
-			fromAddr = email.From
+	seen := make(map[string]bool)
+	for i, m := range batch.Messages {
+		if progress != nil {
+			progress.Update("store", i, len(batch.Messages))
 		}
-		subject := full.Subject
-		if subject == "" {
-			subject = email.Subject
+		e := m.Email
+		if err := store.InsertEmail(e); err != nil {
+			continue
 		}
-		date := full.Date
-		if date == "" {
-			date = email.Date
+		result.Fetched++
+		if !seen[e.ThreadID] {
+			seen[e.ThreadID] = true
+			result.Threads = append(result.Threads, e.ThreadID)
 		}
-
-		e := &types.Email{
-			ID:        full.ID,
-			Account:   account,
-			ThreadID:  full.ThreadID,
-			MessageID: full.MessageID,
-			From:      fromAddr,
-			To:        full.To,
-			CC:        full.CC,
-			Subject:   subject,
-			Snippet:   email.Snippet,
-			Body:      full.Body,
-			Date:      date,
-			Labels:    labels,
-			IsRead:    isRead,
-			FetchedAt: now,
+		store.RecordSender(e.From, e.Date)
+		if m.ICS != "" {
+			RecordInvites(store, e, m.ICS)
 		}
-
-		if err := store.InsertEmail(e); err == nil {
-			result.Fetched++
+		if m.Raw != nil {
+			if err := store.StoreRawSource(e.ID, m.Raw); err != nil {
+				slog.Warn("failed to store raw source", "email", e.ID, "err", err)
+			}
 		}
+	}
 
-		if !quiet {
-			fmt.Fprintf(os.Stdout, "  Fetching %d/%d...\r", i+1, len(newEmails))
-		}
+	if progress != nil {
+		progress.Update("store", len(batch.Messages), len(batch.Messages))
+		progress.Done()
 	}
 
-	result.Skipped = len(results) - len(newEmails)
 	if !quiet {
-		fmt.Printf("  ✓ %d new, %d already synced              \n", result.Fetched, result.Skipped)
+		fmt.Printf("  ✓ %d new, %d already synced\n", result.Fetched, result.Skipped)
+		if result.Capped > 0 {
+			fmt.Printf("  %d older new emails left unsynced (--max)\n", result.Capped)
+		}
+	}
+	saveCursor()
+
+	// Threads that duplicate an already-triaged thread in another account
+	// share its bead.
+	for _, threadID := range result.Threads {
+		if ref, err := store.DuplicateTriageRef(threadID, account); err == nil && ref != nil {
+			if existing, err := store.GetTriageRef(threadID, account); err == nil && existing == nil {
+				store.UpsertTriageRef(threadID, account, ref.BeadID)
+			}
+		}
 	}
 
 	// Auto-comment on beads issues for triaged threads that received new emails.
 	if result.Fetched > 0 && beads.Available() {
-		commented := notifyNewEmails(store, quiet)
+		commented := notifyNewEmails(store, cfg.Triage.OnNewMail, quiet)
 		result.Commented = commented
 	}
 
 	return result, nil
 }
 
+// RecordInvites parses iCalendar data attached to an email and stores each
+// event. Returns the number of events stored.
+func RecordInvites(store db.Store, e *types.Email, ics string) int {
+	cal := ical.Parse(ics)
+	stored := 0
+	for _, ev := range cal.Events {
+		if ev.UID == "" || ev.Start.IsZero() {
+			continue
+		}
+		ce := &types.CalendarEvent{
+			EmailID:   e.ID,
+			Account:   e.Account,
+			ThreadID:  e.ThreadID,
+			UID:       ev.UID,
+			Method:    cal.Method,
+			Summary:   ev.Summary,
+			Location:  ev.Location,
+			Organizer: formatPerson(ev.Organizer),
+			Start:     ev.Start.UTC().Format(time.RFC3339),
+			AllDay:    ev.AllDay,
+			Status:    ev.Status,
+			ICS:       ics,
+		}
+		if !ev.End.IsZero() {
+			ce.End = ev.End.UTC().Format(time.RFC3339)
+		}
+		attendees := make([]string, 0, len(ev.Attendees))
+		for _, a := range ev.Attendees {
+			attendees = append(attendees, formatPerson(a))
+		}
+		ce.Attendees = strings.Join(attendees, ", ")
+
+		if err := store.InsertEvent(ce); err == nil {
+			stored++
+		}
+	}
+	return stored
+}
+
+// formatPerson renders an iCalendar person like an email address header.
+func formatPerson(p ical.Person) string {
+	if p.Name == "" {
+		return p.Email
+	}
+	return fmt.Sprintf("%s <%s>", p.Name, p.Email)
+}
+
 // notifyNewEmails checks for triaged threads that have new emails and adds
-// a comment to the corresponding beads issue.
-func notifyNewEmails(store *db.DB, quiet bool) int {
+// a comment to the corresponding beads issue, first applying the matching
+// on_new_mail rule, if any, to the issue.
+func notifyNewEmails(store db.Store, rules []config.ActivityRule, quiet bool) int {
 	threads, err := store.ThreadsWithNewEmails()
 	if err != nil || len(threads) == 0 {
 		return 0
@@ -228,11 +310,16 @@ func notifyNewEmails(store *db.DB, quiet bool) int {
 
 		comment := fmt.Sprintf("New email activity on thread: %s (%d emails, latest from %s)",
 			t.Subject, t.EmailCount, t.From)
+		change, err := applyActivityRules(store, rules, t)
+		if err != nil {
+			slog.Warn("failed to apply on_new_mail rule", "bead", t.TriageRef.BeadID, "err", err)
+		}
+		if change != "" {
+			comment += "; " + change
+		}
 
 		if err := beads.Comment(t.TriageRef.BeadID, comment); err != nil {
-			if !quiet {
-				fmt.Fprintf(os.Stderr, "  ! comment on %s: %v\n", t.TriageRef.BeadID, err)
-			}
+			slog.Warn("failed to comment on bead", "bead", t.TriageRef.BeadID, "err", err)
 			continue
 		}
 
@@ -242,6 +329,9 @@ func notifyNewEmails(store *db.DB, quiet bool) int {
 		commented++
 		if !quiet {
 			fmt.Printf("  → Notified %s of new email on %q\n", t.TriageRef.BeadID, t.Subject)
+			if change != "" {
+				fmt.Printf("    %s\n", display.Dim.Render(change))
+			}
 		}
 	}
 	return commented
@@ -0,0 +1,71 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	gtasks "google.golang.org/api/tasks/v1"
+)
+
+// Google mirrors beads into a Google Tasks list.
+type Google struct {
+	ctx    context.Context
+	svc    *gtasks.Service
+	listID string
+}
+
+// NewGoogle uses the task list titled list, creating it if missing, or
+// the account's default list if list is empty.
+func NewGoogle(ctx context.Context, svc *gtasks.Service, list string) (*Google, error) {
+	g := &Google{ctx: ctx, svc: svc, listID: "@default"}
+	if list == "" {
+		return g, nil
+	}
+	err := svc.Tasklists.List().MaxResults(100).Pages(ctx, func(page *gtasks.TaskLists) error {
+		for _, l := range page.Items {
+			if l.Title == list && g.listID == "@default" {
+				g.listID = l.Id
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("google tasks: list task lists: %w", err)
+	}
+	if g.listID == "@default" {
+		created, err := svc.Tasklists.Insert(&gtasks.TaskList{Title: list}).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("google tasks: create list %q: %w", list, err)
+		}
+		g.listID = created.Id
+	}
+	return g, nil
+}
+
+func (g *Google) Name() string { return "google" }
+
+// Create adds a task. Google Tasks has no priorities, and keeps only the
+// date of a due time.
+func (g *Google) Create(task Task) (string, error) {
+	t := &gtasks.Task{
+		Title: truncate(task.Title, 1000),
+		Notes: truncate(task.Notes, 8000),
+	}
+	if task.Due != "" {
+		t.Due = task.Due + "T00:00:00.000Z"
+	}
+	created, err := g.svc.Tasks.Insert(g.listID, t).Context(g.ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("google tasks: create task: %w", err)
+	}
+	return created.Id, nil
+}
+
+// Complete marks a task completed.
+func (g *Google) Complete(id string) error {
+	_, err := g.svc.Tasks.Patch(g.listID, id, &gtasks.Task{Status: "completed"}).Context(g.ctx).Do()
+	if err != nil {
+		return fmt.Errorf("google tasks: complete task: %w", err)
+	}
+	return nil
+}
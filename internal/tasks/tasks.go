@@ -0,0 +1,34 @@
+// Package tasks mirrors pending email beads into a personal task manager
+// (Todoist or Google Tasks), for users who work from their todo app.
+package tasks
+
+// Task is a bead as mirrored into a task manager.
+type Task struct {
+	// Title is the bead's action.
+	Title string
+	// Notes describe the bead: its suggestion and a link to the thread.
+	Notes string
+	// Due is the bead's due date (YYYY-MM-DD), if any.
+	Due string
+	// Priority is the mailbeads priority: high, medium, or low.
+	Priority string
+}
+
+// Manager is a task manager beads are mirrored into.
+type Manager interface {
+	// Name is the provider ("todoist" or "google"), which is also the key
+	// the task ID is recorded under in the bead's notes.
+	Name() string
+	// Create adds a task and returns its ID.
+	Create(t Task) (string, error)
+	// Complete marks a task as done.
+	Complete(id string) error
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
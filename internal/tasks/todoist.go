@@ -0,0 +1,166 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/daviddao/mailbeads/internal/config"
+)
+
+// TodoistTokenEnv holds the Todoist API token.
+const TodoistTokenEnv = "TODOIST_API_TOKEN"
+
+const todoistAPI = "https://api.todoist.com/api/v1"
+
+// Todoist mirrors beads into Todoist.
+type Todoist struct {
+	token     string
+	projectID string
+	labels    []string
+	client    *http.Client
+}
+
+// NewTodoist connects to Todoist with the token in $TODOIST_API_TOKEN and
+// looks up cfg.Project, if set.
+func NewTodoist(cfg config.TodoistConfig) (*Todoist, error) {
+	token := os.Getenv(TodoistTokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("no Todoist API token: set $%s", TodoistTokenEnv)
+	}
+	t := &Todoist{token: token, labels: cfg.Labels, client: &http.Client{Timeout: 30 * time.Second}}
+	if cfg.Project != "" {
+		id, err := t.project(cfg.Project)
+		if err != nil {
+			return nil, err
+		}
+		t.projectID = id
+	}
+	return t, nil
+}
+
+func (t *Todoist) Name() string { return "todoist" }
+
+// Create adds a task. Todoist's priorities run from 1 (normal) to 4
+// (urgent).
+func (t *Todoist) Create(task Task) (string, error) {
+	payload := map[string]any{
+		"content":     truncate(task.Title, 500),
+		"description": task.Notes,
+		"priority":    todoistPriority(task.Priority),
+	}
+	if task.Due != "" {
+		payload["due_date"] = task.Due
+	}
+	if t.projectID != "" {
+		payload["project_id"] = t.projectID
+	}
+	if len(t.labels) > 0 {
+		payload["labels"] = t.labels
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := t.do(http.MethodPost, "/tasks", payload, &created); err != nil {
+		return "", err
+	}
+	if created.ID == "" {
+		return "", fmt.Errorf("todoist: no task ID in response")
+	}
+	return created.ID, nil
+}
+
+// Complete closes a task.
+func (t *Todoist) Complete(id string) error {
+	return t.do(http.MethodPost, "/tasks/"+url.PathEscape(id)+"/close", nil, nil)
+}
+
+// project returns the ID of the project named name.
+func (t *Todoist) project(name string) (string, error) {
+	cursor := ""
+	for {
+		path := "/projects?limit=200"
+		if cursor != "" {
+			path += "&cursor=" + url.QueryEscape(cursor)
+		}
+		var page struct {
+			Results []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"results"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := t.do(http.MethodGet, path, nil, &page); err != nil {
+			return "", err
+		}
+		for _, p := range page.Results {
+			if strings.EqualFold(p.Name, name) {
+				return p.ID, nil
+			}
+		}
+		if page.NextCursor == "" {
+			return "", fmt.Errorf("no Todoist project named %q", name)
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// do calls the Todoist API, decoding the JSON response into out unless
+// it is nil.
+func (t *Todoist) do(method, path string, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, todoistAPI+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("todoist: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("todoist: read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		if msg := bytes.TrimSpace(data); len(msg) > 0 {
+			return fmt.Errorf("todoist: %s %s: %s: %s", method, path, resp.Status, msg)
+		}
+		return fmt.Errorf("todoist: %s %s: %s", method, path, resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("todoist: parse response: %w", err)
+	}
+	return nil
+}
+
+func todoistPriority(p string) int {
+	switch p {
+	case "high":
+		return 4
+	case "medium":
+		return 3
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,118 @@
+// Package triage writes a triage decision in two steps: Stage creates or
+// updates its beads issue through bd, and Record stores the thread's
+// triage ref, undo journal entry, and audit entry in one transaction,
+// reverting the issue if that fails. mb triage and the public mailbeads
+// package both go through it, so the two can't drift apart.
+package triage
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// Decision is a triage decision for a thread in a known account.
+type Decision struct {
+	ThreadID string
+	Account  string
+	// Priority is the mailbeads priority (high, medium, low, spam).
+	Priority string
+	// Title is the bead's title: the action to take.
+	Title string
+	// Description, if set, replaces the bead's description.
+	Description string
+	// Due, if set, is the bead's due date.
+	Due string
+	// Bead is the bead to update, or "" to create one.
+	Bead string
+
+	// Notes, Category, and Epic only apply to a created bead.
+	Notes    string
+	Category string
+	Epic     string
+}
+
+// Stage creates d's bead, or updates d.Bead after saving the fields it
+// changes for undo, and returns the record for Record to store. Its Audit
+// entry is left for the caller to fill in; its Op says whether the bead
+// was created (types.OpTriage) or updated. warnf, if not nil, reports an
+// update whose previous fields couldn't be read, so undo can't restore
+// them.
+func Stage(d Decision, warnf func(format string, args ...any)) (*types.TriageRecord, error) {
+	bdPriority := beads.PriorityToBeads(d.Priority)
+	op := &types.Operation{Op: types.OpTriage, BeadID: d.Bead}
+	if d.Bead != "" {
+		op.Op = types.OpUpdate
+		if issue, err := beads.Show(d.Bead); err != nil {
+			if warnf != nil {
+				warnf("read %s for undo journal: %v", d.Bead, err)
+			}
+		} else {
+			op.Previous = map[string]string{"title": issue.Title, "priority": strconv.Itoa(issue.Priority)}
+			if d.Description != "" {
+				op.Previous["description"] = issue.Description
+			}
+			if d.Due != "" {
+				op.Previous["due"] = issue.DueAt
+			}
+		}
+		fields := map[string]string{"title": d.Title, "priority": bdPriority}
+		if d.Description != "" {
+			fields["description"] = d.Description
+		}
+		if d.Due != "" {
+			fields["due"] = d.Due
+		}
+		if err := beads.Update(d.Bead, fields); err != nil {
+			return nil, fmt.Errorf("update beads issue: %w", err)
+		}
+	} else {
+		issue, err := beads.Create(d.Title, d.Description, d.Notes, bdPriority,
+			d.Category, d.Epic, d.Due, nil, d.ThreadID)
+		if err != nil {
+			return nil, fmt.Errorf("create beads issue: %w", err)
+		}
+		op.BeadID = issue.ID
+	}
+	return &types.TriageRecord{
+		ThreadID: d.ThreadID,
+		Account:  d.Account,
+		BeadID:   op.BeadID,
+		Priority: d.Priority,
+		Op:       op,
+	}, nil
+}
+
+// Record stores staged decisions in one transaction. If that fails, their
+// beads are reverted and the error includes any revert failures.
+func Record(store db.Store, recs []*types.TriageRecord) error {
+	if err := store.RecordTriages(recs); err != nil {
+		return errors.Join(fmt.Errorf("record triage: %w", err), Revert(recs))
+	}
+	return nil
+}
+
+// Revert undoes the bead writes of staged decisions that won't be
+// recorded: created beads are closed and updated ones get their previous
+// fields back. Decisions queued for review wrote no bead.
+func Revert(recs []*types.TriageRecord) error {
+	var errs []error
+	for _, r := range recs {
+		var err error
+		switch {
+		case r.Suggestion != nil:
+		case r.Op.Op == types.OpTriage:
+			err = beads.Close(r.BeadID, "triage not recorded")
+		case r.Op.Previous != nil:
+			err = beads.Update(r.BeadID, r.Op.Previous)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("revert %s: %w", r.BeadID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
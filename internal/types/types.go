@@ -13,10 +13,35 @@ type Email struct {
 	Subject   string `json:"subject"`
 	Snippet   string `json:"snippet,omitempty"`
 	Body      string `json:"body,omitempty"`
-	Date      string `json:"date"`
-	Labels    string `json:"labels,omitempty"`
-	IsRead    int    `json:"is_read"`
-	FetchedAt string `json:"fetched_at"`
+	// BodyMissing is set for emails stored by a headers-only sync
+	// (body is NULL in the database).
+	BodyMissing bool `json:"body_missing,omitempty"`
+	// AttachmentText is text extracted from the message's attachments
+	// during sync, searched by mb search.
+	AttachmentText string `json:"attachment_text,omitempty"`
+	Date           string `json:"date"`
+	SentAt         string `json:"sent_at"` // Date normalized to RFC 3339 UTC
+	Labels         string `json:"labels,omitempty"`
+	IsRead         int    `json:"is_read"`
+	FetchedAt      string `json:"fetched_at"`
+	// AuthResults is the raw Authentication-Results header stored at sync;
+	// commands report it parsed, as Auth.
+	AuthResults string     `json:"-"`
+	Auth        *AuthCheck `json:"auth,omitempty"`
+	// IsBulk marks newsletters and list mail, recognized at sync by their
+	// List-Id, Precedence, or one-click unsubscribe headers.
+	IsBulk bool `json:"is_bulk,omitempty"`
+}
+
+// AuthCheck is the sender authentication verdict for an email: the SPF,
+// DKIM, and DMARC results from Authentication-Results ("pass", "fail",
+// "softfail", "none", ...; empty if not reported) and warnings about signs
+// of spoofing, such as a display name showing a different address.
+type AuthCheck struct {
+	SPF      string   `json:"spf,omitempty"`
+	DKIM     string   `json:"dkim,omitempty"`
+	DMARC    string   `json:"dmarc,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // TriageRef is a thin cross-reference mapping an email thread to a beads issue.
@@ -28,6 +53,115 @@ type TriageRef struct {
 	CreatedAt string `json:"created_at"`
 }
 
+// TriageLogEntry is one row of triage_log: a bead's lifecycle from the
+// arrival of its mail to mb done/dismiss. ClosedAt and Outcome ("done" or
+// "dismissed") are empty while the bead is open.
+type TriageLogEntry struct {
+	ThreadID  string `json:"thread_id"`
+	Account   string `json:"account"`
+	BeadID    string `json:"bead_id"`
+	ArrivedAt string `json:"arrived_at"`
+	TriagedAt string `json:"triaged_at"`
+	ClosedAt  string `json:"closed_at,omitempty"`
+	Outcome   string `json:"outcome,omitempty"`
+	Priority  string `json:"priority,omitempty"`
+}
+
+// Dismissal is an untriaged thread auto-dismissed by the named config
+// policy. Subject and From are the thread's latest, for listing.
+type Dismissal struct {
+	ThreadID    string `json:"thread_id"`
+	Account     string `json:"account"`
+	Policy      string `json:"policy"`
+	DismissedAt string `json:"dismissed_at"`
+	Subject     string `json:"subject,omitempty"`
+	From        string `json:"from,omitempty"`
+}
+
+// Suggestion is a triage decision queued for review: proposed by the LLM
+// (Source "llm"), a triage rule ("rule:NAME"), or an mb triage whose
+// confidence was too low ("triage"). Confidence is the decision's 0–1
+// estimate, if it gave one. Subject and From are the thread's latest, for
+// listing.
+type Suggestion struct {
+	ThreadID   string   `json:"thread_id"`
+	Account    string   `json:"account"`
+	Priority   string   `json:"priority"`
+	Action     string   `json:"action"`
+	Suggestion string   `json:"suggestion,omitempty"`
+	AgentNotes string   `json:"agent_notes,omitempty"`
+	Category   string   `json:"category,omitempty"`
+	Due        string   `json:"due,omitempty"`
+	Confidence *float64 `json:"confidence,omitempty"`
+	Source     string   `json:"source"`
+	CreatedAt  string   `json:"created_at"`
+	RejectedAt string   `json:"rejected_at,omitempty"`
+	Subject    string   `json:"subject,omitempty"`
+	From       string   `json:"from,omitempty"`
+}
+
+// Operation is a journal entry for mb undo. Refs are the triage refs the
+// operation created or removed (for an auto-dismiss, the threads it
+// dismissed, without a bead); Previous holds bead fields (as passed to bd
+// update) from before an update.
+type Operation struct {
+	ID       int64             `json:"id"`
+	At       string            `json:"at"`
+	Op       string            `json:"op"`
+	BeadID   string            `json:"bead_id"`
+	Refs     []TriageRef       `json:"refs,omitempty"`
+	Previous map[string]string `json:"previous,omitempty"`
+	UndoneAt string            `json:"undone_at,omitempty"`
+}
+
+// TriageRecord is the local record of one triage decision whose bead is
+// already written: the thread's triage ref, its triage_log priority, and
+// its undo journal entry — or, with Suggestion set, the decision queued
+// for review instead — plus its audit log entry. Linked is set to the
+// number of duplicate threads linked to the bead.
+type TriageRecord struct {
+	ThreadID   string
+	Account    string
+	BeadID     string
+	Priority   string
+	Op         *Operation
+	Suggestion *Suggestion
+	Audit      *AuditEntry
+	Linked     int
+}
+
+// Operation kinds recorded in the journal.
+const (
+	OpTriage      = "triage"
+	OpUpdate      = "update"
+	OpDone        = "done"
+	OpDismiss     = "dismiss"
+	OpAutoDismiss = "auto-dismiss"
+)
+
+// AuditEntry is one row of the audit log.
+type AuditEntry struct {
+	ID       int64  `json:"id"`
+	At       string `json:"at"`
+	Actor    string `json:"actor"`
+	Command  string `json:"command"`
+	Action   string `json:"action"`
+	ThreadID string `json:"thread_id,omitempty"`
+	Account  string `json:"account,omitempty"`
+	BeadID   string `json:"bead_id,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// AuditFilter narrows an audit log query. Zero fields match everything.
+type AuditFilter struct {
+	Since    string
+	Action   string
+	Actor    string
+	ThreadID string
+	BeadID   string
+	Limit    int
+}
+
 // Thread groups emails by thread_id + account with optional triage reference.
 type Thread struct {
 	ThreadID   string     `json:"thread_id"`
@@ -37,6 +171,94 @@ type Thread struct {
 	EmailCount int        `json:"email_count"`
 	LatestDate string     `json:"latest_date"`
 	TriageRef  *TriageRef `json:"triage_ref,omitempty"`
+	// AuthWarnings collects the spoofing warnings of the thread's messages.
+	AuthWarnings []string `json:"auth_warnings,omitempty"`
+	// PredictedPriority is the local classifier's guess (see mb train),
+	// with its probability in PredictedConfidence.
+	PredictedPriority   string  `json:"predicted_priority,omitempty"`
+	PredictedConfidence float64 `json:"predicted_confidence,omitempty"`
+	// SuggestedCategory is the first configured category whose hints
+	// match the thread.
+	SuggestedCategory string `json:"suggested_category,omitempty"`
+	// Participants is everyone on the thread, when listed with them.
+	Participants []*Participant `json:"participants,omitempty"`
+	// CodeLinks are the pull requests and issues the thread links to, when
+	// listed with them.
+	CodeLinks []*CodeLink `json:"code_links,omitempty"`
+	// IsBulk is set when any of the thread's messages is bulk mail.
+	IsBulk bool `json:"is_bulk,omitempty"`
+	// Muted is set for threads muted with mb mute.
+	Muted bool `json:"muted,omitempty"`
+}
+
+// Participant is someone on a thread's From, To, or Cc lines.
+type Participant struct {
+	Address string `json:"address"`
+	Name    string `json:"name,omitempty"`
+	// Roles lists the headers the address appeared in: from, to, cc.
+	Roles []string `json:"roles"`
+	// Sent counts the thread's messages from the address.
+	Sent int `json:"sent"`
+	// Self marks the account's own address.
+	Self bool `json:"self,omitempty"`
+}
+
+// CodeLink is a pull (merge) request or issue on GitHub or GitLab linked
+// from a thread.
+type CodeLink struct {
+	URL string `json:"url"`
+	// Kind is "pr" or "issue".
+	Kind string `json:"kind"`
+	// Forge is "github" or "gitlab".
+	Forge  string `json:"forge"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	// Ref is the forge's short reference: owner/repo#12, or group/project!12
+	// for a GitLab merge request.
+	Ref string `json:"ref"`
+}
+
+// QueryResult is the result of an ad-hoc read-only query (mb query).
+// Rows hold column values by name; Columns keeps their order.
+type QueryResult struct {
+	Columns []string         `json:"columns"`
+	Rows    []map[string]any `json:"rows"`
+	// Truncated is set when the query had more rows than the limit.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Contact aggregates everything mailbeads knows about a sender address.
+// AvgPriority is the mean beads priority (1=high .. 4=spam) of the sender's
+// triaged threads, or 0 if none of their threads have been triaged.
+type Contact struct {
+	Address        string  `json:"address"`
+	Name           string  `json:"name,omitempty"`
+	MessageCount   int     `json:"message_count"`
+	FirstSeen      string  `json:"first_seen"`
+	LastSeen       string  `json:"last_seen"`
+	ThreadCount    int     `json:"thread_count"`
+	TriagedThreads int     `json:"triaged_threads"`
+	AvgPriority    float64 `json:"avg_priority,omitempty"`
+}
+
+// CalendarEvent is a meeting invite parsed from an email's iCalendar data.
+// Start and End are RFC 3339 timestamps; Organizer and Attendees use the
+// same "Name <address>" format as email headers.
+type CalendarEvent struct {
+	EmailID   string `json:"email_id"`
+	Account   string `json:"account"`
+	ThreadID  string `json:"thread_id"`
+	UID       string `json:"uid"`
+	Method    string `json:"method,omitempty"`
+	Summary   string `json:"summary"`
+	Location  string `json:"location,omitempty"`
+	Organizer string `json:"organizer,omitempty"`
+	Attendees string `json:"attendees,omitempty"`
+	Start     string `json:"start"`
+	End       string `json:"end,omitempty"`
+	AllDay    bool   `json:"all_day,omitempty"`
+	Status    string `json:"status,omitempty"`
+	ICS       string `json:"-"`
 }
 
 // Priority constants (used for mb triage CLI flags, mapped to beads priorities).
@@ -62,11 +284,44 @@ func IsValidPriority(p string) bool {
 
 // SyncResult holds the result of syncing a single account.
 type SyncResult struct {
-	Account   string `json:"account"`
-	Fetched   int    `json:"fetched"`
-	Skipped   int    `json:"skipped"`
+	Account string `json:"account"`
+	Fetched int    `json:"fetched"`
+	Skipped int    `json:"skipped"`
+	// Capped counts the older new messages --max left unsynced.
+	Capped int `json:"capped,omitempty"`
+	// Unchanged reports that the mailbox hadn't changed since the last
+	// sync, so nothing was listed.
+	Unchanged bool `json:"unchanged,omitempty"`
+	// Updated counts stored messages whose labels or read state changed.
+	Updated   int    `json:"updated,omitempty"`
 	Commented int    `json:"commented,omitempty"`
+	Notified  int    `json:"notified,omitempty"`
 	Error     string `json:"error,omitempty"`
+
+	// WouldFetch lists, in a dry run, the new messages a sync would fetch.
+	WouldFetch []SyncPreview `json:"would_fetch,omitempty"`
+
+	// Threads lists the thread IDs that received new emails, in fetch order.
+	Threads []string `json:"-"`
+}
+
+// SyncPreview is a message a dry-run sync found but didn't fetch.
+type SyncPreview struct {
+	ID       string `json:"id"`
+	ThreadID string `json:"thread_id"`
+	From     string `json:"from"`
+	Subject  string `json:"subject"`
+	Date     string `json:"date"`
+}
+
+// StatsSnapshot is one row of stats_history: inbox counts at the end of a sync.
+type StatsSnapshot struct {
+	TakenAt    string `json:"taken_at"`
+	Emails     int    `json:"emails"`
+	Threads    int    `json:"threads"`
+	Untriaged  int    `json:"untriaged"`
+	Triaged    int    `json:"triaged"`
+	NewTriaged int    `json:"new_triaged"`
 }
 
 // SyncSummary holds the result of syncing all accounts.
@@ -74,4 +329,8 @@ type SyncSummary struct {
 	Accounts  []SyncResult `json:"accounts"`
 	TotalNew  int          `json:"total_new"`
 	TotalInDB int          `json:"total_in_db"`
+	DryRun    bool         `json:"dry_run,omitempty"`
+	// AutoDismissed lists the threads auto-dismiss policies dismissed
+	// after the sync.
+	AutoDismissed []*Dismissal `json:"auto_dismissed,omitempty"`
 }
@@ -0,0 +1,188 @@
+package mailbeads
+
+import (
+	"context"
+
+	"github.com/daviddao/mailbeads/internal/auth"
+	"github.com/daviddao/mailbeads/internal/gmail"
+	gm "google.golang.org/api/gmail/v1"
+)
+
+// MessageSummary is a search result: a message's headers and snippet.
+type MessageSummary struct {
+	ID        string   `json:"id"`
+	ThreadID  string   `json:"thread_id"`
+	MessageID string   `json:"message_id,omitempty"`
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	CC        string   `json:"cc,omitempty"`
+	Subject   string   `json:"subject"`
+	Date      string   `json:"date"`
+	Snippet   string   `json:"snippet"`
+	Labels    []string `json:"labels,omitempty"`
+	// AuthResults is Gmail's Authentication-Results header (SPF, DKIM,
+	// DMARC verdicts).
+	AuthResults string `json:"authentication_results,omitempty"`
+	// Bulk marks newsletters and list mail.
+	Bulk bool `json:"bulk,omitempty"`
+}
+
+// FullMessage is a message with its decoded body.
+type FullMessage struct {
+	ID        string   `json:"id"`
+	ThreadID  string   `json:"thread_id"`
+	MessageID string   `json:"message_id,omitempty"`
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	CC        string   `json:"cc,omitempty"`
+	Subject   string   `json:"subject"`
+	Date      string   `json:"date"`
+	Body      string   `json:"body"`
+	Labels    []string `json:"labels,omitempty"`
+	Snippet   string   `json:"snippet,omitempty"`
+	// ICS is the message's calendar invite, if it has one.
+	ICS string `json:"ics,omitempty"`
+	// AuthResults is Gmail's Authentication-Results header.
+	AuthResults string `json:"authentication_results,omitempty"`
+	// Bulk marks newsletters and list mail.
+	Bulk bool `json:"bulk,omitempty"`
+}
+
+// RawMessage is a message's original RFC 822 source.
+type RawMessage struct {
+	ID       string `json:"id"`
+	ThreadID string `json:"thread_id"`
+	Raw      string `json:"raw"`
+}
+
+// Gmail is a client for one Gmail account. Calls share mb's rate limiter
+// and retry rate-limit errors.
+type Gmail interface {
+	// Search returns up to max messages matching a Gmail query
+	// ("from:alice newer_than:7d").
+	Search(query string, max int64) ([]MessageSummary, error)
+	Read(messageID string) (*FullMessage, error)
+	ReadRaw(messageID string) (*RawMessage, error)
+	// ReadThread returns a thread's messages, oldest first.
+	ReadThread(threadID string) ([]*FullMessage, error)
+	// Send sends a new message and returns its ID.
+	Send(from, to, subject, body string) (string, error)
+	// Reply sends a reply in threadID and returns its ID. It answers the
+	// thread's latest message, so recipients' clients thread it too.
+	Reply(threadID, from, to, subject, body string) (string, error)
+	// Draft saves a reply in threadID as a draft and returns its ID.
+	Draft(threadID, from, to, subject, body string) (string, error)
+}
+
+// NewGmail authenticates with an account's credentials.json (token.json
+// beside it holds the OAuth token, as for mb) and returns its client.
+func NewGmail(ctx context.Context, credentialsPath string) (Gmail, error) {
+	svc, err := auth.LoadGmailService(ctx, credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	return &gmailClient{svc: svc}, nil
+}
+
+type gmailClient struct {
+	svc *gm.Service
+}
+
+func (c *gmailClient) Search(query string, max int64) ([]MessageSummary, error) {
+	summaries, err := gmail.Search(c.svc, query, max)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MessageSummary, len(summaries))
+	for i, m := range summaries {
+		out[i] = MessageSummary{
+			ID:          m.ID,
+			ThreadID:    m.ThreadID,
+			MessageID:   m.MessageID,
+			From:        m.From,
+			To:          m.To,
+			CC:          m.CC,
+			Subject:     m.Subject,
+			Date:        m.Date,
+			Snippet:     m.Snippet,
+			Labels:      m.Labels,
+			AuthResults: m.AuthResults,
+			Bulk:        m.Bulk,
+		}
+	}
+	return out, nil
+}
+
+func (c *gmailClient) Read(messageID string) (*FullMessage, error) {
+	m, err := gmail.ReadFull(c.svc, messageID)
+	if err != nil {
+		return nil, err
+	}
+	return publicMessage(m), nil
+}
+
+func (c *gmailClient) ReadRaw(messageID string) (*RawMessage, error) {
+	m, err := gmail.ReadRaw(c.svc, messageID)
+	if err != nil {
+		return nil, err
+	}
+	return &RawMessage{ID: m.ID, ThreadID: m.ThreadID, Raw: m.Raw}, nil
+}
+
+func (c *gmailClient) ReadThread(threadID string) ([]*FullMessage, error) {
+	messages, err := gmail.ReadThread(c.svc, threadID)
+	return convert(messages, err, publicMessage)
+}
+
+func (c *gmailClient) Send(from, to, subject, body string) (string, error) {
+	return gmail.Send(c.svc, from, to, subject, body, nil)
+}
+
+func (c *gmailClient) Reply(threadID, from, to, subject, body string) (string, error) {
+	headers, err := c.replyHeaders(threadID)
+	if err != nil {
+		return "", err
+	}
+	return gmail.SendReply(c.svc, threadID, from, to, subject, body, headers)
+}
+
+func (c *gmailClient) Draft(threadID, from, to, subject, body string) (string, error) {
+	headers, err := c.replyHeaders(threadID)
+	if err != nil {
+		return "", err
+	}
+	return gmail.CreateReplyDraft(c.svc, threadID, from, to, subject, body, headers)
+}
+
+// replyHeaders returns In-Reply-To and References for a reply to the
+// latest message in threadID.
+func (c *gmailClient) replyHeaders(threadID string) (map[string]string, error) {
+	messages, err := gmail.ReadThread(c.svc, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 || messages[len(messages)-1].MessageID == "" {
+		return nil, nil
+	}
+	id := messages[len(messages)-1].MessageID
+	return map[string]string{"In-Reply-To": id, "References": id}, nil
+}
+
+func publicMessage(m *gmail.FullMessage) *FullMessage {
+	return &FullMessage{
+		ID:          m.ID,
+		ThreadID:    m.ThreadID,
+		MessageID:   m.MessageID,
+		From:        m.From,
+		To:          m.To,
+		CC:          m.CC,
+		Subject:     m.Subject,
+		Date:        m.Date,
+		Body:        m.Body,
+		Labels:      m.Labels,
+		Snippet:     m.Snippet,
+		ICS:         m.ICS,
+		AuthResults: m.AuthResults,
+		Bulk:        m.Bulk,
+	}
+}
@@ -0,0 +1,75 @@
+// Package mailbeads embeds mailbeads in other Go programs (bots, servers,
+// dashboards) without shelling out to the mb binary.
+//
+// It is the stable surface over mailbeads' internal packages: a Store for
+// the synced mail and triage cross-references, a Syncer that fetches Gmail
+// into it, a Triager that files threads as beads issues, and a Gmail
+// client. These interfaces and the types they take and return are defined
+// here, not aliased from internal packages, so changes inside mailbeads
+// don't reach them: their methods and fields change only with a new major
+// version. MailProvider, implemented by provider plugins, is the
+// exception; it follows the sync core and may gain methods in a minor
+// release.
+//
+// A minimal program:
+//
+//	store, err := mailbeads.Open(".mailbeads/mail.db")
+//	if err != nil { ... }
+//	defer store.Close()
+//	triager := mailbeads.NewTriager(store, "my-bot")
+//	threads, err := store.UntriagedThreads("", 20)
+//	for _, t := range threads {
+//		triager.Triage(mailbeads.TriageRequest{
+//			ThreadID: t.ThreadID, Account: t.Account,
+//			Action: "Reply", Priority: mailbeads.PriorityHigh,
+//		})
+//	}
+//
+// Actions taken through this package are recorded in the undo journal and
+// audit log like their mb equivalents, so mb undo and mb log see them.
+package mailbeads
+
+import (
+	"errors"
+
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// Triage priorities.
+const (
+	PriorityHigh   = types.PriorityHigh
+	PriorityMedium = types.PriorityMedium
+	PriorityLow    = types.PriorityLow
+	PrioritySpam   = types.PrioritySpam
+)
+
+// MemoryPath, passed to Open, opens an empty in-memory Store.
+const MemoryPath = db.MemoryPath
+
+var (
+	// ErrBDMissing is returned by triage operations when the bd (beads)
+	// CLI is not on PATH.
+	ErrBDMissing = errors.New("mailbeads: bd (beads) CLI not found on PATH")
+	// ErrThreadNotFound is returned for a thread that isn't in the Store.
+	ErrThreadNotFound = errors.New("mailbeads: thread not found")
+	// ErrAmbiguousAccount is returned when a thread exists in several
+	// accounts and no account was given.
+	ErrAmbiguousAccount = errors.New("mailbeads: thread exists in several accounts; give one")
+)
+
+// Open opens (or creates) a Store: a SQLite file path, a postgres:// or
+// libsql:// DSN, or MemoryPath.
+func Open(target string) (Store, error) {
+	d, err := db.Open(target)
+	if err != nil {
+		return nil, err
+	}
+	return &store{d: d}, nil
+}
+
+// Discover returns the database of the mailbeads project containing the
+// working directory (.mailbeads/mail.db), or "" if there is none.
+func Discover() string {
+	return db.DiscoverDB()
+}
@@ -0,0 +1,33 @@
+package mailbeads
+
+import "github.com/daviddao/mailbeads/internal/provider"
+
+// MailProvider is a mail service mailbeads syncs from and sends through.
+// Gmail is built in as "gmail"; register others (JMAP, Proton Bridge,
+// Exchange) with RegisterProvider and assign accounts to them in
+// .mailbeads/config.json:
+//
+//	"accounts": {
+//	  "me@fastmail.com": {"provider": "jmap", "settings": {"url": "https://api.fastmail.com/jmap/session"}}
+//	}
+//
+// Sync lists the account's messages since req.Since (or the last 72 hours
+// when it is zero) and returns, fully fetched, those req.Known doesn't
+// report as stored; the Syncer stores them and does the rest.
+type MailProvider = provider.MailProvider
+
+// Types passed to and returned by a MailProvider.
+type (
+	ProviderAccount = provider.Account
+	SyncRequest     = provider.SyncRequest
+	SyncBatch       = provider.Batch
+	ProviderMessage = provider.Message
+	OutgoingMessage = provider.Outgoing
+)
+
+// RegisterProvider makes p available to accounts configured with
+// "provider": name. Call it before syncing, typically from an init
+// function; it panics if name is already registered.
+func RegisterProvider(name string, p MailProvider) {
+	provider.Register(name, p)
+}
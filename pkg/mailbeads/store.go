@@ -0,0 +1,484 @@
+package mailbeads
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/mailauth"
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// Store is the mailbeads database: synced emails, triage
+// cross-references, the undo journal, and the audit log. Mail gets in
+// through a Syncer and triage through a Triager; a Store reads it back.
+//
+// Only Open returns a Store, so no other package implements it.
+type Store interface {
+	Close() error
+
+	// Accounts lists the accounts with stored mail.
+	Accounts() []string
+	// GetEmail returns a stored email, or nil if there is none.
+	GetEmail(id string) (*Email, error)
+	// ThreadEmails returns a thread's emails, oldest first.
+	ThreadEmails(threadID, account string) ([]*Email, error)
+	// SearchEmails returns up to limit emails matching query, as for
+	// mb search; account "" searches every account.
+	SearchEmails(query, account string, limit int) ([]*Email, error)
+
+	// Threads lists an account's threads, or every account's for "".
+	Threads(account string) ([]*Thread, error)
+	// UntriagedThreads lists threads without a triage ref, newest first;
+	// limit 0 means all.
+	UntriagedThreads(account string, limit int) ([]*Thread, error)
+	// ThreadInfo returns a thread's summary, or ErrThreadNotFound.
+	ThreadInfo(threadID, account string) (*Thread, error)
+	// ThreadAccounts lists the accounts a thread ID is stored under.
+	ThreadAccounts(threadID string) ([]string, error)
+
+	// GetTriageRef returns a thread's triage ref, or nil if it has none.
+	GetTriageRef(threadID, account string) (*TriageRef, error)
+	TriageRefsByBead(beadID string) ([]*TriageRef, error)
+	// TriageLog returns the beads triaged or closed at or after since.
+	TriageLog(since string) ([]*TriageLogEntry, error)
+
+	// Operations returns undo journal entries, newest first; with
+	// pending, only those not yet undone.
+	Operations(pending bool, limit int) ([]*Operation, error)
+	AuditLog(f AuditFilter) ([]*AuditEntry, error)
+
+	// Senders returns the known senders whose address or name contains
+	// search ("" for all).
+	Senders(search string) ([]*Contact, error)
+	// UpcomingEvents returns an account's calendar invites starting
+	// between from and until (RFC 3339); account "" means all.
+	UpcomingEvents(account, from, until string) ([]*CalendarEvent, error)
+	// StatsHistory returns the inbox snapshots taken at or after since.
+	StatsHistory(since string) ([]*StatsSnapshot, error)
+
+	// Seed stores a fixture or imported mailbox, skipping emails already
+	// stored, and returns the number of emails added.
+	Seed(s *Seed) (int, error)
+
+	// internal returns the database behind the Store, for NewSyncer and
+	// NewTriager.
+	internal() db.Store
+}
+
+// Email is a stored message.
+type Email struct {
+	ID        string `json:"id"`
+	Account   string `json:"account"`
+	ThreadID  string `json:"thread_id"`
+	MessageID string `json:"message_id,omitempty"`
+	From      string `json:"from"`
+	To        string `json:"to,omitempty"`
+	CC        string `json:"cc,omitempty"`
+	Subject   string `json:"subject"`
+	Snippet   string `json:"snippet,omitempty"`
+	Body      string `json:"body,omitempty"`
+	// BodyMissing is set for emails stored by a headers-only sync.
+	BodyMissing bool `json:"body_missing,omitempty"`
+	// AttachmentText is text extracted from the message's attachments
+	// during sync.
+	AttachmentText string `json:"attachment_text,omitempty"`
+	Date           string `json:"date"`
+	SentAt         string `json:"sent_at"` // Date normalized to RFC 3339 UTC
+	Labels         string `json:"labels,omitempty"`
+	IsRead         int    `json:"is_read"`
+	FetchedAt      string `json:"fetched_at"`
+	// Auth is the sender authentication verdict, nil when there is
+	// nothing to report. Seed ignores it.
+	Auth *AuthCheck `json:"auth,omitempty"`
+	// IsBulk marks newsletters and list mail.
+	IsBulk bool `json:"is_bulk,omitempty"`
+}
+
+// AuthCheck is a message's SPF, DKIM, and DMARC results ("pass", "fail",
+// ...) and the spoofing warnings they raise.
+type AuthCheck struct {
+	SPF      string   `json:"spf,omitempty"`
+	DKIM     string   `json:"dkim,omitempty"`
+	DMARC    string   `json:"dmarc,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Thread is a conversation in one account.
+type Thread struct {
+	ThreadID   string `json:"thread_id"`
+	Account    string `json:"account"`
+	Subject    string `json:"subject"`
+	From       string `json:"from"`
+	EmailCount int    `json:"email_count"`
+	LatestDate string `json:"latest_date"`
+	// TriageRef is set for a triaged thread by listings that include it.
+	TriageRef *TriageRef `json:"triage_ref,omitempty"`
+	// IsBulk is set when any of the thread's messages is bulk mail.
+	IsBulk bool `json:"is_bulk,omitempty"`
+	// Muted is set for threads muted with mb mute.
+	Muted bool `json:"muted,omitempty"`
+}
+
+// TriageRef links a thread to the beads issue it was triaged as.
+type TriageRef struct {
+	ThreadID  string `json:"thread_id"`
+	Account   string `json:"account"`
+	BeadID    string `json:"bead_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+// TriageLogEntry is a bead's lifecycle from the arrival of its mail to
+// done or dismissed. ClosedAt and Outcome ("done" or "dismissed") are
+// empty while the bead is open.
+type TriageLogEntry struct {
+	ThreadID  string `json:"thread_id"`
+	Account   string `json:"account"`
+	BeadID    string `json:"bead_id"`
+	ArrivedAt string `json:"arrived_at"`
+	TriagedAt string `json:"triaged_at"`
+	ClosedAt  string `json:"closed_at,omitempty"`
+	Outcome   string `json:"outcome,omitempty"`
+	Priority  string `json:"priority,omitempty"`
+}
+
+// Operation is an undo journal entry. Op names what was done ("triage",
+// "update", "done", "dismiss", ...); Refs are the triage refs it created
+// or removed.
+type Operation struct {
+	ID       int64       `json:"id"`
+	At       string      `json:"at"`
+	Op       string      `json:"op"`
+	BeadID   string      `json:"bead_id"`
+	Refs     []TriageRef `json:"refs,omitempty"`
+	UndoneAt string      `json:"undone_at,omitempty"`
+}
+
+// AuditEntry is one row of the audit log (mb log).
+type AuditEntry struct {
+	ID       int64  `json:"id"`
+	At       string `json:"at"`
+	Actor    string `json:"actor"`
+	Command  string `json:"command"`
+	Action   string `json:"action"`
+	ThreadID string `json:"thread_id,omitempty"`
+	Account  string `json:"account,omitempty"`
+	BeadID   string `json:"bead_id,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// AuditFilter narrows Store.AuditLog. Zero fields match everything.
+type AuditFilter struct {
+	Since    string
+	Action   string
+	Actor    string
+	ThreadID string
+	BeadID   string
+	Limit    int
+}
+
+// Contact is a sender and how much mail they send.
+type Contact struct {
+	Address      string `json:"address"`
+	Name         string `json:"name,omitempty"`
+	MessageCount int    `json:"message_count"`
+	FirstSeen    string `json:"first_seen"`
+	LastSeen     string `json:"last_seen"`
+}
+
+// CalendarEvent is a meeting invite found in an email. Start and End are
+// RFC 3339 timestamps.
+type CalendarEvent struct {
+	EmailID   string `json:"email_id"`
+	Account   string `json:"account"`
+	ThreadID  string `json:"thread_id"`
+	UID       string `json:"uid"`
+	Method    string `json:"method,omitempty"`
+	Summary   string `json:"summary"`
+	Location  string `json:"location,omitempty"`
+	Organizer string `json:"organizer,omitempty"`
+	Attendees string `json:"attendees,omitempty"`
+	Start     string `json:"start"`
+	End       string `json:"end,omitempty"`
+	AllDay    bool   `json:"all_day,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+// StatsSnapshot is the size of the inbox at one point in time.
+type StatsSnapshot struct {
+	TakenAt    string `json:"taken_at"`
+	Emails     int    `json:"emails"`
+	Threads    int    `json:"threads"`
+	Untriaged  int    `json:"untriaged"`
+	Triaged    int    `json:"triaged"`
+	NewTriaged int    `json:"new_triaged"`
+}
+
+// Seed is data to preload with Store.Seed, shaped like mb --seed's JSON
+// fixtures. Emails need an ID, ThreadID, and Account.
+type Seed struct {
+	Emails []*Email     `json:"emails"`
+	Triage []*TriageRef `json:"triage,omitempty"`
+}
+
+// store adapts the internal database to Store, converting its types.
+type store struct {
+	d db.Store
+}
+
+func (s *store) internal() db.Store { return s.d }
+
+func (s *store) Close() error       { return s.d.Close() }
+func (s *store) Accounts() []string { return s.d.Accounts() }
+
+func (s *store) GetEmail(id string) (*Email, error) {
+	e, err := s.d.GetEmail(id)
+	if err != nil || e == nil {
+		return nil, err
+	}
+	return publicEmail(e), nil
+}
+
+func (s *store) ThreadEmails(threadID, account string) ([]*Email, error) {
+	emails, err := s.d.ThreadEmails(threadID, account)
+	return convert(emails, err, publicEmail)
+}
+
+func (s *store) SearchEmails(query, account string, limit int) ([]*Email, error) {
+	emails, err := s.d.SearchEmails(query, account, limit)
+	return convert(emails, err, publicEmail)
+}
+
+func (s *store) Threads(account string) ([]*Thread, error) {
+	threads, err := s.d.Threads(account)
+	return convert(threads, err, publicThread)
+}
+
+func (s *store) UntriagedThreads(account string, limit int) ([]*Thread, error) {
+	threads, err := s.d.UntriagedThreads(account, limit)
+	return convert(threads, err, publicThread)
+}
+
+func (s *store) ThreadInfo(threadID, account string) (*Thread, error) {
+	t, err := s.d.ThreadInfo(threadID, account)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s in %s", ErrThreadNotFound, threadID, account)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return publicThread(t), nil
+}
+
+func (s *store) ThreadAccounts(threadID string) ([]string, error) {
+	return s.d.ThreadAccounts(threadID)
+}
+
+func (s *store) GetTriageRef(threadID, account string) (*TriageRef, error) {
+	r, err := s.d.GetTriageRef(threadID, account)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	return publicTriageRef(r), nil
+}
+
+func (s *store) TriageRefsByBead(beadID string) ([]*TriageRef, error) {
+	refs, err := s.d.TriageRefsByBead(beadID)
+	return convert(refs, err, publicTriageRef)
+}
+
+func (s *store) TriageLog(since string) ([]*TriageLogEntry, error) {
+	entries, err := s.d.TriageLog(since)
+	return convert(entries, err, func(e *types.TriageLogEntry) *TriageLogEntry {
+		return &TriageLogEntry{
+			ThreadID:  e.ThreadID,
+			Account:   e.Account,
+			BeadID:    e.BeadID,
+			ArrivedAt: e.ArrivedAt,
+			TriagedAt: e.TriagedAt,
+			ClosedAt:  e.ClosedAt,
+			Outcome:   e.Outcome,
+			Priority:  e.Priority,
+		}
+	})
+}
+
+func (s *store) Operations(pending bool, limit int) ([]*Operation, error) {
+	ops, err := s.d.Operations(pending, limit)
+	return convert(ops, err, func(o *types.Operation) *Operation {
+		op := &Operation{ID: o.ID, At: o.At, Op: o.Op, BeadID: o.BeadID, UndoneAt: o.UndoneAt}
+		for i := range o.Refs {
+			op.Refs = append(op.Refs, *publicTriageRef(&o.Refs[i]))
+		}
+		return op
+	})
+}
+
+func (s *store) AuditLog(f AuditFilter) ([]*AuditEntry, error) {
+	entries, err := s.d.AuditLog(types.AuditFilter{
+		Since:    f.Since,
+		Action:   f.Action,
+		Actor:    f.Actor,
+		ThreadID: f.ThreadID,
+		BeadID:   f.BeadID,
+		Limit:    f.Limit,
+	})
+	return convert(entries, err, func(e *types.AuditEntry) *AuditEntry {
+		return &AuditEntry{
+			ID:       e.ID,
+			At:       e.At,
+			Actor:    e.Actor,
+			Command:  e.Command,
+			Action:   e.Action,
+			ThreadID: e.ThreadID,
+			Account:  e.Account,
+			BeadID:   e.BeadID,
+			Detail:   e.Detail,
+		}
+	})
+}
+
+func (s *store) Senders(search string) ([]*Contact, error) {
+	contacts, err := s.d.Senders(search)
+	return convert(contacts, err, func(c *types.Contact) *Contact {
+		return &Contact{
+			Address:      c.Address,
+			Name:         c.Name,
+			MessageCount: c.MessageCount,
+			FirstSeen:    c.FirstSeen,
+			LastSeen:     c.LastSeen,
+		}
+	})
+}
+
+func (s *store) UpcomingEvents(account, from, until string) ([]*CalendarEvent, error) {
+	events, err := s.d.UpcomingEvents(account, from, until)
+	return convert(events, err, func(ev *types.CalendarEvent) *CalendarEvent {
+		return &CalendarEvent{
+			EmailID:   ev.EmailID,
+			Account:   ev.Account,
+			ThreadID:  ev.ThreadID,
+			UID:       ev.UID,
+			Method:    ev.Method,
+			Summary:   ev.Summary,
+			Location:  ev.Location,
+			Organizer: ev.Organizer,
+			Attendees: ev.Attendees,
+			Start:     ev.Start,
+			End:       ev.End,
+			AllDay:    ev.AllDay,
+			Status:    ev.Status,
+		}
+	})
+}
+
+func (s *store) StatsHistory(since string) ([]*StatsSnapshot, error) {
+	snapshots, err := s.d.StatsHistory(since)
+	return convert(snapshots, err, func(x *types.StatsSnapshot) *StatsSnapshot {
+		return &StatsSnapshot{
+			TakenAt:    x.TakenAt,
+			Emails:     x.Emails,
+			Threads:    x.Threads,
+			Untriaged:  x.Untriaged,
+			Triaged:    x.Triaged,
+			NewTriaged: x.NewTriaged,
+		}
+	})
+}
+
+func (s *store) Seed(seed *Seed) (int, error) {
+	in := &db.Seed{}
+	for _, e := range seed.Emails {
+		in.Emails = append(in.Emails, &types.Email{
+			ID:             e.ID,
+			Account:        e.Account,
+			ThreadID:       e.ThreadID,
+			MessageID:      e.MessageID,
+			From:           e.From,
+			To:             e.To,
+			CC:             e.CC,
+			Subject:        e.Subject,
+			Snippet:        e.Snippet,
+			Body:           e.Body,
+			BodyMissing:    e.BodyMissing,
+			AttachmentText: e.AttachmentText,
+			Date:           e.Date,
+			SentAt:         e.SentAt,
+			Labels:         e.Labels,
+			IsRead:         e.IsRead,
+			FetchedAt:      e.FetchedAt,
+			IsBulk:         e.IsBulk,
+		})
+	}
+	for _, r := range seed.Triage {
+		in.Triage = append(in.Triage, &types.TriageRef{
+			ThreadID:  r.ThreadID,
+			Account:   r.Account,
+			BeadID:    r.BeadID,
+			CreatedAt: r.CreatedAt,
+		})
+	}
+	return s.d.Seed(in)
+}
+
+// convert maps the result of an internal query through fn, passing its
+// error on.
+func convert[T, U any](items []*T, err error, fn func(*T) *U) ([]*U, error) {
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*U, len(items))
+	for i, item := range items {
+		out[i] = fn(item)
+	}
+	return out, nil
+}
+
+func publicEmail(e *types.Email) *Email {
+	out := &Email{
+		ID:             e.ID,
+		Account:        e.Account,
+		ThreadID:       e.ThreadID,
+		MessageID:      e.MessageID,
+		From:           e.From,
+		To:             e.To,
+		CC:             e.CC,
+		Subject:        e.Subject,
+		Snippet:        e.Snippet,
+		Body:           e.Body,
+		BodyMissing:    e.BodyMissing,
+		AttachmentText: e.AttachmentText,
+		Date:           e.Date,
+		SentAt:         e.SentAt,
+		Labels:         e.Labels,
+		IsRead:         e.IsRead,
+		FetchedAt:      e.FetchedAt,
+		IsBulk:         e.IsBulk,
+	}
+	if c := mailauth.Check(e.From, e.AuthResults); c != nil {
+		out.Auth = &AuthCheck{SPF: c.SPF, DKIM: c.DKIM, DMARC: c.DMARC, Warnings: c.Warnings}
+	}
+	return out
+}
+
+func publicThread(t *types.Thread) *Thread {
+	out := &Thread{
+		ThreadID:   t.ThreadID,
+		Account:    t.Account,
+		Subject:    t.Subject,
+		From:       t.From,
+		EmailCount: t.EmailCount,
+		LatestDate: t.LatestDate,
+		IsBulk:     t.IsBulk,
+		Muted:      t.Muted,
+	}
+	if t.TriageRef != nil {
+		out.TriageRef = publicTriageRef(t.TriageRef)
+	}
+	return out
+}
+
+func publicTriageRef(r *types.TriageRef) *TriageRef {
+	return &TriageRef{ThreadID: r.ThreadID, Account: r.Account, BeadID: r.BeadID, CreatedAt: r.CreatedAt}
+}
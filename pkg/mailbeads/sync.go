@@ -0,0 +1,110 @@
+package mailbeads
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/daviddao/mailbeads/internal/config"
+	"github.com/daviddao/mailbeads/internal/db"
+	msync "github.com/daviddao/mailbeads/internal/sync"
+)
+
+// SyncOptions adjusts what a sync fetches. The zero value is mb sync's
+// default: an incremental sync of the inbox, with bodies.
+type SyncOptions struct {
+	// Full rescans the last 72 hours instead of resuming after the latest
+	// stored message.
+	Full bool
+	// IncludeSpam syncs all mail, not just the inbox.
+	IncludeSpam bool
+	// Query, in the provider's search syntax (Gmail's for Gmail), syncs
+	// exactly the matching messages instead of the automatic window.
+	Query string
+	// Max, if positive, fetches only the newest Max new messages.
+	Max int
+	// HeadersOnly stores headers and snippets without bodies.
+	HeadersOnly bool
+	// StoreRaw also stores each new message's RFC 822 source.
+	StoreRaw bool
+}
+
+// SyncResult is the outcome of syncing one account.
+type SyncResult struct {
+	Account string `json:"account"`
+	Fetched int    `json:"fetched"`
+	Skipped int    `json:"skipped"`
+	// Capped counts the older new messages SyncOptions.Max left unsynced.
+	Capped int `json:"capped,omitempty"`
+	// Unchanged reports that the mailbox hadn't changed since the last
+	// sync, so nothing was listed.
+	Unchanged bool `json:"unchanged,omitempty"`
+	// Updated counts stored messages whose labels or read state changed.
+	Updated int    `json:"updated,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Threads lists the thread IDs that received new emails, in fetch
+	// order.
+	Threads []string `json:"threads,omitempty"`
+}
+
+// Syncer fetches mail into a Store, from each account's provider.
+type Syncer interface {
+	// Accounts lists the accounts that have credentials.
+	Accounts() []string
+	// Sync fetches new mail for one account. Per-account problems such as
+	// missing credentials are reported in SyncResult.Error.
+	Sync(account string, opts SyncOptions) (*SyncResult, error)
+}
+
+// NewSyncer returns a Syncer for the accounts under projectRoot, which
+// holds one ACCOUNT/credentials.json directory per Gmail account, as for
+// mb sync, and .mailbeads/config.json assigning other accounts their
+// providers. It prints nothing; webhook notifications and auto-triage
+// rules remain mb sync features.
+func NewSyncer(store Store, projectRoot string) (Syncer, error) {
+	cfg, err := config.Load(filepath.Join(projectRoot, ".mailbeads"))
+	if err != nil {
+		return nil, err
+	}
+	return &syncer{store: store.internal(), cfg: cfg, root: projectRoot}, nil
+}
+
+type syncer struct {
+	store db.Store
+	cfg   *config.Config
+	root  string
+}
+
+func (s *syncer) Accounts() []string {
+	return msync.DiscoverAccounts(s.cfg, s.root)
+}
+
+func (s *syncer) Sync(account string, opts SyncOptions) (*SyncResult, error) {
+	result, err := msync.SyncAccount(s.store, s.cfg, s.root, account, msync.Options{
+		Full:        opts.Full,
+		IncludeSpam: opts.IncludeSpam,
+		Query:       opts.Query,
+		Max:         opts.Max,
+		HeadersOnly: opts.HeadersOnly,
+		StoreRaw:    opts.StoreRaw,
+		Quiet:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := &SyncResult{
+		Account:   result.Account,
+		Fetched:   result.Fetched,
+		Skipped:   result.Skipped,
+		Capped:    result.Capped,
+		Unchanged: result.Unchanged,
+		Updated:   result.Updated,
+		Error:     result.Error,
+		Threads:   result.Threads,
+	}
+	if result.Fetched > 0 {
+		if err := s.store.SnapshotStats(); err != nil {
+			return out, fmt.Errorf("record stats: %w", err)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,203 @@
+package mailbeads
+
+import (
+	"fmt"
+
+	"github.com/daviddao/mailbeads/internal/beads"
+	"github.com/daviddao/mailbeads/internal/db"
+	"github.com/daviddao/mailbeads/internal/triage"
+	"github.com/daviddao/mailbeads/internal/types"
+)
+
+// TriageRequest is one triage decision, as for mb triage.
+type TriageRequest struct {
+	ThreadID string
+	// Account may be empty if the thread exists in only one account.
+	Account string
+	// Priority is high, medium (the default), low, or spam.
+	Priority string
+	// Action is the bead's title: what to do about the thread. Required.
+	Action     string
+	Suggestion string
+	AgentNotes string
+	Category   string
+}
+
+// TriageResult is the outcome of a triage decision.
+type TriageResult struct {
+	ThreadID string `json:"thread_id"`
+	Account  string `json:"account"`
+	BeadID   string `json:"bead_id"`
+	Priority string `json:"priority"`
+	Subject  string `json:"subject"`
+	// Created is false when an existing bead (the thread's, or that of
+	// the same message in another account) was updated.
+	Created bool `json:"created"`
+	// Linked counts duplicates in other accounts pointed at the bead.
+	Linked int `json:"linked_duplicates,omitempty"`
+}
+
+// Triager files threads as beads issues through the bd CLI, keeping the
+// Store's cross-references, undo journal, and audit log in step.
+type Triager interface {
+	Triage(req TriageRequest) (*TriageResult, error)
+	// Done closes a bead as handled.
+	Done(beadID string) error
+	// Dismiss closes a bead as spam or irrelevant.
+	Dismiss(beadID string) error
+}
+
+// NewTriager returns a Triager on store. actor names the program in the
+// audit log (mb log), e.g. "support-bot".
+func NewTriager(store Store, actor string) Triager {
+	return &triager{store: store.internal(), actor: actor}
+}
+
+type triager struct {
+	store db.Store
+	actor string
+}
+
+func (t *triager) Triage(req TriageRequest) (*TriageResult, error) {
+	if req.Action == "" {
+		return nil, fmt.Errorf("mailbeads: triage %s: action is required", req.ThreadID)
+	}
+	if req.Priority == "" {
+		req.Priority = PriorityMedium
+	}
+	if !types.IsValidPriority(req.Priority) {
+		return nil, fmt.Errorf("mailbeads: invalid priority %q (must be: high, medium, low, spam)", req.Priority)
+	}
+	if !beads.Available() {
+		return nil, ErrBDMissing
+	}
+	account, err := t.account(req.ThreadID, req.Account)
+	if err != nil {
+		return nil, err
+	}
+	info, err := t.store.ThreadInfo(req.ThreadID, account)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s in %s", ErrThreadNotFound, req.ThreadID, account)
+	}
+
+	existing, err := t.store.GetTriageRef(req.ThreadID, account)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		// Reuse the bead of the same message triaged in another account.
+		if existing, err = t.store.DuplicateTriageRef(req.ThreadID, account); err != nil {
+			return nil, err
+		}
+	}
+
+	notes := fmt.Sprintf("from=%s account=%s thread=%s emails=%d",
+		info.From, account, req.ThreadID, info.EmailCount)
+	if req.AgentNotes != "" {
+		notes += "\n\n" + req.AgentNotes
+	}
+	decision := triage.Decision{
+		ThreadID:    req.ThreadID,
+		Account:     account,
+		Priority:    req.Priority,
+		Title:       req.Action,
+		Description: req.Suggestion,
+		Notes:       notes,
+		Category:    req.Category,
+	}
+	if existing != nil {
+		decision.Bead = existing.BeadID
+	}
+	rec, err := triage.Stage(decision, nil)
+	if err != nil {
+		return nil, err
+	}
+	rec.Audit = t.auditEntry(rec.Op.Op, req.ThreadID, account, rec.BeadID,
+		fmt.Sprintf("priority=%s action=%q", req.Priority, req.Action))
+	if err := triage.Record(t.store, []*types.TriageRecord{rec}); err != nil {
+		return nil, err
+	}
+
+	return &TriageResult{
+		ThreadID: req.ThreadID,
+		Account:  account,
+		BeadID:   rec.BeadID,
+		Priority: req.Priority,
+		Subject:  info.Subject,
+		Created:  existing == nil,
+		Linked:   rec.Linked,
+	}, nil
+}
+
+func (t *triager) Done(beadID string) error {
+	return t.close(beadID, types.OpDone, "done", "done")
+}
+
+func (t *triager) Dismiss(beadID string) error {
+	return t.close(beadID, types.OpDismiss, "dismissed", "dismissed — spam/irrelevant")
+}
+
+// close closes beadID in beads, then its triage refs, as mb done and mb
+// dismiss do.
+func (t *triager) close(beadID, op, outcome, reason string) error {
+	if !beads.Available() {
+		return ErrBDMissing
+	}
+	if err := beads.Close(beadID, reason); err != nil {
+		return fmt.Errorf("close %s: %w", beadID, err)
+	}
+	refs, err := t.store.TriageRefsByBead(beadID)
+	if err != nil {
+		return err
+	}
+	if err := t.store.CloseTriageRef(beadID, outcome); err != nil {
+		return err
+	}
+	entry := &types.Operation{Op: op, BeadID: beadID}
+	threadID, account := "", ""
+	for _, r := range refs {
+		entry.Refs = append(entry.Refs, *r)
+	}
+	if len(refs) > 0 {
+		threadID, account = refs[0].ThreadID, refs[0].Account
+	}
+	if err := t.store.RecordOp(entry); err != nil {
+		return err
+	}
+	return t.audit(op, threadID, account, beadID, outcome)
+}
+
+// account returns explicit, or the only account threadID exists in.
+func (t *triager) account(threadID, explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	accounts, err := t.store.ThreadAccounts(threadID)
+	if err != nil {
+		return "", err
+	}
+	switch len(accounts) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrThreadNotFound, threadID)
+	case 1:
+		return accounts[0], nil
+	}
+	return "", fmt.Errorf("%w: %s is in %v", ErrAmbiguousAccount, threadID, accounts)
+}
+
+func (t *triager) audit(action, threadID, account, beadID, detail string) error {
+	return t.store.AppendAudit(t.auditEntry(action, threadID, account, beadID, detail))
+}
+
+// auditEntry is an audit log entry for an action taken through the API.
+func (t *triager) auditEntry(action, threadID, account, beadID, detail string) *types.AuditEntry {
+	return &types.AuditEntry{
+		Actor:    t.actor,
+		Command:  "mailbeads API",
+		Action:   action,
+		ThreadID: threadID,
+		Account:  account,
+		BeadID:   beadID,
+		Detail:   detail,
+	}
+}